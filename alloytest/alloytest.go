@@ -0,0 +1,152 @@
+package alloytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/3-lines-studio/alloy"
+)
+
+type Fixture struct {
+	ServerJS string
+	ClientJS string
+	CSS      string
+}
+
+type Document struct {
+	Status int
+	Header http.Header
+	HTML   string
+	Title  string
+	RootID string
+	Root   string
+	Props  map[string]any
+}
+
+var (
+	titlePattern = regexp.MustCompile(`(?s)<title>(.*?)</title>`)
+	propsPattern = regexp.MustCompile(`(?s)<script id="([^"]+)-props" type="application/json">(.*?)</script>`)
+)
+
+func ServerBundle(body string) string {
+	return fmt.Sprintf("var __Component = { default: function(props) { %s } };", body)
+}
+
+func StaticFixture(markup string) Fixture {
+	return Fixture{
+		ServerJS: ServerBundle(fmt.Sprintf("return %q;", markup)),
+		ClientJS: "export {};",
+		CSS:      "body{}",
+	}
+}
+
+func Setup(t testing.TB, fixtures map[string]Fixture, options ...func(*alloy.Config)) {
+	t.Helper()
+
+	dist := filepath.ToSlash(alloy.DefaultDistDir)
+	fsys := fstest.MapFS{}
+	pages := map[string]alloy.ManifestPage{}
+	for component, fixture := range fixtures {
+		name := strings.TrimSuffix(filepath.Base(component), filepath.Ext(component))
+		if fixture.ClientJS == "" {
+			fixture.ClientJS = "export {};"
+		}
+		if fixture.CSS == "" {
+			fixture.CSS = "body{}"
+		}
+
+		page := alloy.ManifestPage{
+			Server: name + "-server.js",
+			Client: "client-" + name + ".js",
+			CSS:    name + ".css",
+		}
+		fsys[path.Join(dist, page.Server)] = &fstest.MapFile{Data: []byte(fixture.ServerJS)}
+		fsys[path.Join(dist, page.Client)] = &fstest.MapFile{Data: []byte(fixture.ClientJS)}
+		fsys[path.Join(dist, page.CSS)] = &fstest.MapFile{Data: []byte(fixture.CSS)}
+		pages[name] = page
+	}
+
+	manifest, err := json.Marshal(alloy.Manifest{Version: alloy.ManifestVersion, Pages: pages})
+	if err != nil {
+		t.Fatalf("encode manifest: %v", err)
+	}
+	fsys[path.Join(dist, "manifest.json")] = &fstest.MapFile{Data: manifest}
+
+	t.Cleanup(alloy.SaveConfig())
+	alloy.Init(fsys, options...)
+}
+
+func NewPage(t testing.TB, component string, fixture Fixture, options ...func(*alloy.Config)) *alloy.PageHandler {
+	t.Helper()
+	Setup(t, map[string]Fixture{component: fixture}, options...)
+	return alloy.NewPage(component)
+}
+
+func NewServer(t testing.TB, handler http.Handler) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(alloy.AssetsMiddleware()(handler))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func Get(t testing.TB, handler http.Handler, target string) *Document {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return Parse(t, rec.Code, rec.Header(), rec.Body.String())
+}
+
+func RenderPage(t testing.TB, component string, props map[string]any) *Document {
+	t.Helper()
+
+	serverJS, _, err := alloy.BuildServerBundle(component)
+	if err != nil {
+		t.Fatalf("build %s: %v", component, err)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(component), filepath.Ext(component))
+	files := alloy.PrebuiltFiles{Client: "client-" + name + ".js", CSS: name + ".css"}
+	out, err := alloy.PrerenderPage(serverJS, name+"-root", props, files)
+	if err != nil {
+		t.Fatalf("render %s: %v", component, err)
+	}
+	out = strings.ReplaceAll(out, ` nonce="__ALLOY_CSP_NONCE__"`, "")
+	return Parse(t, http.StatusOK, http.Header{}, out)
+}
+
+func Parse(t testing.TB, status int, header http.Header, markup string) *Document {
+	t.Helper()
+
+	doc := &Document{Status: status, Header: header, HTML: markup}
+	if match := titlePattern.FindStringSubmatch(markup); match != nil {
+		doc.Title = html.UnescapeString(strings.TrimSpace(match[1]))
+	}
+
+	match := propsPattern.FindStringSubmatchIndex(markup)
+	if match == nil {
+		return doc
+	}
+	doc.RootID = markup[match[2]:match[3]]
+	if err := json.Unmarshal([]byte(markup[match[4]:match[5]]), &doc.Props); err != nil {
+		t.Fatalf("decode props of %s: %v", doc.RootID, err)
+	}
+
+	open := fmt.Sprintf(`<div id="%s">`, doc.RootID)
+	if start := strings.Index(markup, open); start >= 0 && start < match[0] {
+		doc.Root = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(markup[start+len(open):match[0]]), "</div>"))
+	}
+	return doc
+}
+
+func (d *Document) Contains(s string) bool {
+	return strings.Contains(d.HTML, s)
+}
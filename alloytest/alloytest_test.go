@@ -0,0 +1,76 @@
+package alloytest
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/3-lines-studio/alloy"
+)
+
+func TestNewPageServesFixture(t *testing.T) {
+	page := NewPage(t, "app/pages/about.tsx", Fixture{
+		ServerJS: ServerBundle(`return "<h1>" + props.title + "</h1>";`),
+	}).WithLoader(func(r *http.Request) map[string]any {
+		return map[string]any{"title": "About & us"}
+	})
+
+	doc := Get(t, page, "/about")
+	if doc.Status != http.StatusOK {
+		t.Fatalf("status: %d", doc.Status)
+	}
+	if doc.Title != "About & us" {
+		t.Fatalf("title: %q", doc.Title)
+	}
+	if doc.RootID != "about-root" || doc.Root != "<h1>About & us</h1>" {
+		t.Fatalf("root %q: %q", doc.RootID, doc.Root)
+	}
+	if doc.Props["title"] != "About & us" {
+		t.Fatalf("props: %v", doc.Props)
+	}
+	if !doc.Contains(`src="/dist/build/client-about.js`) {
+		t.Fatalf("client script missing:\n%s", doc.HTML)
+	}
+}
+
+func TestNewServerServesPagesAndAssets(t *testing.T) {
+	page := NewPage(t, "app/pages/home.tsx", StaticFixture("<p>welcome</p>"))
+	server := NewServer(t, page)
+
+	res, err := http.Get(server.URL + "/")
+	if err != nil {
+		t.Fatalf("get page: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body), "<p>welcome</p>") {
+		t.Fatalf("page body: %s", body)
+	}
+
+	res, err = http.Get(server.URL + "/dist/build/home.css")
+	if err != nil {
+		t.Fatalf("get css: %v", err)
+	}
+	body, _ = io.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || string(body) != "body{}" {
+		t.Fatalf("css: %d %q", res.StatusCode, body)
+	}
+}
+
+func TestSetupRestoresConfig(t *testing.T) {
+	page := NewPage(t, "app/pages/outer.tsx", StaticFixture("<p>outer</p>"), func(cfg *alloy.Config) {
+		cfg.DefaultTitle = "Outer"
+	})
+	t.Run("inner", func(t *testing.T) {
+		NewPage(t, "app/pages/inner.tsx", StaticFixture("<p>inner</p>"), func(cfg *alloy.Config) {
+			cfg.DefaultTitle = "Inner"
+		})
+	})
+
+	doc := Get(t, page, "/")
+	if doc.Status != http.StatusOK || doc.Title != "Outer" || doc.Root != "<p>outer</p>" {
+		t.Fatalf("🔴 expected the outer config back, got %d %q %q", doc.Status, doc.Title, doc.Root)
+	}
+}
@@ -0,0 +1,104 @@
+package alloy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const metafileName = "metafile.json"
+
+type ModuleSize struct {
+	Path  string `json:"path"`
+	Bytes int64  `json:"bytes"`
+}
+
+type PageAnalysis struct {
+	Page    string       `json:"page"`
+	Bytes   int64        `json:"bytes"`
+	Modules []ModuleSize `json:"modules"`
+}
+
+func writeMetafile(dir string, metafile string) error {
+	if err := os.WriteFile(filepath.Join(dir, metafileName), []byte(metafile), 0644); err != nil {
+		return fmt.Errorf("🔴 write metafile: %w", err)
+	}
+	return nil
+}
+
+func AnalyzeBuild(distDir string) ([]PageAnalysis, error) {
+	data, err := os.ReadFile(filepath.Join(distDir, metafileName))
+	if err != nil {
+		return nil, fmt.Errorf("🔴 read metafile (run alloy build first): %w", err)
+	}
+	meta, err := parseMetafile(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := ReadManifest(os.DirFS(distDir), ".")
+	if err != nil {
+		return nil, err
+	}
+
+	outputsByName := map[string]string{}
+	for outPath := range meta.Outputs {
+		outputsByName[filepath.Base(outPath)] = outPath
+	}
+
+	pages := []PageAnalysis{}
+	for _, page := range sortedKeys(manifest.Pages) {
+		entry := manifest.Pages[page]
+		modules := map[string]int64{}
+		for _, name := range append([]string{entry.Client}, entry.Chunks...) {
+			outPath, ok := outputsByName[name]
+			if !ok {
+				continue
+			}
+			for input, info := range meta.Outputs[outPath].Inputs {
+				modules[displayModulePath(input)] += info.BytesInOutput
+			}
+		}
+
+		analysis := PageAnalysis{Page: page, Modules: []ModuleSize{}}
+		for path, bytes := range modules {
+			analysis.Modules = append(analysis.Modules, ModuleSize{Path: path, Bytes: bytes})
+			analysis.Bytes += bytes
+		}
+		sort.Slice(analysis.Modules, func(i, j int) bool {
+			if analysis.Modules[i].Bytes != analysis.Modules[j].Bytes {
+				return analysis.Modules[i].Bytes > analysis.Modules[j].Bytes
+			}
+			return analysis.Modules[i].Path < analysis.Modules[j].Path
+		})
+		pages = append(pages, analysis)
+	}
+	return pages, nil
+}
+
+func displayModulePath(input string) string {
+	input = filepath.ToSlash(input)
+	if i := strings.LastIndex(input, "node_modules/"); i >= 0 {
+		return input[i:]
+	}
+	for strings.HasPrefix(input, "../") {
+		input = strings.TrimPrefix(input, "../")
+	}
+	return input
+}
+
+func WriteAnalysisReport(w io.Writer, pages []PageAnalysis) error {
+	data, err := json.Marshal(pages)
+	if err != nil {
+		return fmt.Errorf("🔴 encode analysis: %w", err)
+	}
+	report := strings.Replace(MustReadAsset("assets/analyze.html"), "__ALLOY_ANALYSIS__", string(data), 1)
+	if _, err := io.WriteString(w, report); err != nil {
+		return fmt.Errorf("🔴 write analysis report: %w", err)
+	}
+	return nil
+}
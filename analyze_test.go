@@ -0,0 +1,48 @@
+package alloy
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeBuild(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "manifest.json"), `{
+		"home": {"server": "home-server.js", "client": "client-home-AAAA.js", "css": "shared.css", "chunks": ["chunk-BBBB.js"]}
+	}`)
+	writeTestFile(t, filepath.Join(dir, metafileName), `{
+		"outputs": {
+			"app/dist/alloy/client-home-AAAA.js": {
+				"inputs": {"app/pages/home.tsx": {"bytesInOutput": 120}, "../../tmp/alloy-clients-1/home.tsx": {"bytesInOutput": 30}}
+			},
+			"app/dist/alloy/chunk-BBBB.js": {
+				"inputs": {"node_modules/react/cjs/react.production.js": {"bytesInOutput": 7000}, "app/pages/home.tsx": {"bytesInOutput": 5}}
+			}
+		}
+	}`)
+
+	pages, err := AnalyzeBuild(dir)
+	if err != nil {
+		t.Fatalf("analyze: %v", err)
+	}
+	if len(pages) != 1 || pages[0].Page != "home" || pages[0].Bytes != 7155 {
+		t.Fatalf("unexpected analysis: %+v", pages)
+	}
+	modules := pages[0].Modules
+	if len(modules) != 3 || modules[0].Path != "node_modules/react/cjs/react.production.js" || modules[1].Bytes != 125 {
+		t.Fatalf("unexpected modules: %+v", modules)
+	}
+	if modules[2].Path != "tmp/alloy-clients-1/home.tsx" {
+		t.Fatalf("parent segments not trimmed: %s", modules[2].Path)
+	}
+
+	var out bytes.Buffer
+	if err := WriteAnalysisReport(&out, pages); err != nil {
+		t.Fatalf("report: %v", err)
+	}
+	if strings.Contains(out.String(), "__ALLOY_ANALYSIS__") || !strings.Contains(out.String(), `"page":"home"`) {
+		t.Fatal("analysis data not embedded in report")
+	}
+}
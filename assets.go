@@ -0,0 +1,259 @@
+package alloy
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+var assetETags sync.Map
+
+type assetETag struct {
+	modTime time.Time
+	size    int64
+	etag    string
+}
+
+var assetContentTypes = map[string]string{
+	".avif":        "image/avif",
+	".css":         "text/css; charset=utf-8",
+	".js":          "text/javascript; charset=utf-8",
+	".json":        "application/json",
+	".map":         "application/json",
+	".mjs":         "text/javascript; charset=utf-8",
+	".svg":         "image/svg+xml",
+	".wasm":        "application/wasm",
+	".webmanifest": "application/manifest+json",
+	".webp":        "image/webp",
+	".woff":        "font/woff",
+	".woff2":       "font/woff2",
+}
+
+type AssetRoot struct {
+	Prefix string
+	Dir    string
+}
+
+type AssetConfig struct {
+	Roots        []AssetRoot
+	CacheControl func(assetPath string, hashed bool) string
+	Fallback     http.Handler
+	Index        bool
+	Dotfiles     bool
+}
+
+func DefaultAssetRoots() []AssetRoot {
+	return []AssetRoot{
+		{Prefix: "", Dir: "public"},
+		{Prefix: DefaultDistDir, Dir: DefaultDistDir},
+	}
+}
+
+func AssetCacheControl(assetPath string, hashed bool) string {
+	if hashed {
+		return "public, max-age=31536000, immutable"
+	}
+	return "public, max-age=300"
+}
+
+func WithAssetRoots(roots ...AssetRoot) func(*AssetConfig) {
+	return func(ac *AssetConfig) {
+		ac.Roots = roots
+	}
+}
+
+func WithAssetCacheControl(fn func(assetPath string, hashed bool) string) func(*AssetConfig) {
+	return func(ac *AssetConfig) {
+		ac.CacheControl = fn
+	}
+}
+
+func WithAssetFallback(handler http.Handler) func(*AssetConfig) {
+	return func(ac *AssetConfig) {
+		ac.Fallback = handler
+	}
+}
+
+func WithAssetIndex() func(*AssetConfig) {
+	return func(ac *AssetConfig) {
+		ac.Index = true
+	}
+}
+
+func WithAssetDotfiles() func(*AssetConfig) {
+	return func(ac *AssetConfig) {
+		ac.Dotfiles = true
+	}
+}
+
+func AssetHandler(options ...func(*AssetConfig)) http.Handler {
+	return AssetsMiddleware(options...)(http.NotFoundHandler())
+}
+
+func newAssetConfig(options []func(*AssetConfig)) *AssetConfig {
+	ac := &AssetConfig{
+		Roots:        DefaultAssetRoots(),
+		CacheControl: AssetCacheControl,
+	}
+	for _, opt := range options {
+		if opt != nil {
+			opt(ac)
+		}
+	}
+	if ac.CacheControl == nil {
+		ac.CacheControl = AssetCacheControl
+	}
+	return ac
+}
+
+func serveAsset(w http.ResponseWriter, r *http.Request, filesystem fs.FS, ac *AssetConfig) bool {
+	isAllowedMethod := r.Method == http.MethodGet || r.Method == http.MethodHead
+	if !isAllowedMethod {
+		return false
+	}
+
+	assetPath := normalizeAssetPath(r.URL.Path)
+	if assetPath == "" && ac.Index && r.URL.Path == "/" {
+		assetPath = "."
+	}
+	if assetPath == "" {
+		return false
+	}
+
+	missed := false
+	for _, root := range collectAssetRoots(filesystem, ac.Roots) {
+		rel, ok := root.match(assetPath)
+		if !ok {
+			continue
+		}
+		if !ac.Dotfiles && hasDotfile(rel) {
+			missed = missed || root.prefix != ""
+			continue
+		}
+
+		index := false
+		if ac.Index && root.isDir(rel) {
+			if !strings.HasSuffix(r.URL.Path, "/") {
+				target := r.URL.Path + "/"
+				if r.URL.RawQuery != "" {
+					target += "?" + r.URL.RawQuery
+				}
+				http.Redirect(w, r, target, http.StatusMovedPermanently)
+				return true
+			}
+			rel, index = path.Join(dirOrRoot(rel), "index.html"), true
+		}
+		if !root.assetExists(rel) {
+			missed = missed || root.prefix != ""
+			continue
+		}
+
+		fullPath := rel
+		if root.prefix != "" {
+			fullPath = path.Join(root.prefix, rel)
+		}
+		setAssetContentType(w, rel)
+		addCacheHeaders(w, fullPath, root, rel, ac.CacheControl)
+		if index {
+			http.ServeFileFS(w, r, root.fs, rel)
+		} else {
+			root.serve(w, r, rel)
+		}
+		return true
+	}
+
+	if missed && ac.Fallback != nil {
+		ac.Fallback.ServeHTTP(w, r)
+		return true
+	}
+	return false
+}
+
+func collectAssetRoots(filesystem fs.FS, configured []AssetRoot) []assetRoot {
+	var roots []assetRoot
+	for _, root := range configured {
+		sub, err := fs.Sub(filesystem, root.Dir)
+		if err != nil {
+			continue
+		}
+		roots = append(roots, assetRoot{
+			prefix:     normalizeAssetPath(root.Prefix),
+			dir:        root.Dir,
+			fs:         sub,
+			fileServer: http.FileServer(http.FS(sub)),
+		})
+	}
+	return roots
+}
+
+func addCacheHeaders(w http.ResponseWriter, assetPath string, root assetRoot, relPath string, cacheControl func(string, bool) string) {
+	hashed := isHashedAsset(assetPath)
+	w.Header().Set("Cache-Control", cacheControl(assetPath, hashed))
+
+	etag, modTime := root.assetMeta(relPath, hashed)
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	if !modTime.IsZero() {
+		w.Header().Set("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+	}
+}
+
+func (r assetRoot) isDir(relPath string) bool {
+	if r.fs == nil {
+		return false
+	}
+	info, err := fs.Stat(r.fs, dirOrRoot(relPath))
+	return err == nil && info.IsDir()
+}
+
+func dirOrRoot(relPath string) string {
+	if relPath == "" {
+		return "."
+	}
+	return relPath
+}
+
+func hasDotfile(relPath string) bool {
+	for _, part := range strings.Split(relPath, "/") {
+		if strings.HasPrefix(part, ".") && part != "." && part != ".well-known" {
+			return true
+		}
+	}
+	return false
+}
+
+func setAssetContentType(w http.ResponseWriter, name string) {
+	if contentType, ok := assetContentTypes[strings.ToLower(path.Ext(name))]; ok {
+		w.Header().Set("Content-Type", contentType)
+	}
+}
+
+func (r assetRoot) contentETag(relPath string, info fs.FileInfo) string {
+	key := path.Join(r.dir, relPath)
+	if cached, ok := assetETags.Load(key); ok {
+		entry := cached.(assetETag)
+		if entry.size == info.Size() && entry.modTime.Equal(info.ModTime()) {
+			return entry.etag
+		}
+	}
+
+	f, err := r.fs.Open(relPath)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	h := sha1.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return ""
+	}
+	etag := fmt.Sprintf(`"%x"`, h.Sum(nil))
+	assetETags.Store(key, assetETag{modTime: info.ModTime(), size: info.Size(), etag: etag})
+	return etag
+}
@@ -1,10 +1,21 @@
-import { hydrateRoot } from 'react-dom/client';
+import { createRoot, hydrateRoot } from 'react-dom/client';
+import { __setPage } from '@alloy/client';
 import Component from '%s';
 
-const propsEl = document.getElementById('%s-props');
-const props = propsEl ? JSON.parse(propsEl.textContent || '{}') : {};
-const rootEl = document.getElementById('%s');
+var propsEl = document.getElementById('%s-props');
+var props = propsEl ? JSON.parse(propsEl.textContent || '{}') : {};
+var ctxEl = document.getElementById('__ALLOY_CTX__');
+(globalThis as any).__ALLOY_CTX__ = ctxEl ? JSON.parse(ctxEl.textContent || '{}') : {};
+var rootEl = document.getElementById('%s');
+
+var root: { render(children: any): void } | undefined;
+__setPage(props, (next) => root?.render(<Component {...next} />));
 
 if (rootEl) {
-	hydrateRoot(rootEl, <Component {...props} />);
+	if (rootEl.hasChildNodes()) {
+		root = hydrateRoot(rootEl, <Component {...props} />);
+	} else {
+		root = createRoot(rootEl);
+		root.render(<Component {...props} />);
+	}
 }
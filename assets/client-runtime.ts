@@ -0,0 +1,83 @@
+type Props = Record<string, any>;
+
+export type Navigation = { state: 'idle' | 'loading'; location?: string };
+
+var page: { props: Props; render: (props: Props) => void } = { props: {}, render: function() {} };
+var navigation: Navigation = { state: 'idle' };
+
+export function __setPage(props: Props, render?: (props: Props) => void) {
+	page = { props: props, render: render || function() {} };
+}
+
+export function useLoaderData<T = Props>(): T {
+	return page.props as T;
+}
+
+export function useRouteParams(): Record<string, string> {
+	return ((globalThis as any).__ALLOY_CTX__ || {}).params || {};
+}
+
+export function useNavigation(): Navigation {
+	return navigation;
+}
+
+export var binding: <T = any>(name: string, ...args: any[]) => T = function(name: string) {
+	var binding = ((globalThis as any).__ALLOY_BINDINGS__ || {})[name];
+	if (!binding) {
+		throw new Error(`binding ${name} is only available during server rendering`);
+	}
+	return binding.apply(null, Array.prototype.slice.call(arguments, 1));
+};
+
+export function buildID(): string {
+	if (typeof document === 'undefined') {
+		return '';
+	}
+	return document.querySelector('meta[name="alloy-build"]')?.getAttribute('content') || '';
+}
+
+export function checkBuild(res: Response): boolean {
+	var current = buildID();
+	var build = res.headers.get('X-Alloy-Build');
+	if (!current || !build || build === current) {
+		return true;
+	}
+	location.reload();
+	return false;
+}
+
+export function revalidate(): Promise<void> {
+	navigation = { state: 'loading', location: location.pathname + location.search };
+	page.render(page.props);
+	var done = function() {
+		navigation = { state: 'idle' };
+		page.render(page.props);
+	};
+	return fetch(location.href, { headers: { 'X-Alloy-Data': '1' }, cache: 'no-store' })
+		.then(function(res) {
+			if (!checkBuild(res)) {
+				return new Promise<void>(function() {});
+			}
+			if (!res.ok) {
+				throw new Error(`revalidate ${location.pathname}: ${res.status}`);
+			}
+			return res.json().then(function(props) {
+				page.props = props;
+			});
+		})
+		.then(done, function(err) {
+			done();
+			throw err;
+		});
+}
+
+export function subscribeLoaderData(): () => void {
+	var source = new EventSource(location.href);
+	source.addEventListener('props', function(event) {
+		page.props = { ...page.props, ...JSON.parse((event as MessageEvent).data) };
+		page.render(page.props);
+	});
+	return function() {
+		source.close();
+	};
+}
@@ -0,0 +1,46 @@
+const path = require('path');
+const readline = require('readline');
+const { createRequire } = require('module');
+
+const appRequire = createRequire(path.join(process.cwd(), 'index.js'));
+const bundles = new Map();
+
+globalThis.__alloyNode = { buffer: require('buffer'), util: require('util'), process, crypto: require('crypto') };
+
+for (const level of ['log', 'info', 'debug']) {
+	console[level] = console.error;
+}
+
+const send = (message) => process.stdout.write(JSON.stringify(message) + '\n');
+
+readline.createInterface({ input: process.stdin, crlfDelay: Infinity }).on('line', async (line) => {
+	let request;
+	try {
+		request = JSON.parse(line);
+	} catch (err) {
+		return;
+	}
+
+	try {
+		if (request.code) {
+			bundles.set(request.bundle, new Function('require', request.code + '\nreturn __Component;')(appRequire));
+		}
+		const component = bundles.get(request.bundle);
+		if (!component) {
+			send({ id: request.id, error: 'unknown bundle ' + request.bundle });
+			return;
+		}
+		globalThis.__ALLOY_CTX__ = request.context || {};
+		globalThis.__ALLOY_SLOTS__ = {};
+		const render = component.default || component;
+		const html = await render(request.props || {});
+		if (typeof html !== 'string') {
+			send({ id: request.id, error: 'render returned non-string: ' + String(html) });
+			return;
+		}
+		const head = typeof component.head === 'function' ? await component.head(request.props || {}) : undefined;
+		send({ id: request.id, html, head, slots: globalThis.__ALLOY_SLOTS__ });
+	} catch (err) {
+		send({ id: request.id, error: String((err && err.stack) || err) });
+	}
+});
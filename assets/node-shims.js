@@ -0,0 +1,220 @@
+var __alloyNode = (function() {
+	var B64 = 'ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/';
+
+	function utf8Encode(str) {
+		var raw = unescape(encodeURIComponent(str));
+		var out = [];
+		for (var i = 0; i < raw.length; i++) out.push(raw.charCodeAt(i));
+		return out;
+	}
+
+	function utf8Decode(bytes) {
+		var raw = '';
+		for (var i = 0; i < bytes.length; i++) raw += String.fromCharCode(bytes[i]);
+		try {
+			return decodeURIComponent(escape(raw));
+		} catch (e) {
+			return raw;
+		}
+	}
+
+	function base64Encode(bytes) {
+		var out = '';
+		for (var i = 0; i < bytes.length; i += 3) {
+			var n = (bytes[i] << 16) | ((bytes[i + 1] || 0) << 8) | (bytes[i + 2] || 0);
+			out += B64[(n >> 18) & 63] + B64[(n >> 12) & 63];
+			out += i + 1 < bytes.length ? B64[(n >> 6) & 63] : '=';
+			out += i + 2 < bytes.length ? B64[n & 63] : '=';
+		}
+		return out;
+	}
+
+	function base64Decode(str) {
+		str = String(str).replace(/-/g, '+').replace(/_/g, '/').replace(/[^A-Za-z0-9+/]/g, '');
+		var out = [];
+		for (var i = 0; i < str.length; i += 4) {
+			var n = 0;
+			for (var j = 0; j < 4; j++) n = (n << 6) | (i + j < str.length ? B64.indexOf(str[i + j]) : 0);
+			out.push((n >> 16) & 255);
+			if (i + 2 < str.length) out.push((n >> 8) & 255);
+			if (i + 3 < str.length) out.push(n & 255);
+		}
+		return out;
+	}
+
+	function Buffer(value, offset, length) {
+		var buf = offset === undefined ? new Uint8Array(value) : new Uint8Array(value, offset, length);
+		Object.setPrototypeOf(buf, Buffer.prototype);
+		return buf;
+	}
+	Object.setPrototypeOf(Buffer.prototype, Uint8Array.prototype);
+	Object.setPrototypeOf(Buffer, Uint8Array);
+
+	Buffer.from = function(value, encoding) {
+		if (typeof value === 'string') {
+			switch (encoding) {
+				case 'hex':
+					var hex = [];
+					for (var i = 0; i + 1 < value.length; i += 2) hex.push(parseInt(value.substr(i, 2), 16));
+					return Buffer(hex);
+				case 'base64':
+				case 'base64url':
+					return Buffer(base64Decode(value));
+				case 'latin1':
+				case 'binary':
+				case 'ascii':
+					return Buffer(value.split('').map(function(c) { return c.charCodeAt(0) & 255; }));
+				default:
+					return Buffer(utf8Encode(value));
+			}
+		}
+		if (value instanceof ArrayBuffer) return Buffer(new Uint8Array(value));
+		return Buffer(Array.prototype.slice.call(value));
+	};
+	Buffer.alloc = function(size, fill) {
+		var buf = Buffer(size);
+		if (fill !== undefined) buf.fill(typeof fill === 'number' ? fill : Buffer.from(String(fill))[0]);
+		return buf;
+	};
+	Buffer.allocUnsafe = Buffer.alloc;
+	Buffer.isBuffer = function(value) { return value instanceof Buffer; };
+	Buffer.byteLength = function(value, encoding) { return typeof value === 'string' ? Buffer.from(value, encoding).length : value.byteLength; };
+	Buffer.concat = function(list, length) {
+		var total = length === undefined ? list.reduce(function(n, b) { return n + b.length; }, 0) : length;
+		var out = Buffer.alloc(total);
+		var offset = 0;
+		list.forEach(function(b) {
+			out.set(b.subarray(0, Math.max(0, total - offset)), offset);
+			offset += b.length;
+		});
+		return out;
+	};
+	Buffer.prototype.toString = function(encoding, start, end) {
+		var bytes = this.subarray(start || 0, end === undefined ? this.length : end);
+		switch (encoding) {
+			case 'hex':
+				return Array.prototype.map.call(bytes, function(b) { return (b < 16 ? '0' : '') + b.toString(16); }).join('');
+			case 'base64':
+				return base64Encode(bytes);
+			case 'base64url':
+				return base64Encode(bytes).replace(/\+/g, '-').replace(/\//g, '_').replace(/=+$/, '');
+			case 'latin1':
+			case 'binary':
+			case 'ascii':
+				return Array.prototype.map.call(bytes, function(b) { return String.fromCharCode(b); }).join('');
+			default:
+				return utf8Decode(bytes);
+		}
+	};
+	Buffer.prototype.equals = function(other) {
+		if (this.length !== other.length) return false;
+		for (var i = 0; i < this.length; i++) if (this[i] !== other[i]) return false;
+		return true;
+	};
+	Buffer.prototype.toJSON = function() { return { type: 'Buffer', data: Array.prototype.slice.call(this) }; };
+
+	function inspect(value) {
+		if (typeof value === 'string') return "'" + value + "'";
+		if (typeof value === 'function') return '[Function: ' + (value.name || 'anonymous') + ']';
+		try {
+			var json = JSON.stringify(value);
+			return json === undefined ? String(value) : json;
+		} catch (e) {
+			return String(value);
+		}
+	}
+
+	var util = {
+		inspect: inspect,
+		format: function(fmt) {
+			var args = Array.prototype.slice.call(arguments, 1);
+			var out = typeof fmt === 'string' ? fmt.replace(/%[sdifjoO%]/g, function(token) {
+				if (token === '%%') return '%';
+				if (args.length === 0) return token;
+				var arg = args.shift();
+				switch (token) {
+					case '%s': return String(arg);
+					case '%d':
+					case '%i': return String(parseInt(arg, 10));
+					case '%f': return String(parseFloat(arg));
+					default: return inspect(arg);
+				}
+			}) : inspect(fmt);
+			return args.reduce(function(s, arg) { return s + ' ' + (typeof arg === 'string' ? arg : inspect(arg)); }, out);
+		},
+		inherits: function(ctor, superCtor) {
+			ctor.super_ = superCtor;
+			Object.setPrototypeOf(ctor.prototype, superCtor.prototype);
+		},
+		promisify: function(fn) {
+			return function() {
+				var self = this;
+				var args = Array.prototype.slice.call(arguments);
+				return new Promise(function(resolve, reject) {
+					fn.apply(self, args.concat(function(err, value) { err ? reject(err) : resolve(value); }));
+				});
+			};
+		},
+		deprecate: function(fn) { return fn; },
+		isDeepStrictEqual: function(a, b) { return JSON.stringify(a) === JSON.stringify(b); },
+		types: {
+			isDate: function(v) { return v instanceof Date; },
+			isRegExp: function(v) { return v instanceof RegExp; },
+			isPromise: function(v) { return v instanceof Promise; },
+			isUint8Array: function(v) { return v instanceof Uint8Array; }
+		},
+		TextEncoder: TextEncoder,
+		TextDecoder: TextDecoder
+	};
+
+	process.env = Object.assign(__alloyNodeEnv, process.env);
+	process.argv = process.argv || [];
+	process.platform = process.platform || 'linux';
+	process.version = process.version || '';
+	process.versions = process.versions || {};
+	process.browser = false;
+	process.cwd = process.cwd || function() { return '/'; };
+	process.nextTick = process.nextTick || function(fn) {
+		var args = Array.prototype.slice.call(arguments, 1);
+		queueMicrotask(function() { fn.apply(null, args); });
+	};
+
+	function bytesOf(data) {
+		if (data instanceof ArrayBuffer) return new Uint8Array(data);
+		return new Uint8Array(data.buffer, data.byteOffset, data.byteLength);
+	}
+
+	function getRandomValues(array) {
+		bytesOf(array).set(new Uint8Array(__alloyRandomBytes(array.byteLength)));
+		return array;
+	}
+
+	var crypto = {
+		getRandomValues: getRandomValues,
+		randomUUID: function() {
+			var b = getRandomValues(new Uint8Array(16));
+			b[6] = (b[6] & 15) | 64;
+			b[8] = (b[8] & 63) | 128;
+			var hex = Buffer.from(b).toString('hex');
+			return hex.slice(0, 8) + '-' + hex.slice(8, 12) + '-' + hex.slice(12, 16) + '-' + hex.slice(16, 20) + '-' + hex.slice(20);
+		},
+		randomBytes: function(size) { return Buffer.from(new Uint8Array(__alloyRandomBytes(size))); },
+		subtle: {
+			digest: function(algorithm, data) {
+				var name = typeof algorithm === 'string' ? algorithm : algorithm.name;
+				return new Promise(function(resolve) { resolve(__alloyDigest(String(name).toUpperCase(), bytesOf(data))); });
+			}
+		}
+	};
+	crypto.webcrypto = crypto;
+
+	if (typeof globalThis.Buffer === 'undefined') globalThis.Buffer = Buffer;
+	if (typeof globalThis.crypto === 'undefined') globalThis.crypto = crypto;
+
+	return {
+		buffer: { Buffer: Buffer },
+		util: util,
+		process: process,
+		crypto: crypto
+	};
+})();
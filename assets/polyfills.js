@@ -23,3 +23,93 @@ TextDecoder.prototype.decode = function(arr) {
 	for (var i = 0; i < arr.length; i++) str += String.fromCharCode(arr[i]);
 	return str;
 };
+
+var __alloyTimers = (function() {
+	var queue = [];
+	var nextID = 1;
+
+	function schedule(fn, delay, args, repeat) {
+		var id = nextID++;
+		delay = Math.max(0, Number(delay) || 0);
+		queue.push({ id: id, at: Date.now() + delay, delay: delay, fn: fn, args: args, repeat: repeat });
+		return id;
+	}
+
+	function cancel(id) {
+		for (var i = 0; i < queue.length; i++) {
+			if (queue[i].id === id) {
+				queue.splice(i, 1);
+				return;
+			}
+		}
+	}
+
+	return {
+		schedule: schedule,
+		cancel: cancel,
+		reset: function() {
+			queue = [];
+		},
+		next: function() {
+			if (queue.length === 0) return -1;
+			var at = queue[0].at;
+			for (var i = 1; i < queue.length; i++) {
+				if (queue[i].at < at) at = queue[i].at;
+			}
+			return Math.max(0, at - Date.now());
+		},
+		run: function() {
+			var now = Date.now();
+			var due = queue.filter(function(t) { return t.at <= now; });
+			due.sort(function(a, b) { return a.at - b.at || a.id - b.id; });
+			for (var i = 0; i < due.length; i++) {
+				var timer = due[i];
+				if (queue.indexOf(timer) < 0) continue;
+				cancel(timer.id);
+				if (timer.repeat) {
+					timer.at = Date.now() + Math.max(1, timer.delay);
+					queue.push(timer);
+				}
+				if (typeof timer.fn === 'function') timer.fn.apply(globalThis, timer.args);
+			}
+		}
+	};
+})();
+
+var __alloyGlobals = (function() {
+	var saved = null;
+
+	return {
+		snapshot: function() {
+			saved = Object.create(null);
+			Object.getOwnPropertyNames(globalThis).forEach(function(name) {
+				saved[name] = Object.getOwnPropertyDescriptor(globalThis, name);
+			});
+		},
+		restore: function() {
+			if (saved === null) return;
+			Object.getOwnPropertyNames(globalThis).forEach(function(name) {
+				var before = saved[name];
+				var now = Object.getOwnPropertyDescriptor(globalThis, name);
+				if (before === undefined) {
+					if (now.configurable) delete globalThis[name];
+					else if (now.writable) globalThis[name] = undefined;
+				} else if (now.value !== before.value || now.get !== before.get || now.set !== before.set) {
+					if (now.configurable) Object.defineProperty(globalThis, name, before);
+					else if (now.writable) globalThis[name] = before.value;
+				}
+			});
+			for (var name in saved) {
+				if (!Object.prototype.hasOwnProperty.call(globalThis, name)) Object.defineProperty(globalThis, name, saved[name]);
+			}
+		}
+	};
+})();
+
+var setTimeout = function(fn, delay) { return __alloyTimers.schedule(fn, delay, Array.prototype.slice.call(arguments, 2), false); };
+var setInterval = function(fn, delay) { return __alloyTimers.schedule(fn, delay, Array.prototype.slice.call(arguments, 2), true); };
+var setImmediate = function(fn) { return __alloyTimers.schedule(fn, 0, Array.prototype.slice.call(arguments, 1), false); };
+var clearTimeout = function(id) { __alloyTimers.cancel(id); };
+var clearInterval = clearTimeout;
+var clearImmediate = clearTimeout;
+var queueMicrotask = function(fn) { Promise.resolve().then(fn); };
@@ -1,6 +1,11 @@
 import { renderToString } from 'react-dom/server.edge';
-import Component from '%s';
+import { __setPage } from '@alloy/client';
+import Component, * as page from '%s';
+
+export const renderMode = (page as any).renderMode;
+export const revalidate = (page as any).revalidate;
 
 export default function render(props: any) {
+	__setPage(props);
 	return renderToString(<Component {...props} />);
 }
@@ -0,0 +1,149 @@
+package alloy
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAssetHandlerOptions(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "static", "logo.svg"), "<svg></svg>")
+	writeTestFile(t, filepath.Join(root, "dist", "build", "client-home-AAAAAAAA.js"), "console.log(1)")
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+	})
+
+	handler := AssetHandler(
+		WithAssetRoots(AssetRoot{Prefix: "/assets", Dir: "static"}, AssetRoot{Prefix: DefaultDistDir, Dir: DefaultDistDir}),
+		WithAssetCacheControl(func(assetPath string, hashed bool) string {
+			if hashed {
+				return "public, max-age=60"
+			}
+			return "no-cache"
+		}),
+		WithAssetFallback(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "gone", http.StatusGone)
+		})),
+	)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/assets/logo.svg", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Cache-Control") != "no-cache" {
+		t.Fatalf("🔴 expected custom root with custom cache policy, got %d %q", rec.Code, rec.Header().Get("Cache-Control"))
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/dist/build/client-home-AAAAAAAA.js", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Cache-Control") != "public, max-age=60" {
+		t.Fatalf("🔴 expected hashed cache policy, got %d %q", rec.Code, rec.Header().Get("Cache-Control"))
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/dist/build/missing.js", nil))
+	if rec.Code != http.StatusGone {
+		t.Fatalf("🔴 expected fallback for a missing asset, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	AssetsMiddleware()(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/dist/build/client-home-AAAAAAAA.js", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Cache-Control") != AssetCacheControl("", true) {
+		t.Fatalf("🔴 expected default middleware to serve dist, got %d %q", rec.Code, rec.Header().Get("Cache-Control"))
+	}
+}
+
+func TestAssetHandlerIndexTypesAndDotfiles(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "public", "index.html"), "<p>home</p>")
+	writeTestFile(t, filepath.Join(root, "public", "docs", "index.html"), "<p>docs</p>")
+	writeTestFile(t, filepath.Join(root, "public", "app.wasm"), "\x00asm")
+	writeTestFile(t, filepath.Join(root, "public", "font.woff2"), "wOF2")
+	writeTestFile(t, filepath.Join(root, "public", ".env"), "SECRET=1")
+	writeTestFile(t, filepath.Join(root, "public", ".well-known", "security.txt"), "Contact: mailto:security@example.com")
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+	})
+
+	get := func(handler http.Handler, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	handler := AssetHandler(WithAssetIndex())
+	for target, body := range map[string]string{"/": "<p>home</p>", "/docs/": "<p>docs</p>"} {
+		if rec := get(handler, target); rec.Code != http.StatusOK || rec.Body.String() != body {
+			t.Fatalf("🔴 expected index for %s, got %d: %s", target, rec.Code, rec.Body.String())
+		}
+	}
+	if rec := get(handler, "/docs?tab=1"); rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/docs/?tab=1" {
+		t.Fatalf("🔴 expected directory redirect, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+	if rec := get(AssetHandler(), "/docs/"); rec.Code != http.StatusNotFound {
+		t.Fatalf("🔴 expected no index by default, got %d", rec.Code)
+	}
+
+	for target, contentType := range map[string]string{"/app.wasm": "application/wasm", "/font.woff2": "font/woff2"} {
+		if rec := get(handler, target); rec.Header().Get("Content-Type") != contentType {
+			t.Fatalf("🔴 expected %s for %s, got %q", contentType, target, rec.Header().Get("Content-Type"))
+		}
+	}
+
+	if rec := get(handler, "/.env"); rec.Code != http.StatusNotFound {
+		t.Fatalf("🔴 expected dotfile to be rejected, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := get(handler, "/.well-known/security.txt"); rec.Code != http.StatusOK {
+		t.Fatalf("🔴 expected .well-known to be served, got %d", rec.Code)
+	}
+	if rec := get(AssetHandler(WithAssetDotfiles()), "/.env"); rec.Code != http.StatusOK {
+		t.Fatalf("🔴 expected dotfile with WithAssetDotfiles, got %d", rec.Code)
+	}
+}
+
+func TestAssetRangeAndConditionalRequests(t *testing.T) {
+	root := t.TempDir()
+	video := strings.Repeat("0123456789", 1<<16)
+	writeTestFile(t, filepath.Join(root, "public", "clip.mp4"), video)
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+	})
+	handler := AssetHandler()
+
+	get := func(header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/clip.mp4", nil)
+		req.Header = header
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get(http.Header{})
+	etag, modified := rec.Header().Get("ETag"), rec.Header().Get("Last-Modified")
+	if rec.Code != http.StatusOK || etag == "" || modified == "" || rec.Header().Get("Accept-Ranges") != "bytes" {
+		t.Fatalf("🔴 expected full response with validators, got %d %v", rec.Code, rec.Header())
+	}
+
+	rec = get(http.Header{"Range": {"bytes=10-19"}})
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != "0123456789" || rec.Header().Get("Content-Range") != fmt.Sprintf("bytes 10-19/%d", len(video)) {
+		t.Fatalf("🔴 expected partial content, got %d %q %q", rec.Code, rec.Header().Get("Content-Range"), rec.Body.String())
+	}
+
+	if rec = get(http.Header{"If-None-Match": {etag}}); rec.Code != http.StatusNotModified {
+		t.Fatalf("🔴 expected 304 for If-None-Match, got %d", rec.Code)
+	}
+	if rec = get(http.Header{"If-Modified-Since": {modified}}); rec.Code != http.StatusNotModified {
+		t.Fatalf("🔴 expected 304 for If-Modified-Since, got %d", rec.Code)
+	}
+	if rec = get(http.Header{"Range": {"bytes=0-9"}, "If-Range": {`"stale"`}}); rec.Code != http.StatusOK || rec.Body.Len() != len(video) {
+		t.Fatalf("🔴 expected full body for a stale If-Range, got %d with %d bytes", rec.Code, rec.Body.Len())
+	}
+
+	writeTestFile(t, filepath.Join(root, "public", "clip.mp4"), video+"!")
+	if rec = get(http.Header{"If-None-Match": {etag}}); rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+		t.Fatalf("🔴 expected a new ETag after the file changed, got %d %q", rec.Code, rec.Header().Get("ETag"))
+	}
+}
@@ -0,0 +1,209 @@
+package alloy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const defaultBenchRequests = 200
+
+type BenchOptions struct {
+	Concurrency int
+	Requests    int
+	Duration    time.Duration
+}
+
+type BenchReport struct {
+	Target      string
+	InProcess   bool
+	Concurrency int
+	Requests    int
+	Errors      int
+	FirstError  error
+	Elapsed     time.Duration
+	Min         time.Duration
+	P50         time.Duration
+	P95         time.Duration
+	P99         time.Duration
+	Max         time.Duration
+	AllocsPerOp uint64
+	BytesPerOp  uint64
+	Runtimes    int64
+}
+
+func LoadPageBundle(distDir string, page string) (string, error) {
+	filesystem, err := unpackFS(os.DirFS("."), distDir)
+	if err != nil {
+		return "", err
+	}
+	manifest, err := ReadManifest(filesystem, distDir)
+	if err != nil {
+		return "", err
+	}
+
+	name := page
+	if ext := filepath.Ext(name); ext == ".tsx" || ext == ".jsx" {
+		name = strings.TrimSuffix(filepath.Base(name), ext)
+	}
+	entry, ok := manifest.Pages[name]
+	if !ok || entry.Server == "" {
+		return "", fmt.Errorf("🔴 page %s not found in %s", page, FormatPath(distDir))
+	}
+
+	serverJS, err := readPrebuiltFile(filesystem, joinPath(filepath.ToSlash(distDir), entry.Server))
+	if err != nil {
+		return "", fmt.Errorf("🔴 read server bundle: %w", err)
+	}
+	return string(serverJS), nil
+}
+
+func BenchPage(serverJS string, props map[string]any, opts BenchOptions) BenchReport {
+	if props == nil {
+		props = map[string]any{}
+	}
+	report := runBench(opts, true, func(ctx context.Context) error {
+		_, err := executeSSR(ctx, serverJS, props)
+		return err
+	})
+	return report
+}
+
+func BenchURL(url string, opts BenchOptions) BenchReport {
+	client := &http.Client{Timeout: 30 * time.Second}
+	report := runBench(opts, false, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
+		io.Copy(io.Discard, res.Body)
+		if res.StatusCode >= 400 {
+			return fmt.Errorf("🔴 %s: %s", url, res.Status)
+		}
+		return nil
+	})
+	report.Target = url
+	return report
+}
+
+func runBench(opts BenchOptions, inProcess bool, fn func(ctx context.Context) error) BenchReport {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = runtime.NumCPU()
+	}
+	if opts.Requests <= 0 && opts.Duration <= 0 {
+		opts.Requests = defaultBenchRequests
+	}
+
+	ctx := context.Background()
+	if opts.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Duration)
+		defer cancel()
+	}
+
+	var (
+		mu         sync.Mutex
+		latencies  []time.Duration
+		errs       int
+		firstError error
+		issued     atomic.Int64
+		wg         sync.WaitGroup
+	)
+
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	runtimesBefore := runtimesCreated.Load()
+	start := time.Now()
+
+	for range opts.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				if opts.Requests > 0 && issued.Add(1) > int64(opts.Requests) {
+					return
+				}
+				began := time.Now()
+				err := fn(context.WithoutCancel(ctx))
+				elapsed := time.Since(began)
+
+				mu.Lock()
+				latencies = append(latencies, elapsed)
+				if err != nil {
+					errs++
+					if firstError == nil {
+						firstError = err
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	report := BenchReport{
+		InProcess:   inProcess,
+		Concurrency: opts.Concurrency,
+		Requests:    len(latencies),
+		Errors:      errs,
+		FirstError:  firstError,
+		Elapsed:     elapsed,
+	}
+	if len(latencies) == 0 {
+		return report
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	report.Min = latencies[0]
+	report.P50 = percentile(latencies, 0.50)
+	report.P95 = percentile(latencies, 0.95)
+	report.P99 = percentile(latencies, 0.99)
+	report.Max = latencies[len(latencies)-1]
+	if inProcess {
+		n := uint64(len(latencies))
+		report.AllocsPerOp = (after.Mallocs - before.Mallocs) / n
+		report.BytesPerOp = (after.TotalAlloc - before.TotalAlloc) / n
+		report.Runtimes = runtimesCreated.Load() - runtimesBefore
+	}
+	return report
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(i, 0)]
+}
+
+func (r BenchReport) Print(w io.Writer) {
+	rps := 0.0
+	if r.Elapsed > 0 {
+		rps = float64(r.Requests) / r.Elapsed.Seconds()
+	}
+	fmt.Fprintf(w, "  %-12s %d (%d errors) in %s, %.1f req/s, concurrency %d\n", "requests", r.Requests, r.Errors, r.Elapsed.Round(time.Millisecond), rps, r.Concurrency)
+	fmt.Fprintf(w, "  %-12s min %s  p50 %s  p95 %s  p99 %s  max %s\n", "latency", r.Min, r.P50, r.P95, r.P99, r.Max)
+	if r.InProcess {
+		fmt.Fprintf(w, "  %-12s %d allocs/op, %s/op\n", "memory", r.AllocsPerOp, FormatSize(int64(r.BytesPerOp)))
+		fmt.Fprintf(w, "  %-12s %d created (%.2f per render)\n", "runtimes", r.Runtimes, float64(r.Runtimes)/float64(max(r.Requests, 1)))
+	}
+	if r.FirstError != nil {
+		fmt.Fprintf(w, "  %-12s %v\n", "first error", r.FirstError)
+	}
+}
@@ -0,0 +1,72 @@
+package alloy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBenchPage(t *testing.T) {
+	serverJS := `var __Component = { default: function(props) { return "<p>" + props.name + "</p>"; } };`
+	report := BenchPage(serverJS, map[string]any{"name": "bench"}, BenchOptions{Concurrency: 2, Requests: 20})
+
+	if report.Requests != 20 || report.Errors != 0 {
+		t.Fatalf("report: %+v", report)
+	}
+	if report.Runtimes != 20 {
+		t.Fatalf("runtimes: want 20, got %d", report.Runtimes)
+	}
+	if report.Min > report.P50 || report.P50 > report.P99 || report.P99 > report.Max {
+		t.Fatalf("latencies out of order: %+v", report)
+	}
+
+	var b strings.Builder
+	report.Print(&b)
+	for _, want := range []string{"p95", "allocs/op", "runtimes"} {
+		if !strings.Contains(b.String(), want) {
+			t.Fatalf("report missing %s:\n%s", want, b.String())
+		}
+	}
+}
+
+func TestBenchURLCountsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			http.Error(w, "boom", http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	if report := BenchURL(server.URL+"/", BenchOptions{Concurrency: 2, Duration: 50 * time.Millisecond}); report.Requests == 0 || report.Errors != 0 {
+		t.Fatalf("ok report: %+v", report)
+	}
+	if report := BenchURL(server.URL+"/broken", BenchOptions{Requests: 3}); report.Errors != 3 || report.FirstError == nil {
+		t.Fatalf("error report: %+v", report)
+	}
+}
+
+func TestLoadPageBundle(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "dist", "home-server.js"), "var __Component = {};")
+	writeTestFile(t, filepath.Join(root, "dist", "manifest.json"), `{"home": {"server": "home-server.js"}}`)
+
+	wd, _ := os.Getwd()
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	for _, page := range []string{"home", "app/pages/home.tsx"} {
+		serverJS, err := LoadPageBundle("dist", page)
+		if err != nil || serverJS != "var __Component = {};" {
+			t.Fatalf("load %s: %q %v", page, serverJS, err)
+		}
+	}
+	if _, err := LoadPageBundle("dist", "missing"); err == nil {
+		t.Fatal("expected error for unknown page")
+	}
+}
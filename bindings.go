@@ -0,0 +1,132 @@
+package alloy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/buke/quickjs-go"
+)
+
+type Binding func(ctx context.Context, args []any) (any, error)
+
+type bindingsContextKey struct{}
+
+type activeRender struct {
+	ctx      context.Context
+	bindings map[string]Binding
+}
+
+var activeBindings sync.Map
+
+const bindingsSource = `globalThis.__ALLOY_BINDINGS__ = {};
+globalThis.__alloyBindings = function(names) {
+	var bindings = {};
+	names.forEach(function(name) {
+		bindings[name] = function() {
+			return JSON.parse(__alloyCallBinding(name, JSON.stringify(Array.prototype.slice.call(arguments))));
+		};
+	});
+	return bindings;
+};
+`
+
+func WithBindings(ctx context.Context, bindings map[string]Binding) context.Context {
+	if len(bindings) == 0 {
+		return ctx
+	}
+	existing, _ := ctx.Value(bindingsContextKey{}).(map[string]Binding)
+	merged := maps.Clone(existing)
+	if merged == nil {
+		merged = map[string]Binding{}
+	}
+	maps.Copy(merged, bindings)
+	return context.WithValue(ctx, bindingsContextKey{}, merged)
+}
+
+func (h *PageHandler) WithBinding(name string, fn Binding) *PageHandler {
+	if h.bindings == nil {
+		h.bindings = map[string]Binding{}
+	}
+	h.bindings[name] = fn
+	return h
+}
+
+func renderBindings(ctx context.Context) map[string]Binding {
+	bindings := map[string]Binding{}
+	if cfg := getConfig(); cfg != nil {
+		maps.Copy(bindings, cfg.Bindings)
+	}
+	if scoped, ok := ctx.Value(bindingsContextKey{}).(map[string]Binding); ok {
+		maps.Copy(bindings, scoped)
+	}
+	return bindings
+}
+
+func loadBindings(js *quickjs.Context) error {
+	js.Globals().Set("__alloyCallBinding", js.NewFunction(func(js *quickjs.Context, this *quickjs.Value, args []*quickjs.Value) *quickjs.Value {
+		if len(args) < 2 {
+			return js.ThrowError(fmt.Errorf("🔴 binding name and arguments required"))
+		}
+		name := args[0].String()
+		active, ok := activeBindings.Load(js)
+		if !ok {
+			return js.ThrowError(fmt.Errorf("🔴 binding %s called outside a render", name))
+		}
+		render := active.(activeRender)
+		fn := render.bindings[name]
+		if fn == nil {
+			return js.ThrowError(fmt.Errorf("🔴 unknown binding %s", name))
+		}
+
+		var callArgs []any
+		if err := json.Unmarshal([]byte(args[1].String()), &callArgs); err != nil {
+			return js.ThrowError(fmt.Errorf("🔴 decode binding %s arguments: %w", name, err))
+		}
+		result, err := fn(render.ctx, callArgs)
+		if err != nil {
+			return js.ThrowError(fmt.Errorf("🔴 binding %s: %w", name, err))
+		}
+		encoded, err := json.Marshal(result)
+		if err != nil {
+			return js.ThrowError(fmt.Errorf("🔴 encode binding %s result: %w", name, err))
+		}
+		return js.NewString(string(encoded))
+	}))
+
+	result := js.Eval(bindingsSource)
+	if result.IsException() {
+		return fmt.Errorf("🔴 bindings: %s", js.Exception().Error())
+	}
+	result.Free()
+	return nil
+}
+
+func installBindings(ctx context.Context, js *quickjs.Context) (func(), error) {
+	bindings := renderBindings(ctx)
+	if len(bindings) == 0 {
+		return func() {}, nil
+	}
+
+	names := sortedKeys(bindings)
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = strconv.Quote(name)
+	}
+	installed := js.Eval("globalThis.__ALLOY_BINDINGS__ = __alloyBindings([" + strings.Join(quoted, ",") + "])")
+	if installed.IsException() {
+		installed.Free()
+		return nil, fmt.Errorf("🔴 install bindings: %s", js.Exception())
+	}
+	installed.Free()
+	activeBindings.Store(js, activeRender{ctx: ctx, bindings: bindings})
+
+	return func() {
+		activeBindings.Delete(js)
+		js.Eval("globalThis.__ALLOY_BINDINGS__ = {}").Free()
+	}, nil
+}
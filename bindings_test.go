@@ -0,0 +1,62 @@
+package alloy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBindingsCallGoDuringSSR(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	withTestConfig(t, func(cfg *Config) {
+		cfg.Bindings = map[string]Binding{
+			"formatMoney": func(ctx context.Context, args []any) (any, error) {
+				return fmt.Sprintf("$%.2f", args[0].(float64)/100), nil
+			},
+		}
+	})
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react", "jsx-runtime.js"), `exports.jsx = (type, props) => ({ type, props });`+"\n")
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react-dom", "server.edge.js"), `exports.renderToString = (el) => String(el.type(el.props));`+"\n")
+	component := filepath.Join(dir, "app", "pages", "cart.tsx")
+	writeTestFile(t, component, `import { binding } from "@alloy/client";
+export default function Cart({ cents }: { cents: number }) {
+  return binding<string>("translate", "total") + ": " + binding<string>("formatMoney", cents);
+}
+`)
+
+	serverJS, _, err := BuildServerBundle(component)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type localeKey struct{}
+	ctx := WithBindings(context.WithValue(context.Background(), localeKey{}, "fr"), map[string]Binding{
+		"translate": func(ctx context.Context, args []any) (any, error) {
+			return map[string]string{"fr": "Total"}[ctx.Value(localeKey{}).(string)] + " (" + args[0].(string) + ")", nil
+		},
+	})
+	out, err := executeSSR(ctx, serverJS, map[string]any{"cents": 1999})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.HTML != "Total (total): $19.99" {
+		t.Fatalf("html = %q", out.HTML)
+	}
+
+	if _, err := executeSSR(context.Background(), serverJS, map[string]any{"cents": 1}); err == nil || !strings.Contains(err.Error(), "binding translate") {
+		t.Fatalf("expected missing binding error, got %v", err)
+	}
+
+	failing := WithBindings(context.Background(), map[string]Binding{
+		"translate": func(ctx context.Context, args []any) (any, error) {
+			return nil, errors.New("catalog offline")
+		},
+	})
+	if _, err := executeSSR(failing, serverJS, map[string]any{"cents": 1}); err == nil || !strings.Contains(err.Error(), "catalog offline") {
+		t.Fatalf("expected binding error, got %v", err)
+	}
+}
@@ -0,0 +1,622 @@
+package alloy
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+type BuildCache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Put(ctx context.Context, key string, data []byte) error
+}
+
+func OpenBuildCache(spec string) (BuildCache, error) {
+	u, err := url.Parse(spec)
+	if err != nil || u.Scheme == "" || len(u.Scheme) == 1 {
+		return DirCache(spec), nil
+	}
+
+	if u.Scheme != "file" && os.Getenv("ALLOY_BUILD_CACHE_SECRET") == "" {
+		logger().Warn("remote build cache entries are trusted as is; set ALLOY_BUILD_CACHE_SECRET to sign them", "cache", u.Redacted())
+	}
+	prefix := strings.Trim(u.Path, "/")
+	switch u.Scheme {
+	case "s3":
+		cache := S3CacheFromEnv(u.Host, prefix)
+		return cache, nil
+	case "gs":
+		cache := S3CacheFromEnv(u.Host, prefix)
+		cache.Endpoint = "https://storage.googleapis.com"
+		cache.Region = "auto"
+		return cache, nil
+	case "http", "https":
+		return &HTTPCache{URL: strings.TrimSuffix(spec, "/")}, nil
+	case "file":
+		return DirCache(u.Path), nil
+	}
+	return nil, fmt.Errorf("🔴 unknown build cache %q: want a directory, s3://, gs:// or http(s)://", spec)
+}
+
+type DirCache string
+
+func (d DirCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	data, err := os.ReadFile(filepath.Join(string(d), filepath.FromSlash(key)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("🔴 read build cache: %w", err)
+	}
+	return data, true, nil
+}
+
+func (d DirCache) Upload(ctx context.Context, key string, data []byte, header http.Header) error {
+	return d.Put(ctx, key, data)
+}
+
+func (d DirCache) Put(ctx context.Context, key string, data []byte) error {
+	file := filepath.Join(string(d), filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("🔴 write build cache: %w", err)
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("🔴 write build cache: %w", err)
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		return fmt.Errorf("🔴 write build cache: %w", err)
+	}
+	return nil
+}
+
+type HTTPCache struct {
+	URL    string
+	Header http.Header
+	Client *http.Client
+}
+
+func (c *HTTPCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return cacheRequest(ctx, c.client(), http.MethodGet, c.URL+"/"+key, nil, func(req *http.Request) error {
+		copyHeader(req.Header, c.Header)
+		return nil
+	})
+}
+
+func (c *HTTPCache) Put(ctx context.Context, key string, data []byte) error {
+	return c.Upload(ctx, key, data, nil)
+}
+
+func (c *HTTPCache) Upload(ctx context.Context, key string, data []byte, header http.Header) error {
+	_, _, err := cacheRequest(ctx, c.client(), http.MethodPut, c.URL+"/"+key, data, func(req *http.Request) error {
+		copyHeader(req.Header, c.Header)
+		copyHeader(req.Header, header)
+		return nil
+	})
+	return err
+}
+
+func (c *HTTPCache) client() *http.Client {
+	if c.Client != nil {
+		return c.Client
+	}
+	return http.DefaultClient
+}
+
+type S3Cache struct {
+	Endpoint     string
+	Bucket       string
+	Prefix       string
+	Region       string
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	Client       *http.Client
+}
+
+func S3CacheFromEnv(bucket, prefix string) *S3Cache {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+	endpoint := os.Getenv("AWS_ENDPOINT_URL")
+	if endpoint == "" {
+		endpoint = "https://s3." + region + ".amazonaws.com"
+	}
+	return &S3Cache{
+		Endpoint:     endpoint,
+		Bucket:       bucket,
+		Prefix:       prefix,
+		Region:       region,
+		AccessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+	}
+}
+
+func (c *S3Cache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return cacheRequest(ctx, c.client(), http.MethodGet, c.objectURL(key), nil, func(req *http.Request) error {
+		c.sign(req, nil, time.Now())
+		return nil
+	})
+}
+
+func (c *S3Cache) Put(ctx context.Context, key string, data []byte) error {
+	return c.Upload(ctx, key, data, nil)
+}
+
+func (c *S3Cache) Upload(ctx context.Context, key string, data []byte, header http.Header) error {
+	_, _, err := cacheRequest(ctx, c.client(), http.MethodPut, c.objectURL(key), data, func(req *http.Request) error {
+		copyHeader(req.Header, header)
+		c.sign(req, data, time.Now())
+		return nil
+	})
+	return err
+}
+
+func (c *S3Cache) client() *http.Client {
+	if c.Client != nil {
+		return c.Client
+	}
+	return http.DefaultClient
+}
+
+func (c *S3Cache) objectURL(key string) string {
+	return strings.TrimSuffix(c.Endpoint, "/") + "/" + c.Bucket + "/" + path.Join(c.Prefix, key)
+}
+
+func (c *S3Cache) sign(req *http.Request, body []byte, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	signed := "host;x-amz-content-sha256;x-amz-date"
+	headers := fmt.Sprintf("host:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\n", req.URL.Host, payloadHash, amzDate)
+	if c.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.SessionToken)
+		signed += ";x-amz-security-token"
+		headers += "x-amz-security-token:" + c.SessionToken + "\n"
+	}
+
+	canonical := strings.Join([]string{req.Method, req.URL.EscapedPath(), req.URL.RawQuery, headers, signed, payloadHash}, "\n")
+	scope := day + "/" + c.Region + "/s3/aws4_request"
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonical))
+
+	key := hmacSHA256([]byte("AWS4"+c.SecretKey), day)
+	key = hmacSHA256(key, c.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", c.AccessKey, scope, signed, signature))
+}
+
+type LayeredCache []BuildCache
+
+func (l LayeredCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	for i, cache := range l {
+		data, ok, err := cache.Get(ctx, key)
+		if err != nil {
+			logger().Warn("build cache", "key", key, "err", err)
+			continue
+		}
+		if ok {
+			for _, lower := range l[:i] {
+				lower.Put(ctx, key, data)
+			}
+			return data, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func (l LayeredCache) Put(ctx context.Context, key string, data []byte) error {
+	var errs []error
+	for _, cache := range l {
+		if err := cache.Put(ctx, key, data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+var lockfiles = []string{"package-lock.json", "pnpm-lock.yaml", "yarn.lock", "bun.lock", "bun.lockb"}
+
+type cachedBuild struct {
+	Deps    []string        `json:"deps"`
+	Sum     string          `json:"sum,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+type cachedClientBuild struct {
+	Assets map[string]ClientAssets `json:"assets"`
+	Files  map[string][]byte       `json:"files"`
+}
+
+func BuildServerBundleCached(ctx context.Context, cache BuildCache, filePath string) (string, []string, bool, error) {
+	if cache == nil {
+		serverJS, deps, err := BuildServerBundle(filePath)
+		return serverJS, deps, false, err
+	}
+
+	cwd, _ := os.Getwd()
+	fingerprint := buildFingerprint("server", relativeTo(cwd, filePath))
+
+	if deps, payload, ok := cacheLookup(ctx, cache, "server", cwd, fingerprint); ok {
+		var serverJS string
+		if json.Unmarshal(payload, &serverJS) == nil && serverJS != "" {
+			return serverJS, absolutePaths(cwd, deps), true, nil
+		}
+	}
+
+	serverJS, deps, err := BuildServerBundle(filePath)
+	if err != nil {
+		return "", nil, false, err
+	}
+
+	relDeps := make([]string, 0, len(deps))
+	for _, dep := range deps {
+		relDeps = append(relDeps, relativeTo(cwd, dep))
+	}
+	payload, _ := json.Marshal(serverJS)
+	if err := cacheStore(ctx, cache, "server", cwd, fingerprint, relDeps, payload); err != nil {
+		logger().Warn("build cache", "page", pageName(filePath), "err", err)
+	}
+	return serverJS, deps, false, nil
+}
+
+func BuildClientBundlesCached(ctx context.Context, cache BuildCache, entries []ClientEntry, outDir string) (map[string]ClientAssets, bool, error) {
+	if cache == nil {
+		assets, err := BuildClientBundles(entries, outDir)
+		return assets, false, err
+	}
+
+	cwd, _ := os.Getwd()
+	absOut, err := resolveAbsPath(outDir, "out dir")
+	if err != nil {
+		return nil, false, err
+	}
+	inputs := []string{"out=" + relativeTo(cwd, absOut)}
+	for _, e := range entries {
+		inputs = append(inputs, fmt.Sprintf("entry %s=%s#%s", e.Name, relativeTo(cwd, e.Component), e.RootID))
+	}
+	fingerprint := buildFingerprint("client", inputs...)
+
+	if _, payload, ok := cacheLookup(ctx, cache, "client", cwd, fingerprint); ok {
+		var build cachedClientBuild
+		if json.Unmarshal(payload, &build) == nil && len(build.Assets) > 0 {
+			if err := writeCachedClientFiles(absOut, build.Files); err != nil {
+				return nil, false, err
+			}
+			for name, assets := range build.Assets {
+				assets.Sources = absolutePaths(cwd, assets.Sources)
+				build.Assets[name] = assets
+			}
+			return build.Assets, true, nil
+		}
+	}
+
+	before := snapshotDir(absOut)
+	assets, err := BuildClientBundles(entries, outDir)
+	if err != nil {
+		return nil, false, err
+	}
+
+	deps, ok := clientCacheDeps(cwd, assets)
+	if !ok {
+		return assets, false, nil
+	}
+	build := cachedClientBuild{Assets: map[string]ClientAssets{}, Files: map[string][]byte{}}
+	for name, page := range assets {
+		sources := make([]string, 0, len(page.Sources))
+		for _, source := range page.Sources {
+			sources = append(sources, relativeTo(cwd, source))
+		}
+		page.Sources = sources
+		build.Assets[name] = page
+	}
+	for rel, info := range snapshotDir(absOut) {
+		if prev, ok := before[rel]; ok && prev == info {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(absOut, filepath.FromSlash(rel)))
+		if err != nil {
+			return assets, false, nil
+		}
+		build.Files[rel] = data
+	}
+	payload, _ := json.Marshal(build)
+	if err := cacheStore(ctx, cache, "client", cwd, fingerprint, deps, payload); err != nil {
+		logger().Warn("build cache", "kind", "client", "err", err)
+	}
+	return assets, false, nil
+}
+
+func clientCacheDeps(cwd string, assets map[string]ClientAssets) ([]string, bool) {
+	seen := map[string]bool{}
+	for _, page := range assets {
+		if len(page.Sources) == 0 {
+			return nil, false
+		}
+		for _, source := range page.Sources {
+			if rel := relativeTo(cwd, source); !strings.HasPrefix(rel, "../") {
+				seen[rel] = true
+			}
+		}
+	}
+	for _, file := range catalogFiles() {
+		seen[relativeTo(cwd, file)] = true
+	}
+	for _, name := range lockfiles {
+		if fileExists(filepath.Join(cwd, name)) {
+			seen[name] = true
+		}
+	}
+	return sortedKeys(seen), true
+}
+
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+func snapshotDir(dir string) map[string]fileStamp {
+	files := map[string]fileStamp{}
+	filepath.WalkDir(dir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if rel, err := filepath.Rel(dir, file); err == nil {
+			files[filepath.ToSlash(rel)] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+		}
+		return nil
+	})
+	return files
+}
+
+func writeCachedClientFiles(dir string, files map[string][]byte) error {
+	outputs := make([]api.OutputFile, 0, len(files))
+	for _, rel := range sortedKeys(files) {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if inside, err := filepath.Rel(dir, path); err != nil || filepath.IsAbs(rel) || inside == "." || inside == ".." || strings.HasPrefix(inside, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("🔴 cached client file %q escapes %s", rel, dir)
+		}
+		outputs = append(outputs, api.OutputFile{Path: path, Contents: files[rel]})
+	}
+	if err := scanOutputFiles(outputs, getConfig().SecretPatterns); err != nil {
+		return err
+	}
+	return writeOutputFiles(outputs)
+}
+
+func cacheLookup(ctx context.Context, cache BuildCache, kind string, cwd string, fingerprint string) ([]string, []byte, bool) {
+	data, ok, err := cache.Get(ctx, cacheIndexKey(fingerprint))
+	if err != nil || !ok {
+		return nil, nil, false
+	}
+	var index cachedBuild
+	if json.Unmarshal(data, &index) != nil {
+		return nil, nil, false
+	}
+	closure, ok := closureHash(cwd, fingerprint, index.Deps)
+	if !ok {
+		return nil, nil, false
+	}
+	key := "alloy/" + kind + "/" + closure
+	data, ok, err = cache.Get(ctx, key)
+	if err != nil || !ok {
+		return nil, nil, false
+	}
+	var entry cachedBuild
+	if json.Unmarshal(data, &entry) != nil || len(entry.Payload) == 0 {
+		return nil, nil, false
+	}
+	if !hmac.Equal([]byte(entry.Sum), []byte(cacheSum(entry.Payload))) {
+		logger().Warn("build cache entry failed its integrity check", "key", key)
+		return nil, nil, false
+	}
+	return entry.Deps, entry.Payload, true
+}
+
+func cacheStore(ctx context.Context, cache BuildCache, kind string, cwd string, fingerprint string, deps []string, payload []byte) error {
+	closure, ok := closureHash(cwd, fingerprint, deps)
+	if !ok {
+		return nil
+	}
+	entry, _ := json.Marshal(cachedBuild{Deps: deps, Sum: cacheSum(payload), Payload: payload})
+	index, _ := json.Marshal(cachedBuild{Deps: deps})
+	if err := cache.Put(ctx, "alloy/"+kind+"/"+closure, entry); err != nil {
+		return err
+	}
+	return cache.Put(ctx, cacheIndexKey(fingerprint), index)
+}
+
+func cacheIndexKey(fingerprint string) string {
+	return "alloy/index/" + sha256Hex([]byte(fingerprint))
+}
+
+func cacheSum(payload []byte) string {
+	if secret := os.Getenv("ALLOY_BUILD_CACHE_SECRET"); secret != "" {
+		return "hmac-sha256:" + hex.EncodeToString(hmacSHA256([]byte(secret), string(payload)))
+	}
+	return "sha256:" + sha256Hex(payload)
+}
+
+func buildFingerprint(kind string, inputs ...string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "alloy=%s\nkind=%s\n", buildToolVersion(), kind)
+	for _, input := range inputs {
+		fmt.Fprintf(&b, "input %s\n", filepath.ToSlash(input))
+	}
+	env := PublicEnv()
+	for _, key := range sortedKeys(env) {
+		fmt.Fprintf(&b, "env %s=%s\n", key, env[key])
+	}
+
+	opts := commonBuildOptions()
+	applyServerPlatform(&opts)
+	platform := "browser"
+	if opts.Platform == api.PlatformNode {
+		platform = "node"
+	}
+	fmt.Fprintf(&b, "platform=%s\ntarget=%d\nminify=%t,%t,%t\n", platform, opts.Target, opts.MinifyWhitespace, opts.MinifyIdentifiers, opts.MinifySyntax)
+	fmt.Fprintf(&b, "renderer=%T\nbundler=%T\n", configuredRenderer(), configuredBundler())
+
+	if cfg := getConfig(); cfg != nil {
+		for _, name := range sortedKeys(cfg.ServerExternals) {
+			fmt.Fprintf(&b, "external %s=%s\n", name, cfg.ServerExternals[name])
+		}
+		for _, plugin := range cfg.BuildPlugins {
+			fmt.Fprintf(&b, "plugin %s\n", plugin.Name)
+		}
+		fmt.Fprintf(&b, "tsconfig=%s\n", cfg.Tsconfig)
+		if cfg.NodeShims {
+			b.WriteString("node-shims\n")
+		}
+		if kind == "client" {
+			imports := configImportMap()
+			for _, name := range sortedKeys(imports) {
+				fmt.Fprintf(&b, "import %s=%s\n", name, imports[name])
+			}
+			for _, name := range sortedKeys(cfg.VendorChunks) {
+				fmt.Fprintf(&b, "vendor %s=%s\n", name, strings.Join(cfg.VendorChunks[name], ","))
+			}
+		}
+	}
+	if locales, _ := DiscoverLocales(localesDir()); len(locales) > 0 {
+		fmt.Fprintf(&b, "locales=%s\n", strings.Join(locales, ","))
+	}
+	return b.String()
+}
+
+var buildToolVersion = sync.OnceValue(func() string {
+	version := alloyVersion()
+	if version != "" && version != "(devel)" && !alloyReplacedLocally() {
+		return version
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return version
+	}
+	data, err := os.ReadFile(exe)
+	if err != nil {
+		return version
+	}
+	return version + "+" + sha256Hex(data)
+})
+
+func alloyReplacedLocally() bool {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return false
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == alloyModule {
+			return dep.Replace != nil && dep.Replace.Version == ""
+		}
+	}
+	return false
+}
+
+func closureHash(cwd string, fingerprint string, deps []string) (string, bool) {
+	h := sha256.New()
+	io.WriteString(h, fingerprint)
+	for _, dep := range deps {
+		contents, err := os.ReadFile(filepath.Join(cwd, filepath.FromSlash(dep)))
+		if err != nil {
+			return "", false
+		}
+		sum := sha256.Sum256(contents)
+		fmt.Fprintf(h, "%s %x\n", dep, sum)
+	}
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+func relativeTo(base, file string) string {
+	if abs, err := filepath.Abs(file); err == nil {
+		file = abs
+	}
+	if rel, err := filepath.Rel(base, file); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(file)
+}
+
+func absolutePaths(base string, rel []string) []string {
+	abs := make([]string, 0, len(rel))
+	for _, p := range rel {
+		abs = append(abs, filepath.Join(base, filepath.FromSlash(p)))
+	}
+	return abs
+}
+
+func cacheRequest(ctx context.Context, client *http.Client, method, target string, body []byte, prepare func(*http.Request) error) ([]byte, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, false, fmt.Errorf("🔴 build cache request: %w", err)
+	}
+	if err := prepare(req); err != nil {
+		return nil, false, err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("🔴 build cache %s: %w", method, err)
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, false, fmt.Errorf("🔴 build cache %s: %w", method, err)
+	}
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return nil, false, nil
+	case res.StatusCode >= 300:
+		return nil, false, fmt.Errorf("🔴 build cache %s %s: status %d", method, req.URL.Path, res.StatusCode)
+	}
+	return data, true, nil
+}
+
+func copyHeader(dst, src http.Header) {
+	for key, values := range src {
+		for _, value := range values {
+			dst.Add(key, value)
+		}
+	}
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
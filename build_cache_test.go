@@ -0,0 +1,291 @@
+package alloy
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDirCacheRoundTrip(t *testing.T) {
+	cache := DirCache(t.TempDir())
+	ctx := context.Background()
+
+	if _, ok, err := cache.Get(ctx, "alloy/server/abc"); err != nil || ok {
+		t.Fatalf("Get on empty cache = %v, %v", ok, err)
+	}
+	if err := cache.Put(ctx, "alloy/server/abc", []byte("bundle")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	data, ok, err := cache.Get(ctx, "alloy/server/abc")
+	if err != nil || !ok || string(data) != "bundle" {
+		t.Fatalf("Get = %q, %v, %v", data, ok, err)
+	}
+}
+
+func TestS3CacheSignsRequests(t *testing.T) {
+	var mu sync.Mutex
+	objects := map[string][]byte{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(auth, "/eu-west-1/s3/aws4_request") || !strings.Contains(auth, "x-amz-security-token") {
+			t.Errorf("Authorization = %q", auth)
+		}
+		if r.Header.Get("X-Amz-Security-Token") != "token" {
+			t.Errorf("missing session token")
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			if r.Header.Get("X-Amz-Content-Sha256") != sha256Hex(body) {
+				t.Errorf("payload hash mismatch")
+			}
+			objects[r.URL.Path] = body
+		case http.MethodGet:
+			data, ok := objects[r.URL.Path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write(data)
+		}
+	}))
+	defer server.Close()
+
+	cache := &S3Cache{Endpoint: server.URL, Bucket: "ci", Prefix: "alloy-cache", Region: "eu-west-1", AccessKey: "AKID", SecretKey: "secret", SessionToken: "token"}
+	ctx := context.Background()
+
+	if _, ok, err := cache.Get(ctx, "a"); err != nil || ok {
+		t.Fatalf("Get missing = %v, %v", ok, err)
+	}
+	if err := cache.Put(ctx, "a", []byte("bundle")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, ok := objects["/ci/alloy-cache/a"]; !ok {
+		t.Fatalf("objects = %v", sortedKeys(objects))
+	}
+	data, ok, err := cache.Get(ctx, "a")
+	if err != nil || !ok || string(data) != "bundle" {
+		t.Fatalf("Get = %q, %v, %v", data, ok, err)
+	}
+}
+
+func TestOpenBuildCache(t *testing.T) {
+	t.Setenv("AWS_REGION", "us-west-2")
+	t.Setenv("AWS_ENDPOINT_URL", "")
+
+	cache, err := OpenBuildCache("s3://ci-cache/alloy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s3, ok := cache.(*S3Cache)
+	if !ok || s3.Bucket != "ci-cache" || s3.Prefix != "alloy" || s3.Endpoint != "https://s3.us-west-2.amazonaws.com" {
+		t.Fatalf("s3 cache = %#v", cache)
+	}
+
+	cache, err = OpenBuildCache("gs://ci-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gs := cache.(*S3Cache); gs.Endpoint != "https://storage.googleapis.com" || gs.Region != "auto" {
+		t.Fatalf("gs cache = %#v", gs)
+	}
+
+	if cache, _ := OpenBuildCache(".alloy/cache"); cache != DirCache(".alloy/cache") {
+		t.Fatalf("dir cache = %#v", cache)
+	}
+	if _, err := OpenBuildCache("ftp://host/cache"); err == nil {
+		t.Fatal("expected error for unknown scheme")
+	}
+}
+
+func TestLayeredCacheBackfills(t *testing.T) {
+	local, remote := DirCache(t.TempDir()), DirCache(t.TempDir())
+	ctx := context.Background()
+	remote.Put(ctx, "k", []byte("v"))
+
+	data, ok, err := LayeredCache{local, remote}.Get(ctx, "k")
+	if err != nil || !ok || string(data) != "v" {
+		t.Fatalf("Get = %q, %v, %v", data, ok, err)
+	}
+	if data, ok, _ := local.Get(ctx, "k"); !ok || string(data) != "v" {
+		t.Fatal("expected local cache to be backfilled")
+	}
+}
+
+func TestBuildServerBundleCached(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react", "jsx-runtime.js"), `exports.jsx = (type, props) => ({ type, props });`+"\n")
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react-dom", "server.edge.js"), `exports.renderToString = (el) => String(el.type(el.props));`+"\n")
+	writeTestFile(t, filepath.Join(dir, "app", "pages", "greeting.ts"), `export const greeting = "hello";`+"\n")
+	writeTestFile(t, filepath.Join(dir, "app", "pages", "home.tsx"), `import { greeting } from "./greeting";
+export default function Home() { return greeting; }
+`)
+	component := filepath.Join(dir, "app", "pages", "home.tsx")
+	cache := DirCache(filepath.Join(dir, ".cache"))
+	ctx := context.Background()
+
+	first, _, hit, err := BuildServerBundleCached(ctx, cache, component)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hit {
+		t.Fatal("first build should miss")
+	}
+
+	second, deps, hit, err := BuildServerBundleCached(ctx, cache, component)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hit || second != first {
+		t.Fatalf("second build hit = %v", hit)
+	}
+	found := false
+	for _, dep := range deps {
+		if dep == filepath.Join(dir, "app", "pages", "greeting.ts") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("deps = %v", deps)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "app", "pages", "greeting.ts"), []byte(`export const greeting = "bye";`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	third, _, hit, err := BuildServerBundleCached(ctx, cache, component)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hit || !strings.Contains(third, "bye") {
+		t.Fatalf("changed dependency should miss, hit = %v", hit)
+	}
+}
+
+func TestBuildServerBundleCachedRejectsTamperedEntries(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react", "jsx-runtime.js"), `exports.jsx = (type, props) => ({ type, props });`+"\n")
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react-dom", "server.edge.js"), `exports.renderToString = (el) => String(el.type(el.props));`+"\n")
+	writeTestFile(t, filepath.Join(dir, "app", "pages", "home.tsx"), `export default function Home() { return "home"; }`+"\n")
+	component := filepath.Join(dir, "app", "pages", "home.tsx")
+	cacheDir := filepath.Join(dir, ".cache")
+	ctx := context.Background()
+
+	if _, _, _, err := BuildServerBundleCached(ctx, DirCache(cacheDir), component); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := filepath.Glob(filepath.Join(cacheDir, "alloy", "server", "*"))
+	if len(entries) != 1 {
+		t.Fatalf("entries = %v", entries)
+	}
+	data, _ := os.ReadFile(entries[0])
+	if !strings.Contains(string(data), `\"home\"`) {
+		t.Fatalf("entry = %s", data)
+	}
+	os.WriteFile(entries[0], []byte(strings.ReplaceAll(string(data), `\"home\"`, `\"evil\"`)), 0644)
+
+	serverJS, _, hit, err := BuildServerBundleCached(ctx, DirCache(cacheDir), component)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hit || strings.Contains(serverJS, "evil") {
+		t.Fatalf("🔴 tampered entry was used, hit = %v", hit)
+	}
+
+	t.Setenv("ALLOY_BUILD_CACHE_SECRET", "ci-secret")
+	if _, _, hit, _ := BuildServerBundleCached(ctx, DirCache(cacheDir), component); hit {
+		t.Fatal("🔴 entry without the secret's hmac should miss")
+	}
+	if _, _, hit, _ := BuildServerBundleCached(ctx, DirCache(cacheDir), component); !hit {
+		t.Fatal("🔴 expected entry signed with the secret to hit")
+	}
+}
+
+func TestBuildFingerprintCoversRendererAndBundler(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) {})
+	base := buildFingerprint("server", "app/pages/home.tsx")
+	if !strings.Contains(base, "platform=browser") || !strings.Contains(base, "minify=true,false,true") {
+		t.Fatalf("🔴 fingerprint missing build options:\n%s", base)
+	}
+
+	getConfig().Renderer = &NodeRenderer{}
+	node := buildFingerprint("server", "app/pages/home.tsx")
+	if node == base || !strings.Contains(node, "platform=node") || !strings.Contains(node, "renderer=*alloy.NodeRenderer") {
+		t.Fatalf("🔴 renderer not in fingerprint:\n%s", node)
+	}
+
+	getConfig().Bundler = &fakeBundler{}
+	if custom := buildFingerprint("server", "app/pages/home.tsx"); !strings.Contains(custom, "bundler=*alloy.fakeBundler") {
+		t.Fatalf("🔴 bundler not in fingerprint:\n%s", custom)
+	}
+}
+
+func TestBuildClientBundlesCached(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react", "jsx-runtime.js"), "exports.jsx = () => null;\n")
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react-dom", "client.js"), "exports.hydrateRoot = () => null; exports.createRoot = () => null;\n")
+	writeTestFile(t, filepath.Join(dir, "app", "pages", "greeting.ts"), `export const greeting = "hello";`+"\n")
+	writeTestFile(t, filepath.Join(dir, "app", "pages", "home.tsx"), `import { greeting } from "./greeting";
+export default function Home() { return greeting; }
+`)
+	withTestConfig(t, func(cfg *Config) {})
+	entries := []ClientEntry{{Name: "home", Component: filepath.Join(dir, "app", "pages", "home.tsx"), RootID: "home"}}
+	cache := DirCache(filepath.Join(dir, ".cache"))
+	ctx := context.Background()
+	distDir := filepath.Join(dir, "dist", "build")
+
+	first, hit, err := BuildClientBundlesCached(ctx, cache, entries, distDir)
+	if err != nil || hit {
+		t.Fatalf("first build hit = %v, err = %v", hit, err)
+	}
+	if err := os.RemoveAll(distDir); err != nil {
+		t.Fatal(err)
+	}
+
+	second, hit, err := BuildClientBundlesCached(ctx, cache, entries, distDir)
+	if err != nil || !hit {
+		t.Fatalf("🔴 second build hit = %v, err = %v", hit, err)
+	}
+	if second["home"].Entry != first["home"].Entry || strings.Join(second["home"].Sources, ",") != strings.Join(first["home"].Sources, ",") {
+		t.Fatalf("🔴 cached assets = %#v, want %#v", second["home"], first["home"])
+	}
+	entry := filepath.Join(distDir, filepath.Base(second["home"].Entry))
+	if data, err := os.ReadFile(entry); err != nil || !strings.Contains(string(data), "hello") {
+		t.Fatalf("🔴 expected cached client bundle to be restored: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(distDir, metafileName)); err != nil {
+		t.Fatalf("🔴 expected cached metafile: %v", err)
+	}
+
+	writeTestFile(t, filepath.Join(dir, "app", "pages", "greeting.ts"), `export const greeting = "bye";`+"\n")
+	if _, hit, err := BuildClientBundlesCached(ctx, cache, entries, distDir); err != nil || hit {
+		t.Fatalf("🔴 changed source should miss, hit = %v, err = %v", hit, err)
+	}
+}
+
+func TestWriteCachedClientFilesStaysInOutDir(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) {})
+	root := t.TempDir()
+	out := filepath.Join(root, "dist", "build")
+	for _, rel := range []string{"../evil.js", "chunks/../../../evil.js", "/evil.js", "."} {
+		if err := writeCachedClientFiles(out, map[string][]byte{rel: []byte("evil")}); err == nil {
+			t.Fatalf("🔴 expected %q to be rejected", rel)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "dist", "evil.js")); err == nil {
+		t.Fatal("🔴 cached file written outside the output dir")
+	}
+	if err := writeCachedClientFiles(out, map[string][]byte{"chunks/a.js": []byte("ok")}); err != nil {
+		t.Fatalf("🔴 nested path rejected: %v", err)
+	}
+}
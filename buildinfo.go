@@ -0,0 +1,109 @@
+package alloy
+
+import (
+	"os"
+	"os/exec"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	alloyModule = "github.com/3-lines-studio/alloy"
+	BuildHeader = "X-Alloy-Build"
+)
+
+type BuildMetadata struct {
+	Commit       string    `json:"commit,omitempty"`
+	BuiltAt      time.Time `json:"builtAt"`
+	AlloyVersion string    `json:"alloyVersion,omitempty"`
+}
+
+func BuildInfo() BuildMetadata {
+	cfg := getConfig()
+	if cfg == nil || cfg.FS == nil {
+		return BuildMetadata{AlloyVersion: alloyVersion()}
+	}
+	manifest, err := ReadManifest(cfg.FS, cfg.DistDir)
+	if err != nil {
+		return BuildMetadata{AlloyVersion: alloyVersion()}
+	}
+	return BuildMetadata{Commit: manifest.Commit, BuiltAt: manifest.BuiltAt, AlloyVersion: manifest.AlloyVersion}
+}
+
+func (b BuildMetadata) ID() string {
+	if b.BuiltAt.IsZero() {
+		return ""
+	}
+	return shortHash(b.Commit + b.BuiltAt.UTC().Format(time.RFC3339Nano))
+}
+
+func BuildID() string {
+	return cachedBuildInfo().ID()
+}
+
+func (b BuildMetadata) generator() string {
+	generator := "alloy"
+	if b.AlloyVersion != "" {
+		generator += " " + b.AlloyVersion
+	}
+	if b.Commit != "" {
+		generator += " (" + shortCommit(b.Commit) + ")"
+	}
+	return generator
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
+
+var alloyVersion = sync.OnceValue(func() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	if info.Main.Path == alloyModule {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == alloyModule {
+			if dep.Replace != nil && dep.Replace.Version != "" {
+				return dep.Replace.Version
+			}
+			return dep.Version
+		}
+	}
+	return ""
+})
+
+var gitCommit = sync.OnceValue(func() string {
+	if commit := os.Getenv("ALLOY_GIT_SHA"); commit != "" {
+		return commit
+	}
+	out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+})
+
+var buildMetadataCache = struct {
+	sync.Mutex
+	cfg  *Config
+	info BuildMetadata
+}{}
+
+func cachedBuildInfo() BuildMetadata {
+	cfg := getConfig()
+	buildMetadataCache.Lock()
+	defer buildMetadataCache.Unlock()
+	if buildMetadataCache.cfg != cfg || os.Getenv("ALLOY_DEV") == "1" {
+		buildMetadataCache.cfg = cfg
+		buildMetadataCache.info = BuildInfo()
+	}
+	return buildMetadataCache.info
+}
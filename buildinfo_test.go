@@ -0,0 +1,64 @@
+package alloy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUpdateManifestRecordsBuildInfo(t *testing.T) {
+	t.Setenv("ALLOY_GIT_SHA", "0123456789abcdef0123456789abcdef01234567")
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "home-server.js"), "server")
+	if err := updateManifest(filepath.Join(dist, "manifest.json"), map[string]ManifestPage{"home": {Server: "home-server.js"}}); err != nil {
+		t.Fatalf("update manifest: %v", err)
+	}
+
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+		cfg.DistDir = "dist/build"
+	})
+
+	info := BuildInfo()
+	if gitCommit() != "" && info.Commit != gitCommit() {
+		t.Fatalf("commit: want %s, got %s", gitCommit(), info.Commit)
+	}
+	if info.BuiltAt.IsZero() {
+		t.Fatalf("built at not recorded: %+v", info)
+	}
+
+	head := buildHead(map[string]any{})
+	if !strings.Contains(head, `<meta name="generator" content="alloy`) {
+		t.Fatalf("generator meta missing:\n%s", head)
+	}
+	if info.Commit != "" && !strings.Contains(head, "("+shortCommit(info.Commit)+")") {
+		t.Fatalf("generator meta missing commit:\n%s", head)
+	}
+	if id := info.ID(); id == "" || !strings.Contains(head, `<meta name="alloy-build" content="`+id+`">`) {
+		t.Fatalf("build id %q missing from head:\n%s", id, head)
+	}
+}
+
+func TestBuildIDChangesPerBuild(t *testing.T) {
+	first := BuildMetadata{Commit: "abc", BuiltAt: time.Unix(1, 0)}
+	second := BuildMetadata{Commit: "abc", BuiltAt: time.Unix(2, 0)}
+	if first.ID() == "" || first.ID() == second.ID() {
+		t.Fatalf("ids: %q %q", first.ID(), second.ID())
+	}
+	if id := (BuildMetadata{}).ID(); id != "" {
+		t.Fatalf("id without manifest: %q", id)
+	}
+}
+
+func TestBuildMetadataGenerator(t *testing.T) {
+	if got := (BuildMetadata{}).generator(); got != "alloy" {
+		t.Fatalf("empty generator: %q", got)
+	}
+	got := BuildMetadata{AlloyVersion: "v1.2.3", Commit: "0123456789abcdef"}.generator()
+	if got != "alloy v1.2.3 (0123456789ab)" {
+		t.Fatalf("generator: %q", got)
+	}
+}
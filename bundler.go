@@ -0,0 +1,23 @@
+package alloy
+
+type Bundler interface {
+	BuildServer(component string) (string, []string, error)
+	BuildClient(entries []ClientEntry, outDir string, locale string) (map[string]ClientAssets, error)
+}
+
+type EsbuildBundler struct{}
+
+func (EsbuildBundler) BuildServer(component string) (string, []string, error) {
+	return buildServerBundle(component)
+}
+
+func (EsbuildBundler) BuildClient(entries []ClientEntry, outDir string, locale string) (map[string]ClientAssets, error) {
+	return buildClientBundles(entries, outDir, locale)
+}
+
+func configuredBundler() Bundler {
+	if cfg := getConfig(); cfg != nil && cfg.Bundler != nil {
+		return cfg.Bundler
+	}
+	return EsbuildBundler{}
+}
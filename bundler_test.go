@@ -0,0 +1,49 @@
+package alloy
+
+import (
+	"context"
+	"testing"
+)
+
+type fakeBundler struct {
+	servers []string
+	clients []string
+}
+
+func (b *fakeBundler) BuildServer(component string) (string, []string, error) {
+	b.servers = append(b.servers, component)
+	return `var __Component = { default: function(props) { return "fake " + props.name; } };`, []string{component}, nil
+}
+
+func (b *fakeBundler) BuildClient(entries []ClientEntry, outDir string, locale string) (map[string]ClientAssets, error) {
+	assets := map[string]ClientAssets{}
+	for _, entry := range entries {
+		b.clients = append(b.clients, entry.Name)
+		assets[entry.Name] = ClientAssets{Entry: outDir + "/" + entry.Name + ".js"}
+	}
+	return assets, nil
+}
+
+func TestConfigBundlerReplacesEsbuild(t *testing.T) {
+	bundler := &fakeBundler{}
+	withTestConfig(t, func(cfg *Config) {
+		cfg.Bundler = bundler
+	})
+	component := "/app/emails/welcome.tsx"
+
+	html, err := RenderComponent(context.Background(), component, map[string]any{"name": "Ada"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if html != "fake Ada" || len(bundler.servers) != 1 || bundler.servers[0] != component {
+		t.Fatalf("html = %q, servers = %v", html, bundler.servers)
+	}
+
+	assets, err := BuildClientBundles([]ClientEntry{{Name: "home", Component: "/app/pages/home.tsx", RootID: "home-root"}}, "dist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assets["home"].Entry != "dist/home.js" || len(bundler.clients) != 1 {
+		t.Fatalf("assets = %v, clients = %v", assets, bundler.clients)
+	}
+}
@@ -0,0 +1,146 @@
+package alloy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	CheckMissingBundle = "missing-bundle"
+	CheckStalePage     = "stale-page"
+	CheckOrphanFile    = "orphan-file"
+	CheckUnroutedPage  = "unrouted-page"
+	CheckUnknownRoute  = "unknown-route"
+)
+
+type CheckProblem struct {
+	Kind   string
+	Page   string
+	Detail string
+}
+
+func (p CheckProblem) String() string {
+	if p.Page == "" {
+		return fmt.Sprintf("%s: %s", p.Kind, p.Detail)
+	}
+	return fmt.Sprintf("%s %s: %s", p.Kind, p.Page, p.Detail)
+}
+
+func ParseRouteManifest(data []byte) (map[string]string, error) {
+	var routes map[string]string
+	if err := json.Unmarshal(data, &routes); err != nil {
+		return nil, fmt.Errorf("🔴 decode route manifest: %w", err)
+	}
+	return routes, nil
+}
+
+func CheckBuild(filesystem fs.FS, pagesDir string, dist string, routes map[string]string) ([]CheckProblem, error) {
+	dist = path.Clean(filepath.ToSlash(dist))
+	unpacked, err := unpackFS(filesystem, dist)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := ReadManifest(unpacked, dist)
+	if err != nil {
+		return nil, err
+	}
+	pages, err := DiscoverPages(pagesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []CheckProblem
+	sources := map[string]bool{}
+	for _, page := range pages {
+		sources[page.Name] = true
+		if _, ok := manifest.Pages[page.Name]; !ok {
+			problems = append(problems, CheckProblem{Kind: CheckMissingBundle, Page: page.Name, Detail: "not in the manifest; run alloy build"})
+		}
+	}
+
+	for _, name := range sortedKeys(manifest.Pages) {
+		if !sources[name] {
+			problems = append(problems, CheckProblem{Kind: CheckStalePage, Page: name, Detail: fmt.Sprintf("no %s in %s", name+".tsx", FormatPath(pagesDir))})
+		}
+		if err := validateManifestPage(unpacked, dist, manifest.Pages[name]); err != nil {
+			for _, line := range strings.Split(err.Error(), "\n") {
+				problems = append(problems, CheckProblem{Kind: CheckMissingBundle, Page: name, Detail: strings.TrimPrefix(line, "🔴 ")})
+			}
+		}
+	}
+
+	orphans, err := orphanDistFiles(filesystem, unpacked, dist, manifest)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range orphans {
+		problems = append(problems, CheckProblem{Kind: CheckOrphanFile, Detail: name})
+	}
+
+	if routes != nil {
+		routed := map[string]bool{}
+		for _, pattern := range sortedKeys(routes) {
+			page := routes[pattern]
+			routed[page] = true
+			if _, ok := manifest.Pages[page]; !ok {
+				problems = append(problems, CheckProblem{Kind: CheckUnknownRoute, Page: page, Detail: fmt.Sprintf("route %s has no page in the manifest", pattern)})
+			}
+		}
+		for _, name := range sortedKeys(manifest.Pages) {
+			if !routed[name] {
+				problems = append(problems, CheckProblem{Kind: CheckUnroutedPage, Page: name, Detail: "not registered on any route"})
+			}
+		}
+	}
+	return problems, nil
+}
+
+func orphanDistFiles(filesystem fs.FS, unpacked fs.FS, dist string, manifest *Manifest) ([]string, error) {
+	known := map[string]bool{}
+	for name := range unpackedFiles {
+		known[name] = true
+	}
+	for _, entry := range manifest.Pages {
+		for _, name := range manifestPageNames(entry) {
+			known[path.Base(name)] = true
+		}
+	}
+	for name, file := range manifest.Files {
+		known[path.Base(name)] = true
+		for _, imp := range file.Imports {
+			known[path.Base(imp)] = true
+		}
+	}
+	if public, err := ReadPublicManifest(unpacked, dist); err == nil {
+		for _, hashed := range public {
+			known[path.Base(hashed)] = true
+		}
+	}
+	if data, err := fs.ReadFile(unpacked, path.Join(dist, metafileName)); err == nil {
+		if meta, err := parseMetafile(string(data)); err == nil {
+			for out := range meta.Outputs {
+				known[path.Base(filepath.ToSlash(out))] = true
+			}
+		}
+	}
+
+	var orphans []string
+	err := fs.WalkDir(filesystem, dist, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if !known[path.Base(strings.TrimSuffix(p, packedExt))] {
+			orphans = append(orphans, strings.TrimPrefix(p, dist+"/"))
+		}
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return orphans, err
+}
@@ -0,0 +1,59 @@
+package alloy
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCheckBuildReportsInconsistencies(t *testing.T) {
+	root := t.TempDir()
+	withTestConfig(t, func(cfg *Config) {})
+	pagesDir := filepath.Join(root, "app", "pages")
+	writeTestFile(t, filepath.Join(pagesDir, "home.tsx"), "export default function Home() { return null; }\n")
+	writeTestFile(t, filepath.Join(pagesDir, "about.tsx"), "export default function About() { return null; }\n")
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "home-server.js"), `var __Component = { default: function() { return "home"; } };`)
+	writeTestFile(t, filepath.Join(dist, "client-home-AAAAAAAA.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "shared.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "client-home-OLDOLDOL.js"), "stale client")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{"version": 2, "pages": {
+		"home": {"server": "home-server.js", "client": "client-home-AAAAAAAA.js", "css": "shared.css"},
+		"blog": {"server": "blog-server.js", "client": "client-blog-BBBBBBBB.js", "css": "shared.css"}
+	}}`)
+
+	routes, err := ParseRouteManifest([]byte(`{"/{$}": "home", "/contact": "contact"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	problems, err := CheckBuild(os.DirFS(root), pagesDir, "dist/build", routes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, problem := range problems {
+		got = append(got, problem.Kind+" "+problem.Page)
+	}
+	for _, want := range []string{
+		CheckMissingBundle + " about",
+		CheckStalePage + " blog",
+		CheckMissingBundle + " blog",
+		CheckOrphanFile + " ",
+		CheckUnknownRoute + " contact",
+		CheckUnroutedPage + " blog",
+	} {
+		if !slices.Contains(got, want) {
+			t.Errorf("missing %q in %v", want, got)
+		}
+	}
+	if slices.Contains(got, CheckMissingBundle+" home") || slices.Contains(got, CheckUnroutedPage+" home") {
+		t.Errorf("home reported: %v", problems)
+	}
+	for _, problem := range problems {
+		if problem.Kind == CheckOrphanFile && problem.Detail != "client-home-OLDOLDOL.js" {
+			t.Errorf("orphan = %q", problem.Detail)
+		}
+	}
+}
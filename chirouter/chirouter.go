@@ -0,0 +1,86 @@
+package chirouter
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/3-lines-studio/alloy"
+	"github.com/go-chi/chi/v5"
+)
+
+const WildcardParam = "path"
+
+var chiParam = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)(:[^{}]*)?\}`)
+
+type Loader = func(r *http.Request) map[string]any
+
+func Handle(r chi.Router, pattern string, page http.Handler) {
+	r.Handle(pattern, Params(page))
+}
+
+func Params(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rctx := chi.RouteContext(r.Context())
+		if rctx == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		params := make(map[string]string, len(rctx.URLParams.Keys))
+		for i, key := range rctx.URLParams.Keys {
+			if key == "*" {
+				key = WildcardParam
+			}
+			params[key] = rctx.URLParams.Values[i]
+		}
+		r = alloy.WithRouteParams(r, Pattern(rctx.RoutePattern()), params)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func Pattern(route string) string {
+	route = chiParam.ReplaceAllString(route, "{$1}")
+	if strings.HasSuffix(route, "*") {
+		route = strings.TrimSuffix(route, "*") + "{" + WildcardParam + "...}"
+	}
+	return route
+}
+
+func Route(page alloy.PageSpec) string {
+	switch page.Name {
+	case "home", "index":
+		return "/"
+	}
+	return "/" + filepath.ToSlash(page.Name)
+}
+
+func HandlePages(r chi.Router, pages []alloy.PageSpec, loaders map[string]Loader) {
+	for _, page := range pages {
+		handler := alloy.NewPage(page.Component)
+		if loader := loaders[page.Name]; loader != nil {
+			handler = handler.WithLoader(loader)
+		}
+		Handle(r, Route(page), handler)
+	}
+}
+
+func HandleDir(r chi.Router, dir string, loaders map[string]Loader) error {
+	pages, err := alloy.DiscoverPages(dir)
+	if err != nil {
+		return err
+	}
+	if len(pages) == 0 {
+		return fmt.Errorf("🔴 no pages found in %s", dir)
+	}
+	for name := range loaders {
+		if !slices.ContainsFunc(pages, func(page alloy.PageSpec) bool { return page.Name == name }) {
+			return fmt.Errorf("🔴 loader for unknown page %s", name)
+		}
+	}
+	HandlePages(r, pages, loaders)
+	return nil
+}
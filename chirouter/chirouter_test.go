@@ -0,0 +1,83 @@
+package chirouter
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/3-lines-studio/alloy"
+	"github.com/3-lines-studio/alloy/alloytest"
+	"github.com/go-chi/chi/v5"
+)
+
+func TestHandleMapsChiParams(t *testing.T) {
+	alloytest.Setup(t, map[string]alloytest.Fixture{
+		"app/pages/docs.tsx": {ServerJS: alloytest.ServerBundle(`return "<p>" + props.version + ":" + props.path + ":" + props.pattern + "</p>";`)},
+	})
+
+	r := chi.NewRouter()
+	Handle(r, "/docs/{version:v[0-9]+}/*", alloy.NewPage("app/pages/docs.tsx").WithLoader(func(r *http.Request) map[string]any {
+		params := alloy.RouteParams(r)
+		return map[string]any{"version": params.Get("version"), "path": params.Wildcard, "pattern": params.Pattern}
+	}))
+
+	doc := alloytest.Get(t, r, "/docs/v2/guides/routing")
+	if doc.Status != http.StatusOK || doc.Root != "<p>v2:guides/routing:/docs/{version}/{path...}</p>" {
+		t.Fatalf("unexpected page %d: %q", doc.Status, doc.Root)
+	}
+}
+
+func TestPatternConvertsChiSyntax(t *testing.T) {
+	for route, want := range map[string]string{
+		"/":                     "/",
+		"/blog/{slug}":          "/blog/{slug}",
+		"/user/{id:[0-9]+}":     "/user/{id}",
+		"/files/*":              "/files/{path...}",
+		"/a/{b}/c/{d:[a-z]+}/*": "/a/{b}/c/{d}/{path...}",
+	} {
+		if got := Pattern(route); got != want {
+			t.Fatalf("Pattern(%q) = %q, want %q", route, got, want)
+		}
+	}
+}
+
+func TestHandleDirRegistersDiscoveredPages(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"home", "about"} {
+		if err := os.WriteFile(filepath.Join(dir, name+".tsx"), []byte("export default function Page() {}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	alloytest.Setup(t, map[string]alloytest.Fixture{
+		filepath.Join(dir, "home.tsx"):  alloytest.StaticFixture("<p>home</p>"),
+		filepath.Join(dir, "about.tsx"): {ServerJS: alloytest.ServerBundle(`return "<p>" + props.team + "</p>";`)},
+	})
+
+	r := chi.NewRouter()
+	err := HandleDir(r, dir, map[string]Loader{
+		"about": func(r *http.Request) map[string]any { return map[string]any{"team": "core"} },
+	})
+	if err != nil {
+		t.Fatalf("HandleDir: %v", err)
+	}
+
+	if doc := alloytest.Get(t, r, "/"); doc.Root != "<p>home</p>" {
+		t.Fatalf("home: %d %q", doc.Status, doc.Root)
+	}
+	if doc := alloytest.Get(t, r, "/about"); doc.Root != "<p>core</p>" {
+		t.Fatalf("about: %d %q", doc.Status, doc.Root)
+	}
+}
+
+func TestHandleDirRejectsUnknownLoader(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "home.tsx"), []byte("export default function Page() {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := HandleDir(chi.NewRouter(), dir, map[string]Loader{"missing": nil})
+	if err == nil {
+		t.Fatal("expected error for loader without page")
+	}
+}
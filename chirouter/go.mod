@@ -0,0 +1,23 @@
+module github.com/3-lines-studio/alloy/chirouter
+
+go 1.25.0
+
+require (
+	github.com/3-lines-studio/alloy v0.0.0
+	github.com/go-chi/chi/v5 v5.2.3
+)
+
+require (
+	github.com/buke/quickjs-go v0.6.7 // indirect
+	github.com/evanw/esbuild v0.27.0 // indirect
+	github.com/go-logr/logr v1.4.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	go.opentelemetry.io/auto/sdk v1.1.0 // indirect
+	go.opentelemetry.io/otel v1.38.0 // indirect
+	go.opentelemetry.io/otel/metric v1.38.0 // indirect
+	go.opentelemetry.io/otel/trace v1.38.0 // indirect
+	golang.org/x/sync v0.21.0 // indirect
+	golang.org/x/sys v0.46.0 // indirect
+)
+
+replace github.com/3-lines-studio/alloy => ../
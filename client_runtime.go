@@ -0,0 +1,47 @@
+package alloy
+
+import (
+	"net/http"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+const (
+	ClientRuntimeModule = "@alloy/client"
+	DataHeader          = "X-Alloy-Data"
+
+	clientRuntimeNamespace = "alloy-client"
+)
+
+func clientRuntimePlugin() api.Plugin {
+	return api.Plugin{
+		Name: "alloy-client",
+		Setup: func(build api.PluginBuild) {
+			build.OnResolve(api.OnResolveOptions{Filter: `^@alloy/client$`}, func(args api.OnResolveArgs) (api.OnResolveResult, error) {
+				return api.OnResolveResult{Path: ClientRuntimeModule, Namespace: clientRuntimeNamespace}, nil
+			})
+
+			build.OnLoad(api.OnLoadOptions{Filter: `.*`, Namespace: clientRuntimeNamespace}, func(args api.OnLoadArgs) (api.OnLoadResult, error) {
+				contents := MustReadAsset("assets/client-runtime.ts")
+				return api.OnLoadResult{Contents: &contents, Loader: api.LoaderTS}, nil
+			})
+		},
+	}
+}
+
+func wantsLoaderData(r *http.Request) bool {
+	return r.Header.Get(DataHeader) == "1"
+}
+
+func serveLoaderData(w http.ResponseWriter, r *http.Request, props map[string]any) {
+	data, err := encodedPropsFrom(r.Context()).marshal(props)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "private, no-store")
+	if r.Method != http.MethodHead {
+		w.Write(data)
+	}
+}
@@ -0,0 +1,81 @@
+package alloy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestClientRuntimeHooksDuringSSR(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(dir)
+	})
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react", "jsx-runtime.js"), `exports.jsx = (type, props) => ({ type, props });`+"\n")
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react-dom", "server.edge.js"), `exports.renderToString = (el) => String(el.type(el.props));`+"\n")
+	component := filepath.Join(dir, "app", "pages", "post.tsx")
+	writeTestFile(t, component, `import { useLoaderData, useRouteParams, useNavigation } from "@alloy/client";
+export default function Post() {
+  const data = useLoaderData<{ title: string }>();
+  return data.title + " " + useRouteParams().slug + " " + useNavigation().state;
+}
+`)
+
+	serverJS, _, err := BuildServerBundle(component)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), renderContextKey{}, map[string]any{"params": map[string]string{"slug": "hello"}})
+	out, err := executeSSR(ctx, serverJS, map[string]any{"title": "Hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.HTML != "Hello hello idle" {
+		t.Fatalf("html = %q", out.HTML)
+	}
+}
+
+func TestPageHandlerServesLoaderData(t *testing.T) {
+	resetBundleCache()
+	t.Cleanup(resetBundleCache)
+
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "post-server.js"), `var __Component = { default: function(props) { return "<h1>" + props.title + " " + globalThis.__ALLOY_CTX__.params.slug + "</h1>"; } };`)
+	writeTestFile(t, filepath.Join(dist, "client-post-AAAAAAAA.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "shared.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{"post": {"server": "post-server.js", "client": "client-post-AAAAAAAA.js", "css": "shared.css"}}`)
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle("/posts/{slug}", NewPage(filepath.Join(root, "pages", "post.tsx")).WithLoader(func(r *http.Request) map[string]any {
+		return map[string]any{"title": "Post " + r.PathValue("slug")}
+	}))
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/posts/hello", nil))
+	if body := rec.Body.String(); !strings.Contains(body, "<h1>Post hello hello</h1>") || !strings.Contains(body, `"params":{"slug":"hello"}`) {
+		t.Fatalf("page missing route params:\n%s", body)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/posts/hello", nil)
+	req.Header.Set(DataHeader, "1")
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != `{"title":"Post hello"}` {
+		t.Fatalf("data = %d %q", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Fatalf("Content-Type = %q", got)
+	}
+	if got := rec.Header().Get("Vary"); !strings.Contains(got, DataHeader) {
+		t.Fatalf("Vary = %q", got)
+	}
+}
@@ -2,13 +2,16 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
-	"syscall"
+	"regexp"
+	"strings"
 
 	"github.com/3-lines-studio/alloy"
 	"golang.org/x/sync/errgroup"
@@ -28,6 +31,18 @@ func main() {
 		runBuild(args)
 	case "dev":
 		runDev(args)
+	case "analyze":
+		runAnalyze(args)
+	case "check":
+		runCheck(args)
+	case "gen":
+		runGen(args)
+	case "bench":
+		runBench(args)
+	case "serve":
+		runServe(args)
+	case "preview":
+		runPreview(args)
 	default:
 		printUsage()
 		os.Exit(1)
@@ -43,9 +58,127 @@ func runBuild(args []string) {
 	fs := flag.NewFlagSet("build", flag.ExitOnError)
 	var pagesDir string
 	var distDir string
+	var secretPatterns []*regexp.Regexp
+	var cssSplit bool
+	var baseline bool
+	var pack bool
+	var signKey string
+	var cacheSpec string
+	var upload string
+	var assetURL string
+	var hashPublic bool
+	diagnostics := alloy.DiagnosticsText
+	budgets := alloy.SizeBudgets{Pages: map[string]int64{}}
+	var cssMode string
+	var cssTransform alloy.CSSTransform
+	var cssTargets []string
+	var tailwindStandalone bool
+	var legacy bool
+	var legacyTarget string
+	var legacyPolyfills []string
+	var cssInput string
+	var cssEntries []alloy.CSSEntry
+	vendorChunks := map[string][]string{}
+	var importMap map[string]string
 
 	fs.StringVar(&pagesDir, "pages", "", "directory containing page components (.tsx)")
 	fs.StringVar(&distDir, "out", "", "output directory for prebuilt bundles")
+	fs.Func("vendor-chunk", "packages to keep in a shared vendor chunk as [name=]pkg,... (repeatable)", func(value string) error {
+		name, pkgs, err := alloy.ParseVendorChunk(value)
+		if err != nil {
+			return err
+		}
+		vendorChunks[name] = pkgs
+		return nil
+	})
+	fs.StringVar(&cssInput, "css", "", "css entry for pages without a --css-entry (default: app/app.css)")
+	fs.Func("css-entry", "extra css entry as name=input:page,... (repeatable)", func(value string) error {
+		entry, err := alloy.ParseCSSEntry(value)
+		if err != nil {
+			return err
+		}
+		cssEntries = append(cssEntries, entry)
+		return nil
+	})
+	fs.StringVar(&cssMode, "css-mode", "", "css pipeline: tailwind or plain (default: detect)")
+	fs.BoolVar(&tailwindStandalone, "tailwind-standalone", false, "download and use the standalone tailwindcss binary instead of npx")
+	fs.BoolVar(&cssSplit, "css-split", false, "build a stylesheet per page from its own sources (tailwind only)")
+	fs.Func("css-transform", "css post-processing: esbuild or lightningcss", func(value string) error {
+		transform, err := alloy.ParseCSSTransform(value)
+		cssTransform = transform
+		return err
+	})
+	fs.Func("css-targets", "comma-separated browser targets for --css-transform, e.g. chrome109,safari15.6", func(value string) error {
+		for _, target := range strings.Split(value, ",") {
+			if target = strings.TrimSpace(target); target != "" {
+				cssTargets = append(cssTargets, target)
+			}
+		}
+		return nil
+	})
+	fs.Func("import-map", "keep packages out of client bundles: an import map .json file, or a CDN like https://esm.sh for react and react-dom", func(value string) error {
+		if strings.HasSuffix(value, ".json") {
+			data, err := os.ReadFile(value)
+			if err != nil {
+				return err
+			}
+			importMap, err = alloy.ParseImportMap(data)
+			return err
+		}
+		var err error
+		importMap, err = alloy.ReactImportMap(value, ".")
+		return err
+	})
+	fs.BoolVar(&legacy, "legacy", false, "also build a nomodule bundle per page for browsers without ES modules")
+	fs.Func("legacy-target", "syntax target for --legacy bundles: es5 or es2015 to es2019 (default: es2015)", func(value string) error {
+		_, err := alloy.ParseLegacyTarget(value)
+		legacyTarget = value
+		return err
+	})
+	fs.Func("legacy-polyfill", "module imported first in every --legacy bundle (repeatable, default: core-js/stable when installed)", func(value string) error {
+		legacyPolyfills = append(legacyPolyfills, value)
+		return nil
+	})
+	fs.Func("budget", "gzip size budget for a page as name=size, * for every page (repeatable)", func(value string) error {
+		name, size, ok := strings.Cut(value, "=")
+		if !ok || name == "" {
+			return fmt.Errorf("want name=size")
+		}
+		n, err := alloy.ParseSize(size)
+		if err != nil {
+			return err
+		}
+		budgets.Pages[name] = n
+		return nil
+	})
+	fs.Func("budget-total", "gzip size budget for all client files", func(value string) error {
+		n, err := alloy.ParseSize(value)
+		if err != nil {
+			return err
+		}
+		budgets.Total = n
+		return nil
+	})
+	fs.BoolVar(&pack, "pack", false, "gzip bundles inside the out dir; Init decompresses them")
+	fs.StringVar(&signKey, "sign-key", "", "ed25519 private key (PEM) used to sign the manifest")
+	fs.StringVar(&cacheSpec, "cache", os.Getenv("ALLOY_BUILD_CACHE"), "build cache for server and client bundles: a directory, s3://bucket/prefix, gs://bucket/prefix or http(s) url")
+	fs.StringVar(&upload, "upload", "", "upload client assets and the manifest to s3://bucket/prefix, gs://bucket/prefix, an http(s) url or a directory")
+	fs.StringVar(&assetURL, "asset-url", "", "public url the uploaded assets are served from")
+	fs.BoolVar(&hashPublic, "hash-public", false, "copy public/ files into the out dir with content hashes and rewrite references to them")
+	fs.Func("diagnostics", "build error format: text, json or github (default: text)", func(value string) error {
+		format, err := alloy.ParseDiagnosticFormat(value)
+		diagnostics = format
+		return err
+	})
+	fs.BoolVar(&baseline, "baseline", false, "compare bundle sizes against the previous build in the out dir")
+	fs.Func("secret-pattern", "regexp that fails the build when found in client bundles (repeatable)", func(value string) error {
+		pattern, err := regexp.Compile(value)
+		if err != nil {
+			return err
+		}
+		secretPatterns = append(secretPatterns, pattern)
+		return nil
+	})
 	fs.Parse(args)
 
 	pagesDir = defaultPagesDir(pagesDir)
@@ -59,11 +192,36 @@ func runBuild(args []string) {
 		os.Exit(1)
 	}
 
+	alloy.Init(os.DirFS("."), func(cfg *alloy.Config) {
+		cfg.DistDir = distDir
+		cfg.SecretPatterns = secretPatterns
+		cfg.CSSMode = alloy.CSSMode(cssMode)
+		cfg.CSSTransform = cssTransform
+		cfg.CSSTargets = cssTargets
+		cfg.TailwindStandalone = tailwindStandalone
+		cfg.CSSInput = cssInput
+		cfg.CSSEntries = cssEntries
+		cfg.VendorChunks = vendorChunks
+		cfg.ImportMap = importMap
+		cfg.LegacyTarget = legacyTarget
+		cfg.LegacyPolyfills = legacyPolyfills
+		cfg.AssetURL = assetURL
+	})
+
 	cleanDist := filepath.Clean(distDir)
 	if cleanDist == "." || cleanDist == string(filepath.Separator) {
 		fmt.Fprintf(os.Stderr, "🔴 refusing to remove dist dir %q\n", distDir)
 		os.Exit(1)
 	}
+	var previous *alloy.SizeReport
+	if baseline {
+		if report, err := alloy.MeasureBundleSizes(distDir); err == nil {
+			previous = report
+		} else {
+			fmt.Fprintf(os.Stdout, "⚠️ No baseline build found in %s\n", alloy.FormatPath(distDir))
+		}
+	}
+
 	if err := os.RemoveAll(cleanDist); err != nil {
 		fmt.Fprintf(os.Stderr, "🔴 %v\n", err)
 		os.Exit(1)
@@ -79,20 +237,55 @@ func runBuild(args []string) {
 		os.Exit(1)
 	}
 
-	fmt.Fprintf(os.Stdout, "\n🔨 Building production bundles\n")
-
-	cssPath := filepath.Join(alloy.DefaultAppDir, "app.css")
-	sharedCSS, err := alloy.RunTailwind(cssPath, filepath.Dir(pagesDir))
+	mismatches, err := alloy.CheckPageProps(".", pages)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "🔴 %v\n", err)
 		os.Exit(1)
 	}
-	sharedCSSPath, err := alloy.SaveCSS(sharedCSS, distDir, "shared")
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "🔴 %v\n", err)
+	if len(mismatches) > 0 {
+		for _, mismatch := range mismatches {
+			fmt.Fprintf(os.Stderr, "🔴 props %v\n", mismatch)
+		}
 		os.Exit(1)
 	}
 
+	var cache alloy.BuildCache
+	if cacheSpec != "" {
+		cache, err = alloy.OpenBuildCache(cacheSpec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Fprintf(os.Stdout, "\n🔨 Building production bundles\n")
+
+	var publicAssets map[string]string
+	if hashPublic {
+		publicAssets, err = alloy.FingerprintPublic("public", distDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "🔴 %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stdout, "🔖 Hashed %d public files\n", len(publicAssets))
+	}
+
+	cssPaths := map[string]string{}
+	if !cssSplit {
+		for _, entry := range alloy.CSSEntriesForPages(pages) {
+			css, err := alloy.BuildCSS(entry.Input, filepath.Dir(pagesDir))
+			if err != nil {
+				exitBuildError(diagnostics, err)
+			}
+			cssPath, err := alloy.SaveCSS(alloy.RewritePublicRefs(css, publicAssets), distDir, entry.Name)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "🔴 %v\n", err)
+				os.Exit(1)
+			}
+			cssPaths[entry.Name] = cssPath
+		}
+	}
+
 	clientInputs := make([]alloy.ClientEntry, 0, len(pages))
 	for _, page := range pages {
 		clientInputs = append(clientInputs, alloy.ClientEntry{
@@ -102,34 +295,185 @@ func runBuild(args []string) {
 		})
 	}
 
-	clientAssets, err := alloy.BuildClientBundles(clientInputs, distDir)
+	clientAssets, clientHit, err := alloy.BuildClientBundlesCached(context.Background(), cache, clientInputs, distDir)
+	if err != nil {
+		exitBuildError(diagnostics, err)
+	}
+	if clientHit {
+		fmt.Fprintf(os.Stdout, "♻️ Build cache: client bundles reused\n")
+	}
+	defaultLocale, localeAssets, err := alloy.BuildLocaleClientBundles(clientInputs, distDir)
+	if err != nil {
+		exitBuildError(diagnostics, err)
+	}
+	if defaultLocale != "" {
+		fmt.Fprintf(os.Stdout, "🌐 Client bundles for %d locales (default: %s)\n", len(localeAssets)+1, defaultLocale)
+	}
+	var legacyBundles map[string]string
+	var localeLegacy map[string]map[string]string
+	if legacy {
+		legacyBundles, err = alloy.BuildLegacyClientBundles(clientInputs, distDir)
+		if err != nil {
+			exitBuildError(diagnostics, err)
+		}
+		localeLegacy, err = alloy.BuildLocaleLegacyBundles(clientInputs, distDir)
+		if err != nil {
+			exitBuildError(diagnostics, err)
+		}
+		fmt.Fprintf(os.Stdout, "🦕 Legacy bundles for %d pages\n", len(legacyBundles))
+	}
+
+	if cssSplit {
+		for _, page := range pages {
+			entry := alloy.CSSEntryForPage(page.Name)
+			css, err := alloy.BuildPageCSS(entry.Input, page.Name, clientAssets[page.Name].Sources, filepath.Dir(pagesDir))
+			if err != nil {
+				exitBuildError(diagnostics, err)
+			}
+			cssPath, err := alloy.SaveCSS(alloy.RewritePublicRefs(css, publicAssets), distDir, page.Name)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "🔴 %v\n", err)
+				os.Exit(1)
+			}
+			cssPaths[page.Name] = cssPath
+		}
+	}
+
+	cacheHits := 0
+	for _, page := range pages {
+		cssPath := cssPaths[alloy.CSSEntryForPage(page.Name).Name]
+		if cssSplit {
+			cssPath = cssPaths[page.Name]
+		}
+		locales := map[string]alloy.LocaleFiles{}
+		for locale, assets := range localeAssets {
+			client := assets[page.Name]
+			locales[locale] = alloy.LocaleFiles{Client: client.Entry, ClientChunks: client.Chunks, Assets: client.Assets, Legacy: localeLegacy[locale][page.Name]}
+		}
+		hit, err := buildPage(page, distDir, clientAssets[page.Name], legacyBundles[page.Name], cssPath, cache, defaultLocale, locales)
+		if err != nil {
+			exitBuildError(diagnostics, err)
+		}
+		if hit {
+			cacheHits++
+		}
+	}
+	if cache != nil {
+		fmt.Fprintf(os.Stdout, "♻️ Build cache: %d/%d server bundles reused\n", cacheHits, len(pages))
+	}
+
+	report, err := alloy.MeasureBundleSizes(distDir)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "🔴 %v\n", err)
 		os.Exit(1)
 	}
+	fmt.Fprintf(os.Stdout, "\n📦 Client bundle sizes\n")
+	report.Print(os.Stdout, previous)
 
-	for _, page := range pages {
-		if err := buildPage(page, distDir, clientAssets[page.Name], sharedCSSPath); err != nil {
+	if err := report.CheckBudgets(budgets); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	if pack {
+		if err := alloy.PackDist(distDir); err != nil {
 			fmt.Fprintf(os.Stderr, "🔴 %v\n", err)
 			os.Exit(1)
 		}
 	}
 
+	if signKey != "" {
+		data, err := os.ReadFile(signKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "🔴 read signing key: %v\n", err)
+			os.Exit(1)
+		}
+		key, err := alloy.ParseSigningKey(data)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		if err := alloy.SignManifest(distDir, key); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stdout, "🔏 Signed manifest\n")
+	}
+
+	if upload != "" {
+		uploader, err := alloy.OpenUploader(upload)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		count, err := alloy.UploadDist(context.Background(), distDir, uploader)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stdout, "☁️ Uploaded %d files ➡️ %s\n", count, upload)
+	}
+
+	if diagnostics == alloy.DiagnosticsJSON {
+		alloy.WriteDiagnostics(os.Stderr, diagnostics, nil)
+	}
 	fmt.Fprintf(os.Stdout, "✅ Build complete: %d pages ➡️ %s\n", len(pages), alloy.FormatPath(distDir))
 }
 
+func exitBuildError(format alloy.DiagnosticFormat, err error) {
+	if format == alloy.DiagnosticsText {
+		fmt.Fprintf(os.Stderr, "🔴 %v\n", err)
+	} else {
+		alloy.WriteDiagnostics(os.Stderr, format, alloy.Diagnostics(err))
+	}
+	os.Exit(1)
+}
+
 func runDev(args []string) {
 	fs := flag.NewFlagSet("watch", flag.ExitOnError)
 	var pagesDir string
 	var distDir string
+	var cssMode string
+	var tailwindStandalone bool
+	var cssInput string
+	var cssEntries []alloy.CSSEntry
+	vendorChunks := map[string][]string{}
 
 	fs.StringVar(&pagesDir, "pages", "", "directory containing page components (.tsx)")
 	fs.StringVar(&distDir, "out", "", "output directory for bundles")
+	fs.Func("vendor-chunk", "packages to keep in a shared vendor chunk as [name=]pkg,... (repeatable)", func(value string) error {
+		name, pkgs, err := alloy.ParseVendorChunk(value)
+		if err != nil {
+			return err
+		}
+		vendorChunks[name] = pkgs
+		return nil
+	})
+	fs.StringVar(&cssInput, "css", "", "css entry for pages without a --css-entry (default: app/app.css)")
+	fs.Func("css-entry", "extra css entry as name=input:page,... (repeatable)", func(value string) error {
+		entry, err := alloy.ParseCSSEntry(value)
+		if err != nil {
+			return err
+		}
+		cssEntries = append(cssEntries, entry)
+		return nil
+	})
+	fs.StringVar(&cssMode, "css-mode", "", "css pipeline: tailwind or plain (default: detect)")
+	fs.BoolVar(&tailwindStandalone, "tailwind-standalone", false, "download and use the standalone tailwindcss binary instead of npx")
 	fs.Parse(args)
 
 	pagesDir = defaultPagesDir(pagesDir)
 	distDir = defaultDistDir(distDir)
 
+	alloy.Init(os.DirFS("."), func(cfg *alloy.Config) {
+		cfg.DistDir = distDir
+		cfg.CSSMode = alloy.CSSMode(cssMode)
+		cfg.TailwindStandalone = tailwindStandalone
+		cfg.CSSInput = cssInput
+		cfg.CSSEntries = cssEntries
+		cfg.VendorChunks = vendorChunks
+	})
+
 	if err := os.MkdirAll(distDir, 0755); err != nil {
 		fmt.Fprintf(os.Stderr, "🔴 create dist dir: %v\n", err)
 		os.Exit(1)
@@ -149,7 +493,7 @@ func runDev(args []string) {
 	defer cancel()
 
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	signal.Notify(sigChan, shutdownSignals...)
 
 	go func() {
 		<-sigChan
@@ -161,9 +505,13 @@ func runDev(args []string) {
 
 	var g errgroup.Group
 
+	g.Go(func() error {
+		return alloy.WatchPropsTypes(ctx, ".", pagesDir)
+	})
+
 	g.Go(func() error {
 		fmt.Fprintf(os.Stdout, "\n👀 Watching %d pages in %s\n", len(pages), alloy.FormatPath(pagesDir))
-		err := alloy.WatchAndBuild(ctx, pages, distDir, initialBuildDone)
+		err := alloy.WatchPagesAndBuild(ctx, pagesDir, distDir, initialBuildDone)
 		if err == context.Canceled {
 			return nil
 		}
@@ -194,7 +542,7 @@ func runDev(args []string) {
 			template := alloy.MustReadAsset("assets/air.toml")
 
 			silent := os.Getenv("DEBUG") == ""
-			defaultConfig := fmt.Sprintf(template, silent)
+			defaultConfig := fmt.Sprintf(template, serverBinary, silent)
 			if _, err := tmpConfig.WriteString(defaultConfig); err != nil {
 				return fmt.Errorf("🔴 write air config: %w", err)
 			}
@@ -205,7 +553,7 @@ func runDev(args []string) {
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 		cmd.Stdin = os.Stdin
-		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+		setProcessGroup(cmd)
 
 		cmd.Env = append(os.Environ(), "ALLOY_DEV=1")
 
@@ -223,12 +571,7 @@ func runDev(args []string) {
 		case err := <-done:
 			return err
 		case <-ctx.Done():
-			if cmd.Process != nil {
-				pgid, err := syscall.Getpgid(cmd.Process.Pid)
-				if err == nil {
-					syscall.Kill(-pgid, syscall.SIGTERM)
-				}
-			}
+			stopProcessGroup(cmd)
 			return nil
 		}
 	})
@@ -239,30 +582,335 @@ func runDev(args []string) {
 	}
 }
 
-func buildPage(page alloy.PageSpec, distDir string, client alloy.ClientAssets, sharedCSSPath string) error {
+func runAnalyze(args []string) {
+	fs := flag.NewFlagSet("analyze", flag.ExitOnError)
+	var distDir string
+	var htmlPath string
+	var addr string
+
+	fs.StringVar(&distDir, "out", "", "output directory of the last build")
+	fs.StringVar(&htmlPath, "html", "", "write a static report to this file instead of serving it")
+	fs.StringVar(&addr, "addr", "localhost:4040", "address to serve the report on")
+	fs.Parse(args)
+
+	distDir = defaultDistDir(distDir)
+
+	pages, err := alloy.AnalyzeBuild(distDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "🔴 %v\n", err)
+		os.Exit(1)
+	}
+
+	if htmlPath != "" {
+		file, err := os.Create(htmlPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "🔴 create report: %v\n", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		if err := alloy.WriteAnalysisReport(file, pages); err != nil {
+			fmt.Fprintf(os.Stderr, "🔴 %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stdout, "✅ Report written ➡️ %s\n", alloy.FormatPath(htmlPath))
+		return
+	}
+
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		alloy.WriteAnalysisReport(w, pages)
+	})
+	fmt.Fprintf(os.Stdout, "📊 Bundle report @ http://%s\n", addr)
+	if err := http.ListenAndServe(addr, nil); err != nil {
+		fmt.Fprintf(os.Stderr, "🔴 serve report: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runCheck(args []string) {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	var pagesDir string
+	var distDir string
+	var routesPath string
+
+	fs.StringVar(&pagesDir, "pages", "", "directory containing page components (.tsx)")
+	fs.StringVar(&distDir, "out", "", "output directory of the last build")
+	fs.StringVar(&routesPath, "routes", "", "route manifest (.json) mapping route patterns to page names")
+	fs.Parse(args)
+
+	pagesDir = defaultPagesDir(pagesDir)
+	distDir = defaultDistDir(distDir)
+
+	var routes map[string]string
+	if routesPath != "" {
+		data, err := os.ReadFile(routesPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "🔴 read route manifest: %v\n", err)
+			os.Exit(1)
+		}
+		routes, err = alloy.ParseRouteManifest(data)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	problems, err := alloy.CheckBuild(os.DirFS("."), pagesDir, distDir, routes)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if len(problems) > 0 {
+		for _, problem := range problems {
+			fmt.Fprintf(os.Stderr, "🔴 %v\n", problem)
+		}
+		fmt.Fprintf(os.Stderr, "\n%d problems in %s\n", len(problems), alloy.FormatPath(distDir))
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stdout, "✅ %s matches %s\n", alloy.FormatPath(distDir), alloy.FormatPath(pagesDir))
+}
+
+func runGen(args []string) {
+	fs := flag.NewFlagSet("gen", flag.ExitOnError)
+	var opts alloy.GenOptions
+
+	fs.StringVar(&opts.PagesDir, "pages", "", "directory containing page components (.tsx)")
+	fs.StringVar(&opts.Root, "root", ".", "directory scanned for //alloy:props types")
+	fs.StringVar(&opts.Out, "out", alloy.DefaultGenFile, "go file to write route constants to")
+	fs.StringVar(&opts.Package, "package", "", "package of the generated go file (default: $GOPACKAGE or main)")
+	fs.Parse(args)
+
+	opts.PagesDir = defaultPagesDir(opts.PagesDir)
+
+	written, err := alloy.Generate(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "🔴 %v\n", err)
+		os.Exit(1)
+	}
+	if len(written) == 0 {
+		fmt.Fprintf(os.Stdout, "✅ Generated files up to date\n")
+		return
+	}
+	for _, path := range written {
+		fmt.Fprintf(os.Stdout, "✅ Generated ➡️ %s\n", alloy.FormatPath(path))
+	}
+}
+
+func runBench(args []string) {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	var distDir string
+	var propsJSON string
+	var opts alloy.BenchOptions
+
+	fs.StringVar(&distDir, "out", "", "output directory of the last build")
+	fs.StringVar(&propsJSON, "props", "", "props as JSON, or @file to read them from a file")
+	fs.IntVar(&opts.Concurrency, "c", 0, "concurrent renders (default: number of CPUs)")
+	fs.IntVar(&opts.Requests, "n", 0, "total renders (default: 200 unless --duration is set)")
+	fs.DurationVar(&opts.Duration, "duration", 0, "run for this long instead of a fixed count")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "🔴 usage: alloy bench [flags] <url|page>\n")
+		os.Exit(1)
+	}
+	target := fs.Arg(0)
+
+	var report alloy.BenchReport
+	if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+		fmt.Fprintf(os.Stdout, "\n🏁 Benchmarking %s\n", target)
+		report = alloy.BenchURL(target, opts)
+	} else {
+		distDir = defaultDistDir(distDir)
+		serverJS, err := alloy.LoadPageBundle(distDir, target)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "🔴 %v\n", err)
+			os.Exit(1)
+		}
+		props, err := readBenchProps(propsJSON)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "🔴 %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stdout, "\n🏁 Benchmarking %s in-process\n", target)
+		report = alloy.BenchPage(serverJS, props, opts)
+	}
+
+	report.Print(os.Stdout)
+	if report.Errors > 0 {
+		os.Exit(1)
+	}
+}
+
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	var root string
+	var addr string
+	var dataDir string
+	var debug bool
+
+	fs.StringVar(&root, "root", ".", "directory containing dist/build and public")
+	fs.StringVar(&addr, "addr", ":8080", "address to listen on")
+	fs.StringVar(&dataDir, "data", "", "directory of {page}.json files used as page props")
+	fs.BoolVar(&debug, "debug", false, "serve pprof and the eval profile at "+alloy.DebugPath)
+	fs.Parse(args)
+
+	alloy.Init(os.DirFS(root), func(cfg *alloy.Config) {
+		cfg.Debug = debug
+	})
+
+	var loaders map[string]func(r *http.Request) map[string]any
+	if dataDir != "" {
+		var err error
+		if loaders, err = alloy.DataLoaders(dataDir); err != nil {
+			fmt.Fprintf(os.Stderr, "🔴 %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	mux := http.NewServeMux()
+	if err := alloy.Routes(mux, loaders); err != nil {
+		fmt.Fprintf(os.Stderr, "🔴 %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stdout, "✅ Serving %s @ http://%s\n", alloy.FormatPath(root), displayAddr(addr))
+	if err := alloy.Serve(context.Background(), addr, alloy.AssetsMiddleware()(mux)); err != nil {
+		fmt.Fprintf(os.Stderr, "🔴 %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runPreview(args []string) {
+	fs := flag.NewFlagSet("preview", flag.ExitOnError)
+	var dir string
+	var out string
+	var addr string
+
+	fs.StringVar(&dir, "dir", alloy.DefaultAppDir, "directory scanned for components (.tsx)")
+	fs.StringVar(&out, "out", ".alloy/preview", "directory for preview bundles")
+	fs.StringVar(&addr, "addr", "localhost:6006", "address to serve the preview on")
+	fs.Parse(args)
+
+	components, err := alloy.DiscoverComponents(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if len(components) == 0 {
+		fmt.Fprintf(os.Stderr, "🔴 no components found in %s\n", dir)
+		os.Exit(1)
+	}
+
+	distDir := filepath.Join(out, alloy.DefaultDistDir)
+	if err := os.MkdirAll(distDir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "🔴 create preview dir: %v\n", err)
+		os.Exit(1)
+	}
+	alloy.Init(os.DirFS(out), func(cfg *alloy.Config) {
+		cfg.DistDir = distDir
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), shutdownSignals...)
+	defer stop()
+
+	built := make(chan struct{})
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		err := alloy.WatchAndBuild(gctx, components, distDir, built)
+		if err == context.Canceled {
+			return nil
+		}
+		return err
+	})
+	g.Go(func() error {
+		select {
+		case <-built:
+		case <-gctx.Done():
+			return nil
+		}
+		fmt.Fprintf(os.Stdout, "🧩 Previewing %d components @ http://%s\n", len(components), displayAddr(addr))
+		return alloy.Serve(gctx, addr, alloy.AssetsMiddleware()(alloy.ComponentPreview(components)))
+	})
+
+	if err := g.Wait(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func displayAddr(addr string) string {
+	if strings.HasPrefix(addr, ":") {
+		return "localhost" + addr
+	}
+	return addr
+}
+
+func readBenchProps(value string) (map[string]any, error) {
+	if value == "" {
+		return nil, nil
+	}
+	data := []byte(value)
+	if file, ok := strings.CutPrefix(value, "@"); ok {
+		var err error
+		if data, err = os.ReadFile(file); err != nil {
+			return nil, fmt.Errorf("🔴 read props: %w", err)
+		}
+	}
+	props := map[string]any{}
+	if err := json.Unmarshal(data, &props); err != nil {
+		return nil, fmt.Errorf("🔴 decode props: %w", err)
+	}
+	return props, nil
+}
+
+func buildPage(page alloy.PageSpec, distDir string, client alloy.ClientAssets, legacy string, cssPath string, cache alloy.BuildCache, locale string, locales map[string]alloy.LocaleFiles) (bool, error) {
 	if distDir == "" {
-		return fmt.Errorf("🔴 out dir required")
+		return false, fmt.Errorf("🔴 out dir required")
 	}
 
-	serverJS, _, err := alloy.BuildServerBundle(page.Component)
+	serverJS, _, hit, err := alloy.BuildServerBundleCached(context.Background(), cache, page.Component)
 	if err != nil {
-		return fmt.Errorf("🔴 build server %s: %w", page.Component, err)
+		return false, fmt.Errorf("🔴 build server %s: %w", page.Component, err)
 	}
 
 	files, err := alloy.SaveServerBundle(serverJS, distDir, page.Name)
 	if err != nil {
-		return fmt.Errorf("🔴 save server %s: %w", page.Component, err)
+		return false, fmt.Errorf("🔴 save server %s: %w", page.Component, err)
 	}
 
 	files.Client = client.Entry
 	files.ClientChunks = client.Chunks
-	files.CSS = sharedCSSPath
+	files.Assets = client.Assets
+	files.Legacy = legacy
+	files.CSS = cssPath
+	if locale != "" {
+		files.Locale = locale
+		files.Locales = locales
+	}
+
+	config, err := alloy.DetectPageConfig(serverJS)
+	if err != nil {
+		return false, fmt.Errorf("🔴 page config %s: %w", page.Component, err)
+	}
+	files.RenderMode = config.RenderMode
+	files.Revalidate = config.Revalidate
+
+	if config.RenderMode == alloy.RenderModeStatic {
+		html, err := alloy.PrerenderPage(serverJS, page.RootID, nil, *files)
+		if err != nil {
+			return false, fmt.Errorf("🔴 prerender %s: %w", page.Component, err)
+		}
+		files.HTML, err = alloy.SaveHTML(html, distDir, page.Name)
+		if err != nil {
+			return false, fmt.Errorf("🔴 save html %s: %w", page.Component, err)
+		}
+	}
 
 	if err := alloy.WriteManifest(distDir, page.Name, *files); err != nil {
-		return fmt.Errorf("🔴 write manifest %s: %w", page.Component, err)
+		return false, fmt.Errorf("🔴 write manifest %s: %w", page.Component, err)
 	}
 
-	return nil
+	return hit, nil
 }
 
 func defaultPagesDir(flagValue string) string {
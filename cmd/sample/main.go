@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"embed"
 	"fmt"
 	"net/http"
@@ -21,13 +22,10 @@ func main() {
 	mux.Handle("/blog/{slug}", alloy.NewPage("app/pages/home.tsx").WithLoader(loader.Blog))
 	mux.Handle("/store/{storeSlug}/product/{productSlug}", alloy.NewPage("app/pages/product.tsx").WithLoader(loader.Product))
 	mux.Handle("/about", alloy.NewPage("app/pages/about.tsx"))
-	mux.HandleFunc("/api/health", func(w http.ResponseWriter, _ *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.Write([]byte(`{"ok":true}`))
-	})
+	mux.Handle("/api/health", alloy.HealthHandler())
 
 	fmt.Println("✅ Server running at http://localhost:8080")
-	if err := http.ListenAndServe(":8080", mux); err != nil {
+	if err := alloy.Serve(context.Background(), ":8080", mux); err != nil {
 		fmt.Fprintf(os.Stderr, "🔴 %s\n", err)
 		os.Exit(1)
 	}
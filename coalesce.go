@@ -0,0 +1,93 @@
+package alloy
+
+import (
+	"context"
+	"net/http"
+
+	"golang.org/x/sync/singleflight"
+)
+
+var renderFlights singleflight.Group
+
+func (h *PageHandler) WithCoalescing() *PageHandler {
+	h.coalesce = true
+	return h
+}
+
+type coalescedRender struct {
+	result *RenderResult
+	flags  map[string]Flag
+}
+
+func coalesceKey(component string, r *http.Request) string {
+	return isrKey(component, r) + "\x00" + r.URL.RawQuery + "\x00" + Locale(r) + "\x00" + Theme(r) + "\x00" + flagKey(r)
+}
+
+func canCoalesce(r *http.Request) bool {
+	if r.Header.Get("Cookie") != "" || r.Header.Get("Authorization") != "" {
+		return false
+	}
+	return r.Method == http.MethodGet || r.Method == http.MethodHead
+}
+
+func (h *PageHandler) serveCoalesced(w http.ResponseWriter, r *http.Request, files PrebuiltFiles, rootID string) {
+	withTheme(w, r, nil)
+	render := func(r *http.Request) (*RenderResult, error) {
+		props, err := h.loadProps(r)
+		if err != nil {
+			return nil, err
+		}
+		r, props, err = checkPropsSize(r, h.component, withTheme(w, r, withFlagProps(r, withVariantProps(r, props))))
+		if err != nil {
+			return nil, err
+		}
+		result, err := h.renderResult(r, files, props, rootID)
+		if err != nil {
+			reportRenderError(r, h.component, props, err)
+			return nil, err
+		}
+		return result, nil
+	}
+
+	leader := r.WithContext(context.WithoutCancel(r.Context()))
+	v, err, shared := renderFlights.Do(coalesceKey(h.component, r), func() (any, error) {
+		result, err := render(leader)
+		if err != nil {
+			return nil, err
+		}
+		return &coalescedRender{result: result, flags: flagSnapshot(leader)}, nil
+	})
+	if err != nil {
+		writeRenderError(w, err)
+		return
+	}
+
+	// The leader may have evaluated flags the key could not include; a
+	// follower that sees them differently renders for itself.
+	rendered := v.(*coalescedRender).result
+	if shared && !sameFlags(r.Context(), v.(*coalescedRender).flags) {
+		if rendered, err = render(r); err != nil {
+			writeRenderError(w, err)
+			return
+		}
+	} else if shared {
+		metrics.coalescedRenders.inc(pageName(h.component))
+	}
+
+	result := *rendered
+	result.Nonce = CSPNonce(r)
+	result.Layout = layoutMeta(r)
+	result.Context = RenderContext(r.Context())
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	writeHTML(r.Context(), w, result.ToHTML(rootID))
+}
+
+func (h *PageHandler) renderResult(r *http.Request, files PrebuiltFiles, props map[string]any, rootID string) (*RenderResult, error) {
+	if files.Server == "" {
+		return RenderTSXFileWithHydrationWithContext(r.Context(), h.component, props, rootID)
+	}
+	if err := RegisterPrebuiltBundleFromFS(h.component, rootID, getConfig().FS, files); err != nil {
+		return nil, err
+	}
+	return RenderPrebuiltWithContext(r.Context(), h.component, props, rootID, files)
+}
@@ -0,0 +1,163 @@
+package alloy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCoalescedPageRunsLoaderOnce(t *testing.T) {
+	resetBundleCache()
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "herd-server.js"), `var __Component = { default: function(props) { return "<p>" + props.n + "</p>"; } };`)
+	writeTestFile(t, filepath.Join(dist, "client-herd-AAAAAAAA.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "shared.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{"herd": {"server": "herd-server.js", "client": "client-herd-AAAAAAAA.js", "css": "shared.css"}}`)
+	withTestConfig(t, func(cfg *Config) { cfg.FS = os.DirFS(root) })
+
+	var calls atomic.Int64
+	release := make(chan struct{})
+	handler := NewPage(filepath.Join(root, "pages", "herd.tsx")).WithCoalescing().WithLoader(func(r *http.Request) map[string]any {
+		n := calls.Add(1)
+		<-release
+		return map[string]any{"n": n}
+	})
+
+	var wg sync.WaitGroup
+	recs := make([]*httptest.ResponseRecorder, 5)
+	for i := range recs {
+		recs[i] = httptest.NewRecorder()
+		wg.Go(func() { handler.ServeHTTP(recs[i], httptest.NewRequest(http.MethodGet, "/herd?page=1", nil)) })
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Fatalf("🔴 expected one loader call, got %d", calls.Load())
+	}
+	for _, rec := range recs {
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "<p>1</p>") {
+			t.Fatalf("🔴 unexpected response %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/herd?page=2", nil))
+	if calls.Load() != 2 || !strings.Contains(rec.Body.String(), "<p>2</p>") {
+		t.Fatalf("🔴 expected a new render for another query, got %d calls: %s", calls.Load(), rec.Body.String())
+	}
+}
+
+type coalesceUserKey struct{}
+
+type coalesceUserFlags struct{}
+
+func (coalesceUserFlags) Evaluate(ctx context.Context, key string) Flag {
+	return Flag{Enabled: ctx.Value(coalesceUserKey{}) == "beta-user"}
+}
+
+func TestCoalescedPageKeepsFlagsPerRequest(t *testing.T) {
+	resetBundleCache()
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "flagged-server.js"), `var __Component = { default: function(props) { return "<p>" + props.flags.beta.enabled + "</p>"; } };`)
+	writeTestFile(t, filepath.Join(dist, "client-flagged-AAAAAAAA.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "shared.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{"flagged": {"server": "flagged-server.js", "client": "client-flagged-AAAAAAAA.js", "css": "shared.css"}}`)
+
+	for _, keys := range [][]string{{"beta"}, nil} {
+		withTestConfig(t, func(cfg *Config) {
+			cfg.FS = os.DirFS(root)
+			cfg.Flags = coalesceUserFlags{}
+			cfg.FlagKeys = keys
+		})
+
+		var calls atomic.Int64
+		release := make(chan struct{})
+		handler := NewPage(filepath.Join(root, "pages", "flagged.tsx")).WithCoalescing().WithLoader(func(r *http.Request) map[string]any {
+			calls.Add(1)
+			FlagEnabled(r.Context(), "beta")
+			<-release
+			return map[string]any{}
+		})
+
+		serve := func(user string) *httptest.ResponseRecorder {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/flagged", nil)
+			handler.ServeHTTP(rec, req.WithContext(context.WithValue(req.Context(), coalesceUserKey{}, user)))
+			return rec
+		}
+		var wg sync.WaitGroup
+		var beta, other *httptest.ResponseRecorder
+		wg.Go(func() { beta = serve("beta-user") })
+		time.Sleep(20 * time.Millisecond)
+		wg.Go(func() { other = serve("other-user") })
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		if !strings.Contains(beta.Body.String(), "<p>true</p>") || !strings.Contains(other.Body.String(), "<p>false</p>") {
+			t.Fatalf("🔴 flag keys %v: followers got the leader's flags: %s / %s", keys, beta.Body.String(), other.Body.String())
+		}
+		if calls.Load() != 2 {
+			t.Fatalf("🔴 flag keys %v: expected a render per flag set, got %d", keys, calls.Load())
+		}
+	}
+}
+
+func TestCoalescedPageRendersPerUser(t *testing.T) {
+	resetBundleCache()
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "account-server.js"), `var __Component = { default: function(props) { return "<p>" + props.user + "</p>"; } };`)
+	writeTestFile(t, filepath.Join(dist, "client-account-AAAAAAAA.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "shared.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{"account": {"server": "account-server.js", "client": "client-account-AAAAAAAA.js", "css": "shared.css"}}`)
+	withTestConfig(t, func(cfg *Config) { cfg.FS = os.DirFS(root) })
+
+	var calls atomic.Int64
+	release := make(chan struct{})
+	handler := NewPage(filepath.Join(root, "pages", "account.tsx")).WithCoalescing().WithLoader(func(r *http.Request) map[string]any {
+		calls.Add(1)
+		<-release
+		user := "anonymous"
+		if cookie, err := r.Cookie("session"); err == nil {
+			user = cookie.Value
+		} else if auth := r.Header.Get("Authorization"); auth != "" {
+			user = strings.TrimPrefix(auth, "Bearer ")
+		}
+		return map[string]any{"user": user}
+	})
+
+	serve := func(header, value string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/account", nil)
+		req.Header.Set(header, value)
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	var wg sync.WaitGroup
+	var alice, bob *httptest.ResponseRecorder
+	wg.Go(func() { alice = serve("Cookie", "session=alice") })
+	time.Sleep(20 * time.Millisecond)
+	wg.Go(func() { bob = serve("Authorization", "Bearer bob") })
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if !strings.Contains(alice.Body.String(), "<p>alice</p>") || !strings.Contains(bob.Body.String(), "<p>bob</p>") {
+		t.Fatalf("🔴 users shared a render: %s / %s", alice.Body.String(), bob.Body.String())
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("🔴 expected a render per user, got %d", calls.Load())
+	}
+}
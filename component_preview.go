@@ -0,0 +1,132 @@
+package alloy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var componentPreviewTemplate = sync.OnceValue(func() *template.Template {
+	return template.Must(template.New("preview").Parse(MustReadAsset("assets/component-preview.html")))
+})
+
+type previewComponent struct {
+	Name  string
+	Props string
+}
+
+func DiscoverComponents(dir string) ([]PageSpec, error) {
+	var components []PageSpec
+	seen := map[string]string{}
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != dir && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(p) != ".tsx" || isTestComponent(d.Name()) {
+			return nil
+		}
+		source, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if !bytes.Contains(source, []byte("export default")) {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(dir, p)
+		name := strings.ReplaceAll(strings.TrimSuffix(filepath.ToSlash(rel), ".tsx"), "/", "-")
+		if other, ok := seen[name]; ok {
+			return fmt.Errorf("🔴 components %s and %s both preview as %s", FormatPath(other), FormatPath(p), name)
+		}
+		seen[name] = p
+		components = append(components, PageSpec{Component: p, Name: name, RootID: defaultRootID(name)})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("🔴 find components: %w", err)
+	}
+	return components, nil
+}
+
+func isTestComponent(name string) bool {
+	for _, suffix := range []string{".test.tsx", ".spec.tsx", ".stories.tsx"} {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func ComponentPreview(components []PageSpec) http.Handler {
+	byName := make(map[string]PageSpec, len(components))
+	for _, component := range components {
+		byName[component.Name] = component
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET "+DevStatusPath, DevStatusHandler())
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		items := make([]previewComponent, 0, len(components))
+		for _, component := range components {
+			items = append(items, previewComponent{Name: component.Name, Props: previewProps(component.Component)})
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := componentPreviewTemplate().Execute(w, items); err != nil {
+			logger().Error("preview index", "err", err)
+		}
+	})
+	mux.HandleFunc("/render/{name}", func(w http.ResponseWriter, r *http.Request) {
+		component, ok := byName[r.PathValue("name")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		raw := r.FormValue("props")
+		if raw == "" {
+			raw = previewProps(component.Component)
+		}
+		var props map[string]any
+		if err := json.Unmarshal([]byte(raw), &props); err != nil {
+			http.Error(w, fmt.Sprintf("🔴 props must be a JSON object: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		cfg := getConfig()
+		files, ok, err := lookupManifest(cfg.FS, DefaultDistDir, component.Name)
+		if err == nil && !ok {
+			err = fmt.Errorf("🔴 %s is still building", component.Name)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		if err := RegisterPrebuiltBundleFromFS(component.Component, component.RootID, cfg.FS, files); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		ServePrebuiltPageWithContext(w, r, component.Component, props, component.RootID, files)
+	})
+	return mux
+}
+
+func previewProps(component string) string {
+	data, err := os.ReadFile(strings.TrimSuffix(component, ".tsx") + ".props.json")
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
@@ -0,0 +1,92 @@
+package alloy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiscoverComponents(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "pages", "home.tsx"), "export default function Home() { return null; }")
+	writeTestFile(t, filepath.Join(dir, "components", "Button.tsx"), "export default function Button() { return null; }")
+	writeTestFile(t, filepath.Join(dir, "components", "Button.test.tsx"), "export default function Test() { return null; }")
+	writeTestFile(t, filepath.Join(dir, "components", "utils.tsx"), "export const x = 1;")
+	writeTestFile(t, filepath.Join(dir, "node_modules", "pkg", "index.tsx"), "export default 1;")
+
+	components, err := DiscoverComponents(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, component := range components {
+		names = append(names, component.Name)
+	}
+	if strings.Join(names, ",") != "components-Button,pages-home" {
+		t.Fatalf("names = %v", names)
+	}
+	if components[0].RootID != "components-Button-root" {
+		t.Errorf("root id = %s", components[0].RootID)
+	}
+
+	writeTestFile(t, filepath.Join(dir, "components-Button.tsx"), "export default function Other() { return null; }")
+	if _, err := DiscoverComponents(dir); err == nil || !strings.Contains(err.Error(), "both preview as components-Button") {
+		t.Fatalf("expected duplicate name error, got %v", err)
+	}
+}
+
+func TestComponentPreviewRendersWithProps(t *testing.T) {
+	resetBundleCache()
+	t.Cleanup(resetBundleCache)
+
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "widgets-Card-server.js"), `var __Component = { default: function(props) { return "<article>" + props.title + "</article>"; } };`)
+	writeTestFile(t, filepath.Join(dist, "widgets-Card-client.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "app.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{"widgets-Card": {"server": "widgets-Card-server.js", "client": "widgets-Card-client.js", "css": "app.css"}}`)
+	component := filepath.Join(root, "app", "widgets", "Card.tsx")
+	writeTestFile(t, strings.TrimSuffix(component, ".tsx")+".props.json", `{"title": "Default"}`)
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+	})
+
+	handler := ComponentPreview([]PageSpec{{Component: component, Name: "widgets-Card", RootID: "widgets-Card-root"}})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `data-name="widgets-Card"`) || !strings.Contains(rec.Body.String(), "Default") {
+		t.Fatalf("index = %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/render/widgets-Card", nil))
+	if !strings.Contains(rec.Body.String(), "<article>Default</article>") {
+		t.Fatalf("default render = %d %s", rec.Code, rec.Body.String())
+	}
+
+	form := url.Values{"props": {`{"title": "Edited"}`}}
+	req := httptest.NewRequest(http.MethodPost, "/render/widgets-Card", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if !strings.Contains(rec.Body.String(), "<article>Edited</article>") {
+		t.Fatalf("edited render = %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/render/widgets-Card?props=[1]", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("bad props status = %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/render/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("missing status = %d", rec.Code)
+	}
+}
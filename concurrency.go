@@ -0,0 +1,83 @@
+package alloy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var ErrRenderQueueTimeout = errors.New("render queue timeout")
+
+var renderSlots struct {
+	sync.Mutex
+	size  int
+	slots chan struct{}
+}
+
+func currentRenderSlots() chan struct{} {
+	size := 0
+	if cfg := getConfig(); cfg != nil {
+		size = cfg.MaxConcurrentRenders
+	}
+
+	renderSlots.Lock()
+	defer renderSlots.Unlock()
+	if size != renderSlots.size {
+		renderSlots.size = size
+		renderSlots.slots = nil
+		if size > 0 {
+			renderSlots.slots = make(chan struct{}, size)
+		}
+	}
+	return renderSlots.slots
+}
+
+func acquireRenderSlot(ctx context.Context) (func(), error) {
+	slots := currentRenderSlots()
+	if slots == nil {
+		return func() {}, nil
+	}
+	release := func() { <-slots }
+
+	select {
+	case slots <- struct{}{}:
+		metrics.renderQueueWait.observe(0)
+		return release, nil
+	default:
+	}
+
+	if timeout := getConfig().RenderQueueTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	rendersQueued.Add(1)
+	defer rendersQueued.Add(-1)
+	start := time.Now()
+	select {
+	case slots <- struct{}{}:
+		metrics.renderQueueWait.observe(time.Since(start).Seconds())
+		return release, nil
+	case <-ctx.Done():
+		metrics.renderQueueTimeouts.inc()
+		return nil, fmt.Errorf("🔴 %w after %s: %w", ErrRenderQueueTimeout, time.Since(start).Round(time.Millisecond), ctx.Err())
+	}
+}
+
+func writeRenderError(w http.ResponseWriter, err error) {
+	var loaderErr *loaderError
+	if errors.As(err, &loaderErr) && errors.Is(err, context.DeadlineExceeded) {
+		http.Error(w, err.Error(), http.StatusGatewayTimeout)
+		return
+	}
+	if errors.Is(err, ErrRenderQueueTimeout) {
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
@@ -0,0 +1,72 @@
+package alloy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMaxConcurrentRendersQueuesUntilDeadline(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) {
+		cfg.MaxConcurrentRenders = 1
+		cfg.RenderQueueTimeout = 20 * time.Millisecond
+	})
+
+	release, err := acquireRenderSlot(context.Background())
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	_, err = executeSSR(context.Background(), poolTestBundle, map[string]any{"name": "queued"})
+	if !errors.Is(err, ErrRenderQueueTimeout) {
+		t.Fatalf("🔴 expected queue timeout, got %v", err)
+	}
+
+	release()
+	out, err := executeSSR(context.Background(), poolTestBundle, map[string]any{"name": "queued"})
+	if err != nil || out.HTML != "<p>queued</p>" {
+		t.Fatalf("🔴 render after release: %q %v", out.HTML, err)
+	}
+}
+
+func TestMaxConcurrentRendersSerializesRenders(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) { cfg.MaxConcurrentRenders = 1 })
+	code := `var __Component = function() { return new Promise(function(resolve) { setTimeout(function() { resolve("ok"); }, 20); }); };`
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for range 4 {
+		wg.Go(func() {
+			_, err := executeSSR(context.Background(), code, nil)
+			errs <- err
+		})
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("render: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Fatalf("🔴 renders ran concurrently (%s)", elapsed)
+	}
+}
+
+func TestWriteRenderErrorReturnsServiceUnavailable(t *testing.T) {
+	rec := httptest.NewRecorder()
+	writeRenderError(rec, errors.Join(errors.New("wrapped"), ErrRenderQueueTimeout))
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") != "1" {
+		t.Fatalf("🔴 expected 503 with Retry-After, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	rec = httptest.NewRecorder()
+	writeRenderError(rec, errors.New("boom"))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("🔴 expected 500, got %d", rec.Code)
+	}
+}
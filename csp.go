@@ -0,0 +1,97 @@
+package alloy
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"os"
+	"strings"
+)
+
+type CSP struct {
+	ScriptSrc    []string
+	StyleSrc     []string
+	ImgSrc       []string
+	FontSrc      []string
+	ConnectSrc   []string
+	FrameSrc     []string
+	InlineStyles bool
+	ReportOnly   bool
+	ReportURI    string
+}
+
+type cspNonceKey struct{}
+
+// Cached ISR and prebuilt static HTML is rendered once for many responses, so
+// it carries this placeholder and each response swaps in its own nonce.
+const cspNoncePlaceholder = "__ALLOY_CSP_NONCE__"
+
+func fillNonce(html string, nonce string) string {
+	if nonce == "" {
+		return strings.ReplaceAll(html, ` nonce="`+cspNoncePlaceholder+`"`, "")
+	}
+	return strings.ReplaceAll(html, ` nonce="`+cspNoncePlaceholder+`"`, ` nonce="`+nonce+`"`)
+}
+
+func CSPNonce(r *http.Request) string {
+	nonce, _ := r.Context().Value(cspNonceKey{}).(string)
+	return nonce
+}
+
+func withCSP(w http.ResponseWriter, r *http.Request) *http.Request {
+	cfg := getConfig()
+	if cfg == nil || cfg.CSP == nil {
+		return r
+	}
+
+	name := "Content-Security-Policy"
+	if cfg.CSP.ReportOnly {
+		name = "Content-Security-Policy-Report-Only"
+	}
+	if w.Header().Get(name) != "" {
+		return r
+	}
+
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	nonce := base64.RawStdEncoding.EncodeToString(buf)
+	w.Header().Set(name, cfg.CSP.policy(nonce, os.Getenv("ALLOY_DEV") == "1"))
+	return r.WithContext(context.WithValue(r.Context(), cspNonceKey{}, nonce))
+}
+
+func (c *CSP) policy(nonce string, dev bool) string {
+	self := "'self'"
+	withNonce := []string{self, "'nonce-" + nonce + "'"}
+
+	directives := [][]string{
+		{"default-src", self},
+		append(append([]string{"script-src"}, withNonce...), c.ScriptSrc...),
+		append(append([]string{"style-src"}, withNonce...), c.StyleSrc...),
+	}
+	if c.InlineStyles || dev {
+		directives = append(directives, []string{"style-src-attr", "'unsafe-inline'"})
+	}
+	directives = append(directives,
+		append([]string{"img-src", self, "data:"}, c.ImgSrc...),
+		append([]string{"font-src", self}, c.FontSrc...),
+		append([]string{"connect-src", self}, c.ConnectSrc...),
+	)
+	if len(c.FrameSrc) > 0 {
+		directives = append(directives, append([]string{"frame-src"}, c.FrameSrc...))
+	}
+	directives = append(directives,
+		[]string{"object-src", "'none'"},
+		[]string{"base-uri", self},
+		[]string{"frame-ancestors", self},
+	)
+	if c.ReportURI != "" {
+		directives = append(directives, []string{"report-uri", c.ReportURI})
+	}
+
+	parts := make([]string, len(directives))
+	for i, directive := range directives {
+		parts[i] = strings.Join(directive, " ")
+	}
+	return strings.Join(parts, "; ")
+}
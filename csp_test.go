@@ -0,0 +1,94 @@
+package alloy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestCSPPolicy(t *testing.T) {
+	csp := &CSP{ScriptSrc: []string{"https://cdn.example.com"}, FrameSrc: []string{"https://www.youtube.com"}, ReportURI: "/csp"}
+	got := csp.policy("abc", false)
+	want := "default-src 'self'; script-src 'self' 'nonce-abc' https://cdn.example.com; style-src 'self' 'nonce-abc'; img-src 'self' data:; font-src 'self'; connect-src 'self'; frame-src https://www.youtube.com; object-src 'none'; base-uri 'self'; frame-ancestors 'self'; report-uri /csp"
+	if got != want {
+		t.Fatalf("🔴 policy:\n got %s\nwant %s", got, want)
+	}
+	if !strings.Contains(csp.policy("abc", true), "style-src-attr 'unsafe-inline'") {
+		t.Fatal("🔴 dev policy should allow the toolbar's inline styles")
+	}
+}
+
+func TestCSPHeaderOnPages(t *testing.T) {
+	handler := etagTestPage(t, func(cfg *Config) { cfg.CSP = &CSP{ReportOnly: true} })
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/etag", nil))
+	policy := rec.Header().Get("Content-Security-Policy-Report-Only")
+	if !regexp.MustCompile(`script-src 'self' 'nonce-[A-Za-z0-9+/]{22}'`).MatchString(policy) {
+		t.Fatalf("🔴 expected nonce policy, got %q", policy)
+	}
+
+	second := httptest.NewRecorder()
+	handler.ServeHTTP(second, httptest.NewRequest(http.MethodGet, "/etag", nil))
+	if second.Header().Get("Content-Security-Policy-Report-Only") == policy {
+		t.Fatal("🔴 nonce should change per response")
+	}
+	if rec.Header().Get("ETag") != "" || second.Header().Get("ETag") != "" {
+		t.Fatal("🔴 pages with a per-request nonce should not get an etag")
+	}
+}
+
+func TestInlineTagsCarryNonce(t *testing.T) {
+	html := (&RenderResult{HTML: "<p>x</p>", CSS: "body{}", ClientJS: "hydrate()", Nonce: "abc"}).ToHTML("root")
+	for _, want := range []string{`<style nonce="abc">body{}</style>`, `<script type="module" nonce="abc">hydrate()</script>`} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("🔴 missing %s in:\n%s", want, html)
+		}
+	}
+}
+
+func TestCachedPagesCarryResponseNonce(t *testing.T) {
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "news-server.js"), fakeStaticServerJS)
+	writeTestFile(t, filepath.Join(dist, "client-news-AAAAAAAA.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "shared.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{
+		"news": {"server": "news-server.js", "client": "client-news-AAAAAAAA.js", "css": "shared.css", "render": "static", "revalidate": 60}
+	}`)
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+		cfg.ISRDir = t.TempDir()
+		cfg.CSP = &CSP{}
+		cfg.ImportMap = map[string]string{"lodash": "https://cdn.example.com/lodash.js"}
+	})
+	t.Cleanup(resetISRCache)
+
+	handler := NewPage(filepath.Join(root, "pages", "news.tsx")).WithLoader(func(r *http.Request) map[string]any {
+		return map[string]any{"name": "cached"}
+	})
+	nonce := regexp.MustCompile(`'nonce-([A-Za-z0-9+/]{22})'`)
+	for _, want := range []string{"MISS", "HIT"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/news", nil))
+		match := nonce.FindStringSubmatch(rec.Header().Get("Content-Security-Policy"))
+		if rec.Header().Get("X-Alloy-Cache") != want || match == nil {
+			t.Fatalf("🔴 expected %s with a nonce policy, got %s %q", want, rec.Header().Get("X-Alloy-Cache"), rec.Header().Get("Content-Security-Policy"))
+		}
+		body := rec.Body.String()
+		if !strings.Contains(body, `<script type="importmap" nonce="`+match[1]+`">`) || strings.Contains(body, cspNoncePlaceholder) {
+			t.Fatalf("🔴 %s response should carry its own nonce:\n%s", want, body)
+		}
+	}
+}
+
+func TestFillNonceStripsPlaceholderWithoutCSP(t *testing.T) {
+	html := `<script type="importmap" nonce="` + cspNoncePlaceholder + `">{}</script>`
+	if got := fillNonce(html, ""); got != `<script type="importmap">{}</script>` {
+		t.Fatalf("🔴 got %q", got)
+	}
+}
@@ -0,0 +1,432 @@
+package alloy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/evanw/esbuild/pkg/api"
+	"golang.org/x/sync/errgroup"
+)
+
+type CSSMode string
+
+const (
+	CSSModeAuto     CSSMode = ""
+	CSSModeTailwind CSSMode = "tailwind"
+	CSSModePlain    CSSMode = "plain"
+)
+
+var tailwindImportPattern = regexp.MustCompile(`(@import\s+["']tailwindcss(?:/utilities)?(?:\.css)?["'])(\s*source\([^)]*\))?`)
+
+var tailwindDirectivePattern = regexp.MustCompile(`@import\s+(url\()?["']tailwindcss|@tailwind\s|@theme\b|@plugin\s|@config\s|@source\s|@utility\s|@apply\s`)
+
+const sharedCSSEntry = "shared"
+
+type CSSEntry struct {
+	Name  string
+	Input string
+	Pages []string
+}
+
+type cssStep struct {
+	name      string
+	pkg       string
+	resolve   func(root string) (string, []string, error)
+	args      func(input, output string) []string
+	watchArgs func(input, output string) []string
+	build     func(input, output string) error
+	watch     func(ctx context.Context, input, output string) error
+}
+
+var sassStep = cssStep{
+	name: "sass",
+	pkg:  "sass",
+	args: func(input, output string) []string {
+		return []string{"--no-source-map", input, output}
+	},
+	watchArgs: func(input, output string) []string {
+		return []string{"--no-source-map", "--watch", input + ":" + output}
+	},
+}
+
+func postcssStep(configPath string) cssStep {
+	configDir := filepath.Dir(mustResolveAbsPath(configPath))
+	return cssStep{
+		name: "postcss",
+		pkg:  "postcss-cli",
+		args: func(input, output string) []string {
+			return []string{input, "-o", output, "--config", configDir}
+		},
+		watchArgs: func(input, output string) []string {
+			return []string{input, "-o", output, "--config", configDir, "--watch"}
+		},
+	}
+}
+
+var tailwindStep = cssStep{
+	name:    "tailwind",
+	pkg:     tailwindPackage,
+	resolve: resolveTailwindRunner,
+	args: func(input, output string) []string {
+		return []string{"-i", input, "-o", output, "--minify"}
+	},
+	watchArgs: func(input, output string) []string {
+		return []string{"-i", input, "-o", output, "--watch=always"}
+	},
+}
+
+var plainStep = cssStep{
+	name:  "plain",
+	build: buildPlainCSS,
+	watch: watchPlainCSS,
+}
+
+func DefaultCSSEntry(appDir string) string {
+	for _, name := range []string{"app.css", "app.scss", "app.sass"} {
+		candidate := filepath.Join(appDir, name)
+		if fileExists(candidate) {
+			return candidate
+		}
+	}
+	return filepath.Join(appDir, "app.css")
+}
+
+func ParseCSSEntry(value string) (CSSEntry, error) {
+	name, rest, ok := strings.Cut(value, "=")
+	if !ok || name == "" || rest == "" {
+		return CSSEntry{}, fmt.Errorf("🔴 css entry %q: want name=input[:page,...]", value)
+	}
+
+	entry := CSSEntry{Name: name, Input: rest}
+	if i := strings.LastIndex(rest, ":"); i >= 0 && !strings.ContainsAny(rest[i+1:], `/\.`) {
+		entry.Input = rest[:i]
+		for _, page := range strings.Split(rest[i+1:], ",") {
+			if page = strings.TrimSpace(page); page != "" {
+				entry.Pages = append(entry.Pages, page)
+			}
+		}
+	}
+	if entry.Input == "" {
+		return CSSEntry{}, fmt.Errorf("🔴 css entry %q: input required", value)
+	}
+	return entry, nil
+}
+
+func (e CSSEntry) matches(page string) bool {
+	for _, pattern := range e.Pages {
+		if ok, _ := path.Match(pattern, page); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func defaultCSSInput() string {
+	cfg := getConfig()
+	if cfg != nil && cfg.CSSInput != "" {
+		return cfg.CSSInput
+	}
+	appDir := DefaultAppDir
+	if cfg != nil && cfg.AppDir != "" {
+		appDir = cfg.AppDir
+	}
+	return DefaultCSSEntry(appDir)
+}
+
+func CSSEntryForPage(page string) CSSEntry {
+	cfg := getConfig()
+	if cfg != nil {
+		for _, entry := range cfg.CSSEntries {
+			if entry.matches(page) {
+				return entry
+			}
+		}
+	}
+	return CSSEntry{Name: sharedCSSEntry, Input: defaultCSSInput()}
+}
+
+func CSSEntriesForPages(pages []PageSpec) []CSSEntry {
+	seen := map[string]bool{}
+	var entries []CSSEntry
+	for _, page := range pages {
+		entry := CSSEntryForPage(page.Name)
+		if seen[entry.Name] {
+			continue
+		}
+		seen[entry.Name] = true
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func isSassFile(p string) bool {
+	ext := strings.ToLower(filepath.Ext(p))
+	return ext == ".scss" || ext == ".sass"
+}
+
+func usesTailwind(inputPath string) bool {
+	data, err := os.ReadFile(inputPath)
+	if err != nil {
+		return true
+	}
+	return tailwindDirectivePattern.Match(data)
+}
+
+func resolveCSSMode(inputPath string) CSSMode {
+	cfg := getConfig()
+	if cfg != nil && cfg.CSSMode != CSSModeAuto {
+		return cfg.CSSMode
+	}
+	if usesTailwind(inputPath) {
+		return CSSModeTailwind
+	}
+	return CSSModePlain
+}
+
+func cssSteps(inputPath string) []cssStep {
+	var steps []cssStep
+	if isSassFile(inputPath) {
+		steps = append(steps, sassStep)
+	}
+
+	cfg := getConfig()
+	if cfg != nil && cfg.PostCSSConfig != "" {
+		return append(steps, postcssStep(cfg.PostCSSConfig))
+	}
+	if resolveCSSMode(inputPath) == CSSModePlain {
+		return append(steps, plainStep)
+	}
+	return append(steps, tailwindStep)
+}
+
+func intermediateCSSPath(inputPath string, step int) string {
+	base := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))
+	return filepath.Join(filepath.Dir(inputPath), fmt.Sprintf(".alloy-%s-%d.css", base, step))
+}
+
+func BuildCSS(inputPath string, root string) (string, error) {
+	outputFile, err := os.CreateTemp("", "alloy-css-*.css")
+	if err != nil {
+		return "", fmt.Errorf("🔴 create temp css: %w", err)
+	}
+	outputPath := outputFile.Name()
+	outputFile.Close()
+	defer os.Remove(outputPath)
+
+	steps := cssSteps(inputPath)
+	if step, ok := cssTransformStep(); ok {
+		steps = append(steps, step)
+	}
+	input := inputPath
+	for i, step := range steps {
+		output := outputPath
+		if i < len(steps)-1 {
+			output = intermediateCSSPath(inputPath, i)
+			defer os.Remove(output)
+		}
+
+		if err := runCSSStep(step, "./", input, output); err != nil {
+			return "", err
+		}
+		input = output
+	}
+
+	css, err := os.ReadFile(outputPath)
+	if err != nil {
+		return "", fmt.Errorf("🔴 read css output: %w", err)
+	}
+
+	return string(css), nil
+}
+
+func BuildPageCSS(inputPath string, page string, sources []string, root string) (string, error) {
+	if isSassFile(inputPath) || resolveCSSMode(inputPath) != CSSModeTailwind || tailwindMajor(root) == 3 {
+		return BuildCSS(inputPath, root)
+	}
+
+	scoped, err := writeScopedTailwindInput(inputPath, page, sources)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(scoped)
+
+	return BuildCSS(scoped, root)
+}
+
+func writeScopedTailwindInput(inputPath string, page string, sources []string) (string, error) {
+	data, err := os.ReadFile(inputPath)
+	if err != nil {
+		return "", fmt.Errorf("🔴 read css %s: %w", inputPath, err)
+	}
+
+	dir := filepath.Dir(mustResolveAbsPath(inputPath))
+	var b strings.Builder
+	b.WriteString(tailwindImportPattern.ReplaceAllString(string(data), "$1 source(none)"))
+	b.WriteString("\n")
+	for _, source := range sources {
+		rel, err := filepath.Rel(dir, source)
+		if err != nil {
+			rel = source
+		}
+		fmt.Fprintf(&b, "@source %q;\n", filepath.ToSlash(rel))
+	}
+
+	base := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))
+	scoped := filepath.Join(dir, fmt.Sprintf(".alloy-%s-%s.css", base, page))
+	if err := os.WriteFile(scoped, []byte(b.String()), 0644); err != nil {
+		return "", fmt.Errorf("🔴 write scoped css: %w", err)
+	}
+	return scoped, nil
+}
+
+func WatchCSS(ctx context.Context, inputPath, outputPath, cwd string) error {
+	absInput := mustResolveAbsPath(inputPath)
+	absOutput := mustResolveAbsPath(outputPath)
+
+	steps := cssSteps(absInput)
+	g, gctx := errgroup.WithContext(ctx)
+
+	input := absInput
+	for i, step := range steps {
+		output := absOutput
+		if i < len(steps)-1 {
+			output = intermediateCSSPath(absInput, i)
+			defer os.Remove(output)
+
+			if err := runCSSStep(step, cwd, input, output); err != nil {
+				return err
+			}
+		}
+
+		stepInput := input
+		g.Go(func() error {
+			return watchCSSStep(gctx, step, cwd, stepInput, output)
+		})
+		input = output
+	}
+
+	return g.Wait()
+}
+
+func runCSSStep(step cssStep, root string, input, output string) error {
+	if step.build != nil {
+		return step.build(input, output)
+	}
+
+	runner, baseArgs, err := resolveStepRunner(step, root)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(runner, append(baseArgs, step.args(input, output)...)...)
+	cmd.Dir = root
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("🔴 %s build %s: %w: %s", step.name, input, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func watchCSSStep(ctx context.Context, step cssStep, cwd string, input, output string) error {
+	if step.watch != nil {
+		return step.watch(ctx, input, output)
+	}
+
+	runner, baseArgs, err := resolveStepRunner(step, cwd)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, runner, append(baseArgs, step.watchArgs(input, output)...)...)
+	cmd.Dir = cwd
+	cmd.Stdout = QuietWriter()
+	cmd.Stderr = QuietWriter()
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("🔴 start %s: %w", step.name, err)
+	}
+
+	err = cmd.Wait()
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+func plainCSSOptions(input string) api.BuildOptions {
+	cfg := getConfig()
+	opts := api.BuildOptions{
+		EntryPoints:      []string{input},
+		Bundle:           true,
+		MinifyWhitespace: true,
+		MinifySyntax:     true,
+		Plugins:          cfg.BuildPlugins,
+	}
+	applyAssetLoaders(&opts, cfg.DistDir)
+	return opts
+}
+
+func buildPlainCSS(input, output string) error {
+	opts := plainCSSOptions(input)
+	opts.Outdir = mustResolveAbsPath(getConfig().DistDir)
+	opts.Write = false
+
+	result := api.Build(opts)
+	if err := checkBuildErrors(result, fmt.Sprintf("css build %s", input)); err != nil {
+		return err
+	}
+
+	var css []byte
+	for _, out := range result.OutputFiles {
+		if filepath.Ext(out.Path) == ".css" {
+			css = out.Contents
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(out.Path), 0755); err != nil {
+			return fmt.Errorf("🔴 make asset dir: %w", err)
+		}
+		if err := os.WriteFile(out.Path, out.Contents, 0644); err != nil {
+			return fmt.Errorf("🔴 write css asset: %w", err)
+		}
+	}
+
+	if err := os.WriteFile(output, css, 0644); err != nil {
+		return fmt.Errorf("🔴 write css output: %w", err)
+	}
+	return nil
+}
+
+func watchPlainCSS(ctx context.Context, input, output string) error {
+	opts := plainCSSOptions(input)
+	opts.Outfile = output
+	opts.Write = true
+	disableMinify(&opts)
+
+	buildCtx, err := api.Context(opts)
+	if err := checkContextError(err, "create css context"); err != nil {
+		return err
+	}
+	defer buildCtx.Dispose()
+
+	if err := buildCtx.Watch(api.WatchOptions{}); err != nil {
+		return fmt.Errorf("🔴 watch css: %w", err)
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
+func resolveStepRunner(step cssStep, root string) (string, []string, error) {
+	if step.resolve != nil {
+		return step.resolve(root)
+	}
+	runner, baseArgs := ResolvePackageRunner(root, step.pkg)
+	if runner == "" {
+		return "", nil, fmt.Errorf("🔴 %s runner not found", step.name)
+	}
+	return runner, baseArgs, nil
+}
@@ -0,0 +1,224 @@
+package alloy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func stepNames(steps []cssStep) []string {
+	names := make([]string, 0, len(steps))
+	for _, step := range steps {
+		names = append(names, step.name)
+	}
+	return names
+}
+
+func TestCSSStepsSelection(t *testing.T) {
+	if got := stepNames(cssSteps("app/app.css")); len(got) != 1 || got[0] != "tailwind" {
+		t.Fatalf("plain css: want [tailwind], got %v", got)
+	}
+	if got := stepNames(cssSteps("app/app.scss")); len(got) != 2 || got[0] != "sass" || got[1] != "tailwind" {
+		t.Fatalf("scss: want [sass tailwind], got %v", got)
+	}
+
+	withTestConfig(t, func(cfg *Config) {
+		cfg.PostCSSConfig = "postcss.config.js"
+	})
+	if got := stepNames(cssSteps("app/app.scss")); len(got) != 2 || got[0] != "sass" || got[1] != "postcss" {
+		t.Fatalf("scss with postcss: want [sass postcss], got %v", got)
+	}
+
+	args := cssSteps("app/app.css")[0].args("in.css", "out.css")
+	wantDir := filepath.Dir(mustResolveAbsPath("postcss.config.js"))
+	if len(args) != 5 || args[3] != "--config" || args[4] != wantDir {
+		t.Fatalf("postcss args: %v", args)
+	}
+}
+
+func TestDefaultCSSEntry(t *testing.T) {
+	dir := t.TempDir()
+	if got := DefaultCSSEntry(dir); got != filepath.Join(dir, "app.css") {
+		t.Fatalf("fallback: got %s", got)
+	}
+
+	writeTestFile(t, filepath.Join(dir, "app.scss"), "$c: red; body { color: $c; }")
+	if got := DefaultCSSEntry(dir); got != filepath.Join(dir, "app.scss") {
+		t.Fatalf("scss entry: got %s", got)
+	}
+}
+
+func TestBuildCSSPlainMode(t *testing.T) {
+	dir := t.TempDir()
+	distDir := filepath.Join(dir, "dist", "build")
+	withTestConfig(t, func(cfg *Config) {
+		cfg.DistDir = distDir
+	})
+
+	writeTestFile(t, filepath.Join(dir, "app", "base.css"), "body {\n  margin: 0;\n}\n")
+	writeTestFile(t, filepath.Join(dir, "app", "bg.png"), "PNG")
+	entry := filepath.Join(dir, "app", "app.css")
+	writeTestFile(t, entry, "@import \"./base.css\";\n.hero {\n  background: url(\"./bg.png\");\n}\n")
+
+	if got := resolveCSSMode(entry); got != CSSModePlain {
+		t.Fatalf("mode: want plain, got %q", got)
+	}
+
+	css, err := BuildCSS(entry, dir)
+	if err != nil {
+		t.Fatalf("build css: %v", err)
+	}
+	if !strings.Contains(css, "body{margin:0}") {
+		t.Fatalf("imported css missing or not minified: %s", css)
+	}
+	if !strings.Contains(css, "/dist/build/assets/bg-") {
+		t.Fatalf("asset url not rewritten: %s", css)
+	}
+
+	assets, err := filepath.Glob(filepath.Join(distDir, "assets", "bg-*.png"))
+	if err != nil || len(assets) != 1 {
+		t.Fatalf("expected copied asset, got %v (%v)", assets, err)
+	}
+}
+
+func TestResolveCSSModeDetectsTailwind(t *testing.T) {
+	dir := t.TempDir()
+	entry := filepath.Join(dir, "app.css")
+	writeTestFile(t, entry, `@import "tailwindcss";`)
+	if got := resolveCSSMode(entry); got != CSSModeTailwind {
+		t.Fatalf("mode: want tailwind, got %q", got)
+	}
+
+	withTestConfig(t, func(cfg *Config) {
+		cfg.CSSMode = CSSModePlain
+	})
+	if got := resolveCSSMode(entry); got != CSSModePlain {
+		t.Fatalf("forced mode: want plain, got %q", got)
+	}
+}
+
+func TestParseCSSEntry(t *testing.T) {
+	entry, err := ParseCSSEntry("marketing=app/marketing.css:home,landing-*")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if entry.Name != "marketing" || entry.Input != "app/marketing.css" || len(entry.Pages) != 2 || entry.Pages[1] != "landing-*" {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+
+	entry, err = ParseCSSEntry(`admin=C:\app\admin.css`)
+	if err != nil || entry.Input != `C:\app\admin.css` || len(entry.Pages) != 0 {
+		t.Fatalf("windows path: %+v %v", entry, err)
+	}
+
+	for _, value := range []string{"", "marketing", "=app.css", "marketing=:home"} {
+		if _, err := ParseCSSEntry(value); err == nil {
+			t.Fatalf("expected error for %q", value)
+		}
+	}
+}
+
+func TestCSSEntryForPage(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) {
+		cfg.CSSInput = "app/main.css"
+		cfg.CSSEntries = []CSSEntry{
+			{Name: "marketing", Input: "app/marketing.css", Pages: []string{"home", "landing-*"}},
+		}
+	})
+
+	if got := CSSEntryForPage("landing-spring"); got.Name != "marketing" {
+		t.Fatalf("want marketing entry, got %+v", got)
+	}
+	if got := CSSEntryForPage("dashboard"); got.Name != "shared" || got.Input != "app/main.css" {
+		t.Fatalf("want shared entry, got %+v", got)
+	}
+
+	entries := CSSEntriesForPages([]PageSpec{{Name: "home"}, {Name: "dashboard"}, {Name: "landing-a"}})
+	if len(entries) != 2 || entries[0].Name != "marketing" || entries[1].Name != "shared" {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestMetafileSourceInputs(t *testing.T) {
+	meta, err := parseMetafile(`{
+		"outputs": {
+			"dist/client-home.js": {
+				"entryPoint": "home.tsx",
+				"imports": [{"path": "dist/chunk-a.js", "kind": "import-statement"}],
+				"inputs": {"app/pages/home.tsx": {}, "alloy-svg-component:app/logo.svg": {}}
+			},
+			"dist/chunk-a.js": {
+				"inputs": {"app/components/button.tsx": {}, "node_modules/react/index.js": {}}
+			},
+			"dist/client-about.js": {
+				"inputs": {"app/pages/about.tsx": {}}
+			}
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	got := meta.sourceInputs("dist/client-home.js", "/work")
+	want := []string{"/work/app/components/button.tsx", "/work/app/pages/home.tsx"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestWriteScopedTailwindInput(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "app.css")
+	writeTestFile(t, input, "@import \"tailwindcss\";\n@theme { --color-brand: red; }\n")
+
+	scoped, err := writeScopedTailwindInput(input, "home", []string{filepath.Join(dir, "pages", "home.tsx")})
+	if err != nil {
+		t.Fatalf("scope: %v", err)
+	}
+	if filepath.Dir(scoped) != dir {
+		t.Fatalf("scoped input should sit next to the entry, got %s", scoped)
+	}
+
+	data, err := os.ReadFile(scoped)
+	if err != nil {
+		t.Fatalf("read scoped: %v", err)
+	}
+	css := string(data)
+	if !strings.Contains(css, `@import "tailwindcss" source(none);`) {
+		t.Fatalf("automatic source detection not disabled:\n%s", css)
+	}
+	if !strings.Contains(css, `@source "pages/home.tsx";`) {
+		t.Fatalf("page source missing:\n%s", css)
+	}
+}
+
+func TestBuildCSSTransformLowersForTargets(t *testing.T) {
+	dir := t.TempDir()
+	withTestConfig(t, func(cfg *Config) {
+		cfg.DistDir = filepath.Join(dir, "dist", "build")
+		cfg.CSSMode = CSSModePlain
+		cfg.CSSTransform = CSSTransformEsbuild
+		cfg.CSSTargets = []string{"chrome100", "safari 15.4"}
+	})
+	entry := filepath.Join(dir, "app", "app.css")
+	writeTestFile(t, entry, ".card {\n  color: red;\n  & .title {\n    user-select: none;\n  }\n}\n")
+
+	css, err := BuildCSS(entry, dir)
+	if err != nil {
+		t.Fatalf("build css: %v", err)
+	}
+	if strings.Contains(css, "&") || !strings.Contains(css, ".card .title{") {
+		t.Fatalf("nesting not lowered: %s", css)
+	}
+	if !strings.Contains(css, "-webkit-user-select:none") {
+		t.Fatalf("prefix missing for safari: %s", css)
+	}
+
+	if _, err := parseCSSTargets([]string{"netscape4"}); err == nil {
+		t.Fatal("expected error for unknown browser")
+	}
+	if got := lightningTargets([]string{"chrome109", "safari15.6", "> 0.5%"}); got != "chrome >= 109, safari >= 15.6, > 0.5%" {
+		t.Fatalf("lightningcss targets: %q", got)
+	}
+}
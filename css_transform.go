@@ -0,0 +1,119 @@
+package alloy
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+type CSSTransform string
+
+const (
+	CSSTransformNone         CSSTransform = ""
+	CSSTransformEsbuild      CSSTransform = "esbuild"
+	CSSTransformLightningCSS CSSTransform = "lightningcss"
+)
+
+var cssTargetPattern = regexp.MustCompile(`^([a-z]+)\s*(\d+(?:\.\d+)*)$`)
+
+var cssEngines = map[string]api.EngineName{
+	"chrome":  api.EngineChrome,
+	"edge":    api.EngineEdge,
+	"firefox": api.EngineFirefox,
+	"ios":     api.EngineIOS,
+	"opera":   api.EngineOpera,
+	"safari":  api.EngineSafari,
+}
+
+func ParseCSSTransform(value string) (CSSTransform, error) {
+	switch transform := CSSTransform(value); transform {
+	case CSSTransformNone, CSSTransformEsbuild, CSSTransformLightningCSS:
+		return transform, nil
+	default:
+		return "", fmt.Errorf("🔴 unknown css transform %q: want esbuild or lightningcss", value)
+	}
+}
+
+func cssTransformStep() (cssStep, bool) {
+	cfg := getConfig()
+	if cfg == nil {
+		return cssStep{}, false
+	}
+	switch cfg.CSSTransform {
+	case CSSTransformEsbuild:
+		return cssStep{name: "esbuild css", build: transformCSS}, true
+	case CSSTransformLightningCSS:
+		targets := lightningTargets(cfg.CSSTargets)
+		return cssStep{
+			name: "lightningcss",
+			pkg:  "lightningcss-cli",
+			args: func(input, output string) []string {
+				args := []string{"--minify", input, "-o", output}
+				if targets != "" {
+					return append(args, "--targets", targets)
+				}
+				return append(args, "--browserslist")
+			},
+		}, true
+	}
+	return cssStep{}, false
+}
+
+func transformCSS(input, output string) error {
+	data, err := os.ReadFile(input)
+	if err != nil {
+		return fmt.Errorf("🔴 read css %s: %w", input, err)
+	}
+	engines, err := parseCSSTargets(getConfig().CSSTargets)
+	if err != nil {
+		return err
+	}
+
+	result := api.Transform(string(data), api.TransformOptions{
+		Loader:           api.LoaderCSS,
+		Sourcefile:       input,
+		MinifyWhitespace: true,
+		MinifySyntax:     true,
+		Engines:          engines,
+	})
+	if err := checkBuildErrors(api.BuildResult{Errors: result.Errors, Warnings: result.Warnings}, fmt.Sprintf("css transform %s", input)); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(output, result.Code, 0644); err != nil {
+		return fmt.Errorf("🔴 write css output: %w", err)
+	}
+	return nil
+}
+
+func parseCSSTargets(targets []string) ([]api.Engine, error) {
+	engines := make([]api.Engine, 0, len(targets))
+	for _, target := range targets {
+		match := cssTargetPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(target)))
+		if match == nil {
+			return nil, fmt.Errorf("🔴 css target %q: want a browser and version like chrome109", target)
+		}
+		name, ok := cssEngines[match[1]]
+		if !ok {
+			return nil, fmt.Errorf("🔴 css target %q: unknown browser %s", target, match[1])
+		}
+		engines = append(engines, api.Engine{Name: name, Version: match[2]})
+	}
+	return engines, nil
+}
+
+func lightningTargets(targets []string) string {
+	queries := make([]string, 0, len(targets))
+	for _, target := range targets {
+		match := cssTargetPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(target)))
+		if match == nil {
+			queries = append(queries, target)
+			continue
+		}
+		queries = append(queries, match[1]+" >= "+match[2])
+	}
+	return strings.Join(queries, ", ")
+}
@@ -0,0 +1,124 @@
+package alloy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DebugPath = "/_alloy/debug"
+
+type EvalProfile struct {
+	Since time.Time              `json:"since"`
+	Pages map[string]EvalSummary `json:"pages"`
+}
+
+type EvalSummary struct {
+	Evals       int64         `json:"evals"`
+	Errors      int64         `json:"errors"`
+	Total       time.Duration `json:"total"`
+	AvgDuration time.Duration `json:"avgDuration"`
+	MaxDuration time.Duration `json:"maxDuration"`
+}
+
+type evalPageKey struct{}
+
+var evalProfile = struct {
+	sync.Mutex
+	since time.Time
+	pages map[string]*EvalSummary
+}{
+	since: time.Now(),
+	pages: map[string]*EvalSummary{},
+}
+
+func debugEnabled() bool {
+	if os.Getenv("ALLOY_DEV") == "1" {
+		return true
+	}
+	cfg := getConfig()
+	return cfg != nil && cfg.Debug
+}
+
+func withEvalPage(ctx context.Context, component string) context.Context {
+	return context.WithValue(ctx, evalPageKey{}, pageName(component))
+}
+
+func recordEval(ctx context.Context, elapsed time.Duration, err error) {
+	if !debugEnabled() {
+		return
+	}
+	page, _ := ctx.Value(evalPageKey{}).(string)
+	if page == "" {
+		page = "(unknown)"
+	}
+
+	evalProfile.Lock()
+	defer evalProfile.Unlock()
+	s := evalProfile.pages[page]
+	if s == nil {
+		s = &EvalSummary{}
+		evalProfile.pages[page] = s
+	}
+	s.Evals++
+	if err != nil {
+		s.Errors++
+	}
+	s.Total += elapsed
+	s.MaxDuration = max(s.MaxDuration, elapsed)
+}
+
+func CurrentEvalProfile() EvalProfile {
+	evalProfile.Lock()
+	defer evalProfile.Unlock()
+	profile := EvalProfile{Since: evalProfile.since.UTC(), Pages: make(map[string]EvalSummary, len(evalProfile.pages))}
+	for page, s := range evalProfile.pages {
+		summary := *s
+		summary.AvgDuration = s.Total / time.Duration(s.Evals)
+		profile.Pages[page] = summary
+	}
+	return profile
+}
+
+func ResetEvalProfile() {
+	evalProfile.Lock()
+	defer evalProfile.Unlock()
+	evalProfile.since = time.Now()
+	evalProfile.pages = map[string]*EvalSummary{}
+}
+
+func DebugHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(DebugPath, http.RedirectHandler(DebugPath+"/pprof/", http.StatusFound))
+	mux.HandleFunc(DebugPath+"/pprof/", func(w http.ResponseWriter, r *http.Request) {
+		switch name := strings.TrimPrefix(r.URL.Path, DebugPath+"/pprof/"); name {
+		case "":
+			pprof.Index(w, r)
+		case "cmdline":
+			pprof.Cmdline(w, r)
+		case "profile":
+			pprof.Profile(w, r)
+		case "symbol":
+			pprof.Symbol(w, r)
+		case "trace":
+			pprof.Trace(w, r)
+		default:
+			pprof.Handler(name).ServeHTTP(w, r)
+		}
+	})
+	mux.HandleFunc(DebugPath+"/eval", func(w http.ResponseWriter, r *http.Request) {
+		profile := CurrentEvalProfile()
+		if r.URL.Query().Has("reset") {
+			ResetEvalProfile()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(profile)
+	})
+	return mux
+}
@@ -0,0 +1,48 @@
+package alloy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDebugEndpoints(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) { cfg.Debug = false })
+	handler := AssetsMiddleware()(http.NotFoundHandler())
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DebugPath+"/pprof/", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("🔴 expected debug endpoints to be off, got %d", rec.Code)
+	}
+
+	withTestConfig(t, func(cfg *Config) { cfg.Debug = true })
+	ResetEvalProfile()
+	t.Cleanup(ResetEvalProfile)
+	code := `var __Component = function() { return "<p>home</p>"; };`
+	for range 2 {
+		if _, err := executeSSR(withEvalPage(t.Context(), "app/pages/home.tsx"), code, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DebugPath+"/eval?reset", nil))
+	var profile EvalProfile
+	if err := json.NewDecoder(rec.Body).Decode(&profile); err != nil {
+		t.Fatal(err)
+	}
+	if home := profile.Pages["home"]; home.Evals != 2 || home.Total <= 0 || home.MaxDuration < home.AvgDuration {
+		t.Fatalf("🔴 unexpected eval profile %+v", profile)
+	}
+	if len(CurrentEvalProfile().Pages) != 0 {
+		t.Fatal("🔴 expected reset to clear the profile")
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DebugPath+"/pprof/goroutine?debug=1", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "goroutine profile") {
+		t.Fatalf("🔴 expected goroutine profile, got %d: %.200s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,140 @@
+package alloy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+const devWatchInterval = 100 * time.Millisecond
+
+type devGraph struct {
+	mu     sync.Mutex
+	deps   map[string][]string
+	mtimes map[string]time.Time
+}
+
+func newDevGraph() *devGraph {
+	return &devGraph{deps: map[string][]string{}, mtimes: map[string]time.Time{}}
+}
+
+func (g *devGraph) update(page string, inputs []string) {
+	if len(inputs) == 0 {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.deps[page] = inputs
+	for _, input := range inputs {
+		if _, ok := g.mtimes[input]; !ok {
+			g.mtimes[input] = fileModTime(input)
+		}
+	}
+}
+
+func (g *devGraph) remove(page string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.deps, page)
+	tracked := map[string]bool{}
+	for _, inputs := range g.deps {
+		for _, input := range inputs {
+			tracked[input] = true
+		}
+	}
+	for file := range g.mtimes {
+		if !tracked[file] {
+			delete(g.mtimes, file)
+		}
+	}
+}
+
+func (g *devGraph) pages(file string) []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var pages []string
+	for _, page := range sortedKeys(g.deps) {
+		for _, input := range g.deps[page] {
+			if input == file {
+				pages = append(pages, page)
+				break
+			}
+		}
+	}
+	return pages
+}
+
+func (g *devGraph) changed() map[string]string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	changed := map[string]bool{}
+	for file, prev := range g.mtimes {
+		if mod := fileModTime(file); !mod.Equal(prev) {
+			g.mtimes[file] = mod
+			changed[file] = true
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	triggers := map[string]string{}
+	for page, inputs := range g.deps {
+		for _, input := range inputs {
+			if changed[input] && (triggers[page] == "" || input < triggers[page]) {
+				triggers[page] = input
+			}
+		}
+	}
+	return triggers
+}
+
+func fileModTime(file string) time.Time {
+	info, err := os.Stat(file)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+func watchDevGraph(ctx context.Context, graph *devGraph, interval time.Duration, rebuild func(page string, trigger string)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			triggers := graph.changed()
+			for _, page := range sortedKeys(triggers) {
+				rebuild(page, triggers[page])
+			}
+		}
+	}
+}
+
+func clientPageInputs(result api.BuildResult, page string, cwd string) []string {
+	if result.Metafile == "" {
+		return nil
+	}
+	meta, err := parseMetafile(result.Metafile)
+	if err != nil {
+		return nil
+	}
+	outputs := make([]string, 0, len(meta.Outputs))
+	for out := range meta.Outputs {
+		outputs = append(outputs, out)
+	}
+	sort.Strings(outputs)
+	for _, out := range outputs {
+		if filepath.Base(out) == page+"-client.js" {
+			return meta.sourceInputs(out, cwd)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package alloy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDevGraphRebuildsOnlyAffectedPages(t *testing.T) {
+	dir := t.TempDir()
+	home := filepath.Join(dir, "home.tsx")
+	about := filepath.Join(dir, "about.tsx")
+	shared := filepath.Join(dir, "button.tsx")
+	for _, file := range []string{home, about, shared} {
+		writeTestFile(t, file, "export default 1;\n")
+	}
+
+	graph := newDevGraph()
+	graph.update("home", []string{home, shared})
+	graph.update("about", []string{about, shared})
+	if got := graph.pages(shared); !reflect.DeepEqual(got, []string{"about", "home"}) {
+		t.Fatalf("🔴 pages(shared) = %v", got)
+	}
+
+	later := time.Now()
+	touch := func(file string) {
+		t.Helper()
+		later = later.Add(time.Minute)
+		if err := os.Chtimes(file, later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if triggers := graph.changed(); triggers != nil {
+		t.Fatalf("🔴 expected no changes, got %v", triggers)
+	}
+	touch(home)
+	if triggers := graph.changed(); !reflect.DeepEqual(triggers, map[string]string{"home": home}) {
+		t.Fatalf("🔴 expected only home to rebuild, got %v", triggers)
+	}
+	touch(shared)
+	if triggers := graph.changed(); !reflect.DeepEqual(triggers, map[string]string{"home": shared, "about": shared}) {
+		t.Fatalf("🔴 expected both pages to rebuild, got %v", triggers)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var mu sync.Mutex
+	rebuilt := map[string]string{}
+	done := make(chan error, 1)
+	go func() {
+		done <- watchDevGraph(ctx, graph, time.Millisecond, func(page string, trigger string) {
+			mu.Lock()
+			rebuilt[page] = trigger
+			mu.Unlock()
+		})
+	}()
+	touch(about)
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		got := rebuilt["about"]
+		mu.Unlock()
+		if got == about {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("🔴 expected about to rebuild, got %v", rebuilt)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := rebuilt["home"]; ok {
+		t.Fatalf("🔴 expected home to be left alone, got %v", rebuilt)
+	}
+}
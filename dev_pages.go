@@ -0,0 +1,372 @@
+package alloy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/evanw/esbuild/pkg/api"
+	"golang.org/x/sync/errgroup"
+)
+
+const devPagesInterval = 500 * time.Millisecond
+
+type devWatcher struct {
+	ctx       context.Context
+	g         *errgroup.Group
+	distDir   string
+	cwd       string
+	serverTmp string
+	clientTmp string
+	graph     *devGraph
+
+	mu    sync.Mutex
+	pages map[string]*devPage
+	css   map[string]bool
+
+	manifest struct {
+		sync.Mutex
+		pending bool
+		removed []string
+		lastErr string
+	}
+}
+
+type devPage struct {
+	spec   PageSpec
+	server api.BuildContext
+	client api.BuildContext
+}
+
+func WatchPagesAndBuild(ctx context.Context, pagesDir string, distDir string, buildDone chan<- struct{}) error {
+	pages, err := DiscoverPages(pagesDir)
+	if err != nil {
+		return err
+	}
+	return watchDev(ctx, pagesDir, pages, distDir, buildDone)
+}
+
+func watchDev(ctx context.Context, pagesDir string, pages []PageSpec, distDir string, buildDone chan<- struct{}) error {
+	cwd, _ := os.Getwd()
+	os.Remove(filepath.Join(distDir, devStatusFile))
+
+	if err := BuildDevBundles(pages, distDir); err != nil {
+		return fmt.Errorf("🔴 initial build: %w", err)
+	}
+
+	logger().Info("initial build complete", "pages", len(pages), "dist", distDir)
+
+	if buildDone != nil {
+		close(buildDone)
+	}
+
+	w, err := newDevWatcher(ctx, distDir, cwd)
+	if err != nil {
+		return err
+	}
+	defer w.close()
+
+	for _, page := range pages {
+		if err := w.add(page, false); err != nil {
+			return err
+		}
+	}
+
+	w.g.Go(func() error {
+		return watchDevGraph(ctx, w.graph, devWatchInterval, w.rebuild)
+	})
+	w.watchCSS()
+	w.writeManifest()
+	w.g.Go(w.watchManifest)
+
+	if pagesDir != "" {
+		w.g.Go(func() error {
+			return w.watchPagesDir(pagesDir)
+		})
+	}
+
+	return w.g.Wait()
+}
+
+func newDevWatcher(ctx context.Context, distDir string, cwd string) (*devWatcher, error) {
+	serverTmp, err := os.MkdirTemp("", "alloy-server-")
+	if err != nil {
+		return nil, fmt.Errorf("🔴 create server temp dir: %w", err)
+	}
+	clientTmp, err := os.MkdirTemp("", "alloy-client-")
+	if err != nil {
+		os.RemoveAll(serverTmp)
+		return nil, fmt.Errorf("🔴 create client temp: %w", err)
+	}
+	return &devWatcher{
+		ctx:       ctx,
+		g:         &errgroup.Group{},
+		distDir:   distDir,
+		cwd:       cwd,
+		serverTmp: serverTmp,
+		clientTmp: clientTmp,
+		graph:     newDevGraph(),
+		pages:     map[string]*devPage{},
+		css:       map[string]bool{},
+	}, nil
+}
+
+func (w *devWatcher) close() {
+	w.mu.Lock()
+	for _, page := range w.pages {
+		page.dispose()
+	}
+	w.pages = map[string]*devPage{}
+	w.mu.Unlock()
+	os.RemoveAll(w.serverTmp)
+	os.RemoveAll(w.clientTmp)
+}
+
+func (w *devWatcher) add(spec PageSpec, build bool) error {
+	absComponent, err := resolveAbsPath(spec.Component, "component path")
+	if err != nil {
+		return err
+	}
+	page := &devPage{spec: spec}
+
+	entryPath := filepath.Join(w.serverTmp, spec.Name+"-entry.tsx")
+	if err := os.WriteFile(entryPath, []byte(generateServerEntryCode(absComponent)), 0644); err != nil {
+		return fmt.Errorf("🔴 write entry: %w", err)
+	}
+
+	opts := commonBuildOptions()
+	opts.EntryPoints = []string{entryPath}
+	opts.Write = true
+	opts.Outfile = filepath.Join(w.distDir, fmt.Sprintf("%s-server.js", spec.Name))
+	opts.Format = api.FormatIIFE
+	opts.GlobalName = "__Component"
+	applyServerPlatform(&opts)
+	opts.Plugins = append(opts.Plugins, devStatusPlugin(w.distDir, "server", spec.Name))
+	applyAssetLoaders(&opts, w.distDir)
+	disableMinify(&opts)
+
+	serverCtx, err := api.Context(opts)
+	if err := checkContextError(err, fmt.Sprintf("create server context %s", spec.Name)); err != nil {
+		return err
+	}
+	page.server = serverCtx
+	if build {
+		serverCtx.Rebuild()
+	}
+	if err := serverCtx.Watch(api.WatchOptions{}); err != nil {
+		page.dispose()
+		return fmt.Errorf("🔴 watch server %s: %w", spec.Name, err)
+	}
+
+	clientEntry := filepath.Join(w.clientTmp, spec.Name+".tsx")
+	if err := os.WriteFile(clientEntry, []byte(generateClientEntryCode(absComponent, spec.RootID)), 0644); err != nil {
+		page.dispose()
+		return fmt.Errorf("🔴 write client entry: %w", err)
+	}
+
+	opts = commonBuildOptions()
+	opts.EntryPointsAdvanced = []api.EntryPoint{{InputPath: clientEntry, OutputPath: spec.Name}}
+	opts.Outdir = w.distDir
+	opts.Splitting = true
+	opts.Format = api.FormatESModule
+	opts.Write = true
+	opts.Metafile = true
+	opts.EntryNames = "[name]-client"
+	opts.ChunkNames = "chunk-[hash]"
+	applyAssetLoaders(&opts, w.distDir)
+	disableMinify(&opts)
+	if err := addVendorEntries(&opts, w.clientTmp); err != nil {
+		page.dispose()
+		return err
+	}
+	opts.Plugins = append(opts.Plugins, devStatusPlugin(w.distDir, "client", spec.Name))
+
+	clientCtx, err := api.Context(opts)
+	if err := checkContextError(err, fmt.Sprintf("create client context %s", spec.Name)); err != nil {
+		page.dispose()
+		return err
+	}
+	page.client = clientCtx
+	inputs := clientPageInputs(clientCtx.Rebuild(), spec.Name, w.cwd)
+	if len(inputs) == 0 {
+		inputs = []string{absComponent}
+	}
+	w.graph.update(spec.Name, inputs)
+
+	w.mu.Lock()
+	w.pages[spec.Name] = page
+	w.mu.Unlock()
+	return nil
+}
+
+func (w *devWatcher) remove(name string) {
+	w.mu.Lock()
+	page := w.pages[name]
+	delete(w.pages, name)
+	w.mu.Unlock()
+	if page == nil {
+		return
+	}
+	page.dispose()
+	w.graph.remove(name)
+	forgetDevStatus(w.distDir, name)
+	os.Remove(filepath.Join(w.serverTmp, name+"-entry.tsx"))
+	os.Remove(filepath.Join(w.clientTmp, name+".tsx"))
+	os.Remove(filepath.Join(w.distDir, name+"-server.js"))
+	os.Remove(filepath.Join(w.distDir, name+"-client.js"))
+}
+
+func (w *devWatcher) rebuild(name string, trigger string) {
+	w.mu.Lock()
+	page := w.pages[name]
+	w.mu.Unlock()
+	if page == nil {
+		return
+	}
+	started := time.Now()
+	result := page.client.Rebuild()
+	w.graph.update(name, clientPageInputs(result, name, w.cwd))
+	recordDevTrigger(w.distDir, name, trigger)
+	logger().Info("rebuild client", "page", name, "trigger", FormatPath(trigger), "errors", len(result.Errors), "duration", time.Since(started))
+	w.retryManifest()
+}
+
+func (w *devWatcher) writeManifest(removed ...string) {
+	w.manifest.Lock()
+	defer w.manifest.Unlock()
+	w.manifest.removed = append(w.manifest.removed, removed...)
+	if err := writeDevManifest(w.specs(), w.distDir, w.manifest.removed...); err != nil {
+		w.manifest.pending = true
+		if msg := err.Error(); msg != w.manifest.lastErr {
+			w.manifest.lastErr = msg
+			logger().Warn("manifest not written, waiting for a clean build", "dist", FormatPath(w.distDir), "err", err)
+		}
+		return
+	}
+	if w.manifest.pending {
+		logger().Info("manifest written", "dist", FormatPath(w.distDir))
+	}
+	w.manifest.pending = false
+	w.manifest.removed = nil
+	w.manifest.lastErr = ""
+}
+
+func (w *devWatcher) retryManifest() {
+	w.manifest.Lock()
+	pending := w.manifest.pending
+	w.manifest.Unlock()
+	if pending {
+		w.writeManifest()
+	}
+}
+
+func (w *devWatcher) watchManifest() error {
+	ticker := time.NewTicker(devPagesInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.ctx.Done():
+			return nil
+		case <-ticker.C:
+			w.retryManifest()
+		}
+	}
+}
+
+func (w *devWatcher) specs() []PageSpec {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	specs := make([]PageSpec, 0, len(w.pages))
+	for _, name := range sortedKeys(w.pages) {
+		specs = append(specs, w.pages[name].spec)
+	}
+	return specs
+}
+
+func (w *devWatcher) watchCSS() {
+	for _, entry := range CSSEntriesForPages(w.specs()) {
+		w.mu.Lock()
+		started := w.css[entry.Name]
+		w.css[entry.Name] = true
+		w.mu.Unlock()
+		if started {
+			continue
+		}
+		w.g.Go(func() error {
+			return WatchCSS(w.ctx, entry.Input, filepath.Join(w.distDir, entry.Name+".css"), w.cwd)
+		})
+	}
+}
+
+func (w *devWatcher) watchPagesDir(pagesDir string) error {
+	ticker := time.NewTicker(devPagesInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := w.syncPages(pagesDir); err != nil {
+				logger().Error("sync pages", "dir", FormatPath(pagesDir), "err", err)
+			}
+		}
+	}
+}
+
+func (w *devWatcher) syncPages(pagesDir string) error {
+	found, err := DiscoverPages(pagesDir)
+	if err != nil {
+		return err
+	}
+
+	current := map[string]bool{}
+	for _, spec := range w.specs() {
+		current[spec.Name] = true
+	}
+	seen := map[string]bool{}
+	var added []PageSpec
+	for _, spec := range found {
+		seen[spec.Name] = true
+		if !current[spec.Name] {
+			added = append(added, spec)
+		}
+	}
+	var removed []string
+	for _, name := range sortedKeys(current) {
+		if !seen[name] {
+			removed = append(removed, name)
+		}
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	var errs []error
+	for _, name := range removed {
+		w.remove(name)
+		logger().Info("page removed", "page", name)
+	}
+	for _, spec := range added {
+		if err := w.add(spec, true); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		logger().Info("page added", "page", spec.Name, "component", FormatPath(spec.Component))
+	}
+	w.watchCSS()
+	w.writeManifest(removed...)
+	return errors.Join(errs...)
+}
+
+func (p *devPage) dispose() {
+	if p.server != nil {
+		p.server.Dispose()
+	}
+	if p.client != nil {
+		p.client.Dispose()
+	}
+}
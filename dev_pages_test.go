@@ -0,0 +1,132 @@
+package alloy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDevWatcherForgetsDeletedPages(t *testing.T) {
+	dir := t.TempDir()
+	pagesDir := filepath.Join(dir, "pages")
+	distDir := filepath.Join(dir, "dist")
+	about := filepath.Join(pagesDir, "about.tsx")
+	writeTestFile(t, about, "export default function About() { return null; }\n")
+	for _, name := range []string{"about", "pricing"} {
+		writeTestFile(t, filepath.Join(distDir, name+"-server.js"), "var __Component = { default: function(props){ return ''; } };")
+		writeTestFile(t, filepath.Join(distDir, name+"-client.js"), "")
+	}
+
+	w, err := newDevWatcher(context.Background(), distDir, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(w.close)
+	for _, name := range []string{"about", "pricing"} {
+		w.pages[name] = &devPage{spec: PageSpec{Name: name, Component: filepath.Join(pagesDir, name+".tsx"), RootID: defaultRootID(name)}}
+	}
+	w.graph.update("pricing", []string{filepath.Join(pagesDir, "pricing.tsx")})
+	if err := writeDevManifest(w.specs(), distDir); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.syncPages(pagesDir); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := w.pages["pricing"]; ok || len(w.graph.deps) != 0 {
+		t.Fatalf("🔴 expected pricing to be dropped, got pages %v, deps %v", sortedKeys(w.pages), w.graph.deps)
+	}
+	if _, err := os.Stat(filepath.Join(distDir, "pricing-client.js")); !os.IsNotExist(err) {
+		t.Fatalf("🔴 expected pricing bundles to be removed, got %v", err)
+	}
+	manifest, err := ReadManifest(os.DirFS(distDir), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := manifest.Pages["pricing"]; ok || manifest.Pages["about"].Server != "about-server.js" {
+		t.Fatalf("🔴 manifest pages = %v", manifest.Pages)
+	}
+}
+
+func TestDevManifestWaitsForValidOutputs(t *testing.T) {
+	distDir := t.TempDir()
+	writeTestFile(t, filepath.Join(distDir, "home-server.js"), "var __Component = { default: function(props){ return ''; } };")
+	writeTestFile(t, filepath.Join(distDir, "home-client.js"), "")
+	home := PageSpec{Name: "home", Component: "pages/home.tsx", RootID: defaultRootID("home")}
+	if err := writeDevManifest([]PageSpec{home}, distDir); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.ReadFile(filepath.Join(distDir, "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeTestFile(t, filepath.Join(distDir, "broken-server.js"), "var __Component = ;")
+	pages := []PageSpec{home, {Name: "broken", Component: "pages/broken.tsx"}, {Name: "pricing", Component: "pages/pricing.tsx"}}
+	err = writeDevManifest(pages, distDir)
+	if err == nil || !strings.Contains(err.Error(), "page broken") || !strings.Contains(err.Error(), "pricing-server.js") {
+		t.Fatalf("🔴 expected invalid outputs to block the manifest, got %v", err)
+	}
+	after, _ := os.ReadFile(filepath.Join(distDir, "manifest.json"))
+	if string(after) != string(before) {
+		t.Fatalf("🔴 expected the previous manifest to be kept, got %s", after)
+	}
+	if _, err := os.Stat(filepath.Join(distDir, "manifest.json.tmp")); !os.IsNotExist(err) {
+		t.Fatalf("🔴 expected no temp manifest, got %v", err)
+	}
+}
+
+func TestDevManifestSkipsEvalWithRenderer(t *testing.T) {
+	distDir := t.TempDir()
+	writeTestFile(t, filepath.Join(distDir, "home-server.js"), `var fs = require("node:fs"); var __Component = { default: function() { return ""; } };`)
+	writeTestFile(t, filepath.Join(distDir, "home-client.js"), "")
+	home := PageSpec{Name: "home", Component: "pages/home.tsx", RootID: defaultRootID("home")}
+
+	withTestConfig(t, func(cfg *Config) {})
+	if err := writeDevManifest([]PageSpec{home}, distDir); err == nil {
+		t.Fatal("🔴 expected QuickJS to reject the node bundle")
+	}
+	getConfig().Renderer = &NodeRenderer{}
+	if err := writeDevManifest([]PageSpec{home}, distDir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(distDir, "manifest.json")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDevWatcherBuildsVendorChunks(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	lib := filepath.Join(dir, "node_modules", "lib", "index.js")
+	writeTestFile(t, lib, "export const lib = 'lib';\n")
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react", "jsx-runtime.js"), "exports.jsx = () => null;\n")
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react-dom", "client.js"), "exports.hydrateRoot = () => null; exports.createRoot = () => null;\n")
+	withTestConfig(t, func(cfg *Config) {
+		cfg.VendorChunks = map[string][]string{"lib": {"lib"}}
+	})
+	component := filepath.Join(dir, "pages", "home.tsx")
+	writeTestFile(t, component, "import { lib } from 'lib';\nexport default function Home() { return lib; }\n")
+	distDir := filepath.Join(dir, "dist")
+
+	w, err := newDevWatcher(context.Background(), distDir, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(w.close)
+	if err := w.add(PageSpec{Name: "home", Component: component, RootID: "home"}, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(distDir, vendorEntryPrefix+"lib-client.js")); err != nil {
+		t.Fatalf("🔴 expected a dev vendor chunk: %v", err)
+	}
+	client, err := os.ReadFile(filepath.Join(distDir, "home-client.js"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(client), "'lib'") || strings.Contains(string(client), `"lib";`) {
+		t.Fatalf("🔴 vendor code bundled into the page:\n%s", client)
+	}
+}
@@ -0,0 +1,155 @@
+package alloy
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+const (
+	DevStatusPath = "/_alloy/status"
+	devStatusFile = "_status.json"
+)
+
+type DevStatus struct {
+	OK        bool                       `json:"ok"`
+	UpdatedAt time.Time                  `json:"updatedAt"`
+	Pages     map[string]PageBuildStatus `json:"pages"`
+}
+
+type PageBuildStatus struct {
+	OK     bool         `json:"ok"`
+	Server *BuildStatus `json:"server,omitempty"`
+	Client *BuildStatus `json:"client,omitempty"`
+}
+
+type BuildStatus struct {
+	OK        bool          `json:"ok"`
+	StartedAt time.Time     `json:"startedAt"`
+	Duration  time.Duration `json:"duration"`
+	Trigger   string        `json:"trigger,omitempty"`
+	Errors    []*BuildError `json:"errors,omitempty"`
+	Warnings  []*BuildError `json:"warnings,omitempty"`
+}
+
+var devStatus = struct {
+	sync.Mutex
+	status DevStatus
+}{
+	status: DevStatus{Pages: map[string]PageBuildStatus{}},
+}
+
+func devStatusPlugin(distDir string, target string, pages ...string) api.Plugin {
+	return api.Plugin{
+		Name: "alloy-dev-status",
+		Setup: func(build api.PluginBuild) {
+			var started time.Time
+			build.OnStart(func() (api.OnStartResult, error) {
+				started = time.Now()
+				return api.OnStartResult{}, nil
+			})
+			build.OnEnd(func(result *api.BuildResult) (api.OnEndResult, error) {
+				recordDevBuild(distDir, target, pages, started, result)
+				return api.OnEndResult{}, nil
+			})
+		},
+	}
+}
+
+func recordDevBuild(distDir string, target string, pages []string, started time.Time, result *api.BuildResult) {
+	status := &BuildStatus{OK: len(result.Errors) == 0, StartedAt: started.UTC(), Duration: time.Since(started)}
+	for _, msg := range result.Errors {
+		status.Errors = append(status.Errors, newBuildError(target, msg))
+	}
+	for _, msg := range result.Warnings {
+		status.Warnings = append(status.Warnings, newBuildError(target, msg))
+	}
+
+	devStatus.Lock()
+	defer devStatus.Unlock()
+	for _, page := range pages {
+		entry := devStatus.status.Pages[page]
+		if target == "client" {
+			entry.Client = status
+		} else {
+			entry.Server = status
+		}
+		entry.OK = (entry.Server == nil || entry.Server.OK) && (entry.Client == nil || entry.Client.OK)
+		devStatus.status.Pages[page] = entry
+	}
+	devStatus.status.OK = true
+	for _, entry := range devStatus.status.Pages {
+		devStatus.status.OK = devStatus.status.OK && entry.OK
+	}
+	writeDevStatus(distDir)
+}
+
+func recordDevTrigger(distDir string, page string, trigger string) {
+	devStatus.Lock()
+	defer devStatus.Unlock()
+	entry := devStatus.status.Pages[page]
+	if entry.Client == nil {
+		return
+	}
+	client := *entry.Client
+	client.Trigger = trigger
+	entry.Client = &client
+	devStatus.status.Pages[page] = entry
+	writeDevStatus(distDir)
+}
+
+func forgetDevStatus(distDir string, page string) {
+	devStatus.Lock()
+	defer devStatus.Unlock()
+	if _, ok := devStatus.status.Pages[page]; !ok {
+		return
+	}
+	delete(devStatus.status.Pages, page)
+	devStatus.status.OK = true
+	for _, entry := range devStatus.status.Pages {
+		devStatus.status.OK = devStatus.status.OK && entry.OK
+	}
+	writeDevStatus(distDir)
+}
+
+func writeDevStatus(distDir string) {
+	devStatus.status.UpdatedAt = time.Now().UTC()
+
+	data, err := json.MarshalIndent(devStatus.status, "", "  ")
+	if err != nil {
+		return
+	}
+	file := filepath.Join(distDir, devStatusFile)
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		logger().Error("write dev status", "file", file, "err", err)
+		return
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		logger().Error("write dev status", "file", file, "err", err)
+	}
+}
+
+func DevStatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		distDir := DefaultDistDir
+		if cfg := getConfig(); cfg != nil && cfg.DistDir != "" {
+			distDir = cfg.DistDir
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		data, err := os.ReadFile(filepath.Join(distDir, devStatusFile))
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(DevStatus{Pages: map[string]PageBuildStatus{}})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+}
@@ -0,0 +1,64 @@
+package alloy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+func TestDevStatusReportsBuildErrors(t *testing.T) {
+	dist := t.TempDir()
+	t.Setenv("ALLOY_DEV", "1")
+	withTestConfig(t, func(cfg *Config) {
+		cfg.DistDir = dist
+	})
+
+	started := time.Now().Add(-20 * time.Millisecond)
+	recordDevBuild(dist, "client", []string{"status-home", "status-about"}, started, &api.BuildResult{})
+	recordDevBuild(dist, "server", []string{"status-home"}, started, &api.BuildResult{Errors: []api.Message{{
+		Text:     "Unexpected \"}\"",
+		Location: &api.Location{File: "app/pages/status-home.tsx", Line: 3, Column: 9},
+	}}})
+
+	handler := AssetsMiddleware()(http.NotFoundHandler())
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DevStatusPath, nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("status = %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+
+	var status DevStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatal(err)
+	}
+	if status.OK {
+		t.Error("expected overall status to fail")
+	}
+	home := status.Pages["status-home"]
+	if home.OK || home.Server == nil || len(home.Server.Errors) != 1 {
+		t.Fatalf("home = %+v", home)
+	}
+	if err := home.Server.Errors[0]; err.File != "app/pages/status-home.tsx" || err.Line != 3 || err.Column != 10 {
+		t.Errorf("error = %+v", err)
+	}
+	if home.Client == nil || !home.Client.OK || home.Client.Duration < 20*time.Millisecond {
+		t.Errorf("client = %+v", home.Client)
+	}
+	if about := status.Pages["status-about"]; !about.OK {
+		t.Errorf("about = %+v", about)
+	}
+}
+
+func TestDevStatusOnlyInDev(t *testing.T) {
+	t.Setenv("ALLOY_DEV", "")
+	handler := AssetsMiddleware()(http.NotFoundHandler())
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DevStatusPath, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d", rec.Code)
+	}
+}
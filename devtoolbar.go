@@ -0,0 +1,195 @@
+package alloy
+
+import (
+	"context"
+	"encoding/json"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+var devToolbarTemplate = sync.OnceValue(func() *template.Template {
+	return template.Must(template.New("devtoolbar").Parse(MustReadAsset("assets/devtoolbar.html")))
+})
+
+const DevPropsPath = "/_alloy/props"
+
+var routeParamPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)(?:\.\.\.)?\}`)
+
+type pageDebug struct {
+	mu     sync.Mutex
+	page   string
+	path   string
+	route  string
+	edited bool
+	params map[string]string
+	props  map[string]any
+	loader time.Duration
+	ssr    time.Duration
+	files  PrebuiltFiles
+}
+
+type pageDebugKey struct{}
+
+func withPageDebug(r *http.Request, component string) *http.Request {
+	if os.Getenv("ALLOY_DEV") != "1" {
+		return r
+	}
+	debug := &pageDebug{page: pageName(component), path: r.URL.RequestURI(), route: r.Pattern, params: map[string]string{}}
+	for _, match := range routeParamPattern.FindAllStringSubmatch(r.Pattern, -1) {
+		debug.params[match[1]] = r.PathValue(match[1])
+	}
+	return r.WithContext(context.WithValue(r.Context(), pageDebugKey{}, debug))
+}
+
+func pageDebugFrom(ctx context.Context) *pageDebug {
+	debug, _ := ctx.Value(pageDebugKey{}).(*pageDebug)
+	return debug
+}
+
+func (d *pageDebug) record(fn func(d *pageDebug)) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	fn(d)
+	d.mu.Unlock()
+}
+
+type devPropsKey struct{}
+
+func devPropsHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		target, err := url.Parse(r.FormValue("path"))
+		if err != nil || target.Scheme != "" || target.Host != "" || !strings.HasPrefix(target.Path, "/") {
+			http.Error(w, "🔴 path must be a page path like /posts/hello", http.StatusBadRequest)
+			return
+		}
+		var props map[string]any
+		if err := json.Unmarshal([]byte(r.FormValue("props")), &props); err != nil || props == nil {
+			http.Error(w, "🔴 props must be a JSON object", http.StatusBadRequest)
+			return
+		}
+
+		page := r.Clone(context.WithValue(r.Context(), devPropsKey{}, props))
+		page.Method = http.MethodGet
+		page.URL = target
+		page.RequestURI = target.RequestURI()
+		page.Body = http.NoBody
+		page.ContentLength = 0
+		page.Form, page.PostForm = nil, nil
+		page.Header.Del("Content-Type")
+		w.Header().Set("Cache-Control", "no-store")
+		next.ServeHTTP(w, page)
+	})
+}
+
+func devProps(ctx context.Context) (map[string]any, bool) {
+	props, ok := ctx.Value(devPropsKey{}).(map[string]any)
+	return props, ok
+}
+
+type devToolbarRow struct {
+	Name  string
+	Value string
+}
+
+type devToolbarFile struct {
+	Name string
+	Size string
+}
+
+func injectDevToolbar(ctx context.Context, html string) string {
+	debug := pageDebugFrom(ctx)
+	if debug == nil {
+		return html
+	}
+	i := strings.LastIndex(html, "</body>")
+	if i < 0 {
+		return html
+	}
+
+	debug.mu.Lock()
+	defer debug.mu.Unlock()
+
+	props, err := json.MarshalIndent(debug.props, "", "  ")
+	if err != nil {
+		props = []byte(err.Error())
+	}
+
+	data := struct {
+		Page   string
+		Path   string
+		Edited bool
+		Loader string
+		SSR    string
+		Route  string
+		Params []devToolbarRow
+		Files  []devToolbarFile
+		Total  string
+		Props  string
+	}{
+		Page:   debug.page,
+		Path:   debug.path,
+		Edited: debug.edited,
+		Loader: formatDebugDuration(debug.loader),
+		SSR:    formatDebugDuration(debug.ssr),
+		Route:  debug.route,
+		Props:  string(props),
+	}
+	for _, name := range sortedKeys(debug.params) {
+		data.Params = append(data.Params, devToolbarRow{Name: name, Value: debug.params[name]})
+	}
+
+	var total int64
+	filesystem := getConfig().FS
+	for _, name := range append(append([]string{debug.files.Client}, debug.files.ClientChunks...), debug.files.CSS) {
+		if name == "" {
+			continue
+		}
+		size, ok := debugFileSize(filesystem, name)
+		if !ok {
+			continue
+		}
+		total += size
+		data.Files = append(data.Files, devToolbarFile{Name: path.Base(name), Size: FormatSize(size)})
+	}
+	data.Total = FormatSize(total)
+
+	var b strings.Builder
+	if err := devToolbarTemplate().Execute(&b, data); err != nil {
+		return html
+	}
+	return html[:i] + b.String() + html[i:]
+}
+
+func debugFileSize(filesystem fs.FS, name string) (int64, bool) {
+	for _, fsys := range []fs.FS{filesystem, os.DirFS(".")} {
+		if fsys == nil {
+			continue
+		}
+		if info, err := fs.Stat(fsys, name); err == nil {
+			return info.Size(), true
+		}
+	}
+	return 0, false
+}
+
+func formatDebugDuration(d time.Duration) string {
+	if d == 0 {
+		return "–"
+	}
+	return d.Round(10 * time.Microsecond).String()
+}
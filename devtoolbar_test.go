@@ -0,0 +1,135 @@
+package alloy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDevToolbarInjectedInDevMode(t *testing.T) {
+	resetBundleCache()
+	t.Cleanup(resetBundleCache)
+
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "post-server.js"), `var __Component = { default: function(props) { return "<p>" + props.slug + "</p>"; } };`)
+	writeTestFile(t, filepath.Join(dist, "client-post-AAAAAAAA.js"), "console.log('client')")
+	writeTestFile(t, filepath.Join(dist, "shared.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{"post": {"server": "post-server.js", "client": "client-post-AAAAAAAA.js", "css": "shared.css"}}`)
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle("/posts/{slug}", NewPage(filepath.Join(root, "pages", "post.tsx")).WithLoader(func(r *http.Request) map[string]any {
+		return map[string]any{"slug": r.PathValue("slug"), "note": "<script>"}
+	}))
+
+	get := func() string {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/posts/hello", nil))
+		return rec.Body.String()
+	}
+
+	if body := get(); strings.Contains(body, "alloy-devtools") {
+		t.Fatalf("toolbar injected outside dev mode:\n%s", body)
+	}
+
+	t.Setenv("ALLOY_DEV", "1")
+	body := get()
+	toolbar := body[strings.Index(body, `<details id="alloy-devtools"`):]
+	for _, want := range []string{
+		"⚡ post · loader ",
+		"<b>route</b> /posts/{slug}",
+		`<td style="padding-right:12px;color:#9ca3af">slug</td><td>hello</td>`,
+		"client-post-AAAAAAAA.js",
+		"shared.css",
+		`&#34;note&#34;: &#34;\u003cscript\u003e&#34;`,
+	} {
+		if !strings.Contains(toolbar, want) {
+			t.Fatalf("toolbar missing %q:\n%s", want, toolbar)
+		}
+	}
+	if !strings.HasSuffix(strings.TrimSpace(toolbar), "</html>") || strings.Index(toolbar, "</details>") > strings.Index(toolbar, "</body>") {
+		t.Fatalf("toolbar not placed before </body>:\n%s", toolbar)
+	}
+}
+
+func TestDevPropsRerendersWithEditedProps(t *testing.T) {
+	resetBundleCache()
+	t.Cleanup(resetBundleCache)
+
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "post-server.js"), `var __Component = { default: function(props) { return "<p>" + props.slug + ":" + (props.tags || []).length + "</p>"; } };`)
+	writeTestFile(t, filepath.Join(dist, "client-post-AAAAAAAA.js"), "console.log('client')")
+	writeTestFile(t, filepath.Join(dist, "shared.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{"post": {"server": "post-server.js", "client": "client-post-AAAAAAAA.js", "css": "shared.css"}}`)
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+	})
+
+	loaderCalls := 0
+	mux := http.NewServeMux()
+	mux.Handle("/posts/{slug}", NewPage(filepath.Join(root, "pages", "post.tsx")).WithLoader(func(r *http.Request) map[string]any {
+		loaderCalls++
+		return map[string]any{"slug": r.PathValue("slug"), "tags": []string{"go"}}
+	}))
+	handler := AssetsMiddleware()(mux)
+
+	post := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, DevPropsPath, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	form := url.Values{"path": {"/posts/hello?ref=toolbar"}, "props": {`{"slug": "edge case", "tags": []}`}}
+
+	if rec := post(form); rec.Code != http.StatusNotFound {
+		t.Fatalf("props endpoint outside dev mode = %d", rec.Code)
+	}
+
+	t.Setenv("ALLOY_DEV", "1")
+	rec := post(form)
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK || !strings.Contains(body, "<p>edge case:0</p>") {
+		t.Fatalf("edited render = %d\n%s", rec.Code, body)
+	}
+	if loaderCalls != 0 {
+		t.Fatalf("loader ran %d times for edited props", loaderCalls)
+	}
+	for _, want := range []string{
+		`<b>route</b> /posts/{slug}`,
+		`<input type="hidden" name="path" value="/posts/hello?ref=toolbar">`,
+		`edited · <a href="/posts/hello?ref=toolbar"`,
+		`&#34;slug&#34;: &#34;edge case&#34;`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("toolbar missing %q:\n%s", want, body)
+		}
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "no-store" {
+		t.Errorf("Cache-Control = %q", got)
+	}
+
+	for _, bad := range []url.Values{
+		{"path": {"/posts/hello"}, "props": {"[1]"}},
+		{"path": {"https://example.com/posts/hello"}, "props": {"{}"}},
+	} {
+		if rec := post(bad); rec.Code != http.StatusBadRequest {
+			t.Errorf("%v status = %d", bad, rec.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, DevPropsPath, nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d", rec.Code)
+	}
+}
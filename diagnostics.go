@@ -0,0 +1,147 @@
+package alloy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+type DiagnosticFormat string
+
+const (
+	DiagnosticsText   DiagnosticFormat = "text"
+	DiagnosticsJSON   DiagnosticFormat = "json"
+	DiagnosticsGitHub DiagnosticFormat = "github"
+)
+
+type Diagnostic struct {
+	File     string          `json:"file,omitempty"`
+	Range    DiagnosticRange `json:"range"`
+	Severity string          `json:"severity"`
+	Code     string          `json:"code,omitempty"`
+	Source   string          `json:"source"`
+	Message  string          `json:"message"`
+}
+
+type DiagnosticRange struct {
+	Start DiagnosticPosition `json:"start"`
+	End   DiagnosticPosition `json:"end"`
+}
+
+type DiagnosticPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func ParseDiagnosticFormat(value string) (DiagnosticFormat, error) {
+	switch format := DiagnosticFormat(value); format {
+	case "", DiagnosticsText:
+		return DiagnosticsText, nil
+	case DiagnosticsJSON, DiagnosticsGitHub:
+		return format, nil
+	default:
+		return "", fmt.Errorf("🔴 unknown diagnostics format %q: want text, json or github", value)
+	}
+}
+
+func Diagnostics(err error) []Diagnostic {
+	if err == nil {
+		return nil
+	}
+	var buildErr *BuildError
+	if errors.As(err, &buildErr) && len(buildErr.Diagnostics) > 0 {
+		return buildErr.Diagnostics
+	}
+	diag := Diagnostic{Severity: "error", Source: "alloy", Message: strings.TrimSpace(strings.TrimPrefix(err.Error(), "🔴"))}
+	if buildErr != nil && buildErr.File != "" {
+		diag.File = buildErr.File
+		diag.Range.Start = DiagnosticPosition{Line: buildErr.Line, Column: buildErr.Column}
+		diag.Range.End = diag.Range.Start
+		diag.Source = "esbuild"
+		diag.Message = buildErr.Text
+	}
+	return []Diagnostic{diag}
+}
+
+func WriteDiagnostics(w io.Writer, format DiagnosticFormat, diags []Diagnostic) error {
+	switch format {
+	case DiagnosticsJSON:
+		if diags == nil {
+			diags = []Diagnostic{}
+		}
+		return json.NewEncoder(w).Encode(struct {
+			Diagnostics []Diagnostic `json:"diagnostics"`
+		}{diags})
+	case DiagnosticsGitHub:
+		for _, d := range diags {
+			command := "error"
+			if d.Severity == "warning" {
+				command = "warning"
+			}
+			var props []string
+			if d.File != "" {
+				props = append(props, "file="+githubProperty(d.File))
+				props = append(props, fmt.Sprintf("line=%d,col=%d,endLine=%d,endColumn=%d", d.Range.Start.Line, d.Range.Start.Column, d.Range.End.Line, d.Range.End.Column))
+			}
+			if d.Code != "" {
+				props = append(props, "title="+githubProperty(d.Code))
+			}
+			if len(props) > 0 {
+				command += " " + strings.Join(props, ",")
+			}
+			if _, err := fmt.Fprintf(w, "::%s::%s\n", command, githubMessage(d.Message)); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		for _, d := range diags {
+			location := ""
+			if d.File != "" {
+				location = fmt.Sprintf("%s:%d:%d: ", d.File, d.Range.Start.Line, d.Range.Start.Column)
+			}
+			if _, err := fmt.Fprintf(w, "🔴 %s%s\n", location, d.Message); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func messageDiagnostics(errs []api.Message, warnings []api.Message) []Diagnostic {
+	diags := make([]Diagnostic, 0, len(errs)+len(warnings))
+	for _, msg := range errs {
+		diags = append(diags, messageDiagnostic(msg, "error"))
+	}
+	for _, msg := range warnings {
+		diags = append(diags, messageDiagnostic(msg, "warning"))
+	}
+	return diags
+}
+
+func messageDiagnostic(msg api.Message, severity string) Diagnostic {
+	diag := Diagnostic{Severity: severity, Code: msg.ID, Source: "esbuild", Message: msg.Text}
+	if msg.PluginName != "" {
+		diag.Source = msg.PluginName
+	}
+	if loc := msg.Location; loc != nil {
+		diag.File = loc.File
+		diag.Range.Start = DiagnosticPosition{Line: loc.Line, Column: loc.Column + 1}
+		diag.Range.End = DiagnosticPosition{Line: loc.Line, Column: loc.Column + 1 + loc.Length}
+	}
+	return diag
+}
+
+var githubEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+
+func githubMessage(s string) string {
+	return githubEscaper.Replace(s)
+}
+
+func githubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
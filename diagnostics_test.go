@@ -0,0 +1,86 @@
+package alloy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+func TestDiagnosticsFromBuildError(t *testing.T) {
+	err := checkBuildErrors(api.BuildResult{
+		Errors: []api.Message{
+			{Text: "Unexpected \"}\"", Location: &api.Location{File: "app/pages/home.tsx", Line: 3, Column: 9, Length: 1}},
+			{Text: "Could not resolve \"./missing\"", Location: &api.Location{File: "app/pages/about.tsx", Line: 1, Column: 20, Length: 11}},
+		},
+		Warnings: []api.Message{
+			{ID: "unsupported-jsx-comment", Text: "Invalid JSX factory", Location: &api.Location{File: "app/pages/home.tsx", Line: 1, Column: 0}},
+		},
+	}, "esbuild server bundle home.tsx")
+
+	diags := Diagnostics(fmt.Errorf("🔴 build server: %w", err))
+	if len(diags) != 3 {
+		t.Fatalf("diagnostics = %+v", diags)
+	}
+	first := diags[0]
+	if first.File != "app/pages/home.tsx" || first.Severity != "error" || first.Source != "esbuild" {
+		t.Errorf("first = %+v", first)
+	}
+	if first.Range.Start != (DiagnosticPosition{Line: 3, Column: 10}) || first.Range.End != (DiagnosticPosition{Line: 3, Column: 11}) {
+		t.Errorf("range = %+v", first.Range)
+	}
+	if diags[2].Severity != "warning" || diags[2].Code != "unsupported-jsx-comment" {
+		t.Errorf("warning = %+v", diags[2])
+	}
+
+	var buf bytes.Buffer
+	if err := WriteDiagnostics(&buf, DiagnosticsJSON, diags); err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Diagnostics []Diagnostic `json:"diagnostics"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded.Diagnostics) != 3 {
+		t.Fatalf("json = %s (%v)", buf.String(), err)
+	}
+
+	buf.Reset()
+	if err := WriteDiagnostics(&buf, DiagnosticsGitHub, diags[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if want := "::error file=app/pages/home.tsx,line=3,col=10,endLine=3,endColumn=11::Unexpected \"}\"\n"; buf.String() != want {
+		t.Errorf("github = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestDiagnosticsFromPlainError(t *testing.T) {
+	diags := Diagnostics(errors.New("🔴 tailwind runner not found\nsecond line"))
+	if len(diags) != 1 || diags[0].File != "" || diags[0].Source != "alloy" || diags[0].Message != "tailwind runner not found\nsecond line" {
+		t.Fatalf("diagnostics = %+v", diags)
+	}
+
+	var buf bytes.Buffer
+	WriteDiagnostics(&buf, DiagnosticsGitHub, diags)
+	if !strings.HasPrefix(buf.String(), "::error::tailwind runner not found%0Asecond line") {
+		t.Errorf("github = %q", buf.String())
+	}
+
+	buf.Reset()
+	WriteDiagnostics(&buf, DiagnosticsJSON, nil)
+	if strings.TrimSpace(buf.String()) != `{"diagnostics":[]}` {
+		t.Errorf("empty json = %q", buf.String())
+	}
+}
+
+func TestParseDiagnosticFormat(t *testing.T) {
+	if format, err := ParseDiagnosticFormat(""); err != nil || format != DiagnosticsText {
+		t.Fatalf("default = %q, %v", format, err)
+	}
+	if _, err := ParseDiagnosticFormat("sarif"); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+}
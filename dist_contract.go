@@ -0,0 +1,129 @@
+package alloy
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+func ValidateDist(filesystem fs.FS, dist string) error {
+	manifest, err := ReadManifest(filesystem, dist)
+	if err != nil {
+		return err
+	}
+	if len(manifest.Pages) == 0 {
+		return fmt.Errorf("🔴 manifest has no pages")
+	}
+
+	var errs []error
+	for _, page := range sortedKeys(manifest.Pages) {
+		if err := validateManifestPage(filesystem, filepath.ToSlash(dist), manifest.Pages[page]); err != nil {
+			errs = append(errs, fmt.Errorf("🔴 page %s: %w", page, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func validateManifestPage(filesystem fs.FS, dist string, entry ManifestPage) error {
+	var errs []error
+	required := func(kind string, name string) []byte {
+		if name == "" {
+			errs = append(errs, fmt.Errorf("🔴 %s file required", kind))
+			return nil
+		}
+		data, err := fs.ReadFile(filesystem, path.Join(dist, name))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("🔴 %s %s: %w", kind, name, err))
+			return nil
+		}
+		if len(data) == 0 {
+			errs = append(errs, fmt.Errorf("🔴 %s %s is empty", kind, name))
+		}
+		return data
+	}
+
+	server := required("server", entry.Server)
+	client := entry.Client
+	if client == "" && len(entry.Chunks) > 0 {
+		client = entry.Chunks[0]
+	}
+	required("client", client)
+	required("css", entry.CSS)
+	for _, name := range append(append([]string{}, entry.Chunks...), entry.Assets...) {
+		required("file", name)
+	}
+	if entry.HTML != "" {
+		required("html", entry.HTML)
+	}
+	if entry.Legacy != "" {
+		required("legacy", entry.Legacy)
+	}
+	for _, locale := range sortedKeys(entry.Locales) {
+		required(locale+" client", entry.Locales[locale].Client)
+		if legacy := entry.Locales[locale].Legacy; legacy != "" {
+			required(locale+" legacy", legacy)
+		}
+	}
+	if _, err := ParseRenderMode(entry.Render); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(server) > 0 {
+		if err := validateServerBundle(string(server)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func validateDevOutputs(distDir string, pages map[string]ManifestPage) error {
+	var errs []error
+	for _, name := range sortedKeys(pages) {
+		entry := pages[name]
+		server, err := os.ReadFile(filepath.Join(distDir, entry.Server))
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("🔴 page %s: server %s: %w", name, entry.Server, err))
+		case len(server) == 0:
+			errs = append(errs, fmt.Errorf("🔴 page %s: server %s is empty", name, entry.Server))
+		default:
+			if err := validateServerBundle(string(server)); err != nil {
+				errs = append(errs, fmt.Errorf("🔴 page %s: %w", name, err))
+			}
+		}
+		if _, err := os.Stat(filepath.Join(distDir, entry.Client)); err != nil {
+			errs = append(errs, fmt.Errorf("🔴 page %s: client %s: %w", name, entry.Client, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func validateServerBundle(serverJS string) error {
+	if configuredRenderer() != nil {
+		return nil
+	}
+	vm, err := newRuntimeWithContext()
+	if err != nil {
+		return fmt.Errorf("🔴 create runtime: %w", err)
+	}
+	defer closeRuntime(vm)
+
+	result := vm.ctx.Eval(serverJS)
+	defer result.Free()
+	if result.IsException() {
+		return fmt.Errorf("🔴 eval server bundle: %s", vm.ctx.Exception())
+	}
+
+	kind := vm.ctx.Eval(`typeof __Component === "undefined" ? "undefined" : typeof (__Component.default || __Component)`)
+	defer kind.Free()
+	if kind.IsException() {
+		return fmt.Errorf("🔴 inspect server bundle: %s", vm.ctx.Exception())
+	}
+	if got := kind.String(); got != "function" {
+		return fmt.Errorf("🔴 server bundle must define __Component as a render function, got %s", got)
+	}
+	return nil
+}
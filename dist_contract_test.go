@@ -0,0 +1,74 @@
+package alloy
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateDist(t *testing.T) {
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "home-server.js"), `var __Component = { default: function(props) { return "<h1>home</h1>"; } };`)
+	writeTestFile(t, filepath.Join(dist, "home.client.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "home.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "about-server.js"), `var Component = {};`)
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{
+		"home": {"server": "home-server.js", "client": "home.client.js", "css": "home.css"},
+		"about": {"server": "about-server.js", "client": "about.client.js", "css": "home.css", "render": "edge"}
+	}`)
+	filesystem := os.DirFS(root)
+
+	err := ValidateDist(filesystem, "dist/build")
+	if err == nil {
+		t.Fatal("expected errors for about")
+	}
+	for _, want := range []string{"page about", "about.client.js", "__Component", `unknown render mode "edge"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("error missing %q:\n%v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "page home") {
+		t.Fatalf("home should be valid:\n%v", err)
+	}
+
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{"home": {"server": "home-server.js", "client": "home.client.js", "css": "home.css"}}`)
+	if err := ValidateDist(filesystem, "dist/build"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateDistSkipsEvalWithRenderer(t *testing.T) {
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "home-server.js"), `var fs = require("node:fs"); var __Component = { default: function() { return "<h1>home</h1>"; } };`)
+	writeTestFile(t, filepath.Join(dist, "home.client.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "home.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{"home": {"server": "home-server.js", "client": "home.client.js", "css": "home.css"}}`)
+
+	withTestConfig(t, func(cfg *Config) {})
+	if err := ValidateDist(os.DirFS(root), "dist/build"); err == nil || !strings.Contains(err.Error(), "eval server bundle") {
+		t.Fatalf("expected QuickJS to reject the node bundle, got %v", err)
+	}
+	getConfig().Renderer = &NodeRenderer{}
+	if err := ValidateDist(os.DirFS(root), "dist/build"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRuntimeOnlySkipsBundling(t *testing.T) {
+	dir := t.TempDir()
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(dir)
+		cfg.RuntimeOnly = true
+	})
+	writeTestFile(t, filepath.Join(dir, "app", "emails", "welcome.tsx"), `export default function Welcome() { return "hi"; }`+"\n")
+
+	_, err := RenderComponent(context.Background(), filepath.Join(dir, "app", "emails", "welcome.tsx"), nil)
+	if !errors.Is(err, ErrRuntimeOnly) {
+		t.Fatalf("err = %v", err)
+	}
+}
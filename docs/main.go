@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"embed"
 	"fmt"
 	"net/http"
+	"os"
 
 	"github.com/3-lines-studio/alloy"
 	"github.com/3-lines-studio/alloy/docs/loader"
@@ -20,7 +22,10 @@ func main() {
 	mux.Handle("/{slug}", alloy.NewPage("app/pages/docs.tsx").WithLoader(loader.Docs))
 
 	handler := alloy.AssetsMiddleware()(mux)
-	http.ListenAndServe(":8080", handler)
 
 	fmt.Println("Running @ http://localhost:8080")
+	if err := alloy.Serve(context.Background(), ":8080", handler); err != nil {
+		fmt.Fprintf(os.Stderr, "🔴 %s\n", err)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,190 @@
+package alloy
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+var documentTemplate = sync.OnceValue(func() string {
+	return MustReadAsset("assets/document-template.html")
+})
+
+type Slot struct {
+	Name      string
+	Component string
+	Loader    func(r *http.Request) map[string]any
+	Hydrate   bool
+}
+
+type DocumentHandler struct {
+	slots  []Slot
+	layout *template.Template
+	meta   []HeadTag
+}
+
+type renderedSlot struct {
+	slot   Slot
+	rootID string
+	result *RenderResult
+}
+
+func NewDocument(slots ...Slot) *DocumentHandler {
+	return &DocumentHandler{slots: slots}
+}
+
+func (d *DocumentHandler) WithLayout(layout string) *DocumentHandler {
+	d.layout = template.Must(template.New("layout").Funcs(template.FuncMap{
+		"slot": func(string) (template.HTML, error) { return "", nil },
+	}).Parse(layout))
+	return d
+}
+
+func (d *DocumentHandler) WithMeta(tags ...HeadTag) *DocumentHandler {
+	d.meta = append(d.meta, tags...)
+	return d
+}
+
+func (d *DocumentHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if id := BuildID(); id != "" {
+		w.Header().Set(BuildHeader, id)
+	}
+	r = withCSP(w, r)
+	r = withLayoutMeta(r, d.meta)
+	r = withRenderContext(withFlags(r))
+
+	rendered, err := d.renderSlots(r)
+	if err != nil {
+		writeRenderError(w, err)
+		return
+	}
+
+	body, err := d.renderLayout(rendered)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	headProps := map[string]any{}
+	var meta []any
+	var extra []HeadTag
+	var css, scripts, headSlots, bodyEnd strings.Builder
+	var stylesheets []string
+	var importMap string
+	for _, s := range rendered {
+		if title, ok := s.result.Props["title"].(string); ok && headProps["title"] == nil {
+			headProps["title"] = title
+		}
+		if tags, ok := s.result.Props["meta"].([]any); ok {
+			meta = append(meta, tags...)
+		}
+		extra = append(extra, s.result.Head...)
+		headSlots.WriteString(slotHTML(s.result.Slots, SlotHead, "\n\t", s.result.nonceAttr()))
+		bodyEnd.WriteString(slotHTML(s.result.Slots, SlotBodyEnd, "\n        ", s.result.nonceAttr()))
+		if key := s.result.CSSPath + s.result.CSS; key != "" && !slices.Contains(stylesheets, key) {
+			stylesheets = append(stylesheets, key)
+			css.WriteString(s.result.buildCSSTag())
+		}
+		if s.slot.Hydrate {
+			if importMap == "" {
+				importMap = s.result.importMapTag()
+			}
+			scripts.WriteString(s.result.buildScriptTag())
+			scripts.WriteString("\n        ")
+		}
+	}
+	if len(meta) > 0 {
+		headProps["meta"] = meta
+	}
+	headProps = withTheme(w, r, headProps)
+
+	head := renderHead(headProps, layoutMeta(r), extra, CSPNonce(r)) + importMap + headSlots.String()
+	script := renderContextScript(RenderContext(r.Context())) + strings.TrimSpace(scripts.String()) + bodyEnd.String()
+	doc := fmt.Sprintf(documentTemplate(), htmlAttrs(headProps), head, css.String(), body, script)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if r.Method != http.MethodHead {
+		writeHTML(r.Context(), w, doc)
+	}
+}
+
+func (d *DocumentHandler) renderSlots(r *http.Request) ([]renderedSlot, error) {
+	cfg := getConfig()
+	rendered := make([]renderedSlot, len(d.slots))
+	var g errgroup.Group
+	for i, slot := range d.slots {
+		g.Go(func() error {
+			props := map[string]any{}
+			if slot.Loader != nil {
+				if loaded := slot.Loader(r); loaded != nil {
+					props = loaded
+				}
+			}
+			props = withFlagProps(r, props)
+
+			rootID := defaultRootID(slot.Component)
+			files, err := resolvePrebuiltFiles(cfg.FS, slot.Component)
+			if err != nil {
+				return err
+			}
+			if err := RegisterPrebuiltBundleFromFS(slot.Component, rootID, cfg.FS, files); err != nil {
+				return fmt.Errorf("🔴 slot %s: %w", slot.Name, err)
+			}
+			result, err := RenderPrebuiltWithContext(r.Context(), slot.Component, props, rootID, files)
+			if err != nil {
+				reportRenderError(r, slot.Component, props, err)
+				return fmt.Errorf("🔴 slot %s: %w", slot.Name, err)
+			}
+			result.Nonce = CSPNonce(r)
+			rendered[i] = renderedSlot{slot: slot, rootID: rootID, result: result}
+			return nil
+		})
+	}
+	return rendered, g.Wait()
+}
+
+func (d *DocumentHandler) renderLayout(rendered []renderedSlot) (string, error) {
+	slots := map[string]string{}
+	var order []string
+	for _, s := range rendered {
+		html := s.result.HTML
+		if s.slot.Hydrate {
+			props, err := json.Marshal(s.result.Props)
+			if err != nil {
+				return "", fmt.Errorf("🔴 encode %s props: %w", s.slot.Name, err)
+			}
+			html = fmt.Sprintf("<div id=\"%s\">%s</div>\n<script id=\"%s-props\" type=\"application/json\">%s</script>", s.rootID, html, s.rootID, props)
+		}
+		slots[s.slot.Name] = html
+		order = append(order, html)
+	}
+
+	if d.layout == nil {
+		return strings.Join(order, "\n"), nil
+	}
+
+	layout, err := d.layout.Clone()
+	if err != nil {
+		return "", fmt.Errorf("🔴 clone layout: %w", err)
+	}
+	layout.Funcs(template.FuncMap{
+		"slot": func(name string) (template.HTML, error) {
+			html, ok := slots[name]
+			if !ok {
+				return "", fmt.Errorf("🔴 unknown slot %q", name)
+			}
+			return template.HTML(html), nil
+		},
+	})
+	var b strings.Builder
+	if err := layout.Execute(&b, nil); err != nil {
+		return "", fmt.Errorf("🔴 render layout: %w", err)
+	}
+	return b.String(), nil
+}
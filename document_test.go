@@ -0,0 +1,70 @@
+package alloy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDocumentRendersSlots(t *testing.T) {
+	resetBundleCache()
+	t.Cleanup(resetBundleCache)
+
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "header-server.js"), `var __Component = { default: function(props) { return "<header>" + props.user + "</header>"; } };`)
+	writeTestFile(t, filepath.Join(dist, "client-header-AAAAAAAA.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "main-server.js"), `var __Component = { default: function(props) { return "<main>" + props.title + "</main>"; } };`)
+	writeTestFile(t, filepath.Join(dist, "client-main-BBBBBBBB.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "shared.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{
+		"header": {"server": "header-server.js", "client": "client-header-AAAAAAAA.js", "css": "shared.css"},
+		"main": {"server": "main-server.js", "client": "client-main-BBBBBBBB.js", "css": "shared.css"}
+	}`)
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+	})
+
+	doc := NewDocument(
+		Slot{Name: "header", Component: filepath.Join(root, "pages", "header.tsx"), Hydrate: true, Loader: func(r *http.Request) map[string]any {
+			return map[string]any{"user": "Ada"}
+		}},
+		Slot{Name: "main", Component: filepath.Join(root, "pages", "main.tsx"), Loader: func(r *http.Request) map[string]any {
+			return map[string]any{"title": "Home"}
+		}},
+	).WithLayout(`<div class="shell">{{slot "header"}}<section>{{slot "main"}}</section></div>`)
+
+	rec := httptest.NewRecorder()
+	doc.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		`<div class="shell"><div id="header-root"><header>Ada</header></div>`,
+		`<script id="header-root-props" type="application/json">{"user":"Ada"}</script>`,
+		`<section><main>Home</main></section>`,
+		`<title>Home</title>`,
+		`src="/dist/build/client-header-AAAAAAAA.js"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("document missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "client-main-BBBBBBBB.js") {
+		t.Fatalf("static slot should not load its client bundle:\n%s", body)
+	}
+	if n := strings.Count(body, "shared.css"); n != 1 {
+		t.Fatalf("stylesheet linked %d times:\n%s", n, body)
+	}
+
+	broken := NewDocument(Slot{Name: "missing", Component: filepath.Join(root, "pages", "missing.tsx")})
+	rec = httptest.NewRecorder()
+	broken.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("missing slot status = %d", rec.Code)
+	}
+}
@@ -0,0 +1,46 @@
+package echorouter
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/3-lines-studio/alloy"
+	"github.com/labstack/echo/v4"
+)
+
+const WildcardParam = "path"
+
+var echoParam = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+
+func Handler(page http.Handler) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		params := map[string]string{}
+		values := c.ParamValues()
+		for i, name := range c.ParamNames() {
+			if i >= len(values) {
+				break
+			}
+			if name == "*" {
+				name = WildcardParam
+			}
+			params[name] = values[i]
+		}
+		page.ServeHTTP(c.Response(), alloy.WithRouteParams(c.Request(), Pattern(c.Path()), params))
+		return nil
+	}
+}
+
+func Handle(e *echo.Echo, path string, page http.Handler) {
+	handler := Handler(page)
+	e.GET(path, handler)
+	e.HEAD(path, handler)
+}
+
+func Pattern(route string) string {
+	route = echoParam.ReplaceAllString(route, "{$1}")
+	if strings.HasSuffix(route, "*") {
+		route = strings.TrimSuffix(route, "*") + "{" + WildcardParam + "...}"
+	}
+	return route
+}
@@ -0,0 +1,40 @@
+package echorouter
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/3-lines-studio/alloy"
+	"github.com/3-lines-studio/alloy/alloytest"
+	"github.com/labstack/echo/v4"
+)
+
+func TestHandleMapsEchoParams(t *testing.T) {
+	alloytest.Setup(t, map[string]alloytest.Fixture{
+		"app/pages/docs.tsx": {ServerJS: alloytest.ServerBundle(`return "<p>" + props.version + ":" + props.path + ":" + props.pattern + "</p>";`)},
+	})
+
+	e := echo.New()
+	Handle(e, "/docs/:version/*", alloy.NewPage("app/pages/docs.tsx").WithLoader(func(r *http.Request) map[string]any {
+		params := alloy.RouteParams(r)
+		return map[string]any{"version": params.Get("version"), "path": params.Wildcard, "pattern": params.Pattern}
+	}))
+
+	doc := alloytest.Get(t, e, "/docs/v2/guides/routing")
+	if doc.Status != http.StatusOK || doc.Root != "<p>v2:guides/routing:/docs/{version}/{path...}</p>" {
+		t.Fatalf("unexpected page %d: %q", doc.Status, doc.Root)
+	}
+}
+
+func TestPatternConvertsEchoSyntax(t *testing.T) {
+	for route, want := range map[string]string{
+		"/":               "/",
+		"/blog/:slug":     "/blog/{slug}",
+		"/files/*":        "/files/{path...}",
+		"/a/:b/c/:d_id/*": "/a/{b}/c/{d_id}/{path...}",
+	} {
+		if got := Pattern(route); got != want {
+			t.Fatalf("Pattern(%q) = %q, want %q", route, got, want)
+		}
+	}
+}
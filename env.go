@@ -0,0 +1,77 @@
+package alloy
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+)
+
+const PublicEnvPrefix = "ALLOY_PUBLIC_"
+
+type Environment string
+
+const (
+	EnvironmentProduction  Environment = "production"
+	EnvironmentStaging     Environment = "staging"
+	EnvironmentPreview     Environment = "preview"
+	EnvironmentDevelopment Environment = "development"
+)
+
+var environmentAliases = map[string]Environment{
+	"prod":  EnvironmentProduction,
+	"stage": EnvironmentStaging,
+	"dev":   EnvironmentDevelopment,
+}
+
+func currentEnvironment() Environment {
+	if cfg := getConfig(); cfg != nil && cfg.Environment != "" {
+		return normalizeEnvironment(string(cfg.Environment))
+	}
+	if env := os.Getenv("ALLOY_ENV"); env != "" {
+		return normalizeEnvironment(env)
+	}
+	return EnvironmentProduction
+}
+
+func normalizeEnvironment(env string) Environment {
+	env = strings.ToLower(strings.TrimSpace(env))
+	if alias, ok := environmentAliases[env]; ok {
+		return alias
+	}
+	return Environment(env)
+}
+
+func noindex() bool {
+	switch currentEnvironment() {
+	case EnvironmentStaging, EnvironmentPreview, EnvironmentDevelopment:
+		return true
+	}
+	return false
+}
+
+func PublicEnv() map[string]string {
+	env := map[string]string{}
+	for _, kv := range os.Environ() {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(key, PublicEnvPrefix) || key == PublicEnvPrefix {
+			continue
+		}
+		env[key] = value
+	}
+	return env
+}
+
+func publicEnvDefines() map[string]string {
+	env := PublicEnv()
+	if len(env) == 0 {
+		return nil
+	}
+
+	defines := make(map[string]string, len(env)*2)
+	for key, value := range env {
+		encoded, _ := json.Marshal(value)
+		defines["process.env."+key] = string(encoded)
+		defines["import.meta.env."+key] = string(encoded)
+	}
+	return defines
+}
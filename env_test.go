@@ -0,0 +1,105 @@
+package alloy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+func TestPublicEnvDefinesInlineValues(t *testing.T) {
+	t.Setenv("ALLOY_PUBLIC_API_URL", "https://api.example.com")
+	t.Setenv("SECRET_TOKEN", "do-not-ship")
+
+	dir := t.TempDir()
+	entry := filepath.Join(dir, "entry.ts")
+	source := `export const a = process.env.ALLOY_PUBLIC_API_URL;
+export const b = import.meta.env.ALLOY_PUBLIC_API_URL;
+export const c = typeof process !== "undefined" ? process.env.SECRET_TOKEN : "";
+`
+	if err := os.WriteFile(entry, []byte(source), 0644); err != nil {
+		t.Fatalf("write entry: %v", err)
+	}
+
+	opts := commonBuildOptions()
+	opts.EntryPoints = []string{entry}
+	opts.Format = api.FormatESModule
+	opts.Write = false
+
+	result := api.Build(opts)
+	if err := checkBuildErrors(result, "build"); err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	out := string(result.OutputFiles[0].Contents)
+	if strings.Count(out, `"https://api.example.com"`) != 2 {
+		t.Fatalf("expected public value inlined twice, got:\n%s", out)
+	}
+	if strings.Contains(out, "do-not-ship") {
+		t.Fatalf("non-public env leaked into bundle:\n%s", out)
+	}
+}
+
+func TestPublicEnvIgnoresBarePrefix(t *testing.T) {
+	t.Setenv("ALLOY_PUBLIC_", "x")
+	t.Setenv("ALLOY_PUBLIC_NAME", "alloy")
+
+	env := PublicEnv()
+	if _, ok := env["ALLOY_PUBLIC_"]; ok {
+		t.Fatalf("bare prefix should be ignored: %v", env)
+	}
+	if env["ALLOY_PUBLIC_NAME"] != "alloy" {
+		t.Fatalf("missing public var: %v", env)
+	}
+}
+
+func TestNoindexOutsideProduction(t *testing.T) {
+	handler := etagTestPage(t, func(cfg *Config) { cfg.Environment = EnvironmentStaging })
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/etag", nil))
+	if rec.Header().Get("X-Robots-Tag") != "noindex" {
+		t.Fatalf("🔴 expected X-Robots-Tag, got %q", rec.Header().Get("X-Robots-Tag"))
+	}
+	if !strings.Contains(rec.Body.String(), `<meta name="robots" content="noindex">`) {
+		t.Fatalf("🔴 expected robots meta:\n%s", rec.Body.String())
+	}
+}
+
+func TestProductionIsIndexable(t *testing.T) {
+	t.Setenv("ALLOY_ENV", "")
+	handler := etagTestPage(t, func(cfg *Config) {})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/etag", nil))
+	if rec.Header().Get("X-Robots-Tag") != "" || strings.Contains(rec.Body.String(), `name="robots"`) {
+		t.Fatal("🔴 production pages should be indexable")
+	}
+
+	t.Setenv("ALLOY_ENV", "preview")
+	if !noindex() {
+		t.Fatal("🔴 ALLOY_ENV=preview should disable indexing")
+	}
+}
+
+func TestNoindexNormalizesEnvironment(t *testing.T) {
+	for env, want := range map[string]bool{
+		"Production": false,
+		"prod":       false,
+		" PROD ":     false,
+		"eu-west":    false,
+		"Staging":    true,
+		"stage":      true,
+		"dev":        true,
+		"Preview":    true,
+	} {
+		t.Setenv("ALLOY_ENV", env)
+		if got := noindex(); got != want {
+			t.Fatalf("🔴 ALLOY_ENV=%q: noindex = %v, want %v", env, got, want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package alloy
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type ETagMode string
+
+const (
+	ETagWeak   ETagMode = "weak"
+	ETagStrong ETagMode = "strong"
+	ETagOff    ETagMode = "off"
+)
+
+const pageETagMaxBody = 1 << 20
+
+type etagWriter struct {
+	http.ResponseWriter
+	status    int
+	body      bytes.Buffer
+	streaming bool
+}
+
+func pageETagMode() ETagMode {
+	if cfg := getConfig(); cfg != nil && cfg.PageETag != "" {
+		return cfg.PageETag
+	}
+	return ETagWeak
+}
+
+func (w *etagWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *etagWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if !w.streaming && w.body.Len()+len(p) > pageETagMaxBody {
+		w.stream()
+	}
+	if w.streaming {
+		return w.ResponseWriter.Write(p)
+	}
+	return w.body.Write(p)
+}
+
+func (w *etagWriter) stream() {
+	w.streaming = true
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.Write(w.body.Bytes())
+	w.body.Reset()
+}
+
+func (w *etagWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *etagWriter) finish(r *http.Request, mode ETagMode) {
+	if w.streaming {
+		return
+	}
+	status := w.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	header := w.Header()
+	if status == http.StatusOK && r.Method == http.MethodGet && header.Get("ETag") == "" {
+		tag := pageETag(w.body.Bytes(), mode)
+		header.Set("ETag", tag)
+		if etagMatches(r.Header.Get("If-None-Match"), tag) {
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+			w.ResponseWriter.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+	w.ResponseWriter.Write(w.body.Bytes())
+}
+
+func pageETag(body []byte, mode ETagMode) string {
+	tag := fmt.Sprintf(`"%x"`, sha1.Sum(body))
+	if mode == ETagStrong {
+		return tag
+	}
+	return "W/" + tag
+}
+
+func etagMatches(header string, tag string) bool {
+	if header == "" {
+		return false
+	}
+	want := strings.TrimPrefix(tag, "W/")
+	for candidate := range strings.SplitSeq(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
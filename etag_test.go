@@ -0,0 +1,83 @@
+package alloy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func etagTestPage(t *testing.T, opt func(*Config)) http.Handler {
+	t.Helper()
+	resetBundleCache()
+	t.Cleanup(resetBundleCache)
+
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "etag-server.js"), `var __Component = { default: function(props) { return "<p>" + props.version + "</p>"; } };`)
+	writeTestFile(t, filepath.Join(dist, "client-etag-AAAAAAAA.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "shared-BBBBBBBB.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{"etag": {"server": "etag-server.js", "client": "client-etag-AAAAAAAA.js", "css": "shared-BBBBBBBB.css"}}`)
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+		opt(cfg)
+	})
+
+	return NewPage(filepath.Join(root, "pages", "etag.tsx")).WithLoader(func(r *http.Request) map[string]any {
+		return map[string]any{"version": "1"}
+	})
+}
+
+func TestPageETagAnswersIfNoneMatch(t *testing.T) {
+	handler := etagTestPage(t, func(cfg *Config) {})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/etag", nil))
+	tag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || !strings.HasPrefix(tag, `W/"`) || !strings.Contains(rec.Body.String(), "<p>1</p>") {
+		t.Fatalf("🔴 expected page with weak etag, got %d %q", rec.Code, tag)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/etag", nil)
+	req.Header.Set("If-None-Match", `"other", `+strings.TrimPrefix(tag, "W/"))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 || rec.Header().Get("ETag") != tag {
+		t.Fatalf("🔴 expected 304, got %d body=%q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestPageETagModes(t *testing.T) {
+	handler := etagTestPage(t, func(cfg *Config) { cfg.PageETag = ETagStrong })
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/etag", nil))
+	if tag := rec.Header().Get("ETag"); !strings.HasPrefix(tag, `"`) {
+		t.Fatalf("🔴 expected strong etag, got %q", tag)
+	}
+
+	handler = etagTestPage(t, func(cfg *Config) { cfg.PageETag = ETagOff })
+	req := httptest.NewRequest(http.MethodGet, "/etag", nil)
+	req.Header.Set("If-None-Match", "*")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != "" {
+		t.Fatalf("🔴 expected etags disabled, got %d %q", rec.Code, rec.Header().Get("ETag"))
+	}
+}
+
+func TestPageETagStreamsLargeBodies(t *testing.T) {
+	handler := etagTestPage(t, func(cfg *Config) {}).(*PageHandler).WithLoader(func(r *http.Request) map[string]any {
+		return map[string]any{"version": strings.Repeat("x", pageETagMaxBody)}
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/etag", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != "" {
+		t.Fatalf("🔴 expected large page without etag, got %d %q", rec.Code, rec.Header().Get("ETag"))
+	}
+	if !strings.Contains(rec.Body.String(), "<p>"+strings.Repeat("x", pageETagMaxBody)+"</p>") {
+		t.Fatalf("🔴 expected full body, got %d bytes", rec.Body.Len())
+	}
+}
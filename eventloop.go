@@ -0,0 +1,57 @@
+package alloy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/buke/quickjs-go"
+)
+
+var ErrRenderTimeout = errors.New("render timeout")
+
+func awaitRender(ctx context.Context, js *quickjs.Context, promise *quickjs.Value) (*quickjs.Value, error) {
+	for {
+		js.Loop()
+		if promise.PromiseState() != quickjs.PromisePending {
+			return js.Await(promise), nil
+		}
+		if err := ctx.Err(); err != nil {
+			promise.Free()
+			return nil, fmt.Errorf("🔴 %w: %w", ErrRenderTimeout, err)
+		}
+
+		next := js.Eval("__alloyTimers.next()")
+		wait := next.ToInt64()
+		next.Free()
+		if wait < 0 {
+			promise.Free()
+			return nil, fmt.Errorf("🔴 render promise never settled: no pending timers")
+		}
+		if wait > 0 {
+			timer := time.NewTimer(time.Duration(wait) * time.Millisecond)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				promise.Free()
+				return nil, fmt.Errorf("🔴 %w: %w", ErrRenderTimeout, ctx.Err())
+			}
+		}
+
+		run := js.Eval("__alloyTimers.run()")
+		if run.IsException() {
+			run.Free()
+			promise.Free()
+			return nil, fmt.Errorf("🔴 timer callback: %w", js.Exception())
+		}
+		run.Free()
+	}
+}
+
+func discardPendingWork(js *quickjs.Context) {
+	js.Loop()
+	reset := js.Eval("__alloyTimers.reset()")
+	reset.Free()
+}
@@ -0,0 +1,89 @@
+package alloy
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecuteSSRAwaitsTimersAndMicrotasks(t *testing.T) {
+	code := `var __Component = function(props) {
+		return new Promise(function(resolve) {
+			var log = [];
+			var cancelled = setTimeout(function() { log.push("cancelled"); }, 0);
+			clearTimeout(cancelled);
+			setTimeout(function(suffix) { log.push("timeout" + suffix); resolve("<p>" + props.name + ":" + log.join(",") + "</p>"); }, 5, "!");
+			queueMicrotask(function() { log.push("microtask"); });
+			var ticks = 0;
+			var interval = setInterval(function() { log.push("tick"); if (++ticks === 2) clearInterval(interval); }, 1);
+		});
+	};`
+
+	out, err := executeSSR(context.Background(), code, map[string]any{"name": "alloy"})
+	if err != nil {
+		t.Fatalf("executeSSR: %v", err)
+	}
+	if out.HTML != "<p>alloy:microtask,tick,tick,timeout!</p>" {
+		t.Fatalf("🔴 unexpected html: %s", out.HTML)
+	}
+}
+
+func TestExecuteSSRTimersBoundedByDeadline(t *testing.T) {
+	code := `var __Component = function() {
+		return new Promise(function(resolve) { setTimeout(function() { resolve("late"); }, 60000); });
+	};`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := executeSSR(ctx, code, nil)
+	if err == nil || !strings.Contains(err.Error(), "render timeout") {
+		t.Fatalf("🔴 expected render timeout, got %v", err)
+	}
+	if !errors.Is(err, ErrRenderTimeout) {
+		t.Fatalf("🔴 expected ErrRenderTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("🔴 timeout took %s", elapsed)
+	}
+}
+
+func TestExecuteSSRReportsUnsettledAndRejectedRenders(t *testing.T) {
+	_, err := executeSSR(context.Background(), `var __Component = function() { return new Promise(function() {}); };`, nil)
+	if err == nil || !strings.Contains(err.Error(), "never settled") {
+		t.Fatalf("🔴 expected unsettled error, got %v", err)
+	}
+
+	_, err = executeSSR(context.Background(), `var __Component = function() {
+		return new Promise(function(_, reject) { setTimeout(function() { reject(new Error("data failed")); }, 1); });
+	};`, nil)
+	if err == nil || !strings.Contains(err.Error(), "data failed") {
+		t.Fatalf("🔴 expected rejection, got %v", err)
+	}
+}
+
+func TestRunSSRDiscardsPendingWork(t *testing.T) {
+	vm, err := newRuntimeWithContext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeRuntime(vm)
+
+	code := `var __Component = function() {
+		setTimeout(function() { globalThis.leaked = true; }, 0);
+		setInterval(function() {}, 1000);
+		Promise.resolve().then(function() { globalThis.drained = true; });
+		return "ok";
+	};`
+	if out, err := runSSR(context.Background(), vm.ctx, code, nil); err != nil || out.HTML != "ok" {
+		t.Fatalf("runSSR: %q %v", out.HTML, err)
+	}
+
+	state := vm.ctx.Eval(`__alloyTimers.next() + "," + globalThis.drained + "," + globalThis.leaked`)
+	defer state.Free()
+	if state.String() != "-1,true,undefined" {
+		t.Fatalf("🔴 expected timers cancelled and microtasks drained, got %s", state.String())
+	}
+}
@@ -0,0 +1,74 @@
+package alloy
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/buke/quickjs-go"
+)
+
+func serverExternalNames() []string {
+	cfg := getConfig()
+	if cfg == nil || len(cfg.ServerExternals) == 0 {
+		return nil
+	}
+
+	return sortedKeys(cfg.ServerExternals)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func serverExternalsSource(externals map[string]string) string {
+	if len(externals) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("var __alloyExternals = {};\n")
+	for _, name := range sortedKeys(externals) {
+		shim := strings.TrimSpace(externals[name])
+		if shim == "" {
+			shim = "{}"
+		}
+		key, _ := json.Marshal(name)
+		fmt.Fprintf(&b, "__alloyExternals[%s] = function() { return (%s); };\n", key, shim)
+	}
+	b.WriteString(`var __alloyModules = {};
+var require = function(name) {
+	if (Object.prototype.hasOwnProperty.call(__alloyModules, name)) return __alloyModules[name];
+	var factory = __alloyExternals[name];
+	if (!factory) throw new Error('Cannot find external module "' + name + '"');
+	__alloyModules[name] = factory();
+	return __alloyModules[name];
+};
+`)
+	return b.String()
+}
+
+func loadServerExternals(ctx *quickjs.Context) error {
+	cfg := getConfig()
+	if cfg == nil {
+		return nil
+	}
+
+	source := serverExternalsSource(cfg.ServerExternals)
+	if source == "" {
+		return nil
+	}
+
+	result := ctx.Eval(source)
+	if result.IsException() {
+		return fmt.Errorf("🔴 server externals: %s", ctx.Exception().Error())
+	}
+	result.Free()
+	return nil
+}
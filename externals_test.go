@@ -0,0 +1,57 @@
+package alloy
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+func TestServerExternalsUseShims(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) {
+		cfg.ServerExternals = map[string]string{
+			"node-only-lib": `({ greet: function(name) { return "<p>hi " + name + "</p>"; } })`,
+			"unused-lib":    "",
+		}
+	})
+
+	dir := t.TempDir()
+	entry := filepath.Join(dir, "entry.ts")
+	writeTestFile(t, entry, `import lib from "node-only-lib";
+export default function render(props: any) { return lib.greet(props.name); }`)
+
+	opts := commonBuildOptions()
+	opts.EntryPoints = []string{entry}
+	opts.Write = false
+	opts.Format = api.FormatIIFE
+	opts.GlobalName = "__Component"
+	opts.Platform = api.PlatformBrowser
+	opts.External = serverExternalNames()
+
+	result := api.Build(opts)
+	if err := checkBuildErrors(result, "build"); err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	out, err := executeSSR(context.Background(), string(result.OutputFiles[0].Contents), map[string]any{"name": "alloy"})
+	if err != nil {
+		t.Fatalf("execute ssr: %v", err)
+	}
+	if out.HTML != "<p>hi alloy</p>" {
+		t.Fatalf("unexpected html: %s", out.HTML)
+	}
+}
+
+func TestServerExternalsMissingModule(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) {
+		cfg.ServerExternals = map[string]string{"known": "{}"}
+	})
+
+	serverJS := `var __Component = { default: function() { return require("unknown").x; } };`
+	_, err := executeSSR(context.Background(), serverJS, nil)
+	if err == nil || !strings.Contains(err.Error(), `external module "unknown"`) {
+		t.Fatalf("expected missing module error, got %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package fiberrouter
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/3-lines-studio/alloy"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+)
+
+const WildcardParam = "path"
+
+var (
+	fiberParam  = regexp.MustCompile(`:([A-Za-z0-9_]+)(<[^>]*>)?\??`)
+	fiberGreedy = regexp.MustCompile(`[*+]`)
+)
+
+func Handler(page http.Handler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+		route := c.Route()
+		params := make(map[string]string, len(route.Params))
+		for _, name := range route.Params {
+			value := strings.Clone(c.Params(name))
+			if strings.HasPrefix(name, "*") || strings.HasPrefix(name, "+") {
+				if _, ok := params[WildcardParam]; ok {
+					continue
+				}
+				name = WildcardParam
+			}
+			params[name] = value
+		}
+		pattern := Pattern(route.Path)
+
+		return adaptor.HTTPHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			page.ServeHTTP(w, alloy.WithRouteParams(r.WithContext(ctx), pattern, params))
+		})(c)
+	}
+}
+
+func Handle(r fiber.Router, path string, page http.Handler) {
+	handler := Handler(page)
+	r.Add(fiber.MethodGet, path, handler)
+	r.Add(fiber.MethodHead, path, handler)
+}
+
+func Pattern(route string) string {
+	route = fiberParam.ReplaceAllString(route, "{$1}")
+	return fiberGreedy.ReplaceAllLiteralString(route, "{"+WildcardParam+"...}")
+}
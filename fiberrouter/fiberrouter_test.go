@@ -0,0 +1,64 @@
+package fiberrouter
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/3-lines-studio/alloy"
+	"github.com/3-lines-studio/alloy/alloytest"
+	"github.com/gofiber/fiber/v2"
+)
+
+type requestKey struct{}
+
+func TestHandleMapsFiberParams(t *testing.T) {
+	alloytest.Setup(t, map[string]alloytest.Fixture{
+		"app/pages/docs.tsx": {ServerJS: alloytest.ServerBundle(`return "<p>" + props.version + ":" + props.path + ":" + props.pattern + ":" + props.user + "</p>";`)},
+	})
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.SetUserContext(context.WithValue(c.UserContext(), requestKey{}, "ada"))
+		return c.Next()
+	})
+	Handle(app, "/docs/:version<minLen(2)>/*", alloy.NewPage("app/pages/docs.tsx").WithLoader(func(r *http.Request) map[string]any {
+		params := alloy.RouteParams(r)
+		return map[string]any{"version": params.Get("version"), "path": params.Wildcard, "pattern": params.Pattern, "user": r.Context().Value(requestKey{})}
+	}))
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/docs/v2/guides/routing", nil))
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	doc := alloytest.Parse(t, res.StatusCode, res.Header, string(body))
+	if doc.Status != http.StatusOK || doc.Root != "<p>v2:guides/routing:/docs/{version}/{path...}:ada</p>" {
+		t.Fatalf("unexpected page %d: %q", doc.Status, doc.Root)
+	}
+
+	res, err = app.Test(httptest.NewRequest(http.MethodHead, "/docs/v2/guides/routing", nil))
+	if err != nil {
+		t.Fatalf("head request: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("HEAD status = %d", res.StatusCode)
+	}
+}
+
+func TestPatternConvertsFiberSyntax(t *testing.T) {
+	for route, want := range map[string]string{
+		"/":              "/",
+		"/blog/:slug":    "/blog/{slug}",
+		"/user/:id<int>": "/user/{id}",
+		"/page/:name?":   "/page/{name}",
+		"/files/*":       "/files/{path...}",
+		"/assets/+":      "/assets/{path...}",
+	} {
+		if got := Pattern(route); got != want {
+			t.Fatalf("Pattern(%q) = %q, want %q", route, got, want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package alloy
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+type Flag struct {
+	Enabled bool   `json:"enabled"`
+	Variant string `json:"variant,omitempty"`
+}
+
+type FlagProvider interface {
+	Evaluate(ctx context.Context, key string) Flag
+}
+
+type StaticFlags map[string]Flag
+
+func (f StaticFlags) Evaluate(ctx context.Context, key string) Flag {
+	return f[key]
+}
+
+type flagState struct {
+	provider FlagProvider
+	mu       sync.Mutex
+	values   map[string]Flag
+}
+
+type flagContextKey struct{}
+
+func EvaluateFlag(ctx context.Context, key string) Flag {
+	if state, ok := ctx.Value(flagContextKey{}).(*flagState); ok {
+		return state.evaluate(ctx, key)
+	}
+	if cfg := getConfig(); cfg != nil && cfg.Flags != nil {
+		return cfg.Flags.Evaluate(ctx, key)
+	}
+	return Flag{}
+}
+
+func FlagEnabled(ctx context.Context, key string) bool {
+	return EvaluateFlag(ctx, key).Enabled
+}
+
+func FlagVariant(ctx context.Context, key string) string {
+	return EvaluateFlag(ctx, key).Variant
+}
+
+func (s *flagState) evaluate(ctx context.Context, key string) Flag {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if flag, ok := s.values[key]; ok {
+		return flag
+	}
+	flag := s.provider.Evaluate(ctx, key)
+	s.values[key] = flag
+	return flag
+}
+
+func (s *flagState) snapshot() map[string]Flag {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.values)
+}
+
+func flagSnapshot(r *http.Request) map[string]Flag {
+	state, ok := r.Context().Value(flagContextKey{}).(*flagState)
+	if !ok {
+		return nil
+	}
+	return state.snapshot()
+}
+
+func flagKey(r *http.Request) string {
+	flags := flagSnapshot(r)
+	var b strings.Builder
+	for _, key := range sortedKeys(flags) {
+		fmt.Fprintf(&b, "%q=%t:%q;", key, flags[key].Enabled, flags[key].Variant)
+	}
+	return b.String()
+}
+
+func sameFlags(ctx context.Context, flags map[string]Flag) bool {
+	for key, flag := range flags {
+		if EvaluateFlag(ctx, key) != flag {
+			return false
+		}
+	}
+	return true
+}
+
+func withFlags(r *http.Request) *http.Request {
+	cfg := getConfig()
+	if cfg == nil || cfg.Flags == nil {
+		return r
+	}
+	state := &flagState{provider: cfg.Flags, values: map[string]Flag{}}
+	ctx := context.WithValue(r.Context(), flagContextKey{}, state)
+	for _, key := range cfg.FlagKeys {
+		state.evaluate(ctx, key)
+	}
+	return r.WithContext(ctx)
+}
+
+func withFlagProps(r *http.Request, props map[string]any) map[string]any {
+	state, ok := r.Context().Value(flagContextKey{}).(*flagState)
+	if !ok {
+		return props
+	}
+	if props == nil {
+		props = map[string]any{}
+	}
+	if _, ok := props["flags"]; !ok {
+		props["flags"] = state.snapshot()
+	}
+	return props
+}
+
+func withFlagContext(ctx context.Context, values map[string]any) map[string]any {
+	state, ok := ctx.Value(flagContextKey{}).(*flagState)
+	if !ok {
+		return values
+	}
+	if cfg := getConfig(); cfg == nil || !cfg.FlagsGlobal {
+		return values
+	}
+	merged := maps.Clone(values)
+	if merged == nil {
+		merged = map[string]any{}
+	}
+	merged["flags"] = state.snapshot()
+	return merged
+}
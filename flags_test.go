@@ -0,0 +1,80 @@
+package alloy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+type countingFlags struct {
+	StaticFlags
+	calls atomic.Int32
+}
+
+func (f *countingFlags) Evaluate(ctx context.Context, key string) Flag {
+	f.calls.Add(1)
+	return f.StaticFlags.Evaluate(ctx, key)
+}
+
+func TestPageHandlerFlags(t *testing.T) {
+	resetBundleCache()
+	t.Cleanup(resetBundleCache)
+
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "home-server.js"), `var __Component = { default: function(props) {
+		return "<p>" + props.checkout + ":" + __ALLOY_CTX__.flags.banner.variant + ":" + JSON.stringify(props.flags) + "</p>";
+	} };`)
+	writeTestFile(t, filepath.Join(dist, "client-home-AAAAAAAA.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "shared.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{"home": {"server": "home-server.js", "client": "client-home-AAAAAAAA.js", "css": "shared.css"}}`)
+
+	provider := &countingFlags{StaticFlags: StaticFlags{
+		"checkout": {Enabled: true},
+		"banner":   {Enabled: true, Variant: "blue"},
+	}}
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+		cfg.Flags = provider
+		cfg.FlagKeys = []string{"banner"}
+		cfg.FlagsGlobal = true
+	})
+
+	handler := NewPage(filepath.Join(root, "pages", "home.tsx")).WithLoader(func(r *http.Request) map[string]any {
+		return map[string]any{"checkout": FlagEnabled(r.Context(), "checkout") && FlagEnabled(r.Context(), "checkout")}
+	})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	body := rec.Body.String()
+
+	want := `<p>true:blue:{"banner":{"enabled":true,"variant":"blue"},"checkout":{"enabled":true}}</p>`
+	if !strings.Contains(body, want) {
+		t.Fatalf("render missing %s:\n%s", want, body)
+	}
+	if !strings.Contains(body, `"flags":{"banner":{"enabled":true,"variant":"blue"},"checkout":{"enabled":true}}`) {
+		t.Fatalf("flags not embedded for hydration:\n%s", body)
+	}
+	if !strings.Contains(body, `<script id="__ALLOY_CTX__" type="application/json">{"flags":`) {
+		t.Fatalf("flags missing from client context:\n%s", body)
+	}
+	if got := provider.calls.Load(); got != 2 {
+		t.Fatalf("provider called %d times, want once per flag", got)
+	}
+}
+
+func TestEvaluateFlagWithoutRequest(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) {
+		cfg.Flags = StaticFlags{"beta": {Enabled: true, Variant: "b"}}
+	})
+	if !FlagEnabled(context.Background(), "beta") || FlagVariant(context.Background(), "beta") != "b" {
+		t.Fatal("flag not read from provider")
+	}
+	if FlagEnabled(context.Background(), "missing") {
+		t.Fatal("unknown flag enabled")
+	}
+}
@@ -0,0 +1,32 @@
+package alloy
+
+import (
+	"context"
+	"net/http"
+)
+
+func (h *PageHandler) AsFragment() *PageHandler {
+	h.fragment = true
+	return h
+}
+
+func Fragment(component string, props map[string]any) (string, error) {
+	return FragmentWithContext(context.Background(), component, props)
+}
+
+func FragmentWithContext(ctx context.Context, component string, props map[string]any) (string, error) {
+	return RenderComponent(ctx, component, props)
+}
+
+func ServeFragment(w http.ResponseWriter, r *http.Request, component string, props map[string]any) {
+	html, err := FragmentWithContext(r.Context(), component, props)
+	if err != nil {
+		reportRenderError(r, component, props, err)
+		writeRenderError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if r.Method != http.MethodHead {
+		writeHTML(r.Context(), w, html)
+	}
+}
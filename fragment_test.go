@@ -0,0 +1,53 @@
+package alloy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFragment(t *testing.T) {
+	resetBundleCache()
+	t.Cleanup(resetBundleCache)
+
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "row-server.js"), `var __Component = { default: function(props) { return "<tr><td>" + props.name + "</td></tr>"; } };`)
+	writeTestFile(t, filepath.Join(dist, "client-row-AAAAAAAA.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "shared.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{"row": {"server": "row-server.js", "client": "client-row-AAAAAAAA.js", "css": "shared.css", "render": "static"}}`)
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+	})
+	component := filepath.Join(root, "pages", "row.tsx")
+
+	html, err := Fragment(component, map[string]any{"name": "Ada"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if html != "<tr><td>Ada</td></tr>" {
+		t.Fatalf("fragment = %q", html)
+	}
+
+	t.Setenv("ALLOY_DEV", "1")
+	handler := NewPage(component).AsFragment().WithLoader(func(r *http.Request) map[string]any {
+		return map[string]any{"name": r.URL.Query().Get("name")}
+	})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/rows?name=Grace", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "<tr><td>Grace</td></tr>" {
+		t.Fatalf("handler = %d %q", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/html") {
+		t.Fatalf("Content-Type = %q", got)
+	}
+
+	rec = httptest.NewRecorder()
+	ServeFragment(rec, httptest.NewRequest(http.MethodGet, "/", nil), filepath.Join(root, "pages", "missing.tsx"), nil)
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("missing fragment status = %d", rec.Code)
+	}
+}
@@ -0,0 +1,124 @@
+package alloy
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+const DefaultGenFile = "alloy_gen.go"
+
+const genHeader = "// Code generated by alloy gen. DO NOT EDIT.\n\n"
+
+type GenOptions struct {
+	Root     string
+	PagesDir string
+	Package  string
+	Out      string
+}
+
+func Generate(opts GenOptions) ([]string, error) {
+	if opts.Root == "" {
+		opts.Root = "."
+	}
+	if opts.PagesDir == "" {
+		opts.PagesDir = DefaultPagesDir
+	}
+	if opts.Package == "" {
+		opts.Package = os.Getenv("GOPACKAGE")
+	}
+	if opts.Package == "" {
+		opts.Package = "main"
+	}
+	if opts.Out == "" {
+		opts.Out = DefaultGenFile
+	}
+
+	pages, err := DiscoverPages(opts.PagesDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("🔴 no pages found in %s", opts.PagesDir)
+	}
+
+	source, err := GenerateRoutes(pages, opts.Package)
+	if err != nil {
+		return nil, err
+	}
+
+	var written []string
+	changed, err := writeGenerated(opts.Out, source)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		written = append(written, opts.Out)
+	}
+
+	props, err := writePropsTypes(opts.Root, opts.PagesDir)
+	if err != nil {
+		return nil, err
+	}
+	return append(written, props...), nil
+}
+
+func GenerateRoutes(pages []PageSpec, pkg string) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(genHeader)
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+
+	seen := map[string]string{}
+	b.WriteString("const (\n")
+	for _, page := range pages {
+		ident := "Page" + exportedIdent(page.Name)
+		if other, ok := seen[ident]; ok {
+			return nil, fmt.Errorf("🔴 pages %s and %s both map to %s", other, page.Name, ident)
+		}
+		seen[ident] = page.Name
+		fmt.Fprintf(&b, "\t%s = %q\n", ident, filepath.ToSlash(page.Component))
+	}
+	b.WriteString(")\n")
+
+	source, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("🔴 format generated routes: %w", err)
+	}
+	return source, nil
+}
+
+func exportedIdent(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func writeGenerated(path string, contents []byte) (bool, error) {
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, contents) {
+		return false, nil
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return false, fmt.Errorf("🔴 make dir: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, contents, 0644); err != nil {
+		return false, fmt.Errorf("🔴 write %s: %w", FormatPath(path), err)
+	}
+	return true, nil
+}
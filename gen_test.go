@@ -0,0 +1,47 @@
+package alloy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateRoutes(t *testing.T) {
+	source, err := GenerateRoutes([]PageSpec{
+		{Component: "app/pages/home.tsx", Name: "home"},
+		{Component: "app/pages/blog-post.tsx", Name: "blog-post"},
+	}, "main")
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	for _, want := range []string{"DO NOT EDIT", "package main", `PageHome     = "app/pages/home.tsx"`, `PageBlogPost = "app/pages/blog-post.tsx"`} {
+		if !strings.Contains(string(source), want) {
+			t.Fatalf("generated source missing %s:\n%s", want, source)
+		}
+	}
+
+	if _, err := GenerateRoutes([]PageSpec{{Name: "blog-post"}, {Name: "blog_post"}}, "main"); err == nil {
+		t.Fatal("expected error for colliding page names")
+	}
+}
+
+func TestGenerateWritesOnlyWhenChanged(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "pages", "home.tsx"), "export default function Home() { return null; }")
+	out := filepath.Join(root, "alloy_gen.go")
+	opts := GenOptions{PagesDir: filepath.Join(root, "pages"), Package: "web", Out: out}
+
+	written, err := Generate(opts)
+	if err != nil || len(written) != 1 {
+		t.Fatalf("first generate: %v %v", written, err)
+	}
+	if data, _ := os.ReadFile(out); !strings.Contains(string(data), "package web") {
+		t.Fatalf("generated file: %s", data)
+	}
+
+	written, err = Generate(opts)
+	if err != nil || len(written) != 0 {
+		t.Fatalf("second generate rewrote files: %v %v", written, err)
+	}
+}
@@ -0,0 +1,36 @@
+package ginrouter
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/3-lines-studio/alloy"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	ginParam    = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+	ginCatchAll = regexp.MustCompile(`\*([A-Za-z0-9_]+)$`)
+)
+
+func Handler(page http.Handler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		params := make(map[string]string, len(c.Params))
+		for _, param := range c.Params {
+			params[param.Key] = strings.TrimPrefix(param.Value, "/")
+		}
+		page.ServeHTTP(c.Writer, alloy.WithRouteParams(c.Request, Pattern(c.FullPath()), params))
+	}
+}
+
+func Handle(r gin.IRoutes, path string, page http.Handler) {
+	handler := Handler(page)
+	r.GET(path, handler)
+	r.HEAD(path, handler)
+}
+
+func Pattern(route string) string {
+	route = ginParam.ReplaceAllString(route, "{$1}")
+	return ginCatchAll.ReplaceAllString(route, "{$1...}")
+}
@@ -0,0 +1,46 @@
+package ginrouter
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/3-lines-studio/alloy"
+	"github.com/3-lines-studio/alloy/alloytest"
+	"github.com/gin-gonic/gin"
+)
+
+type requestKey struct{}
+
+func TestHandleMapsGinParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	alloytest.Setup(t, map[string]alloytest.Fixture{
+		"app/pages/docs.tsx": {ServerJS: alloytest.ServerBundle(`return "<p>" + props.version + ":" + props.rest + ":" + props.pattern + ":" + props.user + "</p>";`)},
+	})
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), requestKey{}, "ada"))
+	})
+	Handle(r, "/docs/:version/*rest", alloy.NewPage("app/pages/docs.tsx").WithLoader(func(r *http.Request) map[string]any {
+		params := alloy.RouteParams(r)
+		return map[string]any{"version": params.Get("version"), "rest": params.Wildcard, "pattern": params.Pattern, "user": r.Context().Value(requestKey{})}
+	}))
+
+	doc := alloytest.Get(t, r, "/docs/v2/guides/routing")
+	if doc.Status != http.StatusOK || doc.Root != "<p>v2:guides/routing:/docs/{version}/{rest...}:ada</p>" {
+		t.Fatalf("unexpected page %d: %q", doc.Status, doc.Root)
+	}
+}
+
+func TestPatternConvertsGinSyntax(t *testing.T) {
+	for route, want := range map[string]string{
+		"/":                "/",
+		"/blog/:slug":      "/blog/{slug}",
+		"/files/*filepath": "/files/{filepath...}",
+	} {
+		if got := Pattern(route); got != want {
+			t.Fatalf("Pattern(%q) = %q, want %q", route, got, want)
+		}
+	}
+}
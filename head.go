@@ -0,0 +1,169 @@
+package alloy
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	voidHeadTags = map[string]bool{"meta": true, "link": true, "base": true}
+	scriptCloser = regexp.MustCompile(`(?i)</(script)`)
+	styleCloser  = regexp.MustCompile(`(?i)</(style)`)
+)
+
+var headKeyAttrs = []string{"name", "property", "http-equiv", "charset", "itemprop", "rel"}
+
+type layoutMetaKey struct{}
+
+func (h *PageHandler) WithMeta(tags ...HeadTag) *PageHandler {
+	h.meta = append(h.meta, tags...)
+	return h
+}
+
+func LayoutMeta(tags ...HeadTag) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, withLayoutMeta(r, tags))
+		})
+	}
+}
+
+func withLayoutMeta(r *http.Request, tags []HeadTag) *http.Request {
+	if len(tags) == 0 {
+		return r
+	}
+	tags = append(layoutMeta(r), tags...)
+	return r.WithContext(context.WithValue(r.Context(), layoutMetaKey{}, tags))
+}
+
+func layoutMeta(r *http.Request) []HeadTag {
+	tags, _ := r.Context().Value(layoutMetaKey{}).([]HeadTag)
+	return tags
+}
+
+func pageTitle(props map[string]any) string {
+	cfg := getConfig()
+	title := stringFromMap(props, "title")
+	if title == "" {
+		if cfg != nil && cfg.DefaultTitle != "" {
+			return cfg.DefaultTitle
+		}
+		return "Alloy"
+	}
+	if cfg != nil && strings.Contains(cfg.TitleTemplate, "%s") {
+		return strings.Replace(cfg.TitleTemplate, "%s", title, 1)
+	}
+	return title
+}
+
+func headTagKey(tag HeadTag) string {
+	switch tag.Tag {
+	case "meta":
+		for _, attr := range []string{"name", "property", "http-equiv", "itemprop"} {
+			if value := tag.Attrs[attr]; value != "" {
+				return "meta:" + attr + "=" + strings.ToLower(value)
+			}
+		}
+		if _, ok := tag.Attrs["charset"]; ok {
+			return "meta:charset"
+		}
+	case "link":
+		if rel := strings.ToLower(tag.Attrs["rel"]); rel == "canonical" || rel == "manifest" {
+			return "link:" + rel
+		}
+		if hreflang := tag.Attrs["hreflang"]; hreflang != "" {
+			return "link:" + strings.ToLower(tag.Attrs["rel"]) + ":" + strings.ToLower(hreflang)
+		}
+	case "base", "title":
+		return tag.Tag
+	}
+	return ""
+}
+
+func mergeHeadTags(layers ...[]HeadTag) []HeadTag {
+	var merged []HeadTag
+	for _, layer := range layers {
+		overrides := map[string][]HeadTag{}
+		for _, tag := range layer {
+			if key := headTagKey(tag); key != "" {
+				overrides[key] = append(overrides[key], tag)
+			}
+		}
+
+		next := make([]HeadTag, 0, len(merged)+len(layer))
+		placed := map[string]bool{}
+		for _, tag := range merged {
+			key := headTagKey(tag)
+			if _, ok := overrides[key]; !ok {
+				next = append(next, tag)
+			} else if !placed[key] {
+				next = append(next, overrides[key]...)
+				placed[key] = true
+			}
+		}
+		for _, tag := range layer {
+			key := headTagKey(tag)
+			if key == "" {
+				next = append(next, tag)
+			} else if !placed[key] {
+				next = append(next, overrides[key]...)
+				placed[key] = true
+			}
+		}
+		merged = next
+	}
+	return merged
+}
+
+func writeHeadTag(b *strings.Builder, tag HeadTag, nonce string) {
+	fmt.Fprintf(b, "\n\t<%s", tag.Tag)
+	for _, k := range headAttrOrder(tag.Attrs) {
+		fmt.Fprintf(b, " %s=\"%s\"", k, html.EscapeString(tag.Attrs[k]))
+	}
+	if nonce != "" && tag.Text != "" && tag.Attrs["nonce"] == "" && (tag.Tag == "style" || tag.Tag == "script" && !isDataScript(tag)) {
+		fmt.Fprintf(b, " nonce=\"%s\"", nonce)
+	}
+	b.WriteString(">")
+	if voidHeadTags[tag.Tag] {
+		return
+	}
+	fmt.Fprintf(b, "%s</%s>", headText(tag), tag.Tag)
+}
+
+func headText(tag HeadTag) string {
+	switch tag.Tag {
+	case "script":
+		if isDataScript(tag) {
+			return strings.ReplaceAll(tag.Text, "<", `\u003c`)
+		}
+		return scriptCloser.ReplaceAllString(strings.ReplaceAll(tag.Text, "<!--", `<\!--`), `<\/$1`)
+	case "style":
+		return styleCloser.ReplaceAllString(tag.Text, `<\/$1`)
+	}
+	return html.EscapeString(tag.Text)
+}
+
+func isDataScript(tag HeadTag) bool {
+	typ := strings.ToLower(tag.Attrs["type"])
+	return typ == "importmap" || strings.HasSuffix(typ, "json")
+}
+
+func headAttrOrder(attrs map[string]string) []string {
+	keys := sortedKeys(attrs)
+	slices.SortStableFunc(keys, func(a, b string) int {
+		ai, bi := slices.Index(headKeyAttrs, a), slices.Index(headKeyAttrs, b)
+		if ai < 0 {
+			ai = len(headKeyAttrs)
+		}
+		if bi < 0 {
+			bi = len(headKeyAttrs)
+		}
+		return ai - bi
+	})
+	return keys
+}
@@ -0,0 +1,139 @@
+package alloy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBuildHeadUsesDefaultTitleAndTemplate(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) {
+		cfg.DefaultTitle = "My Site"
+		cfg.TitleTemplate = "%s · My Site"
+	})
+
+	if head := buildHead(map[string]any{}); !strings.Contains(head, "<title>My Site</title>") {
+		t.Fatalf("🔴 expected default title:\n%s", head)
+	}
+	if head := buildHead(map[string]any{"title": "Pricing"}); !strings.Contains(head, "<title>Pricing · My Site</title>") {
+		t.Fatalf("🔴 expected templated title:\n%s", head)
+	}
+}
+
+func TestBuildHeadMergesDefaultMeta(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) {
+		cfg.DefaultMeta = []HeadTag{
+			{Tag: "meta", Attrs: map[string]string{"name": "description", "content": "Default description"}},
+			{Tag: "meta", Attrs: map[string]string{"property": "og:site_name", "content": "My Site"}},
+			{Tag: "link", Attrs: map[string]string{"rel": "canonical", "href": "https://example.com"}},
+		}
+	})
+
+	head := buildHead(map[string]any{"meta": []any{
+		map[string]any{"name": "Description", "content": "Page description"},
+		map[string]any{"tag": "link", "rel": "canonical", "href": "https://example.com/pricing"},
+	}})
+	for _, want := range []string{
+		`<meta name="Description" content="Page description">`,
+		`<meta property="og:site_name" content="My Site">`,
+		`<link rel="canonical" href="https://example.com/pricing">`,
+	} {
+		if !strings.Contains(head, want) {
+			t.Fatalf("🔴 missing %s in:\n%s", want, head)
+		}
+	}
+	if strings.Contains(head, "Default description") || strings.Count(head, "canonical") != 1 {
+		t.Fatalf("🔴 defaults should be replaced by page meta:\n%s", head)
+	}
+}
+
+func TestBuildHeadForcesNoindexOverPageRobots(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) { cfg.Environment = EnvironmentStaging })
+
+	head := buildHead(map[string]any{"meta": []any{map[string]any{"name": "robots", "content": "index, follow"}}})
+	if strings.Contains(head, "index, follow") || !strings.Contains(head, `<meta name="robots" content="noindex">`) {
+		t.Fatalf("🔴 expected only noindex robots:\n%s", head)
+	}
+}
+
+func TestMergeHeadTagsPrecedence(t *testing.T) {
+	meta := func(key, value, content string) HeadTag {
+		return HeadTag{Tag: "meta", Attrs: map[string]string{key: value, "content": content}}
+	}
+	merged := mergeHeadTags(
+		[]HeadTag{meta("name", "description", "default"), meta("property", "og:image", "default.png"), meta("name", "theme-color", "#fff")},
+		[]HeadTag{meta("name", "description", "layout")},
+		[]HeadTag{meta("property", "og:image", "a.png"), meta("property", "og:image", "b.png"), meta("name", "author", "loader")},
+	)
+
+	var got []string
+	for _, tag := range merged {
+		got = append(got, tag.Attrs["content"])
+	}
+	if strings.Join(got, ",") != "layout,a.png,b.png,#fff,loader" {
+		t.Fatalf("🔴 unexpected merge order: %v", got)
+	}
+}
+
+func TestPageHandlerLayoutMeta(t *testing.T) {
+	handler := LayoutMeta(HeadTag{Tag: "meta", Attrs: map[string]string{"name": "description", "content": "Docs section"}})(
+		etagTestPage(t, func(cfg *Config) {
+			cfg.DefaultMeta = []HeadTag{{Tag: "meta", Attrs: map[string]string{"name": "description", "content": "Site default"}}}
+		}).(*PageHandler).WithMeta(HeadTag{Tag: "meta", Attrs: map[string]string{"name": "author", "content": "Docs team"}}),
+	)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/etag", nil))
+	body := rec.Body.String()
+	if !strings.Contains(body, `content="Docs section"`) || strings.Contains(body, "Site default") || !strings.Contains(body, `content="Docs team"`) {
+		t.Fatalf("🔴 expected layout meta over defaults:\n%s", body)
+	}
+}
+
+func TestBuildHeadRendersTagText(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) { cfg.Environment = EnvironmentProduction })
+
+	head := buildHead(map[string]any{"title": "Page", "meta": []any{
+		map[string]any{"tag": "script", "type": "application/ld+json", "text": `{"name":"</script><b>"}`},
+		map[string]any{"tag": "style", "text": "body{color:red}</STYLE><script>"},
+		map[string]any{"tag": "script", "text": "if (a <!-- b) {}</script>"},
+		map[string]any{"tag": "noscript", "text": "<b>no js</b>"},
+		map[string]any{"tag": "title", "text": "Override & more"},
+	}})
+
+	for _, want := range []string{
+		`<script type="application/ld+json">{"name":"\u003c/script>\u003cb>"}</script>`,
+		`<style>body{color:red}<\/STYLE><script></style>`,
+		`<script>if (a <\!-- b) {}<\/script></script>`,
+		`<noscript>&lt;b&gt;no js&lt;/b&gt;</noscript>`,
+		`<title>Override &amp; more</title>`,
+	} {
+		if !strings.Contains(head, want) {
+			t.Fatalf("🔴 missing %s in:\n%s", want, head)
+		}
+	}
+	if strings.Count(head, "<title>") != 1 {
+		t.Fatalf("🔴 expected a single title:\n%s", head)
+	}
+}
+
+func TestRenderHeadAddsNonceToInlineTags(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) { cfg.Environment = EnvironmentProduction })
+
+	head := renderHead(map[string]any{}, nil, []HeadTag{
+		{Tag: "script", Text: "console.log(1)"},
+		{Tag: "script", Attrs: map[string]string{"type": "application/ld+json"}, Text: "{}"},
+		{Tag: "script", Attrs: map[string]string{"src": "/a.js"}},
+	}, "abc")
+
+	for _, want := range []string{
+		`<script nonce="abc">console.log(1)</script>`,
+		`<script type="application/ld+json">{}</script>`,
+		`<script src="/a.js"></script>`,
+	} {
+		if !strings.Contains(head, want) {
+			t.Fatalf("🔴 missing %s in:\n%s", want, head)
+		}
+	}
+}
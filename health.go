@@ -0,0 +1,76 @@
+package alloy
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"time"
+)
+
+var processStart = time.Now()
+
+type HealthReport struct {
+	Status    string          `json:"status"`
+	StartedAt time.Time       `json:"startedAt"`
+	Uptime    string          `json:"uptime"`
+	GoVersion string          `json:"goVersion"`
+	Manifest  *HealthManifest `json:"manifest,omitempty"`
+	Pages     int             `json:"registeredPages"`
+	Runtimes  RuntimeStats    `json:"runtimes"`
+}
+
+type HealthManifest struct {
+	Version      int       `json:"version"`
+	BuiltAt      time.Time `json:"builtAt"`
+	Commit       string    `json:"commit,omitempty"`
+	AlloyVersion string    `json:"alloyVersion,omitempty"`
+	Pages        int       `json:"pages"`
+}
+
+type RuntimeStats struct {
+	Created int64 `json:"created"`
+	Closed  int64 `json:"closed"`
+	Active  int64 `json:"active"`
+}
+
+func Health() HealthReport {
+	report := HealthReport{
+		Status:    "ok",
+		StartedAt: processStart.UTC(),
+		Uptime:    time.Since(processStart).Round(time.Second).String(),
+		GoVersion: runtime.Version(),
+		Runtimes:  currentRuntimeStats(),
+	}
+
+	if cfg := getConfig(); cfg != nil && cfg.FS != nil {
+		if manifest, err := ReadManifest(cfg.FS, cfg.DistDir); err == nil {
+			report.Manifest = &HealthManifest{
+				Version:      manifest.Version,
+				BuiltAt:      manifest.BuiltAt,
+				Commit:       manifest.Commit,
+				AlloyVersion: manifest.AlloyVersion,
+				Pages:        len(manifest.Pages),
+			}
+		}
+	}
+
+	bundleCache.RLock()
+	report.Pages = len(bundleCache.entries)
+	bundleCache.RUnlock()
+
+	return report
+}
+
+func HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(Health())
+	})
+}
+
+func currentRuntimeStats() RuntimeStats {
+	created := runtimesCreated.Load()
+	closed := runtimesClosed.Load()
+	return RuntimeStats{Created: created, Closed: closed, Active: created - closed}
+}
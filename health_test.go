@@ -0,0 +1,48 @@
+package alloy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHealthHandler(t *testing.T) {
+	resetBundleCache()
+	t.Cleanup(resetBundleCache)
+
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "dist", "build", "manifest.json"), `{"version": 2, "builtAt": "2026-01-02T03:04:05Z", "pages": {"home": {}, "about": {}}}`)
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+		cfg.DistDir = "dist/build"
+	})
+	if err := RegisterPrebuiltBundle(filepath.Join(root, "home.tsx"), "home-root", "server", "client", "css"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := executeSSR(t.Context(), `var __Component = { default: function() { return ""; } };`, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	HealthHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/health", nil))
+	if rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("content type: %s", rec.Header().Get("Content-Type"))
+	}
+
+	var report HealthReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("decode: %v\n%s", err, rec.Body.String())
+	}
+	if report.Status != "ok" || report.Pages != 1 || report.GoVersion == "" {
+		t.Fatalf("report: %+v", report)
+	}
+	if report.Manifest == nil || report.Manifest.Pages != 2 || report.Manifest.BuiltAt.Year() != 2026 {
+		t.Fatalf("manifest: %+v", report.Manifest)
+	}
+	if report.Runtimes.Created == 0 || report.Runtimes.Active != report.Runtimes.Created-report.Runtimes.Closed {
+		t.Fatalf("runtimes: %+v", report.Runtimes)
+	}
+}
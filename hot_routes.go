@@ -0,0 +1,151 @@
+package alloy
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+const defaultHotRoutesInterval = time.Minute
+
+type warmContextKey struct{}
+
+var hotRouteSet atomic.Pointer[map[string]bool]
+
+func ParseSitemap(data []byte) ([]string, error) {
+	var sitemap struct {
+		URLs []struct {
+			Loc string `xml:"loc"`
+		} `xml:"url"`
+	}
+	if err := xml.Unmarshal(data, &sitemap); err != nil {
+		return nil, fmt.Errorf("🔴 decode sitemap: %w", err)
+	}
+	routes := make([]string, 0, len(sitemap.URLs))
+	for _, entry := range sitemap.URLs {
+		u, err := url.Parse(entry.Loc)
+		if err != nil {
+			return nil, fmt.Errorf("🔴 sitemap url %s: %w", entry.Loc, err)
+		}
+		route := u.EscapedPath()
+		if route == "" {
+			route = "/"
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+func hotRoutes() ([]string, error) {
+	cfg := getConfig()
+	if cfg == nil {
+		return nil, nil
+	}
+	routes := append([]string(nil), cfg.HotRoutes...)
+	if cfg.HotSitemap != "" {
+		data, err := fs.ReadFile(cfg.FS, cfg.HotSitemap)
+		if err != nil {
+			return nil, fmt.Errorf("🔴 read sitemap: %w", err)
+		}
+		fromSitemap, err := ParseSitemap(data)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, fromSitemap...)
+	}
+	return routes, nil
+}
+
+func hotRoutesInterval() time.Duration {
+	if cfg := getConfig(); cfg != nil && cfg.HotInterval > 0 {
+		return cfg.HotInterval
+	}
+	return defaultHotRoutesInterval
+}
+
+func isHotRequest(r *http.Request) bool {
+	set := hotRouteSet.Load()
+	if set == nil || r.URL.RawQuery != "" || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+		return false
+	}
+	return (*set)[r.URL.Path]
+}
+
+func warming(r *http.Request) bool {
+	warm, _ := r.Context().Value(warmContextKey{}).(bool)
+	return warm
+}
+
+func WarmRoutes(ctx context.Context, handler http.Handler) error {
+	if os.Getenv("ALLOY_DEV") == "1" {
+		return nil
+	}
+	routes, err := hotRoutes()
+	if err != nil || len(routes) == 0 {
+		return err
+	}
+
+	set := map[string]bool{}
+	for _, route := range routes {
+		if u, err := url.Parse(route); err == nil {
+			set[u.Path] = true
+		}
+	}
+	hotRouteSet.Store(&set)
+	defer hotRouteSet.Store(nil)
+
+	ticker := time.NewTicker(hotRoutesInterval())
+	defer ticker.Stop()
+	for {
+		for _, route := range sortedKeys(set) {
+			warmRoute(ctx, handler, route)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func warmRoute(ctx context.Context, handler http.Handler, route string) {
+	start := time.Now()
+	r, err := http.NewRequestWithContext(context.WithValue(ctx, warmContextKey{}, true), http.MethodGet, route, nil)
+	if err != nil {
+		logger().Error("warm route", "path", route, "err", err)
+		return
+	}
+	w := &discardWriter{header: http.Header{}}
+	handler.ServeHTTP(w, r)
+	if w.status >= http.StatusBadRequest {
+		logger().Error("warm route", "path", route, "status", w.status)
+		return
+	}
+	logger().Debug("warm route", "path", route, "duration", time.Since(start))
+}
+
+type discardWriter struct {
+	header http.Header
+	status int
+}
+
+func (w *discardWriter) Header() http.Header { return w.header }
+
+func (w *discardWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return len(p), nil
+}
+
+func (w *discardWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
@@ -0,0 +1,125 @@
+package alloy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseSitemap(t *testing.T) {
+	routes, err := ParseSitemap([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<url><loc>https://example.com/</loc></url>
+	<url><loc>https://example.com</loc></url>
+	<url><loc>https://example.com/blog/hello%20world?ref=1</loc></url>
+</urlset>`))
+	if err != nil || strings.Join(routes, ",") != "/,/,/blog/hello%20world" {
+		t.Fatalf("🔴 routes = %v, %v", routes, err)
+	}
+}
+
+func TestWarmRoutesKeepsPagesCached(t *testing.T) {
+	resetBundleCache()
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "hot-server.js"), `var __Component = { default: function(props) { return "<p>" + props.n + "</p>"; } };`)
+	writeTestFile(t, filepath.Join(dist, "client-hot-AAAAAAAA.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "shared.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{"hot": {"server": "hot-server.js", "client": "client-hot-AAAAAAAA.js", "css": "shared.css"}}`)
+	writeTestFile(t, filepath.Join(root, "public", "sitemap.xml"), `<urlset><url><loc>https://example.com/hot</loc></url></urlset>`)
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+		cfg.ISRDir = t.TempDir()
+		cfg.HotSitemap = "public/sitemap.xml"
+		cfg.HotInterval = time.Hour
+	})
+
+	var calls atomic.Int64
+	mux := http.NewServeMux()
+	mux.Handle("/hot", NewPage(filepath.Join(root, "pages", "hot.tsx")).WithLoader(func(r *http.Request) map[string]any {
+		return map[string]any{"n": calls.Add(1)}
+	}))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+	go func() { done <- WarmRoutes(ctx, mux) }()
+	key := isrKey(filepath.Join(root, "pages", "hot.tsx"), httptest.NewRequest(http.MethodGet, "/hot", nil))
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		isrCache.Lock()
+		warm := isrCache.entries[key] != nil
+		isrCache.Unlock()
+		if warm {
+			break
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hot", nil))
+	if rec.Header().Get("X-Alloy-Cache") != "HIT" || !strings.Contains(rec.Body.String(), "<p>1</p>") || calls.Load() != 1 {
+		t.Fatalf("🔴 expected warm HIT, got %q after %d loader calls: %s", rec.Header().Get("X-Alloy-Cache"), calls.Load(), rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hot?fresh=1", nil))
+	if rec.Header().Get("X-Alloy-Cache") != "" || calls.Load() != 2 {
+		t.Fatalf("🔴 expected query to bypass the hot cache, got %q", rec.Header().Get("X-Alloy-Cache"))
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if isHotRequest(httptest.NewRequest(http.MethodGet, "/hot", nil)) {
+		t.Fatal("🔴 expected hot routes to stop with WarmRoutes")
+	}
+}
+
+type hotRouteFlags struct{}
+
+func (hotRouteFlags) Evaluate(ctx context.Context, key string) Flag {
+	return Flag{Enabled: true}
+}
+
+func TestHotRoutesRenderLikeUncachedPages(t *testing.T) {
+	resetBundleCache()
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "item-server.js"), `var __Component = { default: function(props) { return "<p>" + props.theme + " " + props.flags.beta.enabled + "</p>"; } };`)
+	writeTestFile(t, filepath.Join(dist, "client-item-AAAAAAAA.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "shared.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{"item": {"server": "item-server.js", "client": "client-item-AAAAAAAA.js", "css": "shared.css"}}`)
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+		cfg.ThemeCookie = "theme"
+		cfg.Flags = hotRouteFlags{}
+		cfg.FlagKeys = []string{"beta"}
+	})
+	t.Cleanup(resetISRCache)
+	hotRouteSet.Store(&map[string]bool{"/items/7": true})
+	t.Cleanup(func() { hotRouteSet.Store(nil) })
+
+	mux := http.NewServeMux()
+	mux.Handle("/items/{id}", NewPage(filepath.Join(root, "pages", "item.tsx")).WithLoader(func(r *http.Request) map[string]any {
+		return map[string]any{}
+	}))
+
+	for _, theme := range []string{"dark", "light", "dark"} {
+		req := httptest.NewRequest(http.MethodGet, "/items/7", nil)
+		req.AddCookie(&http.Cookie{Name: "theme", Value: theme})
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		body := rec.Body.String()
+		if rec.Header().Get("X-Alloy-Cache") == "" || !strings.Contains(body, "<p>"+theme+" true</p>") || !strings.Contains(body, `data-theme="`+theme+`"`) {
+			t.Fatalf("🔴 %s: expected a cached themed render with flags, got %q:\n%s", theme, rec.Header().Get("X-Alloy-Cache"), body)
+		}
+		if !strings.Contains(body, `"params":{"id":"7"}`) {
+			t.Fatalf("🔴 %s: expected route params in the render context:\n%s", theme, body)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package alloy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+const messagesModule = "alloy:messages"
+
+const messagesNamespace = "alloy-messages"
+
+type LocaleFiles struct {
+	Client       string
+	ClientChunks []string
+	Assets       []string
+	Legacy       string
+}
+
+func localesDir() string {
+	cfg := getConfig()
+	if cfg == nil {
+		return filepath.Join(DefaultAppDir, "locales")
+	}
+	if cfg.LocalesDir != "" {
+		return cfg.LocalesDir
+	}
+	return filepath.Join(cfg.AppDir, "locales")
+}
+
+func DiscoverLocales(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("🔴 read locales dir: %w", err)
+	}
+	var locales []string
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		locales = append(locales, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	sort.Strings(locales)
+	return locales, nil
+}
+
+func DefaultLocale(locales []string) string {
+	if cfg := getConfig(); cfg != nil && slices.Contains(locales, cfg.DefaultLocale) {
+		return cfg.DefaultLocale
+	}
+	if slices.Contains(locales, "en") || len(locales) == 0 {
+		return "en"
+	}
+	return locales[0]
+}
+
+func BuildLocaleClientBundles(entries []ClientEntry, outDir string) (string, map[string]map[string]ClientAssets, error) {
+	locales, err := DiscoverLocales(localesDir())
+	if err != nil || len(locales) == 0 {
+		return "", nil, err
+	}
+	defaultLocale := DefaultLocale(locales)
+	bundles := map[string]map[string]ClientAssets{}
+	for _, locale := range locales {
+		if locale == defaultLocale {
+			continue
+		}
+		assets, err := configuredBundler().BuildClient(entries, outDir, locale)
+		if err != nil {
+			return "", nil, fmt.Errorf("🔴 build %s client bundles: %w", locale, err)
+		}
+		bundles[locale] = assets
+	}
+	return defaultLocale, bundles, nil
+}
+
+func messagesPlugin(dir string, locales []string, locale string) api.Plugin {
+	return api.Plugin{
+		Name: "alloy-messages",
+		Setup: func(build api.PluginBuild) {
+			build.OnResolve(api.OnResolveOptions{Filter: `^alloy:messages$`}, func(args api.OnResolveArgs) (api.OnResolveResult, error) {
+				return api.OnResolveResult{Path: messagesModule, Namespace: messagesNamespace}, nil
+			})
+
+			build.OnLoad(api.OnLoadOptions{Filter: `.*`, Namespace: messagesNamespace}, func(args api.OnLoadArgs) (api.OnLoadResult, error) {
+				if locale != "" {
+					file := filepath.Join(dir, locale+".json")
+					data, err := os.ReadFile(file)
+					if err != nil {
+						return api.OnLoadResult{}, fmt.Errorf("🔴 read messages %s: %w", FormatPath(file), err)
+					}
+					contents := string(data)
+					return api.OnLoadResult{Contents: &contents, Loader: api.LoaderJSON, WatchFiles: []string{file}}, nil
+				}
+
+				var b strings.Builder
+				var files []string
+				b.WriteString("const catalogs = {\n")
+				for _, name := range locales {
+					file := filepath.Join(dir, name+".json")
+					data, err := os.ReadFile(file)
+					if err != nil {
+						return api.OnLoadResult{}, fmt.Errorf("🔴 read messages %s: %w", FormatPath(file), err)
+					}
+					fmt.Fprintf(&b, "  %s: %s,\n", strconv.Quote(name), data)
+					files = append(files, file)
+				}
+				b.WriteString("};\n")
+				fmt.Fprintf(&b, "const fallback = %s;\n", strconv.Quote(DefaultLocale(locales)))
+				b.WriteString(`const pick = () => catalogs[(globalThis.__ALLOY_CTX__ || {}).locale] || catalogs[fallback];
+export default new Proxy({}, {
+  get: (_, key) => pick()[key],
+  has: (_, key) => key in pick(),
+  ownKeys: () => Reflect.ownKeys(pick()),
+  getOwnPropertyDescriptor: (_, key) => Object.getOwnPropertyDescriptor(pick(), key),
+});
+`)
+				contents := b.String()
+				return api.OnLoadResult{Contents: &contents, Loader: api.LoaderJS, WatchFiles: files}, nil
+			})
+		},
+	}
+}
+
+func catalogFiles() []string {
+	dir := localesDir()
+	locales, _ := DiscoverLocales(dir)
+	files := make([]string, 0, len(locales))
+	for _, locale := range locales {
+		if abs, err := filepath.Abs(filepath.Join(dir, locale+".json")); err == nil {
+			files = append(files, abs)
+		}
+	}
+	return files
+}
+
+func MatchLocale(r *http.Request, locales []string, fallback string) string {
+	for _, tag := range acceptLanguages(r) {
+		for _, locale := range locales {
+			if strings.EqualFold(tag, locale) {
+				return locale
+			}
+		}
+		base, _, _ := strings.Cut(tag, "-")
+		for _, locale := range locales {
+			if localeBase, _, _ := strings.Cut(locale, "-"); strings.EqualFold(base, localeBase) {
+				return locale
+			}
+		}
+	}
+	return fallback
+}
+
+func acceptLanguages(r *http.Request) []string {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		if tag != "" && tag != "*" && q > 0 {
+			candidates = append(candidates, candidate{tag: tag, q: q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	tags := make([]string, len(candidates))
+	for i, c := range candidates {
+		tags[i] = c.tag
+	}
+	return tags
+}
+
+type localeContextKey struct{}
+
+func Locale(r *http.Request) string {
+	locale, _ := r.Context().Value(localeContextKey{}).(string)
+	return locale
+}
+
+func withLocale(w http.ResponseWriter, r *http.Request, files PrebuiltFiles) (*http.Request, PrebuiltFiles) {
+	if files.Locale == "" {
+		return r, files
+	}
+	locales := append([]string{files.Locale}, sortedKeys(files.Locales)...)
+
+	locale := ""
+	if cfg := getConfig(); cfg != nil && cfg.Locale != nil {
+		locale = cfg.Locale(r)
+	} else {
+		addVary(w.Header(), "Accept-Language")
+	}
+	if !slices.Contains(locales, locale) {
+		locale = MatchLocale(r, locales, files.Locale)
+	}
+	if localized, ok := files.Locales[locale]; ok {
+		files.Client = localized.Client
+		files.ClientChunks = localized.ClientChunks
+		files.Assets = localized.Assets
+		if localized.Legacy != "" {
+			files.Legacy = localized.Legacy
+		}
+	}
+
+	ctx := context.WithValue(r.Context(), localeContextKey{}, locale)
+	return r.WithContext(withRenderContextValue(ctx, "locale", locale)), files
+}
+
+func manifestLocales(locales map[string]LocaleFiles) map[string]ManifestLocale {
+	if len(locales) == 0 {
+		return nil
+	}
+	out := make(map[string]ManifestLocale, len(locales))
+	for locale, files := range locales {
+		out[locale] = ManifestLocale{
+			Client: filepath.Base(files.Client),
+			Chunks: baseNames(files.ClientChunks),
+			Assets: assetRelNames(files.Assets),
+			Legacy: htmlBaseName(files.Legacy),
+		}
+	}
+	return out
+}
+
+func localeFiles(dist string, locales map[string]ManifestLocale) map[string]LocaleFiles {
+	if len(locales) == 0 {
+		return nil
+	}
+	out := make(map[string]LocaleFiles, len(locales))
+	for locale, entry := range locales {
+		out[locale] = LocaleFiles{
+			Client:       path.Join(dist, entry.Client),
+			ClientChunks: joinPaths(dist, entry.Chunks),
+			Assets:       joinPaths(dist, entry.Assets),
+			Legacy:       joinPath(dist, entry.Legacy),
+		}
+	}
+	return out
+}
+
+func manifestLocaleNames(entry ManifestPage) []string {
+	var names []string
+	for _, locale := range sortedKeys(entry.Locales) {
+		files := entry.Locales[locale]
+		names = append(names, files.Client)
+		names = append(names, files.Chunks...)
+		names = append(names, files.Assets...)
+		if files.Legacy != "" {
+			names = append(names, files.Legacy)
+		}
+	}
+	return names
+}
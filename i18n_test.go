@@ -0,0 +1,145 @@
+package alloy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatchLocale(t *testing.T) {
+	locales := []string{"en", "fr", "pt-BR"}
+	for header, want := range map[string]string{
+		"fr-CA,fr;q=0.9,en;q=0.8": "fr",
+		"en;q=0.5, fr":            "fr",
+		"pt-br":                   "pt-BR",
+		"pt-PT":                   "pt-BR",
+		"de, *;q=0.1":             "en",
+		"":                        "en",
+		"fr;q=0":                  "en",
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept-Language", header)
+		if got := MatchLocale(r, locales, "en"); got != want {
+			t.Errorf("MatchLocale(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestLocaleClientBundles(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	withTestConfig(t, func(cfg *Config) {})
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react", "jsx-runtime.js"), `exports.jsx = (type, props) => ({ type, props });`+"\n")
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react-dom", "server.edge.js"), `exports.renderToString = (el) => String(el.type(el.props));`+"\n")
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react-dom", "client.js"), `exports.hydrateRoot = () => {}; exports.createRoot = () => ({ render() {} });`+"\n")
+	writeTestFile(t, filepath.Join(dir, "app", "locales", "en.json"), `{"hello": "Hello there"}`)
+	writeTestFile(t, filepath.Join(dir, "app", "locales", "fr.json"), `{"hello": "Bonjour tout le monde"}`)
+	writeTestFile(t, filepath.Join(dir, "app", "pages", "home.tsx"), `import messages from "alloy:messages";
+export default function Home() { return messages.hello; }
+`)
+	component := filepath.Join(dir, "app", "pages", "home.tsx")
+	entries := []ClientEntry{{Name: "home", Component: component}}
+	dist := filepath.Join(dir, "dist", "build")
+
+	base, err := BuildClientBundles(entries, dist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaultLocale, localized, err := BuildLocaleClientBundles(entries, dist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if defaultLocale != "en" || len(localized) != 1 {
+		t.Fatalf("default = %q, locales = %v", defaultLocale, localized)
+	}
+
+	read := func(p string) string {
+		data, err := os.ReadFile(filepath.Join(dir, p))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	en, fr := read(base["home"].Entry), read(localized["fr"]["home"].Entry)
+	if !strings.Contains(en, "Hello there") || strings.Contains(en, "Bonjour") {
+		t.Fatalf("default bundle %s:\n%s", base["home"].Entry, en)
+	}
+	if !strings.Contains(fr, "Bonjour tout le monde") || strings.Contains(fr, "Hello there") {
+		t.Fatalf("fr bundle %s:\n%s", localized["fr"]["home"].Entry, fr)
+	}
+	if !strings.Contains(localized["fr"]["home"].Entry, "client-home.fr-") {
+		t.Fatalf("fr entry name = %s", localized["fr"]["home"].Entry)
+	}
+
+	serverJS, deps, err := BuildServerBundle(component)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, dep := range deps {
+		found = found || dep == filepath.Join(dir, "app", "locales", "fr.json")
+	}
+	if !found {
+		t.Fatalf("catalogs missing from deps: %v", deps)
+	}
+	for locale, want := range map[string]string{"fr": "Bonjour tout le monde", "de": "Hello there"} {
+		ctx := context.WithValue(context.Background(), renderContextKey{}, map[string]any{"locale": locale})
+		out, err := executeSSR(ctx, serverJS, map[string]any{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out.HTML != want {
+			t.Errorf("%s render = %q, want %q", locale, out.HTML, want)
+		}
+	}
+}
+
+func TestPageHandlerServesLocaleBundle(t *testing.T) {
+	resetBundleCache()
+	t.Cleanup(resetBundleCache)
+
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "home-server.js"), `var __Component = { default: function(props) { return "<p>" + __ALLOY_CTX__.locale + "</p>"; } };`)
+	writeTestFile(t, filepath.Join(dist, "client-home.en-AAAAAAAA.js"), "en")
+	writeTestFile(t, filepath.Join(dist, "client-home.fr-BBBBBBBB.js"), "fr")
+	writeTestFile(t, filepath.Join(dist, "shared.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{"home": {
+		"server": "home-server.js", "client": "client-home.en-AAAAAAAA.js", "css": "shared.css",
+		"locale": "en", "locales": {"fr": {"client": "client-home.fr-BBBBBBBB.js"}}
+	}}`)
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+	})
+
+	var seen string
+	handler := NewPage(filepath.Join(root, "pages", "home.tsx")).WithLoader(func(r *http.Request) map[string]any {
+		seen = Locale(r)
+		return map[string]any{}
+	})
+	get := func(lang string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Language", lang)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("fr-FR,fr;q=0.9")
+	body := rec.Body.String()
+	if !strings.Contains(body, "<p>fr</p>") || !strings.Contains(body, "client-home.fr-BBBBBBBB.js") || strings.Contains(body, "client-home.en-") {
+		t.Fatalf("fr page:\n%s", body)
+	}
+	if seen != "fr" || rec.Header().Get("Vary") != "X-Alloy-Data, Accept-Language" {
+		t.Fatalf("loader locale = %q, Vary = %q", seen, rec.Header().Get("Vary"))
+	}
+
+	body = get("de").Body.String()
+	if !strings.Contains(body, "<p>en</p>") || !strings.Contains(body, "client-home.en-AAAAAAAA.js") {
+		t.Fatalf("fallback page:\n%s", body)
+	}
+}
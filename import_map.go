@@ -0,0 +1,99 @@
+package alloy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func ParseImportMap(data []byte) (map[string]string, error) {
+	var wrapped struct {
+		Imports map[string]string `json:"imports"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err == nil && wrapped.Imports != nil {
+		return wrapped.Imports, nil
+	}
+	var imports map[string]string
+	if err := json.Unmarshal(data, &imports); err != nil {
+		return nil, fmt.Errorf("🔴 decode import map: %w", err)
+	}
+	return imports, nil
+}
+
+func ReactImportMap(cdn string, root string) (map[string]string, error) {
+	react, err := installedVersion(root, "react")
+	if err != nil {
+		return nil, err
+	}
+	reactDOM, err := installedVersion(root, "react-dom")
+	if err != nil {
+		return nil, err
+	}
+
+	base := strings.TrimSuffix(cdn, "/")
+	if !strings.Contains(base, "://") {
+		base = "https://" + base
+	}
+	deps := "?deps=react@" + react
+	return map[string]string{
+		"react":                 fmt.Sprintf("%s/react@%s", base, react),
+		"react/jsx-runtime":     fmt.Sprintf("%s/react@%s/jsx-runtime", base, react),
+		"react-dom":             fmt.Sprintf("%s/react-dom@%s%s", base, reactDOM, deps),
+		"react-dom/client":      fmt.Sprintf("%s/react-dom@%s/client%s", base, reactDOM, deps),
+		"react/jsx-dev-runtime": fmt.Sprintf("%s/react@%s/jsx-dev-runtime", base, react),
+	}, nil
+}
+
+func installedVersion(root string, pkg string) (string, error) {
+	file := filepath.Join(root, "node_modules", pkg, "package.json")
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("🔴 read %s version: %w", pkg, err)
+	}
+	var manifest struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil || manifest.Version == "" {
+		return "", fmt.Errorf("🔴 no version in %s", FormatPath(file))
+	}
+	return manifest.Version, nil
+}
+
+func importMapExternals(imports map[string]string) []string {
+	externals := make([]string, 0, len(imports))
+	for _, specifier := range sortedKeys(imports) {
+		if strings.HasSuffix(specifier, "/") {
+			specifier += "*"
+		}
+		externals = append(externals, specifier)
+	}
+	return externals
+}
+
+func configImportMap() map[string]string {
+	if cfg := getConfig(); cfg != nil {
+		return cfg.ImportMap
+	}
+	return nil
+}
+
+func importMapTag(imports map[string]string, nonceAttr string) string {
+	if len(imports) == 0 {
+		return ""
+	}
+	encoded, err := json.Marshal(map[string]any{"imports": imports})
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("\n\t<script type=\"importmap\"%s>%s</script>", nonceAttr, encoded)
+}
+
+func (r *RenderResult) importMapTag() string {
+	imports := r.ImportMap
+	if imports == nil {
+		imports = configImportMap()
+	}
+	return importMapTag(imports, r.nonceAttr())
+}
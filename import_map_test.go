@@ -0,0 +1,78 @@
+package alloy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReactImportMap(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react", "package.json"), `{"name": "react", "version": "19.1.0"}`)
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react-dom", "package.json"), `{"name": "react-dom", "version": "19.1.0"}`)
+
+	imports, err := ReactImportMap("esm.sh", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imports["react"] != "https://esm.sh/react@19.1.0" || imports["react-dom/client"] != "https://esm.sh/react-dom@19.1.0/client?deps=react@19.1.0" {
+		t.Fatalf("imports = %v", imports)
+	}
+
+	for _, data := range []string{`{"imports": {"react": "/vendor/react.js"}}`, `{"react": "/vendor/react.js"}`} {
+		parsed, err := ParseImportMap([]byte(data))
+		if err != nil || parsed["react"] != "/vendor/react.js" {
+			t.Fatalf("ParseImportMap(%s) = %v, %v", data, parsed, err)
+		}
+	}
+}
+
+func TestImportMapExternalizesClientPackages(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	imports := map[string]string{
+		"react":             "/vendor/react.js",
+		"react/jsx-runtime": "/vendor/jsx-runtime.js",
+		"react-dom/client":  "/vendor/react-dom-client.js",
+	}
+	withTestConfig(t, func(cfg *Config) {
+		cfg.ImportMap = imports
+	})
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react", "jsx-runtime.js"), `exports.jsx = function bundledJSX() {};`+"\n")
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react-dom", "client.js"), `exports.hydrateRoot = function bundledHydrate() {};`+"\n")
+	component := filepath.Join(dir, "app", "pages", "home.tsx")
+	writeTestFile(t, component, "export default function Home() { return <p>home</p>; }\n")
+
+	dist := filepath.Join(dir, "dist")
+	assets, err := BuildClientBundles([]ClientEntry{{Name: "home", Component: component, RootID: "home-root"}}, dist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	js, err := os.ReadFile(filepath.Join(dist, filepath.Base(assets["home"].Entry)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(js), "bundledJSX") || !strings.Contains(string(js), `"react/jsx-runtime"`) {
+		t.Fatalf("react was bundled: %s", js)
+	}
+
+	files := PrebuiltFiles{Server: "dist/home-server.js", Client: "dist/" + filepath.Base(assets["home"].Entry), CSS: "dist/shared.css"}
+	if err := WriteManifest(dist, "home", files); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := ReadManifest(os.DirFS(dir), "dist")
+	if err != nil || manifest.ImportMap["react"] != "/vendor/react.js" {
+		t.Fatalf("manifest import map = %v, %v", manifest, err)
+	}
+
+	withTestConfig(t, func(cfg *Config) {})
+	files.ImportMap = manifest.ImportMap
+	result := prebuiltResult("<p>home</p>", nil, files)
+	result.Nonce = "abc"
+	html := result.ToHTML("home-root")
+	tag := `<script type="importmap" nonce="abc">{"imports":{"react":"/vendor/react.js","react-dom/client":"/vendor/react-dom-client.js","react/jsx-runtime":"/vendor/jsx-runtime.js"}}</script>`
+	if !strings.Contains(html, tag) || strings.Index(html, tag) > strings.Index(html, `type="module"`) {
+		t.Fatalf("html = %s", html)
+	}
+}
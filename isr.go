@@ -0,0 +1,328 @@
+package alloy
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultISRDir        = ".alloy/isr"
+	defaultISRMaxEntries = 1000
+)
+
+type isrEntry struct {
+	html         []byte
+	generatedAt  time.Time
+	regenerating bool
+	elem         *list.Element
+}
+
+var isrCache = struct {
+	sync.Mutex
+	entries map[string]*isrEntry
+	order   *list.List
+}{
+	entries: make(map[string]*isrEntry),
+	order:   list.New(),
+}
+
+var isrTrimmedDirs sync.Map
+
+var isrRegenerations sync.WaitGroup
+
+func (h *PageHandler) WithRevalidate(interval time.Duration) *PageHandler {
+	h.revalidate = interval
+	return h
+}
+
+func isrKey(component string, r *http.Request) string {
+	key := component + "\x00" + r.URL.Path + varyKey(r)
+	if variant := Variant(r); variant != "" {
+		key += "\x00" + variant
+	}
+	if page := Locale(r) + "\x00" + Theme(r) + "\x00" + flagKey(r); page != "\x00\x00" {
+		key += "\x00" + page
+	}
+	if values := RenderContext(r.Context()); len(values) > 0 {
+		if encoded, err := json.Marshal(values); err == nil {
+			key += "\x00" + shortHash(string(encoded))
+		}
+	}
+	return key
+}
+
+func isrDir() string {
+	if cfg := getConfig(); cfg != nil && cfg.ISRDir != "" {
+		return cfg.ISRDir
+	}
+	return defaultISRDir
+}
+
+func isrMaxEntries() int {
+	if cfg := getConfig(); cfg != nil && cfg.ISRMaxEntries > 0 {
+		return cfg.ISRMaxEntries
+	}
+	return defaultISRMaxEntries
+}
+
+// putISREntry must be called with isrCache locked. It returns the keys
+// evicted to stay within ISRMaxEntries.
+func putISREntry(key string, entry *isrEntry) []string {
+	if old := isrCache.entries[key]; old != nil && old.elem != nil {
+		isrCache.order.Remove(old.elem)
+	}
+	entry.elem = isrCache.order.PushFront(key)
+	isrCache.entries[key] = entry
+
+	var evicted []string
+	for isrCache.order.Len() > isrMaxEntries() {
+		oldest := isrCache.order.Back()
+		isrCache.order.Remove(oldest)
+		delete(isrCache.entries, oldest.Value.(string))
+		evicted = append(evicted, oldest.Value.(string))
+	}
+	return evicted
+}
+
+func removeISRFiles(keys []string) {
+	for _, key := range keys {
+		os.Remove(isrFile(key))
+	}
+}
+
+func isrFile(key string) string {
+	sum := sha1.Sum([]byte(key))
+	return filepath.Join(isrDir(), hex.EncodeToString(sum[:])+".html")
+}
+
+func (h *PageHandler) serveISR(w http.ResponseWriter, r *http.Request, files PrebuiltFiles, rootID string, revalidate time.Duration) {
+	withTheme(w, r, nil)
+	key := isrKey(h.component, r)
+	if warming(r) {
+		html, err := h.renderHTML(r, files, rootID)
+		if err != nil {
+			writeRenderError(w, err)
+			return
+		}
+		storeISREntry(key, html)
+		writeISRResponse(w, r, []byte(html), "WARM")
+		return
+	}
+
+	var evicted []string
+	isrCache.Lock()
+	entry := isrCache.entries[key]
+	if entry == nil {
+		entry = loadISREntry(key, files, h.loader == nil && Variant(r) == "" && buildDefaults(r, files))
+		if entry != nil {
+			evicted = putISREntry(key, entry)
+		}
+	} else {
+		isrCache.order.MoveToFront(entry.elem)
+	}
+
+	if entry == nil {
+		isrCache.Unlock()
+		bg := r.WithContext(context.WithoutCancel(r.Context()))
+		html, err, shared := renderFlights.Do("isr\x00"+key, func() (any, error) {
+			html, err := h.renderHTML(bg, files, rootID)
+			if err != nil {
+				return nil, err
+			}
+			storeISREntry(key, html)
+			return html, nil
+		})
+		if shared {
+			metrics.coalescedRenders.inc(pageName(h.component))
+		}
+		if err != nil {
+			writeRenderError(w, err)
+			return
+		}
+		recordPageStats(pageName(h.component), func(c *pageCounters) { c.cacheMisses++ })
+		writeISRResponse(w, r, []byte(html.(string)), "MISS")
+		return
+	}
+
+	status := "HIT"
+	if time.Since(entry.generatedAt) > revalidate {
+		status = "STALE"
+		if !entry.regenerating {
+			entry.regenerating = true
+			bg := r.Clone(withoutVary(context.WithoutCancel(r.Context())))
+			isrRegenerations.Add(1)
+			go h.regenerateISR(key, bg, files, rootID)
+		}
+	}
+	html := entry.html
+	isrCache.Unlock()
+	removeISRFiles(evicted)
+
+	recordPageStats(pageName(h.component), func(c *pageCounters) { c.cacheHits++ })
+	writeISRResponse(w, r, html, status)
+}
+
+// The build prerenders each page once, for the default locale with no theme,
+// flags or request context.
+func buildDefaults(r *http.Request, files PrebuiltFiles) bool {
+	locale := Locale(r)
+	return (locale == "" || locale == files.Locale) && Theme(r) == "" && flagKey(r) == "" && len(RenderContext(r.Context())) == 0
+}
+
+func (h *PageHandler) regenerateISR(key string, r *http.Request, files PrebuiltFiles, rootID string) {
+	defer isrRegenerations.Done()
+	html, err := h.renderHTML(r, files, rootID)
+	if err != nil {
+		isrCache.Lock()
+		if entry := isrCache.entries[key]; entry != nil {
+			entry.regenerating = false
+		}
+		isrCache.Unlock()
+		logger().Error("revalidate", "page", pageName(h.component), "path", r.URL.Path, "err", err)
+		return
+	}
+	storeISREntry(key, html)
+	logger().Debug("revalidate", "page", pageName(h.component), "path", r.URL.Path)
+}
+
+func (h *PageHandler) renderHTML(r *http.Request, files PrebuiltFiles, rootID string) (string, error) {
+	props, err := h.loadProps(r)
+	if err != nil {
+		return "", err
+	}
+	r, props, err = checkPropsSize(r, h.component, themeProps(r, withFlagProps(r, withVariantProps(r, props))))
+	if err != nil {
+		return "", err
+	}
+
+	if files.Server == "" {
+		result, err := RenderTSXFileWithHydrationWithContext(r.Context(), h.component, props, rootID)
+		if err != nil {
+			reportRenderError(r, h.component, props, err)
+			return "", err
+		}
+		result.Nonce = cspNoncePlaceholder
+		result.Layout = layoutMeta(r)
+		result.Context = RenderContext(r.Context())
+		return result.ToHTML(rootID), nil
+	}
+
+	if err := RegisterPrebuiltBundleFromFS(h.component, rootID, getConfig().FS, files); err != nil {
+		return "", err
+	}
+	result, err := RenderPrebuiltWithContext(r.Context(), h.component, props, rootID, files)
+	if err != nil {
+		reportRenderError(r, h.component, props, err)
+		return "", err
+	}
+	result.Nonce = cspNoncePlaceholder
+	result.Layout = layoutMeta(r)
+	result.Context = RenderContext(r.Context())
+	return result.ToHTML(rootID), nil
+}
+
+func loadISREntry(key string, files PrebuiltFiles, seedFromBuild bool) *isrEntry {
+	file := isrFile(key)
+	if html, err := os.ReadFile(file); err == nil {
+		generatedAt := time.Now()
+		if info, err := os.Stat(file); err == nil {
+			generatedAt = info.ModTime()
+		}
+		return &isrEntry{html: html, generatedAt: generatedAt}
+	}
+
+	if seedFromBuild && files.HTML != "" {
+		if html, err := readPrebuiltFile(getConfig().FS, files.HTML); err == nil {
+			return &isrEntry{html: html}
+		}
+	}
+	return nil
+}
+
+func storeISREntry(key string, html string) {
+	entry := &isrEntry{html: []byte(html), generatedAt: time.Now()}
+
+	isrCache.Lock()
+	evicted := putISREntry(key, entry)
+	isrCache.Unlock()
+	removeISRFiles(evicted)
+
+	file := isrFile(key)
+	if err := writeISRFile(file, entry.html); err != nil {
+		logger().Error("persist revalidated page", "file", file, "err", err)
+	}
+}
+
+func writeISRFile(file string, html []byte) error {
+	dir := filepath.Dir(file)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if _, trimmed := isrTrimmedDirs.LoadOrStore(dir, true); !trimmed {
+		trimISRDir(dir, isrMaxEntries())
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(file)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(html); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+func trimISRDir(dir string, limit int) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	type page struct {
+		name string
+		mod  time.Time
+	}
+	var pages []page
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			os.Remove(filepath.Join(dir, entry.Name()))
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !strings.HasSuffix(entry.Name(), ".html") {
+			continue
+		}
+		pages = append(pages, page{name: entry.Name(), mod: info.ModTime()})
+	}
+	slices.SortFunc(pages, func(a, b page) int { return b.mod.Compare(a.mod) })
+	for _, p := range pages[min(limit, len(pages)):] {
+		os.Remove(filepath.Join(dir, p.name))
+	}
+}
+
+func writeISRResponse(w http.ResponseWriter, r *http.Request, html []byte, status string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Alloy-Cache", status)
+	metrics.isrCache.inc(strings.ToLower(status))
+	if r.Method != http.MethodHead {
+		writeHTML(r.Context(), w, fillNonce(string(html), CSPNonce(r)))
+	}
+}
@@ -0,0 +1,147 @@
+package alloy
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPageHandlerRevalidatesStaticPages(t *testing.T) {
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "news-server.js"), fakeStaticServerJS)
+	writeTestFile(t, filepath.Join(dist, "client-news-AAAAAAAA.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "shared.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{
+		"news": {"server": "news-server.js", "client": "client-news-AAAAAAAA.js", "css": "shared.css", "render": "static", "revalidate": 60}
+	}`)
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+		cfg.ISRDir = t.TempDir()
+	})
+	t.Cleanup(resetISRCache)
+
+	version := 0
+	handler := NewPage(filepath.Join(root, "pages", "news.tsx")).WithLoader(func(r *http.Request) map[string]any {
+		version++
+		return map[string]any{"name": fmt.Sprintf("v%d", version)}
+	})
+
+	get := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/news", nil))
+		return rec
+	}
+
+	if rec := get(); rec.Header().Get("X-Alloy-Cache") != "MISS" || !strings.Contains(rec.Body.String(), "<p>v1</p>") {
+		t.Fatalf("first request: %s %q", rec.Header().Get("X-Alloy-Cache"), rec.Body.String())
+	}
+	if rec := get(); rec.Header().Get("X-Alloy-Cache") != "HIT" || !strings.Contains(rec.Body.String(), "<p>v1</p>") {
+		t.Fatalf("second request: %s %q", rec.Header().Get("X-Alloy-Cache"), rec.Body.String())
+	}
+
+	key := isrKey(handler.component, httptest.NewRequest(http.MethodGet, "/news", nil))
+	isrCache.Lock()
+	isrCache.entries[key].generatedAt = time.Now().Add(-time.Hour)
+	isrCache.Unlock()
+
+	if rec := get(); rec.Header().Get("X-Alloy-Cache") != "STALE" || !strings.Contains(rec.Body.String(), "<p>v1</p>") {
+		t.Fatalf("stale request: %s %q", rec.Header().Get("X-Alloy-Cache"), rec.Body.String())
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		isrCache.Lock()
+		html := string(isrCache.entries[key].html)
+		isrCache.Unlock()
+		if strings.Contains(html, "<p>v2</p>") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("page not regenerated: %q", html)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	resetISRCache()
+
+	if rec := get(); rec.Header().Get("X-Alloy-Cache") != "HIT" || !strings.Contains(rec.Body.String(), "<p>v2</p>") {
+		t.Fatalf("persisted page not reused after restart: %s %q", rec.Header().Get("X-Alloy-Cache"), rec.Body.String())
+	}
+}
+
+func TestISRCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) {
+		cfg.ISRDir = t.TempDir()
+		cfg.ISRMaxEntries = 2
+	})
+	t.Cleanup(resetISRCache)
+
+	storeISREntry("a", "<p>a</p>")
+	storeISREntry("b", "<p>b</p>")
+	isrCache.Lock()
+	isrCache.order.MoveToFront(isrCache.entries["a"].elem)
+	isrCache.Unlock()
+	storeISREntry("c", "<p>c</p>")
+
+	isrCache.Lock()
+	_, hasA := isrCache.entries["a"]
+	_, hasB := isrCache.entries["b"]
+	isrCache.Unlock()
+	if !hasA || hasB {
+		t.Fatalf("entries after eviction: a=%v b=%v", hasA, hasB)
+	}
+	if _, err := os.Stat(isrFile("b")); !os.IsNotExist(err) {
+		t.Fatalf("evicted page still on disk: %v", err)
+	}
+	if _, err := os.Stat(isrFile("c")); err != nil {
+		t.Fatalf("stored page missing: %v", err)
+	}
+}
+
+func TestStoreISREntryConcurrentWriters(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) { cfg.ISRDir = t.TempDir() })
+	t.Cleanup(resetISRCache)
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Go(func() { storeISREntry("news", fmt.Sprintf("<p>%d</p>", i)) })
+	}
+	wg.Wait()
+
+	html, err := os.ReadFile(isrFile("news"))
+	if err != nil || !strings.HasPrefix(string(html), "<p>") {
+		t.Fatalf("persisted page = %q, %v", html, err)
+	}
+	entries, _ := os.ReadDir(isrDir())
+	if len(entries) != 1 {
+		t.Fatalf("leftover files: %v", entries)
+	}
+}
+
+func TestTrimISRDirKeepsNewestPages(t *testing.T) {
+	dir := t.TempDir()
+	for i, name := range []string{"old.html", "mid.html", "new.html"} {
+		writeTestFile(t, filepath.Join(dir, name), name)
+		mod := time.Now().Add(time.Duration(i-3) * time.Hour)
+		os.Chtimes(filepath.Join(dir, name), mod, mod)
+	}
+	writeTestFile(t, filepath.Join(dir, "new.html.123.tmp"), "partial")
+
+	trimISRDir(dir, 2)
+
+	entries, _ := os.ReadDir(dir)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if strings.Join(names, ",") != "mid.html,new.html" {
+		t.Fatalf("files after trim = %v", names)
+	}
+}
@@ -0,0 +1,211 @@
+package alloy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+const defaultLegacyTarget = "es5"
+
+var legacyTargets = map[string]api.Target{
+	"es5":    api.ES5,
+	"es2015": api.ES2015,
+	"es2016": api.ES2016,
+	"es2017": api.ES2017,
+	"es2018": api.ES2018,
+	"es2019": api.ES2019,
+}
+
+func ParseLegacyTarget(value string) (api.Target, error) {
+	if value == "" {
+		value = defaultLegacyTarget
+	}
+	target, ok := legacyTargets[strings.ToLower(value)]
+	if !ok {
+		return 0, fmt.Errorf("🔴 unknown legacy target %q: want es5 or es2015 to es2019", value)
+	}
+	return target, nil
+}
+
+func legacyPolyfills() []string {
+	if cfg := getConfig(); cfg != nil && cfg.LegacyPolyfills != nil {
+		return cfg.LegacyPolyfills
+	}
+	if _, err := os.Stat(filepath.Join("node_modules", "core-js", "package.json")); err == nil {
+		return []string{"core-js/stable"}
+	}
+	return nil
+}
+
+func generateLegacyEntryCode(componentPath, rootID string, polyfills []string) string {
+	var b strings.Builder
+	for _, polyfill := range polyfills {
+		fmt.Fprintf(&b, "import %s;\n", strconv.Quote(polyfill))
+	}
+	b.WriteString(generateClientEntryCode(componentPath, rootID))
+	return b.String()
+}
+
+func BuildLegacyClientBundles(entries []ClientEntry, outDir string) (map[string]string, error) {
+	return buildLegacyClientBundles(entries, outDir, "")
+}
+
+func BuildLocaleLegacyBundles(entries []ClientEntry, outDir string) (map[string]map[string]string, error) {
+	locales, err := DiscoverLocales(localesDir())
+	if err != nil || len(locales) == 0 {
+		return nil, err
+	}
+	defaultLocale := DefaultLocale(locales)
+	bundles := map[string]map[string]string{}
+	for _, locale := range locales {
+		if locale == defaultLocale {
+			continue
+		}
+		legacy, err := buildLegacyClientBundles(entries, outDir, locale)
+		if err != nil {
+			return nil, fmt.Errorf("🔴 build %s legacy bundles: %w", locale, err)
+		}
+		bundles[locale] = legacy
+	}
+	return bundles, nil
+}
+
+func buildLegacyClientBundles(entries []ClientEntry, outDir string, locale string) (map[string]string, error) {
+	start := time.Now()
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("🔴 entries required")
+	}
+	if outDir == "" {
+		return nil, fmt.Errorf("🔴 out dir required")
+	}
+
+	target := defaultLegacyTarget
+	if cfg := getConfig(); cfg != nil && cfg.LegacyTarget != "" {
+		target = cfg.LegacyTarget
+	}
+	esTarget, err := ParseLegacyTarget(target)
+	if err != nil {
+		return nil, err
+	}
+
+	absOut, err := resolveAbsPath(outDir, "out dir")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(absOut, 0755); err != nil {
+		return nil, fmt.Errorf("🔴 make out dir: %w", err)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "alloy-legacy-")
+	if err != nil {
+		return nil, fmt.Errorf("🔴 make temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	polyfills := legacyPolyfills()
+	entryPoints := make(map[string]ClientEntry, len(entries))
+	for _, e := range entries {
+		if e.Name == "" || e.Component == "" {
+			return nil, fmt.Errorf("🔴 entry name and component required")
+		}
+		rootID := e.RootID
+		if rootID == "" {
+			rootID = defaultRootID(e.Component)
+		}
+
+		absPath, err := resolveAbsPath(e.Component, fmt.Sprintf("entry %s", e.Name))
+		if err != nil {
+			return nil, err
+		}
+
+		entryPath := filepath.Join(tmpDir, e.Name+".tsx")
+		if err := os.WriteFile(entryPath, []byte(generateLegacyEntryCode(absPath, rootID, polyfills)), 0644); err != nil {
+			return nil, fmt.Errorf("🔴 write legacy entry %s: %w", e.Name, err)
+		}
+		entryPoints[e.Name] = ClientEntry{Name: e.Name, Component: entryPath, RootID: rootID}
+	}
+
+	cwd, _ := os.Getwd()
+
+	opts := commonBuildOptions()
+	opts.EntryPointsAdvanced = toEntryPoints(entryPoints)
+	opts.Outdir = absOut
+	opts.Format = api.FormatIIFE
+	opts.Target = esTarget
+	opts.Write = false
+	opts.Metafile = true
+	opts.EntryNames = "legacy-[name]-[hash]"
+	if locales, _ := DiscoverLocales(localesDir()); len(locales) > 0 {
+		if locale == "" {
+			locale = DefaultLocale(locales)
+		}
+		opts.EntryNames = "legacy-[name]." + locale + "-[hash]"
+		opts.Plugins = append([]api.Plugin{messagesPlugin(localesDir(), locales, locale)}, opts.Plugins...)
+	}
+	applyAssetLoaders(&opts, absOut)
+
+	result := api.Build(opts)
+
+	if err := checkBuildErrors(result, "legacy client build error"); err != nil {
+		return nil, legacyTargetError(err, target)
+	}
+
+	if err := scanOutputFiles(result.OutputFiles, getConfig().SecretPatterns); err != nil {
+		return nil, err
+	}
+	if err := writeOutputFiles(result.OutputFiles); err != nil {
+		return nil, err
+	}
+
+	meta, err := parseMetafile(result.Metafile)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := map[string]string{}
+	prefix := distURLPrefix(absOut)
+	for outPath, out := range meta.Outputs {
+		if out.EntryPoint == "" || filepath.Ext(outPath) != ".js" {
+			continue
+		}
+		entryRel, err := outputRel(cwd, absOut, outPath)
+		if err != nil {
+			return nil, fmt.Errorf("🔴 entry rel: %w", err)
+		}
+		name := entryName(out.EntryPoint, entryPoints)
+		if name == "" {
+			base := strings.TrimPrefix(strings.TrimSuffix(filepath.Base(entryRel), ".js"), "legacy-")
+			if i := strings.LastIndex(base, "-"); i > 0 {
+				name = strings.TrimSuffix(base[:i], "."+locale)
+			}
+		}
+		if _, ok := entryPoints[name]; !ok {
+			continue
+		}
+		outputs[name] = filepath.ToSlash(filepath.Join(prefix, entryRel))
+	}
+
+	for name := range entryPoints {
+		if outputs[name] == "" {
+			return nil, fmt.Errorf("🔴 missing legacy bundle for %s", name)
+		}
+	}
+
+	logger().Debug("build", "kind", "legacy", "pages", len(outputs), "target", target, "locale", locale, "duration", time.Since(start))
+	return outputs, nil
+}
+
+func legacyTargetError(err error, target string) error {
+	var buildErr *BuildError
+	if errors.As(err, &buildErr) && strings.Contains(buildErr.Text, "to the configured target environment") {
+		buildErr.Text += fmt.Sprintf("; rewrite it as %s or set a newer --legacy-target such as es2015", target)
+	}
+	return err
+}
@@ -0,0 +1,108 @@
+package alloy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+func TestBuildLegacyClientBundles(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	withTestConfig(t, func(cfg *Config) {
+		cfg.LegacyPolyfills = []string{"legacy-shim"}
+	})
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react", "jsx-runtime.js"), `exports.jsx = (type, props) => ({ type, props });`+"\n")
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react-dom", "client.js"), `exports.hydrateRoot = () => ({ render: function() {} }); exports.createRoot = () => ({ render: function() {} });`+"\n")
+	writeTestFile(t, filepath.Join(dir, "node_modules", "legacy-shim", "index.js"), `window.LEGACY_SHIM = "loaded";`+"\n")
+	component := filepath.Join(dir, "app", "pages", "home.tsx")
+	writeTestFile(t, component, "export default function Home(props: { items?: string[] }) { var items = props.items || []; return items.map((item) => `${item}!`).join(\"\"); }\n")
+
+	bundles, err := BuildLegacyClientBundles([]ClientEntry{{Name: "home", Component: component, RootID: "home-root"}}, filepath.Join(dir, "dist"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(filepath.Base(bundles["home"]), "legacy-home-") {
+		t.Fatalf("bundles = %v", bundles)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "dist", filepath.Base(bundles["home"])))
+	if err != nil {
+		t.Fatal(err)
+	}
+	js := string(data)
+	if es5 := api.Transform(js, api.TransformOptions{Target: api.ES5}); len(es5.Errors) > 0 || strings.Contains(js, "import ") {
+		t.Fatalf("legacy bundle is not ES5: %v\n%s", es5.Errors, js)
+	}
+	if shim, entry := strings.Index(js, "LEGACY_SHIM"), strings.Index(js, "home-root"); shim < 0 || shim > entry {
+		t.Fatalf("polyfill must run before the page entry: %s", js)
+	}
+
+	if _, err := ParseLegacyTarget("es3"); err == nil {
+		t.Fatal("expected unknown target error")
+	}
+
+	writeTestFile(t, component, "export default function Home({ items = [] }: { items?: string[] }) { const out = items.join(\"\"); return out; }\n")
+	_, err = BuildLegacyClientBundles([]ClientEntry{{Name: "home", Component: component, RootID: "home-root"}}, filepath.Join(dir, "dist"))
+	if err == nil || !strings.Contains(err.Error(), "home.tsx") || !strings.Contains(err.Error(), "--legacy-target") {
+		t.Fatalf("expected a lowering error naming the file, got %v", err)
+	}
+}
+
+func TestBuildLocaleLegacyBundles(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	withTestConfig(t, func(cfg *Config) {
+		cfg.LegacyPolyfills = []string{}
+	})
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react", "jsx-runtime.js"), `exports.jsx = (type, props) => ({ type, props });`+"\n")
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react-dom", "client.js"), `exports.hydrateRoot = () => ({ render: function() {} }); exports.createRoot = () => ({ render: function() {} });`+"\n")
+	writeTestFile(t, filepath.Join(dir, "app", "locales", "en.json"), `{"hello": "Hello"}`)
+	writeTestFile(t, filepath.Join(dir, "app", "locales", "fr.json"), `{"hello": "Bonjour"}`)
+	component := filepath.Join(dir, "app", "pages", "home.tsx")
+	writeTestFile(t, component, "import messages from 'alloy:messages';\nexport default function Home() { return messages.hello; }\n")
+	entries := []ClientEntry{{Name: "home", Component: component, RootID: "home-root"}}
+
+	primary, err := BuildLegacyClientBundles(entries, filepath.Join(dir, "dist"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	locales, err := BuildLocaleLegacyBundles(entries, filepath.Join(dir, "dist"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fr := locales["fr"]["home"]
+	if !strings.HasPrefix(filepath.Base(primary["home"]), "legacy-home.en-") || !strings.HasPrefix(filepath.Base(fr), "legacy-home.fr-") || len(locales) != 1 {
+		t.Fatalf("primary = %v, locales = %v", primary, locales)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "dist", filepath.Base(fr)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "Bonjour") || strings.Contains(string(data), "Hello") {
+		t.Fatalf("fr legacy bundle has the wrong messages: %s", data)
+	}
+}
+
+func TestLegacyScriptTag(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) {})
+	result := prebuiltResult("<p>hi</p>", nil, PrebuiltFiles{
+		Client: "dist/build/client-home-AAAAAAAA.js",
+		CSS:    "dist/build/shared.css",
+		Legacy: "dist/build/legacy-home-BBBBBBBB.js",
+	})
+
+	html := result.ToHTML("home-root")
+	module := `<script type="module" src="/dist/build/client-home-AAAAAAAA.js"></script>`
+	legacy := `<script nomodule defer src="/dist/build/legacy-home-BBBBBBBB.js"></script>`
+	if !strings.Contains(html, module) || !strings.Contains(html, legacy) || strings.Index(html, module) > strings.Index(html, legacy) {
+		t.Fatalf("html = %s", html)
+	}
+
+	if html := prebuiltResult("", nil, PrebuiltFiles{Client: "dist/build/client-home-AAAAAAAA.js"}).ToHTML("home-root"); strings.Contains(html, "nomodule") {
+		t.Fatalf("unexpected legacy script: %s", html)
+	}
+}
@@ -0,0 +1,124 @@
+package alloy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type LoaderFunc func(ctx context.Context, r *http.Request) (map[string]any, error)
+
+type loaderError struct {
+	page string
+	err  error
+}
+
+func (e *loaderError) Error() string {
+	return fmt.Sprintf("🔴 loader %s: %v", e.page, e.err)
+}
+
+func (e *loaderError) Unwrap() error {
+	return e.err
+}
+
+func (h *PageHandler) WithLoaderContext(loader LoaderFunc) *PageHandler {
+	h.loader = loader
+	return h
+}
+
+func (h *PageHandler) WithLoaderTimeout(timeout time.Duration, fallback func(r *http.Request) map[string]any) *PageHandler {
+	h.loaderTimeout = timeout
+	h.loaderFallback = fallback
+	return h
+}
+
+func (h *PageHandler) loadProps(r *http.Request) (map[string]any, error) {
+	if props, ok := devProps(r.Context()); ok {
+		pageDebugFrom(r.Context()).record(func(d *pageDebug) {
+			d.props = props
+			d.edited = true
+		})
+		return props, nil
+	}
+	if h.loader == nil {
+		props := map[string]any{}
+		pageDebugFrom(r.Context()).record(func(d *pageDebug) { d.props = props })
+		return props, nil
+	}
+	ctx, span := startSpan(r.Context(), "alloy.loader", attribute.String("alloy.page", pageName(h.component)))
+
+	started := time.Now()
+	load := func(ctx context.Context) (map[string]any, error) {
+		if h.loaderCache != nil {
+			return h.cachedLoad(r.WithContext(ctx), h.loader)
+		}
+		return h.loader(ctx, r.WithContext(ctx))
+	}
+	var props map[string]any
+	var err error
+	if h.loaderTimeout > 0 {
+		props, err = h.loadWithTimeout(ctx, r, load)
+	} else {
+		props, err = load(ctx)
+	}
+	endSpan(span, err)
+	pageDebugFrom(ctx).record(func(d *pageDebug) {
+		d.loader = time.Since(started)
+		d.props = props
+	})
+	if err != nil {
+		err = &loaderError{page: pageName(h.component), err: err}
+		reportRenderError(r, h.component, nil, err)
+		return nil, err
+	}
+	return props, nil
+}
+
+func (h *PageHandler) loadWithTimeout(ctx context.Context, r *http.Request, load func(ctx context.Context) (map[string]any, error)) (map[string]any, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.loaderTimeout)
+	defer cancel()
+
+	type loaded struct {
+		props map[string]any
+		err   error
+	}
+	done := make(chan loaded, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- loaded{err: fmt.Errorf("🔴 loader panic: %v", p)}
+			}
+		}()
+		props, err := load(ctx)
+		done <- loaded{props: props, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		if !errors.Is(res.err, context.DeadlineExceeded) || r.Context().Err() != nil {
+			return res.props, res.err
+		}
+	case <-ctx.Done():
+		if err := r.Context().Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	if h.loaderFallback == nil {
+		return nil, fmt.Errorf("🔴 loader exceeded %s: %w", h.loaderTimeout, context.DeadlineExceeded)
+	}
+	logger().Warn("loader timeout", "page", pageName(h.component), "path", r.URL.Path, "timeout", h.loaderTimeout)
+	props := maps.Clone(h.loaderFallback(r))
+	if props == nil {
+		props = map[string]any{}
+	}
+	if _, ok := props["fallback"]; !ok {
+		props["fallback"] = true
+	}
+	return props, nil
+}
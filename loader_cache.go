@@ -0,0 +1,278 @@
+package alloy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultLoaderCacheEntries = 10000
+	defaultLoaderPushStreams  = 1000
+	loaderPushStreamsPerKey   = 100
+	loaderSweepInterval       = time.Minute
+)
+
+type loaderCache struct {
+	ttl   time.Duration
+	stale time.Duration
+	push  bool
+	key   func(r *http.Request) string
+}
+
+type loaderEntry struct {
+	props      map[string]any
+	expiresAt  time.Time
+	evictAt    time.Time
+	refreshing bool
+}
+
+var loaderStore = struct {
+	sync.Mutex
+	entries     map[string]*loaderEntry
+	subscribers map[string]map[chan map[string]any]bool
+	streams     int
+	swept       time.Time
+}{
+	entries:     map[string]*loaderEntry{},
+	subscribers: map[string]map[chan map[string]any]bool{},
+}
+
+func (h *PageHandler) WithLoaderCache(ttl time.Duration, key func(r *http.Request) string) *PageHandler {
+	h.cacheOptions().ttl = ttl
+	if key != nil {
+		h.loaderCache.key = key
+	}
+	return h
+}
+
+func (h *PageHandler) WithLoaderRevalidate(stale time.Duration) *PageHandler {
+	h.cacheOptions().stale = stale
+	return h
+}
+
+func (h *PageHandler) WithLoaderPush() *PageHandler {
+	h.cacheOptions().push = true
+	return h
+}
+
+func (h *PageHandler) cacheOptions() *loaderCache {
+	if h.loaderCache == nil {
+		h.loaderCache = &loaderCache{key: func(r *http.Request) string { return r.URL.RequestURI() }}
+	}
+	return h.loaderCache
+}
+
+func InvalidateLoaderCache(page string, keys ...string) {
+	loaderStore.Lock()
+	defer loaderStore.Unlock()
+	if len(keys) == 0 {
+		for stored := range loaderStore.entries {
+			if strings.HasPrefix(stored, page+"\x00") {
+				delete(loaderStore.entries, stored)
+			}
+		}
+		return
+	}
+	for _, key := range keys {
+		delete(loaderStore.entries, page+"\x00"+key)
+	}
+}
+
+func (h *PageHandler) loaderCacheKey(r *http.Request) string {
+	return pageName(h.component) + "\x00" + h.loaderCache.key(r)
+}
+
+func (h *PageHandler) cachedLoad(r *http.Request, load LoaderFunc) (map[string]any, error) {
+	key := h.loaderCacheKey(r)
+	now := time.Now()
+
+	loaderStore.Lock()
+	entry := loaderStore.entries[key]
+	result := "miss"
+	switch {
+	case entry == nil:
+	case now.Before(entry.expiresAt):
+		result = "hit"
+	case now.Before(entry.expiresAt.Add(h.loaderCache.stale)):
+		result = "stale"
+		if !entry.refreshing {
+			entry.refreshing = true
+			go h.refreshLoader(key, r.Clone(context.WithoutCancel(r.Context())), load)
+		}
+	default:
+		delete(loaderStore.entries, key)
+		entry = nil
+	}
+	loaderStore.Unlock()
+	metrics.loaderCache.inc(result)
+	if entry != nil {
+		return maps.Clone(entry.props), nil
+	}
+
+	v, err, _ := renderFlights.Do("loader\x00"+key, func() (any, error) {
+		props, err := load(r.Context(), r)
+		if err != nil {
+			return nil, err
+		}
+		h.storeLoader(key, props)
+		return props, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return maps.Clone(v.(map[string]any)), nil
+}
+
+func (h *PageHandler) refreshLoader(key string, r *http.Request, load LoaderFunc) {
+	props, err := load(r.Context(), r)
+	if err != nil {
+		loaderStore.Lock()
+		if entry := loaderStore.entries[key]; entry != nil {
+			entry.refreshing = false
+		}
+		loaderStore.Unlock()
+		logger().Error("revalidate loader", "page", pageName(h.component), "path", r.URL.Path, "err", err)
+		return
+	}
+	h.storeLoader(key, props)
+	if h.loaderCache.push {
+		publishLoader(key, props)
+	}
+	logger().Debug("revalidate loader", "page", pageName(h.component), "path", r.URL.Path)
+}
+
+func (h *PageHandler) storeLoader(key string, props map[string]any) {
+	now := time.Now()
+	expiresAt := now.Add(h.loaderCache.ttl)
+	loaderStore.Lock()
+	defer loaderStore.Unlock()
+	loaderStore.entries[key] = &loaderEntry{props: props, expiresAt: expiresAt, evictAt: expiresAt.Add(h.loaderCache.stale)}
+	if limit := loaderCacheMaxEntries(); len(loaderStore.entries) > limit || now.Sub(loaderStore.swept) > loaderSweepInterval {
+		sweepLoaderStore(now, limit)
+	}
+}
+
+func loaderCacheMaxEntries() int {
+	if cfg := getConfig(); cfg != nil && cfg.LoaderCacheMaxEntries > 0 {
+		return cfg.LoaderCacheMaxEntries
+	}
+	return defaultLoaderCacheEntries
+}
+
+// sweepLoaderStore must be called with loaderStore locked. It drops expired
+// entries, then the ones closest to expiring until the store fits in limit.
+func sweepLoaderStore(now time.Time, limit int) {
+	loaderStore.swept = now
+	for key, entry := range loaderStore.entries {
+		if now.After(entry.evictAt) {
+			delete(loaderStore.entries, key)
+		}
+	}
+	if len(loaderStore.entries) <= limit {
+		return
+	}
+	keys := make([]string, 0, len(loaderStore.entries))
+	for key := range loaderStore.entries {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		return loaderStore.entries[a].evictAt.Compare(loaderStore.entries[b].evictAt)
+	})
+	for _, key := range keys[:len(keys)-limit] {
+		delete(loaderStore.entries, key)
+	}
+}
+
+func publishLoader(key string, props map[string]any) {
+	loaderStore.Lock()
+	defer loaderStore.Unlock()
+	for ch := range loaderStore.subscribers[key] {
+		select {
+		case ch <- props:
+		default:
+		}
+	}
+}
+
+func (h *PageHandler) wantsLoaderPush(r *http.Request) bool {
+	return h.loaderCache != nil && h.loaderCache.push && r.Method == http.MethodGet && strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+func loaderPushMaxStreams() int {
+	if cfg := getConfig(); cfg != nil && cfg.LoaderPushMaxStreams > 0 {
+		return cfg.LoaderPushMaxStreams
+	}
+	return defaultLoaderPushStreams
+}
+
+func subscribeLoader(key string) (chan map[string]any, bool) {
+	loaderStore.Lock()
+	defer loaderStore.Unlock()
+	if loaderStore.streams >= loaderPushMaxStreams() || len(loaderStore.subscribers[key]) >= loaderPushStreamsPerKey {
+		return nil, false
+	}
+	updates := make(chan map[string]any, 1)
+	if loaderStore.subscribers[key] == nil {
+		loaderStore.subscribers[key] = map[chan map[string]any]bool{}
+	}
+	loaderStore.subscribers[key][updates] = true
+	loaderStore.streams++
+	return updates, true
+}
+
+func unsubscribeLoader(key string, updates chan map[string]any) {
+	loaderStore.Lock()
+	defer loaderStore.Unlock()
+	delete(loaderStore.subscribers[key], updates)
+	if len(loaderStore.subscribers[key]) == 0 {
+		delete(loaderStore.subscribers, key)
+	}
+	loaderStore.streams--
+}
+
+func (h *PageHandler) serveLoaderPush(w http.ResponseWriter, r *http.Request) {
+	if cfg := getConfig(); cfg != nil && cfg.integrityErr != nil {
+		http.Error(w, cfg.integrityErr.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	key := h.loaderCacheKey(r)
+	updates, ok := subscribeLoader(key)
+	if !ok {
+		metrics.loaderPush.inc("rejected")
+		logger().Warn("loader push streams full", "page", pageName(h.component), "path", r.URL.Path)
+		w.Header().Set("Retry-After", "30")
+		http.Error(w, "🔴 too many loader push streams", http.StatusServiceUnavailable)
+		return
+	}
+	defer unsubscribeLoader(key, updates)
+	metrics.loaderPush.inc("open")
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	flusher := http.NewResponseController(w)
+	flusher.Flush()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case props := <-updates:
+			data, err := json.Marshal(props)
+			if err != nil {
+				logger().Error("push loader props", "page", pageName(h.component), "err", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: props\ndata: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
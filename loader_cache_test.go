@@ -0,0 +1,154 @@
+package alloy
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoaderCache(t *testing.T) {
+	var calls int
+	handler := NewPage("app/pages/product.tsx").WithLoader(func(r *http.Request) map[string]any {
+		calls++
+		return map[string]any{"id": r.URL.Query().Get("id"), "call": calls}
+	}).WithLoaderCache(time.Hour, func(r *http.Request) string { return r.URL.Query().Get("id") })
+	t.Cleanup(func() { InvalidateLoaderCache("product") })
+
+	load := func(target string) map[string]any {
+		props, _ := handler.loadProps(httptest.NewRequest(http.MethodGet, target, nil))
+		return props
+	}
+
+	first := load("/product?id=1&utm=a")
+	first["theme"] = "dark"
+	if second := load("/product?id=1&utm=b"); calls != 1 || second["call"] != 1 || second["theme"] != nil {
+		t.Fatalf("🔴 expected a cached, unshared copy, got %v after %d calls", second, calls)
+	}
+	if load("/product?id=2"); calls != 2 {
+		t.Fatalf("🔴 expected another key to load, got %d calls", calls)
+	}
+
+	InvalidateLoaderCache("product", "1")
+	if props := load("/product?id=1"); calls != 3 || props["call"] != 3 {
+		t.Fatalf("🔴 expected invalidated key to reload, got %v", props)
+	}
+	if load("/product?id=2"); calls != 3 {
+		t.Fatalf("🔴 expected other keys to stay cached, got %d calls", calls)
+	}
+	InvalidateLoaderCache("product")
+	if load("/product?id=2"); calls != 4 {
+		t.Fatalf("🔴 expected page invalidation to clear every key, got %d calls", calls)
+	}
+
+	handler.WithLoaderCache(time.Millisecond, nil)
+	load("/product?id=3")
+	time.Sleep(5 * time.Millisecond)
+	if load("/product?id=3"); calls != 6 {
+		t.Fatalf("🔴 expected expired entry to reload, got %d calls", calls)
+	}
+}
+
+func TestLoaderCacheRevalidatesAndPushes(t *testing.T) {
+	var calls atomic.Int64
+	handler := NewPage("app/pages/dashboard.tsx").WithLoader(func(r *http.Request) map[string]any {
+		return map[string]any{"call": calls.Add(1)}
+	}).WithLoaderCache(time.Millisecond, nil).WithLoaderRevalidate(time.Hour).WithLoaderPush()
+	t.Cleanup(func() { InvalidateLoaderCache("dashboard") })
+	load := func() map[string]any {
+		props, _ := handler.loadProps(httptest.NewRequest(http.MethodGet, "/dashboard", nil))
+		return props
+	}
+	load()
+
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, srv.URL+"/dashboard", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("🔴 expected an event stream, got %q", res.Header.Get("Content-Type"))
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	if props := load(); props["call"] != int64(1) {
+		t.Fatalf("🔴 expected stale props, got %v", props)
+	}
+
+	lines := bufio.NewScanner(res.Body)
+	var event []string
+	for lines.Scan() && lines.Text() != "" {
+		event = append(event, lines.Text())
+	}
+	if strings.Join(event, "\n") != "event: props\ndata: {\"call\":2}" {
+		t.Fatalf("🔴 unexpected event %q", event)
+	}
+}
+
+func TestLoaderCacheSweepsAndCapsEntries(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) { cfg.LoaderCacheMaxEntries = 3 })
+	handler := NewPage("app/pages/search.tsx").WithLoader(func(r *http.Request) map[string]any {
+		return map[string]any{"q": r.URL.Query().Get("q")}
+	}).WithLoaderCache(time.Hour, nil)
+	t.Cleanup(func() { InvalidateLoaderCache("search") })
+
+	for _, q := range []string{"a", "b", "c", "d", "e"} {
+		handler.loadProps(httptest.NewRequest(http.MethodGet, "/search?q="+q, nil))
+	}
+	loaderStore.Lock()
+	count := len(loaderStore.entries)
+	_, newest := loaderStore.entries[handler.loaderCacheKey(httptest.NewRequest(http.MethodGet, "/search?q=e", nil))]
+	loaderStore.entries["search\x00expired"] = &loaderEntry{evictAt: time.Now().Add(-time.Second)}
+	sweepLoaderStore(time.Now(), 3)
+	_, expired := loaderStore.entries["search\x00expired"]
+	loaderStore.Unlock()
+
+	if count != 3 || !newest {
+		t.Fatalf("🔴 expected the 3 newest entries, got %d (newest kept: %v)", count, newest)
+	}
+	if expired {
+		t.Fatal("🔴 expected expired entry to be swept")
+	}
+}
+
+func TestLoaderPushLimitsStreams(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) { cfg.LoaderPushMaxStreams = 1 })
+	var hooked atomic.Int64
+	handler := NewPage("app/pages/feed.tsx").WithLoader(func(r *http.Request) map[string]any {
+		return map[string]any{}
+	}).WithLoaderCache(time.Hour, nil).WithLoaderPush().WithContext(func(r *http.Request) context.Context {
+		hooked.Add(1)
+		return r.Context()
+	})
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	open := func() *http.Response {
+		req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, srv.URL+"/feed", nil)
+		req.Header.Set("Accept", "text/event-stream")
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { res.Body.Close() })
+		return res
+	}
+
+	if res := open(); res.StatusCode != http.StatusOK {
+		t.Fatalf("🔴 first stream status %d", res.StatusCode)
+	}
+	if res := open(); res.StatusCode != http.StatusServiceUnavailable || res.Header.Get("Retry-After") == "" {
+		t.Fatalf("🔴 expected the second stream to be rejected, got %d", res.StatusCode)
+	}
+	if hooked.Load() != 2 {
+		t.Fatalf("🔴 expected the context hook on every stream, got %d", hooked.Load())
+	}
+}
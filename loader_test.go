@@ -0,0 +1,120 @@
+package alloy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContextLoader(t *testing.T) {
+	var reported error
+	handler := etagTestPage(t, func(cfg *Config) {
+		cfg.OnRenderError = func(ctx context.Context, err PageError) { reported = err.Err }
+	}).(*PageHandler)
+
+	mux := http.NewServeMux()
+	mux.Handle("/etag/{version}", handler.WithLoaderContext(func(ctx context.Context, r *http.Request) (map[string]any, error) {
+		version := RenderContext(ctx)["params"].(map[string]string)["version"]
+		switch version {
+		case "missing":
+			return nil, errors.New("no such version")
+		case "slow":
+			ctx, cancel := context.WithTimeout(ctx, time.Millisecond)
+			defer cancel()
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return map[string]any{"version": version}, nil
+	}))
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/etag/2", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "<p>2</p>") {
+		t.Fatalf("🔴 expected loader props from route context, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/etag/missing", nil))
+	if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), "loader etag: no such version") {
+		t.Fatalf("🔴 expected loader error, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if reported == nil || !strings.Contains(reported.Error(), "no such version") {
+		t.Fatalf("🔴 expected loader error to be reported, got %v", reported)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/etag/slow", nil))
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("🔴 expected loader deadline to answer 504, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestLoaderTimeoutRendersFallback(t *testing.T) {
+	handler := etagTestPage(t, func(cfg *Config) {}).(*PageHandler)
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	handler.WithLoader(func(r *http.Request) map[string]any {
+		<-release
+		return map[string]any{"version": "live"}
+	}).WithLoaderTimeout(10*time.Millisecond, func(r *http.Request) map[string]any {
+		return map[string]any{"version": "cached"}
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/etag", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "<p>cached</p>") || !strings.Contains(rec.Body.String(), `"fallback":true`) {
+		t.Fatalf("🔴 expected fallback props, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	handler.WithLoaderTimeout(10*time.Millisecond, nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/etag", nil))
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("🔴 expected 504 without fallback, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestWithContextReachesLoader(t *testing.T) {
+	type versionKey struct{}
+	deadline := time.Now().Add(time.Minute)
+	handler := etagTestPage(t, func(cfg *Config) {}).(*PageHandler)
+	var cancels []context.CancelFunc
+	t.Cleanup(func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	})
+	handler.WithContext(func(r *http.Request) context.Context {
+		ctx, cancel := context.WithDeadline(context.WithValue(r.Context(), versionKey{}, "ctx"), deadline)
+		cancels = append(cancels, cancel)
+		return ctx
+	}).WithLoaderContext(func(ctx context.Context, r *http.Request) (map[string]any, error) {
+		if got, ok := ctx.Deadline(); !ok || !got.Equal(deadline) {
+			return nil, errors.New("missing deadline")
+		}
+		return map[string]any{"version": ctx.Value(versionKey{})}, nil
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/etag", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "<p>ctx</p>") {
+		t.Fatalf("🔴 expected loader to see the derived context, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	handler.WithContext(func(r *http.Request) context.Context {
+		ctx, cancel := context.WithCancel(r.Context())
+		cancel()
+		return ctx
+	}).WithLoader(func(r *http.Request) map[string]any {
+		return map[string]any{"version": "late"}
+	})
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/etag", nil))
+	if rec.Code == http.StatusOK {
+		t.Fatalf("🔴 expected a cancelled context to stop the render, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
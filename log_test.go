@@ -0,0 +1,70 @@
+package alloy
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPageHandlerLogsRenders(t *testing.T) {
+	resetBundleCache()
+	t.Cleanup(resetBundleCache)
+
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "about-server.js"), `var __Component = { default: function() { return "<p>about</p>"; } };`)
+	writeTestFile(t, filepath.Join(dist, "client-about-AAAAAAAA.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "shared.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{"about": {"server": "about-server.js", "client": "client-about-AAAAAAAA.js", "css": "shared.css"}}`)
+
+	var buf bytes.Buffer
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+		cfg.Logger = slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	})
+
+	handler := AssetsMiddleware()(NewPage(filepath.Join(root, "pages", "about.tsx")))
+	for _, target := range []string{"/about", "/dist/build/shared.css"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d", target, rec.Code)
+		}
+	}
+
+	records := map[string]map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("decode log line %q: %v", line, err)
+		}
+		records[record["msg"].(string)] = record
+	}
+
+	render := records["render"]
+	if render == nil || render["page"] != "about" || render["path"] != "/about" || render["status"] != float64(200) || render["duration"] == nil {
+		t.Fatalf("render record: %v", render)
+	}
+	asset := records["asset"]
+	if asset == nil || asset["path"] != "/dist/build/shared.css" || asset["status"] != float64(200) {
+		t.Fatalf("asset record: %v", asset)
+	}
+}
+
+func TestStatusWriterDefaultsToOK(t *testing.T) {
+	sw := &statusWriter{ResponseWriter: httptest.NewRecorder()}
+	if sw.Status() != http.StatusOK {
+		t.Fatalf("status: %d", sw.Status())
+	}
+	sw.WriteHeader(http.StatusNotFound)
+	sw.WriteHeader(http.StatusOK)
+	if sw.Status() != http.StatusNotFound {
+		t.Fatalf("status: %d", sw.Status())
+	}
+}
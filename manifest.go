@@ -0,0 +1,205 @@
+package alloy
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+const ManifestVersion = 2
+
+type ManifestFile struct {
+	Bytes     int64    `json:"bytes"`
+	Gzip      int64    `json:"gzip"`
+	Integrity string   `json:"integrity"`
+	Imports   []string `json:"imports,omitempty"`
+}
+
+type Manifest struct {
+	Version      int                     `json:"version"`
+	BuiltAt      time.Time               `json:"builtAt"`
+	Commit       string                  `json:"commit,omitempty"`
+	AlloyVersion string                  `json:"alloyVersion,omitempty"`
+	Packed       bool                    `json:"packed,omitempty"`
+	Pages        map[string]ManifestPage `json:"pages"`
+	Files        map[string]ManifestFile `json:"files,omitempty"`
+	ImportMap    map[string]string       `json:"importMap,omitempty"`
+	Signature    string                  `json:"signature,omitempty"`
+}
+
+func ParseManifest(data []byte) (*Manifest, error) {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("🔴 decode manifest: %w", err)
+	}
+
+	var version int
+	if raw, ok := probe["version"]; ok && json.Unmarshal(raw, &version) == nil {
+		if version > ManifestVersion {
+			return nil, fmt.Errorf("🔴 manifest version %d is newer than supported version %d", version, ManifestVersion)
+		}
+		m := &Manifest{}
+		if err := json.Unmarshal(data, m); err != nil {
+			return nil, fmt.Errorf("🔴 decode manifest: %w", err)
+		}
+		if m.Pages == nil {
+			m.Pages = map[string]ManifestPage{}
+		}
+		return m, nil
+	}
+
+	m := &Manifest{Version: 1, Pages: map[string]ManifestPage{}}
+	if err := json.Unmarshal(data, &m.Pages); err != nil {
+		return nil, fmt.Errorf("🔴 decode manifest: %w", err)
+	}
+	return m, nil
+}
+
+func ReadManifest(filesystem fs.FS, dist string) (*Manifest, error) {
+	data, err := fs.ReadFile(filesystem, path.Join(filepath.ToSlash(dist), "manifest.json"))
+	if err != nil {
+		return nil, fmt.Errorf("🔴 read manifest: %w", err)
+	}
+	return ParseManifest(data)
+}
+
+func (m *Manifest) PageFiles(page string) []string {
+	entry, ok := m.Pages[page]
+	if !ok {
+		return nil
+	}
+	var files []string
+	for _, name := range append([]string{entry.Client, entry.CSS}, entry.Chunks...) {
+		if name != "" {
+			files = append(files, name)
+		}
+	}
+	return files
+}
+
+func (m *Manifest) Integrity(name string) string {
+	return m.Files[name].Integrity
+}
+
+func updateManifest(manifestPath string, updates map[string]ManifestPage, removed ...string) error {
+	manifest := &Manifest{Pages: map[string]ManifestPage{}}
+
+	if data, err := os.ReadFile(manifestPath); err == nil {
+		existing, err := ParseManifest(data)
+		if err != nil {
+			return err
+		}
+		manifest = existing
+	}
+
+	for _, name := range removed {
+		delete(manifest.Pages, name)
+	}
+	maps.Copy(manifest.Pages, updates)
+
+	dir := filepath.Dir(manifestPath)
+	manifest.Version = ManifestVersion
+	manifest.BuiltAt = time.Now().UTC()
+	manifest.Commit = gitCommit()
+	manifest.AlloyVersion = alloyVersion()
+	manifest.Files = describeManifestFiles(dir, manifest.Pages, manifest.Files, updates)
+	manifest.ImportMap = configImportMap()
+	manifest.Signature = ""
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("🔴 encode manifest: %w", err)
+	}
+
+	tmp := manifestPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("🔴 write manifest file: %w", err)
+	}
+	if err := os.Rename(tmp, manifestPath); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("🔴 write manifest file: %w", err)
+	}
+
+	return nil
+}
+
+func describeManifestFiles(dir string, pages map[string]ManifestPage, previous map[string]ManifestFile, updates map[string]ManifestPage) map[string]ManifestFile {
+	refreshed := map[string]bool{}
+	for _, entry := range updates {
+		for _, name := range manifestPageNames(entry) {
+			refreshed[name] = true
+		}
+	}
+
+	imports := metafileChunkImports(dir)
+	files := map[string]ManifestFile{}
+	for _, entry := range pages {
+		for _, name := range manifestPageNames(entry) {
+			if _, done := files[name]; done {
+				continue
+			}
+			if info, ok := previous[name]; ok && !refreshed[name] {
+				files[name] = info
+				continue
+			}
+			contents, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+			if err != nil {
+				continue
+			}
+			files[name] = ManifestFile{
+				Bytes:     int64(len(contents)),
+				Gzip:      gzipSize(contents),
+				Integrity: integrity(contents),
+				Imports:   imports[name],
+			}
+		}
+	}
+	if len(files) == 0 {
+		return nil
+	}
+	return files
+}
+
+func manifestPageNames(entry ManifestPage) []string {
+	var names []string
+	for _, name := range append(append([]string{entry.Server, entry.Client, entry.CSS, entry.HTML, entry.Legacy}, append(entry.Chunks, entry.Assets...)...), manifestLocaleNames(entry)...) {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func metafileChunkImports(dir string) map[string][]string {
+	data, err := os.ReadFile(filepath.Join(dir, metafileName))
+	if err != nil {
+		return nil
+	}
+	meta, err := parseMetafile(string(data))
+	if err != nil {
+		return nil
+	}
+
+	imports := map[string][]string{}
+	for outPath, out := range meta.Outputs {
+		for _, imp := range out.Imports {
+			if imp.Kind == "import-statement" && !imp.External {
+				name := filepath.Base(outPath)
+				imports[name] = append(imports[name], filepath.Base(imp.Path))
+			}
+		}
+	}
+	return imports
+}
+
+func integrity(contents []byte) string {
+	sum := sha512.Sum384(contents)
+	return "sha384-" + base64.StdEncoding.EncodeToString(sum[:])
+}
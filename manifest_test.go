@@ -0,0 +1,67 @@
+package alloy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseManifestV1(t *testing.T) {
+	manifest, err := ParseManifest([]byte(`{"home": {"server": "home-server.js", "client": "home-client.js", "css": "shared.css"}}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if manifest.Version != 1 || manifest.Pages["home"].Client != "home-client.js" {
+		t.Fatalf("unexpected manifest: %+v", manifest)
+	}
+
+	if _, err := ParseManifest([]byte(`{"version": 99, "pages": {}}`)); err == nil {
+		t.Fatal("expected error for newer manifest version")
+	}
+}
+
+func TestUpdateManifestWritesFileGraph(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "home-server.js"), "server")
+	writeTestFile(t, filepath.Join(dir, "client-home-AAAA.js"), "import './chunk-BBBB.js';")
+	writeTestFile(t, filepath.Join(dir, "chunk-BBBB.js"), "chunk")
+	writeTestFile(t, filepath.Join(dir, "shared.css"), "body{}")
+	writeTestFile(t, filepath.Join(dir, metafileName), `{
+		"outputs": {
+			"dist/client-home-AAAA.js": {"imports": [{"path": "dist/chunk-BBBB.js", "kind": "import-statement"}]},
+			"dist/chunk-BBBB.js": {}
+		}
+	}`)
+
+	manifestPath := filepath.Join(dir, "manifest.json")
+	err := updateManifest(manifestPath, map[string]ManifestPage{
+		"home": {Server: "home-server.js", Client: "client-home-AAAA.js", CSS: "shared.css", Chunks: []string{"chunk-BBBB.js"}},
+	})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	manifest, err := ReadManifest(os.DirFS(dir), ".")
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if manifest.Version != ManifestVersion || manifest.BuiltAt.IsZero() {
+		t.Fatalf("missing version or timestamp: %+v", manifest)
+	}
+
+	client := manifest.Files["client-home-AAAA.js"]
+	if client.Bytes != int64(len("import './chunk-BBBB.js';")) || client.Gzip == 0 {
+		t.Fatalf("unexpected sizes: %+v", client)
+	}
+	if client.Integrity != integrity([]byte("import './chunk-BBBB.js';")) || manifest.Integrity("shared.css") == "" {
+		t.Fatalf("missing integrity: %+v", manifest.Files)
+	}
+	if len(client.Imports) != 1 || client.Imports[0] != "chunk-BBBB.js" {
+		t.Fatalf("unexpected imports: %v", client.Imports)
+	}
+
+	files := manifest.PageFiles("home")
+	if len(files) != 3 || files[0] != "client-home-AAAA.js" {
+		t.Fatalf("unexpected page files: %v", files)
+	}
+}
@@ -0,0 +1,203 @@
+package alloy
+
+/*
+#include <stdint.h>
+
+typedef struct JSRuntime JSRuntime;
+
+typedef struct {
+	int64_t malloc_size, malloc_limit, memory_used_size;
+	int64_t malloc_count;
+	int64_t memory_used_count;
+	int64_t atom_count, atom_size;
+	int64_t str_count, str_size;
+	int64_t obj_count, obj_size;
+	int64_t prop_count, prop_size;
+	int64_t shape_count, shape_size;
+	int64_t js_func_count, js_func_size, js_func_code_size;
+	int64_t js_func_pc2line_count, js_func_pc2line_size;
+	int64_t c_func_count, array_count;
+	int64_t fast_array_count, fast_array_elements;
+	int64_t binary_object_count, binary_object_size;
+} alloyMemoryUsage;
+
+void JS_ComputeMemoryUsage(JSRuntime *rt, alloyMemoryUsage *s);
+*/
+import "C"
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"runtime/debug"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/buke/quickjs-go"
+)
+
+const (
+	runtimeMemoryInterval = time.Second
+	quickjsModule         = "github.com/buke/quickjs-go"
+)
+
+// alloyMemoryUsage mirrors JSMemoryUsage from the QuickJS build shipped with
+// these quickjs-go releases. Other versions leave memory stats disabled.
+var memoryUsageVersions = map[string]bool{"v0.6.7": true}
+
+type MemoryStats struct {
+	Runtimes      int           `json:"runtimes"`
+	MallocSize    int64         `json:"mallocSize"`
+	MemoryUsed    int64         `json:"memoryUsed"`
+	MaxMallocSize int64         `json:"maxMallocSize"`
+	Objects       int64         `json:"objects"`
+	Properties    int64         `json:"properties"`
+	Strings       int64         `json:"strings"`
+	Atoms         int64         `json:"atoms"`
+	Shapes        int64         `json:"shapes"`
+	Functions     int64         `json:"functions"`
+	Arrays        int64         `json:"arrays"`
+	GCRuns        int64         `json:"gcRuns"`
+	GCFreed       int64         `json:"gcFreed"`
+	GCPause       time.Duration `json:"gcPause"`
+	SampledAt     time.Time     `json:"sampledAt"`
+}
+
+var runtimeMemory = struct {
+	sync.Mutex
+	samples map[*jsRuntime]MemoryStats
+}{
+	samples: map[*jsRuntime]MemoryStats{},
+}
+
+var memoryUsageSupported = sync.OnceValue(func() bool {
+	version := ""
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Path == quickjsModule {
+				version = dep.Version
+				if dep.Replace != nil {
+					version = dep.Replace.Version
+				}
+			}
+		}
+	}
+	field, ok := reflect.TypeFor[quickjs.Runtime]().FieldByName("ref")
+	supported := memoryUsageVersions[version] && ok && field.Type.Kind() == reflect.Pointer && field.Type.Elem().Name() == "_Ctype_struct_JSRuntime"
+	if !supported {
+		logger().Warn("runtime memory stats disabled", "quickjs", version)
+	}
+	return supported
+})
+
+func runtimeRef(rt *quickjs.Runtime) *C.JSRuntime {
+	if !memoryUsageSupported() {
+		return nil
+	}
+	return (*C.JSRuntime)(unsafe.Pointer(reflect.ValueOf(rt).Elem().FieldByName("ref").Pointer()))
+}
+
+func computeMemoryUsage(ref *C.JSRuntime) MemoryStats {
+	var usage C.alloyMemoryUsage
+	C.JS_ComputeMemoryUsage(ref, &usage)
+	return MemoryStats{
+		Runtimes:      1,
+		MallocSize:    int64(usage.malloc_size),
+		MemoryUsed:    int64(usage.memory_used_size),
+		MaxMallocSize: int64(usage.malloc_size),
+		Objects:       int64(usage.obj_count),
+		Properties:    int64(usage.prop_count),
+		Strings:       int64(usage.str_count),
+		Atoms:         int64(usage.atom_count),
+		Shapes:        int64(usage.shape_count),
+		Functions:     int64(usage.js_func_count + usage.c_func_count),
+		Arrays:        int64(usage.array_count),
+		SampledAt:     time.Now().UTC(),
+	}
+}
+
+func sampleRuntimeMemory(vm *jsRuntime) {
+	runtimeMemory.Lock()
+	last, ok := runtimeMemory.samples[vm]
+	runtimeMemory.Unlock()
+	if ok && time.Since(last.SampledAt) < runtimeMemoryInterval {
+		return
+	}
+
+	ref := runtimeRef(vm.rt)
+	if ref == nil {
+		return
+	}
+	before := computeMemoryUsage(ref)
+	started := time.Now()
+	vm.rt.RunGC()
+	sample := computeMemoryUsage(ref)
+	sample.GCRuns = last.GCRuns + 1
+	sample.GCFreed = last.GCFreed + max(before.MallocSize-sample.MallocSize, 0)
+	sample.GCPause = last.GCPause + time.Since(started)
+	runtimeMemory.Lock()
+	runtimeMemory.samples[vm] = sample
+	runtimeMemory.Unlock()
+}
+
+func forgetRuntimeMemory(vm *jsRuntime) {
+	runtimeMemory.Lock()
+	delete(runtimeMemory.samples, vm)
+	runtimeMemory.Unlock()
+}
+
+func currentMemoryStats() MemoryStats {
+	runtimeMemory.Lock()
+	defer runtimeMemory.Unlock()
+	var total MemoryStats
+	for _, s := range runtimeMemory.samples {
+		total.Runtimes++
+		total.MallocSize += s.MallocSize
+		total.MemoryUsed += s.MemoryUsed
+		total.MaxMallocSize = max(total.MaxMallocSize, s.MallocSize)
+		total.Objects += s.Objects
+		total.Properties += s.Properties
+		total.Strings += s.Strings
+		total.Atoms += s.Atoms
+		total.Shapes += s.Shapes
+		total.Functions += s.Functions
+		total.Arrays += s.Arrays
+		total.GCRuns += s.GCRuns
+		total.GCFreed += s.GCFreed
+		total.GCPause += s.GCPause
+		if s.SampledAt.After(total.SampledAt) {
+			total.SampledAt = s.SampledAt
+		}
+	}
+	return total
+}
+
+func writeMemoryMetrics(w io.Writer) {
+	s := currentMemoryStats()
+	fmt.Fprintf(w, "# HELP alloy_runtime_memory_bytes Memory allocated by pooled QuickJS runtimes.\n# TYPE alloy_runtime_memory_bytes gauge\n")
+	fmt.Fprintf(w, "alloy_runtime_memory_bytes{kind=\"malloc\"} %d\n", s.MallocSize)
+	fmt.Fprintf(w, "alloy_runtime_memory_bytes{kind=\"used\"} %d\n", s.MemoryUsed)
+	fmt.Fprintf(w, "alloy_runtime_memory_bytes{kind=\"max_runtime\"} %d\n", s.MaxMallocSize)
+	fmt.Fprintf(w, "# HELP alloy_runtime_objects Live values in pooled QuickJS runtimes.\n# TYPE alloy_runtime_objects gauge\n")
+	for _, kind := range []struct {
+		name  string
+		count int64
+	}{
+		{"array", s.Arrays},
+		{"atom", s.Atoms},
+		{"function", s.Functions},
+		{"object", s.Objects},
+		{"property", s.Properties},
+		{"shape", s.Shapes},
+		{"string", s.Strings},
+	} {
+		fmt.Fprintf(w, "alloy_runtime_objects{kind=%q} %d\n", kind.name, kind.count)
+	}
+	fmt.Fprintf(w, "# HELP alloy_runtime_gc_runs_total Garbage collections run on pooled QuickJS runtimes.\n# TYPE alloy_runtime_gc_runs_total counter\n")
+	fmt.Fprintf(w, "alloy_runtime_gc_runs_total %d\n", s.GCRuns)
+	fmt.Fprintf(w, "# HELP alloy_runtime_gc_freed_bytes_total Bytes released by QuickJS garbage collection.\n# TYPE alloy_runtime_gc_freed_bytes_total counter\n")
+	fmt.Fprintf(w, "alloy_runtime_gc_freed_bytes_total %d\n", s.GCFreed)
+	fmt.Fprintf(w, "# HELP alloy_runtime_gc_pause_seconds_total Time spent in QuickJS garbage collection.\n# TYPE alloy_runtime_gc_pause_seconds_total counter\n")
+	fmt.Fprintf(w, "alloy_runtime_gc_pause_seconds_total %g\n", s.GCPause.Seconds())
+}
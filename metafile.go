@@ -0,0 +1,119 @@
+package alloy
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type metafileImport struct {
+	Path     string `json:"path"`
+	Kind     string `json:"kind"`
+	External bool   `json:"external,omitempty"`
+}
+
+type metafileInput struct {
+	Bytes   int64            `json:"bytes"`
+	Imports []metafileImport `json:"imports"`
+}
+
+type metafileOutput struct {
+	Bytes      int64            `json:"bytes"`
+	EntryPoint string           `json:"entryPoint"`
+	CSSBundle  string           `json:"cssBundle"`
+	Imports    []metafileImport `json:"imports"`
+	Inputs     map[string]struct {
+		BytesInOutput int64 `json:"bytesInOutput"`
+	} `json:"inputs"`
+}
+
+type metafile struct {
+	Inputs  map[string]metafileInput  `json:"inputs"`
+	Outputs map[string]metafileOutput `json:"outputs"`
+}
+
+func parseMetafile(data string) (*metafile, error) {
+	var mf metafile
+	if err := json.Unmarshal([]byte(data), &mf); err != nil {
+		return nil, fmt.Errorf("🔴 parse metafile: %w", err)
+	}
+	return &mf, nil
+}
+
+func (m *metafile) fileImports(outPath string) []string {
+	seen := map[string]bool{}
+	var files []string
+
+	var walk func(string)
+	walk = func(p string) {
+		if seen[p] {
+			return
+		}
+		seen[p] = true
+		for _, imp := range m.Outputs[p].Imports {
+			switch imp.Kind {
+			case "file-loader":
+				if !seen[imp.Path] {
+					seen[imp.Path] = true
+					files = append(files, imp.Path)
+				}
+			case "import-statement":
+				walk(imp.Path)
+			}
+		}
+	}
+	walk(outPath)
+
+	sort.Strings(files)
+	return files
+}
+
+func (m *metafile) sourceInputs(outPath, cwd string) []string {
+	seen := map[string]bool{}
+	inputs := map[string]bool{}
+
+	var walk func(string)
+	walk = func(p string) {
+		if seen[p] {
+			return
+		}
+		seen[p] = true
+		out := m.Outputs[p]
+		for input := range out.Inputs {
+			inputs[input] = true
+		}
+		for _, imp := range out.Imports {
+			if imp.Kind == "import-statement" {
+				walk(imp.Path)
+			}
+		}
+	}
+	walk(outPath)
+
+	var files []string
+	for input := range inputs {
+		if strings.Contains(input, ":") || strings.Contains(input, "node_modules/") {
+			continue
+		}
+		if !filepath.IsAbs(input) {
+			input = filepath.Join(cwd, input)
+		}
+		files = append(files, input)
+	}
+	sort.Strings(files)
+	return files
+}
+
+func outputRel(cwd, absOut, outPath string) (string, error) {
+	abs := outPath
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(cwd, abs)
+	}
+	rel, err := filepath.Rel(absOut, abs)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
@@ -0,0 +1,207 @@
+package alloy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var renderDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+type counterVec struct {
+	name   string
+	help   string
+	labels []string
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+func newCounterVec(name string, help string, labels ...string) *counterVec {
+	return &counterVec{name: name, help: help, labels: labels, values: map[string]float64{}}
+}
+
+func (c *counterVec) add(delta float64, values ...string) {
+	key := labelKey(c.labels, values)
+	c.mu.Lock()
+	c.values[key] += delta
+	c.mu.Unlock()
+}
+
+func (c *counterVec) inc(values ...string) {
+	c.add(1, values...)
+}
+
+func (c *counterVec) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	for _, key := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, key, formatMetric(c.values[key]))
+	}
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+type histogramVec struct {
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*histogram
+}
+
+func newHistogramVec(name string, help string, buckets []float64, labels ...string) *histogramVec {
+	return &histogramVec{name: name, help: help, labels: labels, buckets: buckets, series: map[string]*histogram{}}
+}
+
+func (h *histogramVec) observe(value float64, values ...string) {
+	key := labelKey(h.labels, values)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	series := h.series[key]
+	if series == nil {
+		series = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.series[key] = series
+	}
+	for i, bound := range h.buckets {
+		if value <= bound {
+			series.counts[i]++
+		}
+	}
+	series.count++
+	series.sum += value
+}
+
+func (h *histogramVec) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	for _, key := range sortedKeys(h.series) {
+		series := h.series[key]
+		for i, bound := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, withLabel(key, "le", formatMetric(bound)), series.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, withLabel(key, "le", "+Inf"), series.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, key, formatMetric(series.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, key, series.count)
+	}
+}
+
+func labelKey(names []string, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		pairs[i] = fmt.Sprintf(`%s="%s"`, name, labelEscaper.Replace(value))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func withLabel(key string, name string, value string) string {
+	pair := fmt.Sprintf(`%s="%s"`, name, value)
+	if key == "" {
+		return "{" + pair + "}"
+	}
+	return strings.TrimSuffix(key, "}") + "," + pair + "}"
+}
+
+func formatMetric(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var metrics = struct {
+	renders             *counterVec
+	renderDuration      *histogramVec
+	ssrErrors           *counterVec
+	bundleCache         *counterVec
+	isrCache            *counterVec
+	loaderCache         *counterVec
+	loaderPush          *counterVec
+	assetRequests       *counterVec
+	assetBytes          *counterVec
+	runtimeRecycles     *counterVec
+	renderQueueWait     *histogramVec
+	renderQueueTimeouts *counterVec
+	propsBytes          *histogramVec
+	coalescedRenders    *counterVec
+}{
+	renders:             newCounterVec("alloy_renders_total", "Page requests served by alloy.", "page", "status"),
+	renderDuration:      newHistogramVec("alloy_render_duration_seconds", "Time to serve a page request.", renderDurationBuckets, "page"),
+	ssrErrors:           newCounterVec("alloy_ssr_errors_total", "Server renders that failed in QuickJS.", "page"),
+	bundleCache:         newCounterVec("alloy_bundle_cache_lookups_total", "Bundle cache lookups by result.", "result"),
+	isrCache:            newCounterVec("alloy_isr_cache_total", "Revalidated page lookups by result.", "result"),
+	loaderCache:         newCounterVec("alloy_loader_cache_total", "Cached loader lookups by result.", "result"),
+	loaderPush:          newCounterVec("alloy_loader_push_streams_total", "Loader push streams by result.", "result"),
+	assetRequests:       newCounterVec("alloy_asset_requests_total", "Static assets served.", "status"),
+	assetBytes:          newCounterVec("alloy_asset_bytes_total", "Bytes of static assets served."),
+	runtimeRecycles:     newCounterVec("alloy_runtime_recycles_total", "Pooled QuickJS runtimes retired by reason.", "reason"),
+	renderQueueWait:     newHistogramVec("alloy_render_queue_wait_seconds", "Time renders waited for a free slot.", renderDurationBuckets),
+	renderQueueTimeouts: newCounterVec("alloy_render_queue_timeouts_total", "Renders rejected after waiting for a free slot."),
+	propsBytes:          newHistogramVec("alloy_props_bytes", "Serialized props size per render.", propsSizeBuckets, "page"),
+	coalescedRenders:    newCounterVec("alloy_coalesced_renders_total", "Requests that shared a concurrent identical render.", "page"),
+}
+
+func MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WriteMetrics(w)
+	})
+}
+
+func WriteMetrics(w io.Writer) {
+	metrics.renders.write(w)
+	metrics.renderDuration.write(w)
+	metrics.ssrErrors.write(w)
+	metrics.bundleCache.write(w)
+	metrics.isrCache.write(w)
+	metrics.loaderCache.write(w)
+	metrics.loaderPush.write(w)
+	metrics.assetRequests.write(w)
+	metrics.assetBytes.write(w)
+	metrics.runtimeRecycles.write(w)
+	metrics.renderQueueWait.write(w)
+	metrics.renderQueueTimeouts.write(w)
+	metrics.propsBytes.write(w)
+	metrics.coalescedRenders.write(w)
+	writeMemoryMetrics(w)
+}
+
+func recordRender(component string, status int, elapsed time.Duration) {
+	page := pageName(component)
+	metrics.renders.inc(page, strconv.Itoa(status))
+	metrics.renderDuration.observe(elapsed.Seconds(), page)
+	recordPageStats(page, func(c *pageCounters) {
+		c.renders++
+		if status >= http.StatusInternalServerError {
+			c.errors++
+		}
+		c.total += elapsed
+		c.max = max(c.max, elapsed)
+	})
+}
+
+func cacheResult(hit bool) string {
+	if hit {
+		return "hit"
+	}
+	return "miss"
+}
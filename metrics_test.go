@@ -0,0 +1,81 @@
+package alloy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHistogramVecWrite(t *testing.T) {
+	h := newHistogramVec("test_seconds", "Test.", []float64{0.1, 1}, "page")
+	h.observe(0.05, "home")
+	h.observe(0.5, "home")
+	h.observe(2, "home")
+
+	var b strings.Builder
+	h.write(&b)
+	for _, want := range []string{
+		"# TYPE test_seconds histogram",
+		`test_seconds_bucket{page="home",le="0.1"} 1`,
+		`test_seconds_bucket{page="home",le="1"} 2`,
+		`test_seconds_bucket{page="home",le="+Inf"} 3`,
+		`test_seconds_sum{page="home"} 2.55`,
+		`test_seconds_count{page="home"} 3`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Fatalf("missing %q in:\n%s", want, b.String())
+		}
+	}
+}
+
+func TestCounterVecEscapesLabels(t *testing.T) {
+	c := newCounterVec("test_total", "Test.", "page")
+	c.inc(`a"b`)
+	c.add(2, `a"b`)
+
+	var b strings.Builder
+	c.write(&b)
+	if !strings.Contains(b.String(), `test_total{page="a\"b"} 3`) {
+		t.Fatalf("counter output:\n%s", b.String())
+	}
+}
+
+func TestMetricsHandlerReportsRendersAndAssets(t *testing.T) {
+	resetBundleCache()
+	t.Cleanup(resetBundleCache)
+
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "metrics-server.js"), `var __Component = { default: function() { return "<p>metrics</p>"; } };`)
+	writeTestFile(t, filepath.Join(dist, "client-metrics-AAAAAAAA.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "shared.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{"metrics": {"server": "metrics-server.js", "client": "client-metrics-AAAAAAAA.js", "css": "shared.css"}}`)
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+	})
+
+	handler := AssetsMiddleware()(NewPage(filepath.Join(root, "pages", "metrics.tsx")))
+	for _, target := range []string{"/metrics-page", "/dist/build/shared.css"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	MetricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Fatalf("content type: %s", rec.Header().Get("Content-Type"))
+	}
+	for _, want := range []string{
+		`alloy_renders_total{page="metrics",status="200"}`,
+		`alloy_render_duration_seconds_count{page="metrics"}`,
+		`alloy_bundle_cache_lookups_total{result="hit"}`,
+		`alloy_asset_requests_total{status="200"}`,
+		"alloy_asset_bytes_total ",
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Fatalf("metrics missing %s:\n%s", want, rec.Body.String())
+		}
+	}
+}
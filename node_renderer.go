@@ -0,0 +1,213 @@
+package alloy
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"sync"
+)
+
+type NodeRenderer struct {
+	Command string
+	Args    []string
+	Dir     string
+	Size    int
+
+	once   sync.Once
+	pool   chan *nodeProcess
+	mu     sync.Mutex
+	procs  map[*nodeProcess]bool
+	closed bool
+}
+
+type nodeProcess struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	loaded map[string]bool
+	nextID int
+}
+
+type nodeResponse struct {
+	ID int `json:"id"`
+	RenderResponse
+}
+
+func NewNodeRenderer(size int) *NodeRenderer {
+	return &NodeRenderer{Size: size}
+}
+
+func (n *NodeRenderer) init() {
+	n.once.Do(func() {
+		if n.Command == "" {
+			n.Command = "node"
+		}
+		if n.Size <= 0 {
+			n.Size = runtime.NumCPU()
+		}
+		n.pool = make(chan *nodeProcess, n.Size)
+		n.procs = map[*nodeProcess]bool{}
+		for range n.Size {
+			n.pool <- nil
+		}
+	})
+}
+
+func (n *NodeRenderer) Render(ctx context.Context, req RenderRequest) (RenderResponse, error) {
+	n.init()
+
+	var proc *nodeProcess
+	select {
+	case proc = <-n.pool:
+	case <-ctx.Done():
+		return RenderResponse{}, fmt.Errorf("🔴 wait for node renderer: %w", ctx.Err())
+	}
+
+	if proc == nil {
+		started, err := n.start()
+		if err != nil {
+			n.pool <- nil
+			return RenderResponse{}, err
+		}
+		proc = started
+	}
+
+	out, err := proc.render(ctx, req)
+	if err != nil && !isRenderError(err) {
+		n.discard(proc)
+		n.pool <- nil
+		return RenderResponse{}, err
+	}
+	n.pool <- proc
+	return out, err
+}
+
+func (n *NodeRenderer) Close() error {
+	n.init()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.closed = true
+	for proc := range n.procs {
+		proc.stop()
+	}
+	n.procs = map[*nodeProcess]bool{}
+	return nil
+}
+
+func (n *NodeRenderer) start() (*nodeProcess, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.closed {
+		return nil, fmt.Errorf("🔴 node renderer closed")
+	}
+
+	args := append(append([]string{}, n.Args...), "-e", MustReadAsset("assets/node-renderer.js"))
+	cmd := exec.Command(n.Command, args...)
+	cmd.Dir = n.Dir
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("🔴 node stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("🔴 node stdout: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("🔴 start %s: %w", n.Command, err)
+	}
+
+	proc := &nodeProcess{cmd: cmd, stdin: stdin, stdout: bufio.NewReader(stdout), loaded: map[string]bool{}}
+	n.procs[proc] = true
+	return proc, nil
+}
+
+func (n *NodeRenderer) discard(proc *nodeProcess) {
+	n.mu.Lock()
+	delete(n.procs, proc)
+	n.mu.Unlock()
+	proc.stop()
+}
+
+type renderError struct {
+	message string
+}
+
+func (e *renderError) Error() string {
+	return "🔴 render component: " + e.message
+}
+
+func isRenderError(err error) bool {
+	_, ok := err.(*renderError)
+	return ok
+}
+
+func (p *nodeProcess) render(ctx context.Context, req RenderRequest) (RenderResponse, error) {
+	p.nextID++
+	message := struct {
+		ID int `json:"id"`
+		RenderRequest
+	}{ID: p.nextID, RenderRequest: req}
+	if p.loaded[req.Bundle] {
+		message.Code = ""
+	}
+
+	line, err := json.Marshal(message)
+	if err != nil {
+		return RenderResponse{}, fmt.Errorf("🔴 marshal render request: %w", err)
+	}
+
+	done := make(chan error, 1)
+	var res nodeResponse
+	go func() {
+		if _, err := p.stdin.Write(append(line, '\n')); err != nil {
+			done <- fmt.Errorf("🔴 write to node: %w", err)
+			return
+		}
+		for {
+			reply, err := p.stdout.ReadBytes('\n')
+			if err != nil {
+				done <- fmt.Errorf("🔴 read from node: %w", err)
+				return
+			}
+			if err := json.Unmarshal(reply, &res); err != nil {
+				done <- fmt.Errorf("🔴 decode node response: %w", err)
+				return
+			}
+			if res.ID == message.ID {
+				done <- nil
+				return
+			}
+		}
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return RenderResponse{}, err
+		}
+	case <-ctx.Done():
+		return RenderResponse{}, fmt.Errorf("🔴 %w: %w", ErrRenderTimeout, ctx.Err())
+	}
+
+	if req.Code != "" {
+		p.loaded[req.Bundle] = true
+	}
+	if res.Error != "" {
+		return RenderResponse{}, &renderError{message: res.Error}
+	}
+	return res.RenderResponse, nil
+}
+
+func (p *nodeProcess) stop() {
+	p.stdin.Close()
+	if p.cmd.Process != nil {
+		p.cmd.Process.Kill()
+	}
+	p.cmd.Wait()
+}
@@ -0,0 +1,119 @@
+package alloy
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const nodeTestBundle = `var __Component = { default: function(props) {
+	console.log("noise on stdout is redirected");
+	return "<p>" + props.name + " " + require("crypto").createHash("sha1").update("x").digest("hex").slice(0, 6) + "</p>";
+} };`
+
+func requireNode(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Skip("node not installed")
+	}
+}
+
+func TestNodeRendererRendersWithNodeAPIs(t *testing.T) {
+	requireNode(t)
+	renderer := NewNodeRenderer(2)
+	t.Cleanup(func() { renderer.Close() })
+
+	for range 3 {
+		out, err := renderer.Render(context.Background(), RenderRequest{Bundle: bundleID(nodeTestBundle), Code: nodeTestBundle, Props: map[string]any{"name": "Ada"}})
+		if err != nil {
+			t.Fatalf("render: %v", err)
+		}
+		if out.HTML != "<p>Ada 11f6ad</p>" {
+			t.Fatalf("🔴 unexpected html: %q", out.HTML)
+		}
+	}
+}
+
+func TestNodeRendererReportsComponentErrors(t *testing.T) {
+	requireNode(t)
+	renderer := NewNodeRenderer(1)
+	t.Cleanup(func() { renderer.Close() })
+
+	code := `var __Component = function() { throw new Error("boom"); };`
+	_, err := renderer.Render(context.Background(), RenderRequest{Bundle: bundleID(code), Code: code})
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("🔴 expected component error, got %v", err)
+	}
+
+	out, err := renderer.Render(context.Background(), RenderRequest{Bundle: bundleID(nodeTestBundle), Code: nodeTestBundle, Props: map[string]any{"name": "Grace"}})
+	if err != nil || !strings.Contains(out.HTML, "Grace") {
+		t.Fatalf("🔴 process should survive component errors: %q %v", out.HTML, err)
+	}
+}
+
+func TestNodeRendererReplacesProcessOnTimeout(t *testing.T) {
+	requireNode(t)
+	renderer := NewNodeRenderer(1)
+	t.Cleanup(func() { renderer.Close() })
+
+	code := `var __Component = function() { return new Promise(function() {}); };`
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if _, err := renderer.Render(ctx, RenderRequest{Bundle: bundleID(code), Code: code}); err == nil {
+		t.Fatal("🔴 expected timeout")
+	}
+
+	out, err := renderer.Render(context.Background(), RenderRequest{Bundle: bundleID(nodeTestBundle), Code: nodeTestBundle, Props: map[string]any{"name": "Lin"}})
+	if err != nil || !strings.Contains(out.HTML, "Lin") {
+		t.Fatalf("🔴 expected fresh process after timeout: %q %v", out.HTML, err)
+	}
+}
+
+func TestExecuteSSRUsesConfiguredRenderer(t *testing.T) {
+	requireNode(t)
+	renderer := NewNodeRenderer(1)
+	t.Cleanup(func() { renderer.Close() })
+	withTestConfig(t, func(cfg *Config) { cfg.Renderer = renderer })
+
+	out, err := executeSSR(context.Background(), nodeTestBundle, map[string]any{"name": "Node"})
+	if err != nil {
+		t.Fatalf("executeSSR: %v", err)
+	}
+	if !strings.Contains(out.HTML, "<p>Node ") {
+		t.Fatalf("🔴 expected node output, got %q", out.HTML)
+	}
+}
+
+func TestNodeRendererBuildsNodeBuiltins(t *testing.T) {
+	requireNode(t)
+	dir := t.TempDir()
+	t.Chdir(dir)
+	renderer := NewNodeRenderer(1)
+	t.Cleanup(func() { renderer.Close() })
+	withTestConfig(t, func(cfg *Config) { cfg.Renderer = renderer })
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react", "jsx-runtime.js"), `exports.jsx = (type, props) => ({ type, props });`+"\n")
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react-dom", "server.edge.js"), `exports.renderToString = (el) => el.type(el.props);`+"\n")
+	component := filepath.Join(dir, "app", "pages", "hash.tsx")
+	writeTestFile(t, component, `import { createHash } from "node:crypto";
+import { basename } from "path";
+
+export default function Hash(props: { name: string }) {
+  return basename("/tmp/" + props.name) + " " + createHash("sha1").update("x").digest("hex").slice(0, 6);
+}
+`)
+
+	serverJS, _, err := BuildServerBundle(component)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	out, err := executeSSR(context.Background(), serverJS, map[string]any{"name": "ada"})
+	if err != nil {
+		t.Fatalf("executeSSR: %v", err)
+	}
+	if out.HTML != "ada 11f6ad" {
+		t.Fatalf("🔴 unexpected html: %q", out.HTML)
+	}
+}
@@ -0,0 +1,88 @@
+package alloy
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"strings"
+
+	"github.com/buke/quickjs-go"
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+const nodeShimsNamespace = "alloy-node"
+
+var nodeShimDigests = map[string]func() hash.Hash{
+	"SHA-1":   sha1.New,
+	"SHA-256": sha256.New,
+	"SHA-384": sha512.New384,
+	"SHA-512": sha512.New,
+}
+
+func nodeShimsEnabled() bool {
+	cfg := getConfig()
+	return cfg != nil && cfg.NodeShims
+}
+
+func nodeShimsPlugin() api.Plugin {
+	return api.Plugin{
+		Name: "alloy-node",
+		Setup: func(build api.PluginBuild) {
+			build.OnResolve(api.OnResolveOptions{Filter: `^(node:)?(buffer|util|process|crypto)$`}, func(args api.OnResolveArgs) (api.OnResolveResult, error) {
+				return api.OnResolveResult{Path: strings.TrimPrefix(args.Path, "node:"), Namespace: nodeShimsNamespace}, nil
+			})
+
+			build.OnLoad(api.OnLoadOptions{Filter: `.*`, Namespace: nodeShimsNamespace}, func(args api.OnLoadArgs) (api.OnLoadResult, error) {
+				contents := fmt.Sprintf("module.exports = __alloyNode.%s;", args.Path)
+				return api.OnLoadResult{Contents: &contents, Loader: api.LoaderJS}, nil
+			})
+		},
+	}
+}
+
+func loadNodeShims(js *quickjs.Context) error {
+	if !nodeShimsEnabled() {
+		return nil
+	}
+
+	js.Globals().Set("__alloyRandomBytes", js.NewFunction(func(js *quickjs.Context, this *quickjs.Value, args []*quickjs.Value) *quickjs.Value {
+		if len(args) < 1 {
+			return js.ThrowError(fmt.Errorf("🔴 random byte count required"))
+		}
+		size := args[0].ToInt32()
+		if size < 0 || size > 65536 {
+			return js.ThrowRangeError("random byte count %d out of range", size)
+		}
+		buf := make([]byte, size)
+		rand.Read(buf)
+		return js.NewArrayBuffer(buf)
+	}))
+	js.Globals().Set("__alloyDigest", js.NewFunction(func(js *quickjs.Context, this *quickjs.Value, args []*quickjs.Value) *quickjs.Value {
+		if len(args) < 2 {
+			return js.ThrowError(fmt.Errorf("🔴 digest algorithm and data required"))
+		}
+		newHash, ok := nodeShimDigests[args[0].String()]
+		if !ok {
+			return js.ThrowError(fmt.Errorf("🔴 unsupported digest algorithm %s", args[0].String()))
+		}
+		data, err := args[1].ToUint8Array()
+		if err != nil {
+			return js.ThrowError(fmt.Errorf("🔴 digest data: %w", err))
+		}
+		h := newHash()
+		h.Write(data)
+		return js.NewArrayBuffer(h.Sum(nil))
+	}))
+
+	env, _ := json.Marshal(PublicEnv())
+	result := js.Eval(fmt.Sprintf("var __alloyNodeEnv = %s;\n%s", env, nodeShimsSource))
+	if result.IsException() {
+		return fmt.Errorf("🔴 node shims: %s", js.Exception().Error())
+	}
+	result.Free()
+	return nil
+}
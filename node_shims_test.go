@@ -0,0 +1,48 @@
+package alloy
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestNodeShimsInServerBundle(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("ALLOY_PUBLIC_REGION", "eu")
+	withTestConfig(t, func(cfg *Config) {
+		cfg.NodeShims = true
+	})
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react", "jsx-runtime.js"), `exports.jsx = (type, props) => ({ type, props });`+"\n")
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react-dom", "server.edge.js"), `exports.renderToString = (el) => el.type(el.props);`+"\n")
+	component := filepath.Join(dir, "app", "pages", "shims.tsx")
+	writeTestFile(t, component, `import { Buffer } from "node:buffer";
+import { format } from "util";
+import process from "process";
+
+export default async function Shims() {
+  const digest = await crypto.subtle.digest("SHA-256", new TextEncoder().encode("abc"));
+  return [
+    Buffer.from("héllo").toString("base64"),
+    Buffer.from("aGk=", "base64").toString(),
+    format("%s=%d", "n", 42),
+    process.env.ALLOY_PUBLIC_REGION,
+    Buffer.from(digest).toString("hex").slice(0, 16),
+    crypto.randomUUID().length,
+  ].join(" ");
+}
+`)
+
+	serverJS, _, err := BuildServerBundle(component)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := executeSSR(context.Background(), serverJS, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "aMOpbGxv hi n=42 eu ba7816bf8f01cfea 36"; out.HTML != want {
+		t.Fatalf("html = %q, want %q", out.HTML, want)
+	}
+
+}
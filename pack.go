@@ -0,0 +1,143 @@
+package alloy
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const packedExt = ".gz"
+
+var unpackedFiles = map[string]bool{"manifest.json": true, metafileName: true, publicManifestName: true}
+
+func PackDist(distDir string) error {
+	manifestPath := filepath.Join(distDir, "manifest.json")
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return fmt.Errorf("🔴 read manifest: %w", err)
+	}
+	manifest, err := ParseManifest(data)
+	if err != nil {
+		return err
+	}
+
+	err = filepath.WalkDir(distDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(distDir, p)
+		if unpackedFiles[filepath.ToSlash(rel)] || strings.HasSuffix(p, packedExt) {
+			return nil
+		}
+		return packFile(p)
+	})
+	if err != nil {
+		return err
+	}
+
+	manifest.Packed = true
+	manifest.Signature = ""
+	encoded, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("🔴 encode manifest: %w", err)
+	}
+	if err := os.WriteFile(manifestPath, encoded, 0644); err != nil {
+		return fmt.Errorf("🔴 write manifest file: %w", err)
+	}
+	return nil
+}
+
+func packFile(p string) error {
+	contents, err := os.ReadFile(p)
+	if err != nil {
+		return fmt.Errorf("🔴 read %s: %w", FormatPath(p), err)
+	}
+
+	var buf bytes.Buffer
+	zw, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if _, err := zw.Write(contents); err != nil {
+		return fmt.Errorf("🔴 compress %s: %w", FormatPath(p), err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("🔴 compress %s: %w", FormatPath(p), err)
+	}
+
+	if err := os.WriteFile(p+packedExt, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("🔴 write %s: %w", FormatPath(p+packedExt), err)
+	}
+	return os.Remove(p)
+}
+
+func unpackFS(filesystem fs.FS, dist string) (fs.FS, error) {
+	if filesystem == nil {
+		return filesystem, nil
+	}
+	dist = path.Clean(filepath.ToSlash(dist))
+	manifest, err := ReadManifest(filesystem, dist)
+	if err != nil || !manifest.Packed {
+		return filesystem, nil
+	}
+
+	files := map[string][]byte{}
+	err = fs.WalkDir(filesystem, dist, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(p, packedExt) {
+			return err
+		}
+		compressed, err := fs.ReadFile(filesystem, p)
+		if err != nil {
+			return fmt.Errorf("🔴 read %s: %w", p, err)
+		}
+		zr, err := gzip.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			return fmt.Errorf("🔴 decompress %s: %w", p, err)
+		}
+		contents, err := io.ReadAll(zr)
+		if err != nil {
+			return fmt.Errorf("🔴 decompress %s: %w", p, err)
+		}
+		files[strings.TrimSuffix(p, packedExt)] = contents
+		return nil
+	})
+	if err != nil {
+		return filesystem, err
+	}
+
+	return &unpackedFS{base: filesystem, files: files, modTime: manifest.BuiltAt}, nil
+}
+
+type unpackedFS struct {
+	base    fs.FS
+	files   map[string][]byte
+	modTime time.Time
+}
+
+func (u *unpackedFS) Open(name string) (fs.File, error) {
+	if contents, ok := u.files[name]; ok {
+		return &unpackedFile{Reader: bytes.NewReader(contents), name: path.Base(name), size: int64(len(contents)), modTime: u.modTime}, nil
+	}
+	return u.base.Open(name)
+}
+
+type unpackedFile struct {
+	*bytes.Reader
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func (f *unpackedFile) Stat() (fs.FileInfo, error) { return f, nil }
+func (f *unpackedFile) Close() error               { return nil }
+func (f *unpackedFile) Name() string               { return f.name }
+func (f *unpackedFile) Size() int64                { return f.size }
+func (f *unpackedFile) Mode() fs.FileMode          { return 0444 }
+func (f *unpackedFile) ModTime() time.Time         { return f.modTime }
+func (f *unpackedFile) IsDir() bool                { return false }
+func (f *unpackedFile) Sys() any                   { return nil }
@@ -0,0 +1,62 @@
+package alloy
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPackDistRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "home-server.js"), "server code")
+	writeTestFile(t, filepath.Join(dist, "client-home-AAAA.js"), "client code")
+	writeTestFile(t, filepath.Join(dist, "assets", "logo-BBBB.svg"), "<svg/>")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{"home": {"server": "home-server.js", "client": "client-home-AAAA.js", "css": "shared.css"}}`)
+
+	if err := PackDist(dist); err != nil {
+		t.Fatalf("pack: %v", err)
+	}
+	if fileExists(filepath.Join(dist, "home-server.js")) || !fileExists(filepath.Join(dist, "home-server.js.gz")) {
+		t.Fatal("server bundle not packed")
+	}
+	if !fileExists(filepath.Join(dist, "manifest.json")) {
+		t.Fatal("manifest must stay uncompressed")
+	}
+
+	filesystem, err := unpackFS(os.DirFS(root), "dist/build")
+	if err != nil {
+		t.Fatalf("unpack: %v", err)
+	}
+
+	data, err := fs.ReadFile(filesystem, "dist/build/home-server.js")
+	if err != nil || string(data) != "server code" {
+		t.Fatalf("read unpacked server: %q %v", data, err)
+	}
+	info, err := fs.Stat(filesystem, "dist/build/assets/logo-BBBB.svg")
+	if err != nil || info.Size() != int64(len("<svg/>")) {
+		t.Fatalf("stat unpacked asset: %v %v", info, err)
+	}
+
+	distFS, err := fs.Sub(filesystem, "dist/build")
+	if err != nil {
+		t.Fatalf("sub: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	http.FileServer(http.FS(distFS)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/client-home-AAAA.js", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "client code" {
+		t.Fatalf("serve unpacked client: %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestUnpackFSIgnoresUnpackedDist(t *testing.T) {
+	root := t.TempDir()
+	filesystem := os.DirFS(root)
+	got, err := unpackFS(filesystem, "dist/build")
+	if err != nil || got != filesystem {
+		t.Fatalf("expected filesystem unchanged, got %v %v", got, err)
+	}
+}
@@ -0,0 +1,343 @@
+package alloy
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var linkHref = regexp.MustCompile(`<a\s[^>]*?href="([^"]*)"`)
+
+var routeWildcardName = regexp.MustCompile(`^[\p{L}_][\p{L}\p{Nd}_]*$`)
+
+var prefetchRoutes = struct {
+	sync.RWMutex
+	mux      *http.ServeMux
+	pages    map[string]string
+	patterns []routePattern
+}{
+	mux:   http.NewServeMux(),
+	pages: map[string]string{},
+}
+
+type prefetchState struct {
+	page    string
+	targets []string
+	auto    bool
+	files   PrebuiltFiles
+}
+
+type prefetchContextKey struct{}
+
+func (h *PageHandler) WithPrefetch(pages ...string) *PageHandler {
+	h.prefetch = pages
+	h.prefetchSet = true
+	return h
+}
+
+func registerPageRoute(pattern string, component string) {
+	if pattern == "" {
+		return
+	}
+	prefetchRoutes.RLock()
+	_, ok := prefetchRoutes.pages[pattern]
+	prefetchRoutes.RUnlock()
+	if ok {
+		return
+	}
+
+	prefetchRoutes.Lock()
+	defer prefetchRoutes.Unlock()
+	if _, ok := prefetchRoutes.pages[pattern]; ok {
+		return
+	}
+	parsed, err := parseRoutePattern(pattern)
+	if err == nil {
+		for _, other := range prefetchRoutes.patterns {
+			if parsed.conflicts(other) {
+				err = fmt.Errorf("🔴 route pattern %q conflicts with %q", pattern, other.raw)
+				break
+			}
+		}
+	}
+	if err != nil {
+		prefetchRoutes.pages[pattern] = ""
+		logger().Error("page not prefetchable", "page", pageName(component), "err", err)
+		return
+	}
+	prefetchRoutes.mux.Handle(pattern, http.NotFoundHandler())
+	prefetchRoutes.pages[pattern] = pageName(component)
+	prefetchRoutes.patterns = append(prefetchRoutes.patterns, parsed)
+}
+
+func routePage(target string) (string, bool) {
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme != "" || u.Host != "" || !strings.HasPrefix(u.Path, "/") {
+		return "", false
+	}
+	prefetchRoutes.RLock()
+	defer prefetchRoutes.RUnlock()
+	_, pattern := prefetchRoutes.mux.Handler(&http.Request{Method: http.MethodGet, URL: u, Host: ""})
+	page := prefetchRoutes.pages[pattern]
+	return page, page != ""
+}
+
+func prefetchLimit() int {
+	if cfg := getConfig(); cfg != nil {
+		return cfg.PrefetchLimit
+	}
+	return 0
+}
+
+func (h *PageHandler) withPrefetch(r *http.Request, files PrebuiltFiles) *http.Request {
+	registerPageRoute(r.Pattern, h.component)
+	state := &prefetchState{page: pageName(h.component), targets: h.prefetch, auto: !h.prefetchSet, files: files}
+	if !state.auto && len(state.targets) == 0 || state.auto && prefetchLimit() <= 0 {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), prefetchContextKey{}, state))
+}
+
+func injectPrefetch(ctx context.Context, doc string) string {
+	state, ok := ctx.Value(prefetchContextKey{}).(*prefetchState)
+	if !ok {
+		return doc
+	}
+	i := strings.Index(doc, "</head>")
+	if i < 0 {
+		return doc
+	}
+
+	targets := state.targets
+	if state.auto {
+		targets = linkedPages(doc, state.page, prefetchLimit())
+	}
+
+	loaded := map[string]bool{}
+	for _, name := range append([]string{state.files.Client, state.files.CSS}, state.files.ClientChunks...) {
+		loaded[name] = true
+	}
+	locale, _ := ctx.Value(localeContextKey{}).(string)
+	cfg := getConfig()
+
+	var b strings.Builder
+	for _, page := range targets {
+		files, ok, err := lookupManifest(cfg.FS, DefaultDistDir, page)
+		if err != nil || !ok {
+			continue
+		}
+		if localized, ok := files.Locales[locale]; ok {
+			files.Client, files.ClientChunks = localized.Client, localized.ClientChunks
+		}
+		for _, name := range append(append([]string{files.Client}, files.ClientChunks...), files.CSS) {
+			if name == "" || loaded[name] {
+				continue
+			}
+			loaded[name] = true
+			as := "script"
+			if strings.HasSuffix(name, ".css") {
+				as = "style"
+			}
+			fmt.Fprintf(&b, "\t<link rel=\"prefetch\" href=\"%s\" as=\"%s\">\n", html.EscapeString(AssetURL(ensureLeadingSlash(name))), as)
+		}
+	}
+	if b.Len() == 0 {
+		return doc
+	}
+	return doc[:i] + b.String() + doc[i:]
+}
+
+func linkedPages(doc string, current string, limit int) []string {
+	var pages []string
+	for _, match := range linkHref.FindAllStringSubmatch(doc, -1) {
+		page, ok := routePage(html.UnescapeString(match[1]))
+		if !ok || page == current || slices.Contains(pages, page) {
+			continue
+		}
+		pages = append(pages, page)
+		if len(pages) == limit {
+			break
+		}
+	}
+	return pages
+}
+
+type routeRelation int
+
+const (
+	routeEquivalent routeRelation = iota
+	routeMoreGeneral
+	routeMoreSpecific
+	routeOverlaps
+	routeDisjoint
+)
+
+type routePattern struct {
+	raw      string
+	method   string
+	host     string
+	segments []routeSegment
+}
+
+type routeSegment struct {
+	literal string
+	wild    bool
+	multi   bool
+}
+
+// parseRoutePattern follows net/http's ServeMux pattern syntax so that
+// conflicts can be detected before Handle panics on them.
+func parseRoutePattern(pattern string) (routePattern, error) {
+	p := routePattern{raw: pattern}
+	rest := strings.TrimSpace(pattern)
+	if method, after, ok := strings.Cut(rest, " "); ok {
+		p.method, rest = method, strings.TrimLeft(after, " \t")
+	}
+	i := strings.IndexByte(rest, '/')
+	if i < 0 {
+		return p, fmt.Errorf("🔴 route pattern %q has no path", pattern)
+	}
+	p.host = rest[:i]
+
+	names := map[string]bool{}
+	for path := rest[i:]; path != ""; {
+		path = path[1:]
+		if path == "" {
+			p.segments = append(p.segments, routeSegment{multi: true})
+			break
+		}
+		seg, tail := path, ""
+		if j := strings.IndexByte(path, '/'); j >= 0 {
+			seg, tail = path[:j], path[j:]
+		}
+		path = tail
+
+		switch {
+		case seg == "{$}":
+			if tail != "" {
+				return p, fmt.Errorf("🔴 route pattern %q: {$} must be last", pattern)
+			}
+			p.segments = append(p.segments, routeSegment{literal: "/"})
+		case strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}"):
+			name, multi := strings.CutSuffix(seg[1:len(seg)-1], "...")
+			if multi && tail != "" {
+				return p, fmt.Errorf("🔴 route pattern %q: {%s...} must be last", pattern, name)
+			}
+			if !routeWildcardName.MatchString(name) || names[name] {
+				return p, fmt.Errorf("🔴 route pattern %q: bad wildcard %q", pattern, seg)
+			}
+			names[name] = true
+			p.segments = append(p.segments, routeSegment{wild: true, multi: multi})
+		case strings.ContainsAny(seg, "{}"):
+			return p, fmt.Errorf("🔴 route pattern %q: wildcard must be a whole segment", pattern)
+		default:
+			literal, err := url.PathUnescape(seg)
+			if err != nil {
+				return p, fmt.Errorf("🔴 route pattern %q: %w", pattern, err)
+			}
+			p.segments = append(p.segments, routeSegment{literal: literal})
+		}
+	}
+	return p, nil
+}
+
+func (p routePattern) conflicts(q routePattern) bool {
+	if p.host != q.host {
+		return false
+	}
+	rel := p.compareMethods(q)
+	if rel == routeDisjoint {
+		return false
+	}
+	rel = combineRouteRelations(rel, p.comparePaths(q))
+	return rel == routeEquivalent || rel == routeOverlaps
+}
+
+func (p routePattern) compareMethods(q routePattern) routeRelation {
+	switch {
+	case p.method == q.method:
+		return routeEquivalent
+	case p.method == "":
+		return routeMoreGeneral
+	case q.method == "":
+		return routeMoreSpecific
+	case p.method == http.MethodGet && q.method == http.MethodHead:
+		return routeMoreGeneral
+	case p.method == http.MethodHead && q.method == http.MethodGet:
+		return routeMoreSpecific
+	}
+	return routeDisjoint
+}
+
+func (p routePattern) comparePaths(q routePattern) routeRelation {
+	pMulti := p.segments[len(p.segments)-1].multi
+	qMulti := q.segments[len(q.segments)-1].multi
+	if len(p.segments) != len(q.segments) && !pMulti && !qMulti {
+		return routeDisjoint
+	}
+	rel := routeEquivalent
+	ps, qs := p.segments, q.segments
+	for ; len(ps) > 0 && len(qs) > 0; ps, qs = ps[1:], qs[1:] {
+		if rel = combineRouteRelations(rel, compareRouteSegments(ps[0], qs[0])); rel == routeDisjoint {
+			return rel
+		}
+	}
+	switch {
+	case len(ps) == 0 && len(qs) == 0:
+		return rel
+	case len(ps) < len(qs) && pMulti:
+		return combineRouteRelations(rel, routeMoreGeneral)
+	case len(qs) < len(ps) && qMulti:
+		return combineRouteRelations(rel, routeMoreSpecific)
+	}
+	return routeDisjoint
+}
+
+func compareRouteSegments(a, b routeSegment) routeRelation {
+	switch {
+	case a.multi && b.multi:
+		return routeEquivalent
+	case a.multi:
+		return routeMoreGeneral
+	case b.multi:
+		return routeMoreSpecific
+	case a.wild && b.wild:
+		return routeEquivalent
+	case a.wild && b.literal == "/", b.wild && a.literal == "/":
+		return routeDisjoint
+	case a.wild:
+		return routeMoreGeneral
+	case b.wild:
+		return routeMoreSpecific
+	case a.literal == b.literal:
+		return routeEquivalent
+	}
+	return routeDisjoint
+}
+
+func combineRouteRelations(a, b routeRelation) routeRelation {
+	switch a {
+	case routeEquivalent:
+		return b
+	case routeDisjoint:
+		return routeDisjoint
+	case routeOverlaps:
+		if b == routeDisjoint {
+			return routeDisjoint
+		}
+		return routeOverlaps
+	}
+	switch {
+	case b == routeEquivalent:
+		return a
+	case a == routeMoreGeneral && b == routeMoreSpecific, a == routeMoreSpecific && b == routeMoreGeneral:
+		return routeOverlaps
+	}
+	return b
+}
@@ -0,0 +1,130 @@
+package alloy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPageHandlerPrefetchesLinkedPages(t *testing.T) {
+	resetBundleCache()
+	t.Cleanup(resetBundleCache)
+
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "home-server.js"), `var __Component = { default: function() {
+		return '<a href="/about">About</a><a href="/posts/hello?x=1">Post</a><a href="https://example.com/">Out</a><a href="/">Home</a>';
+	} };`)
+	writeTestFile(t, filepath.Join(dist, "about-server.js"), `var __Component = { default: function() { return "<p>about</p>"; } };`)
+	for _, name := range []string{"client-home-AAAAAAAA.js", "client-about-BBBBBBBB.js", "client-post-CCCCCCCC.js", "chunk-DDDDDDDD.js", "chunk-EEEEEEEE.js"} {
+		writeTestFile(t, filepath.Join(dist, name), "js")
+	}
+	writeTestFile(t, filepath.Join(dist, "shared.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "about.css"), "p{}")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{
+		"home": {"server": "home-server.js", "client": "client-home-AAAAAAAA.js", "chunks": ["chunk-DDDDDDDD.js"], "css": "shared.css"},
+		"about": {"server": "about-server.js", "client": "client-about-BBBBBBBB.js", "chunks": ["chunk-DDDDDDDD.js", "chunk-EEEEEEEE.js"], "css": "about.css"},
+		"post": {"server": "about-server.js", "client": "client-post-CCCCCCCC.js", "css": "shared.css"}
+	}`)
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+		cfg.PrefetchLimit = 1
+	})
+
+	mux := http.NewServeMux()
+	home := NewPage(filepath.Join(root, "pages", "home.tsx"))
+	mux.Handle("/{$}", home)
+	mux.Handle("/about", NewPage(filepath.Join(root, "pages", "about.tsx")))
+	mux.Handle("/posts/{slug}", NewPage(filepath.Join(root, "pages", "post.tsx")))
+	registerPageRoute("/about", filepath.Join(root, "pages", "about.tsx"))
+	registerPageRoute("/posts/{slug}", filepath.Join(root, "pages", "post.tsx"))
+
+	get := func() string {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec.Body.String()
+	}
+	head := func(body string) string {
+		return body[:strings.Index(body, "</head>")]
+	}
+
+	body := head(get())
+	for _, want := range []string{
+		`<link rel="prefetch" href="/dist/build/client-about-BBBBBBBB.js" as="script">`,
+		`<link rel="prefetch" href="/dist/build/chunk-EEEEEEEE.js" as="script">`,
+		`<link rel="prefetch" href="/dist/build/about.css" as="style">`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("head missing %s:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, `prefetch" href="/dist/build/chunk-DDDDDDDD.js`) || strings.Contains(body, "client-post-") {
+		t.Fatalf("prefetched loaded chunk or page past the limit:\n%s", body)
+	}
+
+	getConfig().PrefetchLimit = 5
+	if body := head(get()); !strings.Contains(body, "client-post-CCCCCCCC.js") || strings.Contains(body, "client-home-AAAAAAAA.js\" as") {
+		t.Fatalf("routed page not prefetched:\n%s", body)
+	}
+
+	home.WithPrefetch("post")
+	if body := head(get()); !strings.Contains(body, "client-post-CCCCCCCC.js") || strings.Contains(body, "client-about-") {
+		t.Fatalf("explicit prefetch list not used:\n%s", body)
+	}
+
+	home.WithPrefetch()
+	if body := head(get()); strings.Contains(body, `rel="prefetch"`) {
+		t.Fatalf("prefetch not disabled:\n%s", body)
+	}
+}
+
+func TestRoutePatternConflictsMatchServeMux(t *testing.T) {
+	patterns := []string{
+		"/", "/{$}", "/about", "/about/", "/posts/{slug}", "/posts/{id}", "/posts/latest",
+		"/posts/{slug}/edit", "/{section}/latest", "/files/{path...}", "/files/", "GET /posts/{id}",
+		"HEAD /posts/{slug}", "POST /posts/{slug}", "example.com/about", "/{a}/{b}", "/x/{b}", "/{a}/x",
+	}
+	for _, a := range patterns {
+		for _, b := range patterns {
+			if a == b {
+				continue
+			}
+			mux := http.NewServeMux()
+			mux.Handle(a, http.NotFoundHandler())
+			panicked := func() (panicked bool) {
+				defer func() { panicked = recover() != nil }()
+				mux.Handle(b, http.NotFoundHandler())
+				return false
+			}()
+			pa, err := parseRoutePattern(a)
+			if err != nil {
+				t.Fatalf("parse %q: %v", a, err)
+			}
+			pb, err := parseRoutePattern(b)
+			if err != nil {
+				t.Fatalf("parse %q: %v", b, err)
+			}
+			if got := pb.conflicts(pa); got != panicked {
+				t.Fatalf("%q vs %q: conflicts = %v, ServeMux panicked = %v", a, b, got, panicked)
+			}
+		}
+	}
+}
+
+func TestRegisterPageRouteSkipsConflicts(t *testing.T) {
+	registerPageRoute("/conflict/{slug}", "pages/first.tsx")
+	registerPageRoute("/conflict/{id}", "pages/second.tsx")
+	registerPageRoute("/conflict/{bad", "pages/third.tsx")
+
+	if page, ok := routePage("/conflict/hello"); !ok || page != "first" {
+		t.Fatalf("routePage = %q %v", page, ok)
+	}
+	for _, pattern := range []string{"/conflict/{id}", "/conflict/{bad"} {
+		if page, ok := prefetchRoutes.pages[pattern]; !ok || page != "" {
+			t.Fatalf("%s not rejected: %q %v", pattern, page, ok)
+		}
+	}
+}
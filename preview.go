@@ -0,0 +1,100 @@
+package alloy
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const PreviewCookieName = "alloy_preview"
+
+const DefaultPreviewMaxAge = time.Hour
+
+type previewContextKey struct{}
+
+func EnablePreview(w http.ResponseWriter, r *http.Request, maxAge time.Duration) error {
+	secret := previewSecret()
+	if secret == "" {
+		return fmt.Errorf("🔴 preview secret not configured")
+	}
+	if maxAge <= 0 {
+		maxAge = DefaultPreviewMaxAge
+	}
+
+	expires := time.Now().Add(maxAge)
+	http.SetCookie(w, &http.Cookie{
+		Name:     PreviewCookieName,
+		Value:    signPreview(secret, expires.Unix()),
+		Path:     "/",
+		Expires:  expires,
+		MaxAge:   int(maxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+func DisablePreview(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     PreviewCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func IsPreview(r *http.Request) bool {
+	if preview, ok := r.Context().Value(previewContextKey{}).(bool); ok {
+		return preview
+	}
+	return verifyPreviewCookie(r)
+}
+
+func withPreview(r *http.Request) (*http.Request, bool) {
+	preview := verifyPreviewCookie(r)
+	return r.WithContext(context.WithValue(r.Context(), previewContextKey{}, preview)), preview
+}
+
+func previewSecret() string {
+	if cfg := getConfig(); cfg != nil {
+		return cfg.PreviewSecret
+	}
+	return ""
+}
+
+func signPreview(secret string, expires int64) string {
+	payload := strconv.FormatInt(expires, 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return payload + "." + hex.EncodeToString(mac.Sum(nil))
+}
+
+func verifyPreviewCookie(r *http.Request) bool {
+	secret := previewSecret()
+	if secret == "" {
+		return false
+	}
+	cookie, err := r.Cookie(PreviewCookieName)
+	if err != nil {
+		return false
+	}
+
+	payload, _, ok := strings.Cut(cookie.Value, ".")
+	if !ok {
+		return false
+	}
+	expires, err := strconv.ParseInt(payload, 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return false
+	}
+	return hmac.Equal([]byte(cookie.Value), []byte(signPreview(secret, expires)))
+}
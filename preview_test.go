@@ -0,0 +1,95 @@
+package alloy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPreviewCookie(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) {
+		cfg.PreviewSecret = "s3cret"
+	})
+
+	rec := httptest.NewRecorder()
+	if err := EnablePreview(rec, httptest.NewRequest(http.MethodGet, "/api/preview", nil), time.Minute); err != nil {
+		t.Fatalf("enable: %v", err)
+	}
+	cookie := rec.Result().Cookies()[0]
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookie)
+	if !IsPreview(req) {
+		t.Fatal("signed cookie not accepted")
+	}
+
+	forged := httptest.NewRequest(http.MethodGet, "/", nil)
+	forged.AddCookie(&http.Cookie{Name: PreviewCookieName, Value: signPreview("other", time.Now().Add(time.Hour).Unix())})
+	if IsPreview(forged) {
+		t.Fatal("cookie signed with another secret accepted")
+	}
+
+	expired := httptest.NewRequest(http.MethodGet, "/", nil)
+	expired.AddCookie(&http.Cookie{Name: PreviewCookieName, Value: signPreview("s3cret", time.Now().Add(-time.Minute).Unix())})
+	if IsPreview(expired) {
+		t.Fatal("expired cookie accepted")
+	}
+
+	rec = httptest.NewRecorder()
+	DisablePreview(rec)
+	if c := rec.Result().Cookies()[0]; c.Name != PreviewCookieName || c.MaxAge >= 0 {
+		t.Fatalf("disable cookie: %+v", c)
+	}
+}
+
+func TestEnablePreviewRequiresSecret(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) { cfg.PreviewSecret = "" })
+	if err := EnablePreview(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil), 0); err == nil {
+		t.Fatal("expected error without secret")
+	}
+}
+
+func TestPreviewBypassesRevalidation(t *testing.T) {
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "post-server.js"), fakeStaticServerJS)
+	writeTestFile(t, filepath.Join(dist, "client-post-AAAAAAAA.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "shared.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{
+		"post": {"server": "post-server.js", "client": "client-post-AAAAAAAA.js", "css": "shared.css", "render": "static", "revalidate": 60}
+	}`)
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+		cfg.ISRDir = t.TempDir()
+		cfg.PreviewSecret = "s3cret"
+	})
+	t.Cleanup(resetISRCache)
+
+	handler := NewPage(filepath.Join(root, "pages", "post.tsx")).WithLoader(func(r *http.Request) map[string]any {
+		if IsPreview(r) {
+			return map[string]any{"name": "draft"}
+		}
+		return map[string]any{"name": "published"}
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/post", nil))
+	if !strings.Contains(rec.Body.String(), "<p>published</p>") {
+		t.Fatalf("published page: %q", rec.Body.String())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/post", nil)
+	req.AddCookie(&http.Cookie{Name: PreviewCookieName, Value: signPreview("s3cret", time.Now().Add(time.Hour).Unix())})
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if !strings.Contains(rec.Body.String(), "<p>draft</p>") {
+		t.Fatalf("preview page: %q", rec.Body.String())
+	}
+	if rec.Header().Get("X-Alloy-Cache") != "" || rec.Header().Get("Cache-Control") != "private, no-store" {
+		t.Fatalf("preview headers: %v", rec.Header())
+	}
+}
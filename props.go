@@ -0,0 +1,393 @@
+package alloy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const propsDirective = "//alloy:props"
+
+const propsFileSuffix = ".props.d.ts"
+
+const propsHeader = "// Code generated by alloy gen. DO NOT EDIT.\n\n"
+
+func TypedLoader[T any](loader func(r *http.Request) T) func(r *http.Request) map[string]any {
+	return func(r *http.Request) map[string]any {
+		data, err := json.Marshal(loader(r))
+		if err != nil {
+			logger().Error("encode props", "path", r.URL.Path, "err", err)
+			return map[string]any{}
+		}
+		props := map[string]any{}
+		if err := json.Unmarshal(data, &props); err != nil {
+			logger().Error("props must encode to a JSON object", "path", r.URL.Path, "err", err)
+		}
+		return props
+	}
+}
+
+type propsPackage struct {
+	types map[string]ast.Expr
+}
+
+type propsTarget struct {
+	page string
+	pkg  *propsPackage
+	name string
+}
+
+func GeneratePropsTypes(root string, pagesDir string) (map[string][]byte, error) {
+	targets, err := findPropsTargets(root)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{}
+	for _, target := range targets {
+		out := filepath.Join(pagesDir, target.page+propsFileSuffix)
+		if _, ok := files[out]; ok {
+			return nil, fmt.Errorf("🔴 page %s has more than one //alloy:props type", target.page)
+		}
+		files[out] = []byte(target.pkg.declarations(target.name))
+	}
+	return files, nil
+}
+
+func writePropsTypes(root string, pagesDir string) ([]string, error) {
+	files, err := GeneratePropsTypes(root, pagesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var written []string
+	for _, path := range sortedKeys(files) {
+		changed, err := writeGenerated(path, files[path])
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			written = append(written, path)
+		}
+	}
+	return written, nil
+}
+
+func WatchPropsTypes(ctx context.Context, root string, pagesDir string) error {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	last := ""
+	for {
+		if stamp := goSourcesStamp(root); stamp != last {
+			last = stamp
+			written, err := writePropsTypes(root, pagesDir)
+			if err != nil {
+				logger().Error("generate props types", "err", err)
+			}
+			for _, path := range written {
+				logger().Info("generated props types", "file", FormatPath(path))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func goSourcesStamp(root string) string {
+	var b strings.Builder
+	walkGoSources(root, func(path string, info fs.FileInfo) {
+		fmt.Fprintf(&b, "%s:%d:%d\n", path, info.Size(), info.ModTime().UnixNano())
+	})
+	return b.String()
+}
+
+func walkGoSources(root string, visit func(path string, info fs.FileInfo)) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "node_modules" || name == "vendor" || name == "dist") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		visit(path, info)
+		return nil
+	})
+}
+
+func findPropsTargets(root string) ([]propsTarget, error) {
+	packages := map[string]*propsPackage{}
+	var targets []propsTarget
+	var parseErr error
+
+	err := walkGoSources(root, func(path string, _ fs.FileInfo) {
+		if parseErr != nil {
+			return
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ParseComments)
+		if err != nil {
+			parseErr = fmt.Errorf("🔴 parse %s: %w", FormatPath(path), err)
+			return
+		}
+
+		dir := filepath.Dir(path)
+		pkg := packages[dir]
+		if pkg == nil {
+			pkg = &propsPackage{types: map[string]ast.Expr{}}
+			packages[dir] = pkg
+		}
+
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				pkg.types[typeSpec.Name.Name] = typeSpec.Type
+
+				doc := typeSpec.Doc
+				if doc == nil && len(gen.Specs) == 1 {
+					doc = gen.Doc
+				}
+				if page := propsPage(doc); page != "" {
+					targets = append(targets, propsTarget{page: page, pkg: pkg, name: typeSpec.Name.Name})
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("🔴 scan go sources: %w", err)
+	}
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	sort.Slice(targets, func(i, j int) bool { return targets[i].page < targets[j].page })
+	return targets, nil
+}
+
+func propsPage(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+	for _, comment := range doc.List {
+		if page, ok := strings.CutPrefix(comment.Text, propsDirective+" "); ok {
+			return strings.TrimSpace(page)
+		}
+	}
+	return ""
+}
+
+func (p *propsPackage) declarations(root string) string {
+	emitted := map[string]bool{}
+	queue := []string{root}
+	var decls []string
+
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if emitted[name] {
+			continue
+		}
+		emitted[name] = true
+
+		var deps []string
+		expr := p.types[name]
+		if fields, ok := p.structFields(expr, &deps); ok {
+			decls = append(decls, fmt.Sprintf("export interface %s %s\n", name, fields))
+		} else {
+			decls = append(decls, fmt.Sprintf("export type %s = %s;\n", name, p.tsType(expr, &deps)))
+		}
+		queue = append(queue, deps...)
+	}
+
+	var b bytes.Buffer
+	b.WriteString(propsHeader)
+	b.WriteString(strings.Join(decls, "\n"))
+	fmt.Fprintf(&b, "\nexport type Props = %s;\n", root)
+	return b.String()
+}
+
+type propsField struct {
+	Key      string
+	Optional bool
+	Type     string
+}
+
+func (p *propsPackage) structFields(expr ast.Expr, deps *[]string) (string, bool) {
+	st, ok := expr.(*ast.StructType)
+	if !ok {
+		return "", false
+	}
+
+	var fields []propsField
+	p.collectFields(st, deps, &fields)
+	if len(fields) == 0 {
+		return "{}", true
+	}
+
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, field := range fields {
+		optional := ""
+		if field.Optional {
+			optional = "?"
+		}
+		fmt.Fprintf(&b, "  %s%s: %s;\n", tsKey(field.Key), optional, field.Type)
+	}
+	b.WriteString("}")
+	return b.String(), true
+}
+
+func (p *propsPackage) collectFields(st *ast.StructType, deps *[]string, fields *[]propsField) {
+	for _, field := range st.Fields.List {
+		tag := ""
+		if field.Tag != nil {
+			raw, _ := strconv.Unquote(field.Tag.Value)
+			tag = reflect.StructTag(raw).Get("json")
+		}
+		if tag == "-" {
+			continue
+		}
+		tagName, options, _ := strings.Cut(tag, ",")
+		options = "," + options + ","
+
+		if len(field.Names) == 0 {
+			if ident, ok := field.Type.(*ast.Ident); ok && tagName == "" {
+				if embedded, ok := p.types[ident.Name].(*ast.StructType); ok {
+					p.collectFields(embedded, deps, fields)
+					continue
+				}
+			}
+		}
+
+		names := field.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{ast.NewIdent(embeddedName(field.Type))}
+		}
+		for _, ident := range names {
+			if !ident.IsExported() {
+				continue
+			}
+			key := ident.Name
+			if tagName != "" {
+				key = tagName
+			}
+
+			_, pointer := field.Type.(*ast.StarExpr)
+			tsType := p.tsType(field.Type, deps)
+			if strings.Contains(options, ",string,") {
+				tsType = "string"
+			}
+			*fields = append(*fields, propsField{
+				Key:      key,
+				Optional: pointer || strings.Contains(options, ",omitempty,") || strings.Contains(options, ",omitzero,"),
+				Type:     tsType,
+			})
+		}
+	}
+}
+
+func (p *propsPackage) fields(name string) []propsField {
+	st, ok := p.types[name].(*ast.StructType)
+	if !ok {
+		return nil
+	}
+	var deps []string
+	var fields []propsField
+	p.collectFields(st, &deps, &fields)
+	return fields
+}
+
+func embeddedName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+func (p *propsPackage) tsType(expr ast.Expr, deps *[]string) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return "string"
+		case "bool":
+			return "boolean"
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "float32", "float64", "byte", "rune":
+			return "number"
+		case "any":
+			return "unknown"
+		}
+		if _, ok := p.types[t.Name]; ok {
+			*deps = append(*deps, t.Name)
+			return t.Name
+		}
+		return "unknown"
+	case *ast.StarExpr:
+		return p.tsType(t.X, deps) + " | null"
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && ident.Name == "byte" && t.Len == nil {
+			return "string"
+		}
+		elem := p.tsType(t.Elt, deps)
+		if strings.Contains(elem, " ") {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]"
+	case *ast.MapType:
+		return "Record<string, " + p.tsType(t.Value, deps) + ">"
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok && pkg.Name == "time" && t.Sel.Name == "Time" {
+			return "string"
+		}
+		return "unknown"
+	case *ast.StructType:
+		fields, _ := p.structFields(t, deps)
+		return fields
+	}
+	return "unknown"
+}
+
+func tsKey(key string) string {
+	for i, r := range key {
+		if r == '_' || r == '$' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9') {
+			continue
+		}
+		return strconv.Quote(key)
+	}
+	return key
+}
@@ -0,0 +1,256 @@
+package alloy
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type PropsMismatch struct {
+	Page    string
+	Key     string
+	Message string
+}
+
+func (m PropsMismatch) Error() string {
+	return fmt.Sprintf("%s: %s: %s", m.Page, m.Key, m.Message)
+}
+
+var (
+	tsDefaultParamPattern = regexp.MustCompile(`export\s+default\s+function\s*\w*\s*(?:<[^>]*>)?\s*\(\s*(?:\w+|\{[^}]*\})\s*:\s*(\w+)\s*([<.]?)`)
+	tsPropKeyPattern      = regexp.MustCompile(`^(?:[A-Za-z_$][\w$]*|"[^"]*"|'[^']*')$`)
+	tsArrayPattern        = regexp.MustCompile(`Array<([^<>]+)>`)
+	tsPrimitivePattern    = regexp.MustCompile(`\b(string|number|boolean|null|undefined)\b|[\[\]|()]`)
+)
+
+type tsProp struct {
+	optional bool
+	typ      string
+}
+
+type tsProps struct {
+	fields map[string]tsProp
+	open   bool
+}
+
+func CheckPageProps(root string, pages []PageSpec) ([]PropsMismatch, error) {
+	targets, err := findPropsTargets(root)
+	if err != nil {
+		return nil, err
+	}
+	byPage := map[string]propsTarget{}
+	for _, target := range targets {
+		byPage[target.page] = target
+	}
+
+	var mismatches []PropsMismatch
+	for _, page := range pages {
+		target, ok := byPage[page.Name]
+		if !ok {
+			continue
+		}
+		source, err := os.ReadFile(page.Component)
+		if err != nil {
+			return nil, fmt.Errorf("🔴 read %s: %w", FormatPath(page.Component), err)
+		}
+		declared, ok := parseTSProps(string(source))
+		if !ok {
+			continue
+		}
+		mismatches = append(mismatches, compareProps(page.Name, target.pkg.fields(target.name), declared)...)
+	}
+	return mismatches, nil
+}
+
+func parseTSProps(source string) (tsProps, bool) {
+	source = stripTSComments(source)
+
+	name := "Props"
+	if match := tsDefaultParamPattern.FindStringSubmatch(source); match != nil {
+		if match[2] != "" {
+			return tsProps{}, false
+		}
+		name = match[1]
+	}
+	if regexp.MustCompile(`import\s+(?:type\s+)?(?:\{[^}]*\b` + name + `\b[^}]*\}|` + name + `\b)[^;'"]*from\s+['"]`).MatchString(source) {
+		return tsProps{}, false
+	}
+
+	decl := regexp.MustCompile(`\b(?:interface\s+` + name + `\s*|type\s+` + name + `\s*=\s*)\{`).FindStringIndex(source)
+	if decl == nil {
+		return tsProps{}, false
+	}
+	rest := source[decl[1]-1:]
+	body, ok := matchBraces(rest)
+	if !ok {
+		return tsProps{}, false
+	}
+	if after := strings.TrimLeft(rest[len(body)+2:], " \t"); strings.HasPrefix(after, "&") || strings.HasPrefix(after, "|") {
+		return tsProps{}, false
+	}
+
+	props := tsProps{fields: map[string]tsProp{}}
+	for _, member := range splitTopLevel(body, ";,\n") {
+		member = strings.TrimSpace(member)
+		if member == "" {
+			continue
+		}
+		if strings.HasPrefix(member, "[") {
+			props.open = true
+			continue
+		}
+		key, typ, ok := strings.Cut(member, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(key), "readonly "))
+		optional := strings.HasSuffix(key, "?")
+		key = strings.TrimSpace(strings.TrimSuffix(key, "?"))
+		if !tsPropKeyPattern.MatchString(key) {
+			return tsProps{}, false
+		}
+		key = strings.Trim(key, `"'`)
+		props.fields[key] = tsProp{optional: optional, typ: strings.TrimSpace(typ)}
+	}
+	return props, true
+}
+
+func stripTSComments(source string) string {
+	var b strings.Builder
+	var quote byte
+	for i := 0; i < len(source); i++ {
+		c := source[i]
+		switch {
+		case quote != 0:
+			b.WriteByte(c)
+			if c == '\\' && i+1 < len(source) {
+				i++
+				b.WriteByte(source[i])
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+			b.WriteByte(c)
+		case c == '/' && strings.HasPrefix(source[i:], "//"):
+			end := strings.IndexByte(source[i:], '\n')
+			if end < 0 {
+				return b.String()
+			}
+			i += end - 1
+		case c == '/' && strings.HasPrefix(source[i:], "/*"):
+			end := strings.Index(source[i+2:], "*/")
+			if end < 0 {
+				return b.String()
+			}
+			i += end + 3
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+func matchBraces(source string) (string, bool) {
+	depth := 0
+	for i, r := range source {
+		switch r {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return source[1:i], true
+			}
+		}
+	}
+	return "", false
+}
+
+func splitTopLevel(source string, separators string) []string {
+	var parts []string
+	depth := 0
+	start := 0
+	for i, r := range source {
+		switch {
+		case strings.ContainsRune("{[(<", r):
+			depth++
+		case r == '>' && i > 0 && source[i-1] == '=':
+		case strings.ContainsRune("}])>", r):
+			depth--
+		case depth == 0 && strings.ContainsRune(separators, r):
+			parts = append(parts, source[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, source[start:])
+}
+
+func compareProps(page string, fields []propsField, declared tsProps) []PropsMismatch {
+	var mismatches []PropsMismatch
+	returned := map[string]bool{}
+
+	for _, field := range fields {
+		returned[field.Key] = true
+		prop, ok := declared.fields[field.Key]
+		if !ok {
+			if !declared.open {
+				mismatches = append(mismatches, PropsMismatch{Page: page, Key: field.Key, Message: "returned by the loader but not declared in Props"})
+			}
+			continue
+		}
+
+		goType, goNullable := normalizeTSType(field.Type)
+		tsType, tsNullable := normalizeTSType(prop.typ)
+		if !checkableTSType(tsType) && !tsNullable {
+			continue
+		}
+		if field.Optional && !prop.optional && !tsNullable {
+			mismatches = append(mismatches, PropsMismatch{Page: page, Key: field.Key, Message: "may be omitted by the loader but is required in Props"})
+			continue
+		}
+		if goNullable && !tsNullable && !prop.optional {
+			mismatches = append(mismatches, PropsMismatch{Page: page, Key: field.Key, Message: "may be null from the loader but Props does not allow null"})
+			continue
+		}
+		if checkableTSType(goType) && checkableTSType(tsType) && goType != tsType {
+			mismatches = append(mismatches, PropsMismatch{Page: page, Key: field.Key, Message: fmt.Sprintf("loader returns %s but Props declares %s", goType, tsType)})
+		}
+	}
+
+	for _, key := range sortedKeys(declared.fields) {
+		if !returned[key] && !declared.fields[key].optional {
+			mismatches = append(mismatches, PropsMismatch{Page: page, Key: key, Message: "required by Props but never returned by the loader"})
+		}
+	}
+	return mismatches
+}
+
+func normalizeTSType(typ string) (string, bool) {
+	typ = strings.Join(strings.Fields(typ), "")
+	for tsArrayPattern.MatchString(typ) {
+		typ = tsArrayPattern.ReplaceAllString(typ, "($1)[]")
+	}
+
+	nullable := false
+	var members []string
+	for _, member := range splitTopLevel(typ, "|") {
+		if member == "null" || member == "undefined" {
+			nullable = true
+			continue
+		}
+		if strings.HasPrefix(member, "(") && strings.HasSuffix(member, ")[]") && !strings.Contains(member[1:len(member)-3], "|") {
+			member = member[1:len(member)-3] + "[]"
+		}
+		members = append(members, member)
+	}
+	sort.Strings(members)
+	return strings.Join(members, "|"), nullable
+}
+
+func checkableTSType(typ string) bool {
+	return typ != "" && tsPrimitivePattern.ReplaceAllString(typ, "") == ""
+}
@@ -0,0 +1,136 @@
+package alloy
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const propsCheckLoader = `package loader
+
+//alloy:props post
+type PostProps struct {
+	Title  string   ` + "`json:\"title\"`" + `
+	Views  int      ` + "`json:\"views\"`" + `
+	Tags   []string ` + "`json:\"tags\"`" + `
+	Cover  *string  ` + "`json:\"cover\"`" + `
+	Draft  bool     ` + "`json:\"draft,omitempty\"`" + `
+}
+`
+
+func TestCheckPagePropsMatches(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "loader", "post.go"), propsCheckLoader)
+	component := filepath.Join(root, "pages", "post.tsx")
+	writeTestFile(t, component, `
+interface PostProps {
+  title: string; // shown in <h1>
+  views: number;
+  tags: Array<string>;
+  cover?: string | null;
+  draft?: boolean;
+}
+
+export default function Post({ title }: PostProps) {
+  return <h1>{title}</h1>;
+}
+`)
+
+	mismatches, err := CheckPageProps(root, []PageSpec{{Component: component, Name: "post"}})
+	if err != nil || len(mismatches) != 0 {
+		t.Fatalf("mismatches: %v %v", mismatches, err)
+	}
+}
+
+func TestCheckPagePropsReportsDrift(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "loader", "post.go"), propsCheckLoader)
+	component := filepath.Join(root, "pages", "post.tsx")
+	writeTestFile(t, component, `
+export type Props = {
+  title: string;
+  views: string;
+  tags: string[];
+  cover: string;
+  draft: boolean;
+  author: string;
+};
+
+export default function Post(props: Props) {
+  return <h1>{props.title}</h1>;
+}
+`)
+
+	mismatches, err := CheckPageProps(root, []PageSpec{{Component: component, Name: "post"}})
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	var got []string
+	for _, m := range mismatches {
+		got = append(got, m.Error())
+	}
+	report := strings.Join(got, "\n")
+	for _, want := range []string{
+		"post: views: loader returns number but Props declares string",
+		"post: cover: may be omitted by the loader but is required in Props",
+		"post: draft: may be omitted by the loader but is required in Props",
+		"post: author: required by Props but never returned by the loader",
+	} {
+		if !strings.Contains(report, want) {
+			t.Fatalf("missing %q in:\n%s", want, report)
+		}
+	}
+	if len(mismatches) != 4 {
+		t.Fatalf("unexpected mismatches:\n%s", report)
+	}
+}
+
+func TestCheckPagePropsSkipsGeneratedTypes(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "loader", "post.go"), propsCheckLoader)
+	component := filepath.Join(root, "pages", "post.tsx")
+	writeTestFile(t, component, `import type { Props } from './post.props';
+export default function Post(props: Props) { return null; }
+`)
+
+	mismatches, err := CheckPageProps(root, []PageSpec{{Component: component, Name: "post"}})
+	if err != nil || len(mismatches) != 0 {
+		t.Fatalf("mismatches: %v %v", mismatches, err)
+	}
+}
+
+func TestCheckPagePropsSkipsUnparseableProps(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "loader", "post.go"), propsCheckLoader)
+	component := filepath.Join(root, "pages", "post.tsx")
+
+	for name, source := range map[string]string{
+		"extends":   "import type { Base } from './base';\ninterface Props extends Base { title: string }\nexport default function Post(props: Props) { return null; }\n",
+		"imported":  "import { Props } from '../types';\nexport default function Post(props: Props) { return null; }\n",
+		"generic":   "interface Props<T> { title: T }\nexport default function Post<T>(props: Props<T>) { return null; }\n",
+		"intersect": "type Props = { title: string } & Extra;\nexport default function Post(props: Props) { return null; }\n",
+		"alias": `type Props = {
+  title: string;
+  views: number;
+  tags: Tags;
+  cover: Maybe<string>;
+  draft?: boolean;
+};
+export default function Post(props: Props) { return null; }
+`,
+	} {
+		writeTestFile(t, component, source)
+		mismatches, err := CheckPageProps(root, []PageSpec{{Component: component, Name: "post"}})
+		if err != nil || len(mismatches) != 0 {
+			t.Fatalf("%s: mismatches: %v %v", name, mismatches, err)
+		}
+	}
+}
+
+func TestStripTSCommentsKeepsStrings(t *testing.T) {
+	got := stripTSComments("const a = \"http://x\"; // note\nconst b = '/* no */'; /* yes */ const c = `//`;")
+	want := "const a = \"http://x\"; \nconst b = '/* no */';   const c = `//`;"
+	if got != want {
+		t.Fatalf("got %q", got)
+	}
+}
@@ -0,0 +1,116 @@
+package alloy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+)
+
+const DefaultPropsWarnSize = 256 << 10
+
+type PropsLimitAction string
+
+const (
+	PropsLimitFail     PropsLimitAction = "fail"
+	PropsLimitTruncate PropsLimitAction = "truncate"
+)
+
+var ErrPropsTooLarge = errors.New("props too large")
+
+var propsSizeBuckets = []float64{1 << 10, 8 << 10, 32 << 10, 128 << 10, 512 << 10, 2 << 20, 8 << 20}
+
+func propsLimits() (warn int, max int, action PropsLimitAction) {
+	cfg := getConfig()
+	if cfg == nil {
+		return DefaultPropsWarnSize, 0, PropsLimitFail
+	}
+	warn, max, action = cfg.PropsWarnSize, cfg.PropsMaxSize, cfg.PropsLimitAction
+	if warn == 0 {
+		warn = DefaultPropsWarnSize
+	}
+	if action == "" {
+		action = PropsLimitFail
+	}
+	return warn, max, action
+}
+
+type encodedPropsKey struct{}
+
+type encodedProps struct {
+	props map[string]any
+	keys  int
+	data  []byte
+}
+
+func checkPropsSize(r *http.Request, component string, props map[string]any) (*http.Request, map[string]any, error) {
+	data, err := json.Marshal(props)
+	if err != nil {
+		return r, props, nil
+	}
+	size := len(data)
+	page := pageName(component)
+	metrics.propsBytes.observe(float64(size), page)
+
+	warn, max, action := propsLimits()
+	if warn > 0 && size > warn {
+		logger().WarnContext(r.Context(), "large props", "page", page, "path", r.URL.Path, "bytes", size, "warn", warn)
+	}
+	if max <= 0 || size <= max {
+		return withEncodedProps(r, props, data), props, nil
+	}
+
+	if action != PropsLimitTruncate {
+		return r, props, fmt.Errorf("🔴 %w: %s props are %d bytes, limit is %d", ErrPropsTooLarge, page, size, max)
+	}
+	truncated, dropped := truncateProps(props, size, max)
+	logger().WarnContext(r.Context(), "truncated props", "page", page, "path", r.URL.Path, "bytes", size, "max", max, "dropped", dropped)
+	if data, err := json.Marshal(truncated); err == nil {
+		r = withEncodedProps(r, truncated, data)
+	}
+	return r, truncated, nil
+}
+
+func withEncodedProps(r *http.Request, props map[string]any, data []byte) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), encodedPropsKey{}, &encodedProps{props: props, keys: len(props), data: data}))
+}
+
+func encodedPropsFrom(ctx context.Context) *encodedProps {
+	enc, _ := ctx.Value(encodedPropsKey{}).(*encodedProps)
+	return enc
+}
+
+func (e *encodedProps) marshal(props map[string]any) ([]byte, error) {
+	if e != nil && e.keys == len(props) && reflect.ValueOf(e.props).UnsafePointer() == reflect.ValueOf(props).UnsafePointer() {
+		return e.data, nil
+	}
+	return json.Marshal(props)
+}
+
+func truncateProps(props map[string]any, size int, max int) (map[string]any, []string) {
+	sizes := map[string]int{}
+	for key, value := range props {
+		data, _ := json.Marshal(value)
+		sizes[key] = len(data) + len(key) + 4
+	}
+	keys := sortedKeys(sizes)
+	slices.SortStableFunc(keys, func(a, b string) int { return sizes[b] - sizes[a] })
+
+	truncated := make(map[string]any, len(props))
+	for key, value := range props {
+		truncated[key] = value
+	}
+	var dropped []string
+	for _, key := range keys {
+		if size <= max {
+			break
+		}
+		delete(truncated, key)
+		size -= sizes[key]
+		dropped = append(dropped, key)
+	}
+	return truncated, dropped
+}
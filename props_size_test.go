@@ -0,0 +1,99 @@
+package alloy
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCheckPropsSizeWarnsAboveSoftLimit(t *testing.T) {
+	var logs bytes.Buffer
+	withTestConfig(t, func(cfg *Config) {
+		cfg.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+		cfg.PropsWarnSize = 64
+	})
+
+	r := httptest.NewRequest("GET", "/report", nil)
+	props := map[string]any{"rows": strings.Repeat("x", 100)}
+	_, got, err := checkPropsSize(r, "app/pages/report.tsx", props)
+	if err != nil || len(got) != 1 {
+		t.Fatalf("🔴 soft limit should not fail: %v", err)
+	}
+	if !strings.Contains(logs.String(), "large props") || !strings.Contains(logs.String(), "page=report") {
+		t.Fatalf("🔴 expected warning, got %q", logs.String())
+	}
+}
+
+func TestCheckPropsSizeFailsAboveHardLimit(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) {
+		cfg.PropsWarnSize = -1
+		cfg.PropsMaxSize = 64
+	})
+
+	r := httptest.NewRequest("GET", "/report", nil)
+	_, _, err := checkPropsSize(r, "app/pages/report.tsx", map[string]any{"rows": strings.Repeat("x", 100)})
+	if !errors.Is(err, ErrPropsTooLarge) {
+		t.Fatalf("🔴 expected ErrPropsTooLarge, got %v", err)
+	}
+}
+
+func TestCheckPropsSizeTruncatesLargestKeys(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) {
+		cfg.PropsWarnSize = -1
+		cfg.PropsMaxSize = 250
+		cfg.PropsLimitAction = PropsLimitTruncate
+	})
+
+	r := httptest.NewRequest("GET", "/report", nil)
+	props := map[string]any{
+		"title": "Report",
+		"rows":  strings.Repeat("x", 300),
+		"chart": strings.Repeat("y", 150),
+		"notes": strings.Repeat("z", 20),
+	}
+	_, got, err := checkPropsSize(r, "app/pages/report.tsx", props)
+	if err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+	keys := sortedKeys(got)
+	if !slices.Equal(keys, []string{"chart", "notes", "title"}) {
+		t.Fatalf("🔴 expected only rows dropped, got %v", keys)
+	}
+	if len(props) != 4 {
+		t.Fatal("🔴 loader props must not be modified")
+	}
+}
+
+func TestCheckPropsSizeEncodesPropsOnce(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) {})
+
+	props := map[string]any{"name": "real"}
+	r, got, err := checkPropsSize(httptest.NewRequest("GET", "/report", nil), "app/pages/report.tsx", props)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc := encodedPropsFrom(r.Context())
+	if data, _ := enc.marshal(got); enc == nil || string(data) != `{"name":"real"}` || &data[0] != &enc.data[0] {
+		t.Fatalf("🔴 expected the encoded props to be reused, got %s", data)
+	}
+
+	enc.data = []byte(`{"name":"encoded"}`)
+	vm, err := newRuntimeWithContext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeRuntime(vm)
+	out, err := runSSR(r.Context(), vm.ctx, `var __Component = function(props) { return props.name; };`, got)
+	if err != nil || out.HTML != "encoded" {
+		t.Fatalf("🔴 runSSR should use the encoded props, got %q %v", out.HTML, err)
+	}
+
+	got["extra"] = true
+	if data, _ := enc.marshal(got); !strings.Contains(string(data), "extra") {
+		t.Fatalf("🔴 changed props should be encoded again, got %s", data)
+	}
+}
@@ -0,0 +1,105 @@
+package alloy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGeneratePropsTypes(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "loader", "docs.go"), `package loader
+
+import "time"
+
+type Base struct {
+	Title string `+"`json:\"title\"`"+`
+}
+
+//alloy:props docs
+type DocsProps struct {
+	Base
+	Slug      string            `+"`json:\"slug\"`"+`
+	Views     int               `+"`json:\"views,omitempty\"`"+`
+	Published time.Time         `+"`json:\"published\"`"+`
+	Author    *Author           `+"`json:\"author\"`"+`
+	Tags      []string          `+"`json:\"tags\"`"+`
+	Meta      map[string]any    `+"`json:\"meta\"`"+`
+	Status    Status            `+"`json:\"status\"`"+`
+	ID        int64             `+"`json:\"id,string\"`"+`
+	Secret    string            `+"`json:\"-\"`"+`
+	internal  string
+}
+
+type Author struct {
+	Name string
+}
+
+type Status string
+`)
+
+	files, err := GeneratePropsTypes(root, filepath.Join(root, "app", "pages"))
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	out := string(files[filepath.Join(root, "app", "pages", "docs.props.d.ts")])
+	for _, want := range []string{
+		"DO NOT EDIT",
+		"export interface DocsProps {",
+		"  title: string;",
+		"  slug: string;",
+		"  views?: number;",
+		"  published: string;",
+		"  author?: Author | null;",
+		"  tags: string[];",
+		"  meta: Record<string, unknown>;",
+		"  status: Status;",
+		"  id: string;",
+		"export interface Author {\n  Name: string;\n}",
+		"export type Status = string;",
+		"export type Props = DocsProps;",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("props types missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Secret") || strings.Contains(out, "internal") {
+		t.Fatalf("props types include hidden fields:\n%s", out)
+	}
+}
+
+func TestGeneratePropsTypesSkipsIgnoredDirs(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "loader", "docs.go"), "package loader\n\n//alloy:props docs\ntype DocsProps struct{}\n")
+	for _, dir := range []string{"testdata", "_old", ".cache"} {
+		writeTestFile(t, filepath.Join(root, "loader", dir, "broken.go"), "package broken\n\n//alloy:props "+dir+"\ntype Broken struct{ oops\n")
+	}
+	writeTestFile(t, filepath.Join(root, "loader", "_draft.go"), "not go")
+
+	files, err := GeneratePropsTypes(root, filepath.Join(root, "app", "pages"))
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("files = %v", files)
+	}
+}
+
+func TestTypedLoader(t *testing.T) {
+	type props struct {
+		Name  string    `json:"name"`
+		Count int       `json:"count"`
+		When  time.Time `json:"when"`
+	}
+	loader := TypedLoader(func(r *http.Request) props {
+		return props{Name: r.URL.Query().Get("name"), Count: 2}
+	})
+
+	got := loader(httptest.NewRequest(http.MethodGet, "/?name=ada", nil))
+	if got["name"] != "ada" || got["count"] != float64(2) || got["when"] == nil {
+		t.Fatalf("props: %v", got)
+	}
+}
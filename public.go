@@ -0,0 +1,145 @@
+package alloy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const publicManifestName = "public-manifest.json"
+
+var cssURLPattern = regexp.MustCompile(`url\(\s*(['"]?)([^'")\s]+)(['"]?)\s*\)`)
+
+var publicRefAttrs = map[string][]string{
+	"link": {"href"},
+	"meta": {"content"},
+}
+
+func FingerprintPublic(publicDir string, distDir string) (map[string]string, error) {
+	absDist, err := resolveAbsPath(distDir, "dist dir")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.RemoveAll(filepath.Join(absDist, "public")); err != nil {
+		return nil, fmt.Errorf("🔴 clear public copies: %w", err)
+	}
+	prefix := distURLPrefix(absDist)
+
+	public := map[string]string{}
+	err = filepath.WalkDir(publicDir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(publicDir, file)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if hasDotfile(rel) {
+			return nil
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("🔴 read %s: %w", FormatPath(file), err)
+		}
+
+		ext := path.Ext(rel)
+		hashed := path.Join("public", fmt.Sprintf("%s-%s%s", strings.TrimSuffix(rel, ext), shortHash(string(data)), ext))
+		out := filepath.Join(absDist, filepath.FromSlash(hashed))
+		if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+			return fmt.Errorf("🔴 make dir: %w", err)
+		}
+		if err := os.WriteFile(out, data, 0644); err != nil {
+			return fmt.Errorf("🔴 write %s: %w", FormatPath(out), err)
+		}
+		public["/"+rel] = "/" + path.Join(prefix, hashed)
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return public, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(public, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("🔴 encode public manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(absDist, publicManifestName), data, 0644); err != nil {
+		return nil, fmt.Errorf("🔴 write public manifest: %w", err)
+	}
+	return public, nil
+}
+
+func ReadPublicManifest(filesystem fs.FS, dist string) (map[string]string, error) {
+	data, err := fs.ReadFile(filesystem, path.Join(filepath.ToSlash(dist), publicManifestName))
+	if err != nil {
+		return nil, fmt.Errorf("🔴 read public manifest: %w", err)
+	}
+	var public map[string]string
+	if err := json.Unmarshal(data, &public); err != nil {
+		return nil, fmt.Errorf("🔴 decode public manifest: %w", err)
+	}
+	return public, nil
+}
+
+func RewritePublicRefs(css string, public map[string]string) string {
+	if len(public) == 0 {
+		return css
+	}
+	return cssURLPattern.ReplaceAllStringFunc(css, func(match string) string {
+		parts := cssURLPattern.FindStringSubmatch(match)
+		hashed, ok := publicRef(public, parts[2])
+		if !ok {
+			return match
+		}
+		return "url(" + parts[1] + hashed + parts[3] + ")"
+	})
+}
+
+func PublicAsset(p string) string {
+	cfg := getConfig()
+	if cfg == nil {
+		return p
+	}
+	if hashed, ok := publicRef(cfg.publicAssets, p); ok {
+		return hashed
+	}
+	return p
+}
+
+func publicRef(public map[string]string, ref string) (string, bool) {
+	name, suffix := ref, ""
+	if i := strings.IndexAny(ref, "?#"); i >= 0 {
+		name, suffix = ref[:i], ref[i:]
+	}
+	hashed, ok := public[name]
+	if !ok {
+		return "", false
+	}
+	return AssetURL(hashed) + suffix, true
+}
+
+func rewriteHeadTagRefs(tag HeadTag) HeadTag {
+	cfg := getConfig()
+	if cfg == nil || len(cfg.publicAssets) == 0 {
+		return tag
+	}
+	for _, attr := range publicRefAttrs[tag.Tag] {
+		hashed, ok := publicRef(cfg.publicAssets, tag.Attrs[attr])
+		if !ok {
+			continue
+		}
+		tag.Attrs = maps.Clone(tag.Attrs)
+		tag.Attrs[attr] = hashed
+	}
+	return tag
+}
@@ -0,0 +1,74 @@
+package alloy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFingerprintPublicRewritesRefs(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "public", "images", "og.png"), "png")
+	writeTestFile(t, filepath.Join(root, "public", "favicon.ico"), "ico")
+	writeTestFile(t, filepath.Join(root, "public", ".env"), "SECRET=1")
+	dist := filepath.Join(root, "dist", "build")
+
+	public, err := FingerprintPublic(filepath.Join(root, "public"), dist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashed := public["/images/og.png"]
+	if len(public) != 2 || !strings.HasPrefix(hashed, "/dist/build/public/images/og-") || !isHashedAsset(hashed) {
+		t.Fatalf("🔴 public manifest = %v", public)
+	}
+	if data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(hashed, "/")))); err != nil || string(data) != "png" {
+		t.Fatalf("🔴 expected hashed copy, got %q, %v", data, err)
+	}
+
+	css := RewritePublicRefs(`.hero{background:url("/images/og.png?v=1")}.x{background:url(/missing.png)}`, public)
+	if css != `.hero{background:url("`+hashed+`?v=1")}.x{background:url(/missing.png)}` {
+		t.Fatalf("🔴 css = %s", css)
+	}
+
+	read, err := ReadPublicManifest(os.DirFS(root), "dist/build")
+	if err != nil || read["/favicon.ico"] != public["/favicon.ico"] {
+		t.Fatalf("🔴 ReadPublicManifest = %v, %v", read, err)
+	}
+	withTestConfig(t, func(cfg *Config) {
+		cfg.publicAssets = read
+	})
+	if PublicAsset("/images/og.png") != hashed || PublicAsset("/other.png") != "/other.png" {
+		t.Fatalf("🔴 PublicAsset = %s", PublicAsset("/images/og.png"))
+	}
+
+	meta := []any{map[string]any{"property": "og:image", "content": "/images/og.png"}}
+	head := renderHead(map[string]any{"meta": meta}, nil, []HeadTag{{Tag: "link", Attrs: map[string]string{"rel": "icon", "href": "/favicon.ico"}}}, "")
+	if !strings.Contains(head, `content="`+hashed+`"`) || !strings.Contains(head, `href="`+public["/favicon.ico"]+`"`) {
+		t.Fatalf("🔴 head = %s", head)
+	}
+}
+
+func TestFingerprintPublicUsesDistDir(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "public", "logo.svg"), "v1")
+	dist := filepath.Join(root, "site", "static")
+
+	first, err := FingerprintPublic(filepath.Join(root, "public"), dist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(first["/logo.svg"], "/site/static/public/logo-") {
+		t.Fatalf("🔴 public manifest = %v", first)
+	}
+
+	writeTestFile(t, filepath.Join(root, "public", "logo.svg"), "v2")
+	second, err := FingerprintPublic(filepath.Join(root, "public"), dist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(filepath.Join(dist, "public"))
+	if err != nil || len(entries) != 1 || "/site/static/public/"+entries[0].Name() != second["/logo.svg"] {
+		t.Fatalf("🔴 expected only the current copy, got %v, %v", entries, err)
+	}
+}
@@ -0,0 +1,87 @@
+package alloy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const maxRemoteResponse = 32 << 20
+
+type RemoteRenderer struct {
+	URL    string
+	Client *http.Client
+	Header http.Header
+}
+
+func NewRemoteRenderer(url string) *RemoteRenderer {
+	return &RemoteRenderer{URL: url}
+}
+
+func (rr *RemoteRenderer) Render(ctx context.Context, req RenderRequest) (RenderResponse, error) {
+	code := req.Code
+	req.Code = ""
+
+	out, status, err := rr.post(ctx, req)
+	if err == nil && status == http.StatusNotFound && code != "" {
+		req.Code = code
+		out, status, err = rr.post(ctx, req)
+	}
+	if err != nil {
+		return RenderResponse{}, err
+	}
+
+	switch {
+	case status == http.StatusOK:
+		return out, nil
+	case out.Error != "" && status == http.StatusUnprocessableEntity:
+		return RenderResponse{}, &renderError{message: out.Error}
+	case out.Error != "":
+		return RenderResponse{}, fmt.Errorf("🔴 remote renderer %s: %d %s", rr.URL, status, out.Error)
+	default:
+		return RenderResponse{}, fmt.Errorf("🔴 remote renderer %s: %d %s", rr.URL, status, http.StatusText(status))
+	}
+}
+
+func (rr *RemoteRenderer) post(ctx context.Context, req RenderRequest) (RenderResponse, int, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return RenderResponse{}, 0, fmt.Errorf("🔴 marshal render request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, rr.URL, bytes.NewReader(body))
+	if err != nil {
+		return RenderResponse{}, 0, fmt.Errorf("🔴 remote renderer request: %w", err)
+	}
+	for key, values := range rr.Header {
+		httpReq.Header[key] = values
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+
+	client := rr.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(httpReq)
+	if err != nil && ctx.Err() != nil {
+		return RenderResponse{}, 0, fmt.Errorf("🔴 %w: %w", ErrRenderTimeout, ctx.Err())
+	}
+	if err != nil {
+		return RenderResponse{}, 0, fmt.Errorf("🔴 remote renderer %s: %w", rr.URL, err)
+	}
+	defer res.Body.Close()
+
+	var out RenderResponse
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxRemoteResponse))
+	if err != nil {
+		return RenderResponse{}, 0, fmt.Errorf("🔴 read remote render: %w", err)
+	}
+	if len(data) > 0 && json.Unmarshal(data, &out) != nil && res.StatusCode == http.StatusOK {
+		return RenderResponse{}, 0, fmt.Errorf("🔴 decode remote render: %s", data)
+	}
+	return out, res.StatusCode, nil
+}
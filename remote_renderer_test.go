@@ -0,0 +1,100 @@
+package alloy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func fakeRenderFarm(t *testing.T) (*httptest.Server, *[]RenderRequest) {
+	t.Helper()
+	var (
+		mu       sync.Mutex
+		bundles  = map[string]string{}
+		requests []RenderRequest
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req RenderRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		requests = append(requests, req)
+		if req.Code != "" {
+			bundles[req.Bundle] = req.Code
+		}
+		_, known := bundles[req.Bundle]
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case !known:
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(RenderResponse{Error: "unknown bundle"})
+		case req.Props["fail"] == true:
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(RenderResponse{Error: "ReferenceError: window is not defined"})
+		default:
+			json.NewEncoder(w).Encode(RenderResponse{
+				HTML: "<h1>" + req.Props["name"].(string) + "</h1>",
+				Head: []HeadTag{{Tag: "meta", Attrs: map[string]string{"name": "rendered-by", "content": "farm"}}},
+			})
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestRemoteRendererUploadsBundleOnDemand(t *testing.T) {
+	srv, requests := fakeRenderFarm(t)
+	renderer := NewRemoteRenderer(srv.URL)
+	req := RenderRequest{Bundle: bundleID("bundle"), Code: "bundle", Props: map[string]any{"name": "Ada"}}
+
+	for range 2 {
+		out, err := renderer.Render(t.Context(), req)
+		if err != nil {
+			t.Fatalf("render: %v", err)
+		}
+		if out.HTML != "<h1>Ada</h1>" || len(out.Head) != 1 {
+			t.Fatalf("🔴 unexpected response: %+v", out)
+		}
+	}
+
+	var withCode int
+	for _, r := range *requests {
+		if r.Code != "" {
+			withCode++
+		}
+	}
+	if len(*requests) != 3 || withCode != 1 {
+		t.Fatalf("🔴 expected code sent once after a miss, got %d requests (%d with code)", len(*requests), withCode)
+	}
+}
+
+func TestRemoteRendererReportsComponentErrors(t *testing.T) {
+	srv, _ := fakeRenderFarm(t)
+	_, err := NewRemoteRenderer(srv.URL).Render(t.Context(), RenderRequest{Bundle: "b", Code: "x", Props: map[string]any{"fail": true}})
+	if !isRenderError(err) || !strings.Contains(err.Error(), "window is not defined") {
+		t.Fatalf("🔴 expected render error, got %v", err)
+	}
+}
+
+func TestConfigRemoteRendererAddsHeadTags(t *testing.T) {
+	srv, _ := fakeRenderFarm(t)
+	withTestConfig(t, func(cfg *Config) { cfg.RemoteRenderer = srv.URL })
+
+	out, err := executeSSR(t.Context(), "var __Component = {};", map[string]any{"name": "Remote"})
+	if err != nil {
+		t.Fatalf("executeSSR: %v", err)
+	}
+	result := prebuiltResult(out.HTML, map[string]any{"title": "Remote"}, PrebuiltFiles{Client: "client-home-AAAAAAAA.js", CSS: "shared.css"})
+	result.Head = out.Head
+	html := result.ToHTML("home-root")
+	if !strings.Contains(html, "<h1>Remote</h1>") || !strings.Contains(html, `content="farm"`) {
+		t.Fatalf("🔴 expected remote html and head tags: %s", html)
+	}
+}
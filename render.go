@@ -2,6 +2,7 @@ package alloy
 
 import (
 	"context"
+	"crypto/ed25519"
 	"crypto/sha1"
 	"embed"
 	"encoding/json"
@@ -10,13 +11,15 @@ import (
 	"html"
 	"io"
 	"io/fs"
-	"maps"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/exec"
 	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -24,7 +27,10 @@ import (
 
 	"github.com/buke/quickjs-go"
 	"github.com/evanw/esbuild/pkg/api"
-	"golang.org/x/sync/errgroup"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 //go:embed assets/*
@@ -32,12 +38,21 @@ var assetsFS embed.FS
 
 var (
 	polyfillsSource     string
+	nodeShimsSource     string
 	htmlTemplate        string
 	entryTemplate       string
 	clientEntryTemplate string
 	renderTemplate      string
 	renderTimeout       atomic.Value
 	globalConfig        atomic.Value
+	runtimesCreated     atomic.Int64
+	runtimesClosed      atomic.Int64
+)
+
+var (
+	ErrComponentNotFound   = errors.New("component not found")
+	ErrBundleNotRegistered = errors.New("bundle not registered")
+	ErrRuntimeOnly         = errors.New("bundling disabled in runtime-only mode")
 )
 
 const (
@@ -61,29 +76,48 @@ type bundleCacheEntry struct {
 	prebuilt   bool
 }
 
-type manifestEntry struct {
-	Server string   `json:"server"`
-	Client string   `json:"client,omitempty"`
-	CSS    string   `json:"css"`
+type ManifestPage struct {
+	Server     string   `json:"server"`
+	Client     string   `json:"client,omitempty"`
+	CSS        string   `json:"css"`
+	Chunks     []string `json:"chunks,omitempty"`
+	Assets     []string `json:"assets,omitempty"`
+	HTML       string   `json:"html,omitempty"`
+	Legacy     string   `json:"legacy,omitempty"`
+	Render     string   `json:"render,omitempty"`
+	Revalidate int      `json:"revalidate,omitempty"`
+
+	Locale  string                    `json:"locale,omitempty"`
+	Locales map[string]ManifestLocale `json:"locales,omitempty"`
+}
+
+type ManifestLocale struct {
+	Client string   `json:"client"`
 	Chunks []string `json:"chunks,omitempty"`
+	Assets []string `json:"assets,omitempty"`
+	Legacy string   `json:"legacy,omitempty"`
 }
 
 type assetRoot struct {
 	prefix     string
+	dir        string
 	fs         fs.FS
 	fileServer http.Handler
 }
 
-type serverCtxInfo struct {
-	ctx api.BuildContext
-	tmp string
-}
-
 type PrebuiltFiles struct {
 	Server       string
 	Client       string
 	ClientChunks []string
 	CSS          string
+	Assets       []string
+	HTML         string
+	Legacy       string
+	RenderMode   RenderMode
+	Revalidate   time.Duration
+	Locale       string
+	Locales      map[string]LocaleFiles
+	ImportMap    map[string]string
 }
 
 type RenderResult struct {
@@ -93,12 +127,23 @@ type RenderResult struct {
 	Props       map[string]any
 	ClientPath  string
 	ClientPaths []string
+	LegacyPath  string
+	ImportMap   map[string]string
 	CSSPath     string
+	Head        []HeadTag
+	Layout      []HeadTag
+	Slots       map[string][]string
+	Nonce       string
+	Context     map[string]any
+
+	encoded *encodedProps
 }
 
 type ClientAssets struct {
-	Entry  string
-	Chunks []string
+	Entry   string
+	Chunks  []string
+	Assets  []string
+	Sources []string
 }
 
 type ClientEntry struct {
@@ -108,25 +153,111 @@ type ClientEntry struct {
 }
 
 type HeadTag struct {
-	Tag   string
-	Attrs map[string]string
-	Text  string
+	Tag   string            `json:"tag"`
+	Attrs map[string]string `json:"attrs,omitempty"`
+	Text  string            `json:"text,omitempty"`
 }
 
 type Config struct {
 	FS            fs.FS
 	DefaultTitle  string
+	TitleTemplate string
 	DefaultMeta   []HeadTag
 	AppDir        string
 	PagesDir      string
 	DistDir       string
 	RenderTimeout time.Duration
+
+	SecretPatterns  []*regexp.Regexp
+	Tsconfig        string
+	ServerExternals map[string]string
+	NodeShims       bool
+	BuildPlugins    []api.Plugin
+	PostCSSConfig   string
+	CSSMode         CSSMode
+	CSSInput        string
+	CSSEntries      []CSSEntry
+	CSSTransform    CSSTransform
+	CSSTargets      []string
+	VendorChunks    map[string][]string
+	ImportMap       map[string]string
+	LegacyTarget    string
+	LegacyPolyfills []string
+	Bundler         Bundler
+	RuntimeOnly     bool
+	ISRDir          string
+	ISRMaxEntries   int
+	HotRoutes       []string
+	HotSitemap      string
+	HotInterval     time.Duration
+	PreviewSecret   string
+	Renderer        Renderer
+	RemoteRenderer  string
+
+	RuntimePoolSize   int
+	RuntimeMaxRenders int
+	RuntimeMaxAge     time.Duration
+
+	MaxConcurrentRenders  int
+	RenderQueueTimeout    time.Duration
+	LoaderCacheMaxEntries int
+	LoaderPushMaxStreams  int
+
+	PropsWarnSize    int
+	PropsMaxSize     int
+	PropsLimitAction PropsLimitAction
+	PageETag         ETagMode
+	CSP              *CSP
+	Environment      Environment
+	ThemeCookie      string
+	Variant          VariantResolver
+	PrefetchLimit    int
+	LocalesDir       string
+	DefaultLocale    string
+	Locale           func(r *http.Request) string
+	Vary             []string
+	VaryNormalize    map[string]func(value string) string
+	Flags            FlagProvider
+	FlagKeys         []string
+	FlagsGlobal      bool
+	RequestContext   func(r *http.Request) map[string]any
+	Bindings         map[string]Binding
+	AssetURL         string
+	OnRenderError    func(ctx context.Context, err PageError)
+
+	Logger         *slog.Logger
+	TracerProvider trace.TracerProvider
+	Propagator     propagation.TextMapPropagator
+	Debug          bool
+
+	TailwindStandalone bool
+	TailwindVersion    string
+	TailwindBinary     string
+
+	ManifestPublicKey ed25519.PublicKey
+	Strict            bool
+
+	integrityErr error
+	publicAssets map[string]string
 }
 
 type PageHandler struct {
-	component string
-	loader    func(r *http.Request) map[string]any
-	ctx       func(r *http.Request) context.Context
+	component  string
+	loader     LoaderFunc
+	ctx        func(r *http.Request) context.Context
+	mode       RenderMode
+	revalidate time.Duration
+	meta       []HeadTag
+
+	prefetch    []string
+	prefetchSet bool
+	fragment    bool
+	bindings    map[string]Binding
+	coalesce    bool
+	loaderCache *loaderCache
+
+	loaderTimeout  time.Duration
+	loaderFallback func(r *http.Request) map[string]any
 }
 
 type PageSpec struct {
@@ -135,11 +266,40 @@ type PageSpec struct {
 	RootID    string
 }
 
-func AssetsMiddleware() func(http.Handler) http.Handler {
+func AssetsMiddleware(options ...func(*AssetConfig)) func(http.Handler) http.Handler {
+	ac := newAssetConfig(options)
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			cfg := getConfig()
-			if cfg.FS != nil && serveAsset(w, r, cfg.FS) {
+			start := time.Now()
+			sw := &statusWriter{ResponseWriter: w}
+			if noindex() {
+				w.Header().Set("X-Robots-Tag", "noindex")
+			}
+			if cfg.integrityErr != nil && strings.HasPrefix(r.URL.Path, "/"+path.Clean(filepath.ToSlash(cfg.DistDir))+"/") {
+				http.Error(w, cfg.integrityErr.Error(), http.StatusServiceUnavailable)
+				return
+			}
+			if r.URL.Path == DevStatusPath && os.Getenv("ALLOY_DEV") == "1" {
+				DevStatusHandler().ServeHTTP(w, r)
+				return
+			}
+			if (r.URL.Path == DebugPath || strings.HasPrefix(r.URL.Path, DebugPath+"/")) && debugEnabled() {
+				DebugHandler().ServeHTTP(w, r)
+				return
+			}
+			if r.URL.Path == DevPropsPath && os.Getenv("ALLOY_DEV") == "1" {
+				devPropsHandler(next).ServeHTTP(w, r)
+				return
+			}
+			if cfg.FS != nil && serveAsset(sw, r, cfg.FS, ac) {
+				metrics.assetRequests.inc(strconv.Itoa(sw.Status()))
+				metrics.assetBytes.add(float64(sw.bytes))
+				logger().LogAttrs(r.Context(), slog.LevelDebug, "asset",
+					slog.String("path", r.URL.Path),
+					slog.Int("status", sw.Status()),
+					slog.Duration("duration", time.Since(start)),
+				)
 				return
 			}
 			next.ServeHTTP(w, r)
@@ -147,39 +307,6 @@ func AssetsMiddleware() func(http.Handler) http.Handler {
 	}
 }
 
-func serveAsset(w http.ResponseWriter, r *http.Request, filesystem fs.FS) bool {
-	isAllowedMethod := r.Method == http.MethodGet || r.Method == http.MethodHead
-	if !isAllowedMethod {
-		return false
-	}
-
-	assetPath := normalizeAssetPath(r.URL.Path)
-	if assetPath == "" {
-		return false
-	}
-
-	roots := collectAssetRoots(filesystem)
-	for _, root := range roots {
-		rel, ok := root.match(assetPath)
-		if !ok {
-			continue
-		}
-		if !root.assetExists(rel) {
-			continue
-		}
-
-		fullPath := assetPath
-		if root.prefix != "" {
-			fullPath = path.Join(root.prefix, rel)
-		}
-		addCacheHeaders(w, fullPath, root, rel)
-		root.serve(w, r, rel)
-		return true
-	}
-
-	return false
-}
-
 func init() {
 	loadEmbeddedAssets()
 	renderTimeout.Store(defaultRenderTimeout)
@@ -194,6 +321,7 @@ func init() {
 
 func loadEmbeddedAssets() {
 	polyfillsSource = MustReadAsset("assets/polyfills.js")
+	nodeShimsSource = MustReadAsset("assets/node-shims.js")
 	htmlTemplate = MustReadAsset("assets/html-template.html")
 	entryTemplate = MustReadAsset("assets/server-entry.tsx")
 	clientEntryTemplate = MustReadAsset("assets/client-entry.tsx")
@@ -225,11 +353,51 @@ func Init(filesystem fs.FS, options ...func(*Config)) {
 		}
 	}
 
+	if unpacked, err := unpackFS(cfg.FS, cfg.DistDir); err != nil {
+		log := cfg.Logger
+		if log == nil {
+			log = slog.Default()
+		}
+		log.Error("unpack dist", "dist", cfg.DistDir, "err", err)
+	} else {
+		cfg.FS = unpacked
+	}
+
+	if cfg.FS != nil && os.Getenv("ALLOY_DEV") != "1" {
+		if public, err := ReadPublicManifest(cfg.FS, cfg.DistDir); err == nil {
+			cfg.publicAssets = public
+		}
+	}
+
+	if cfg.ManifestPublicKey != nil && cfg.FS != nil {
+		if err := VerifyManifest(cfg.FS, cfg.DistDir, cfg.ManifestPublicKey); err != nil {
+			cfg.integrityErr = err
+			log := cfg.Logger
+			if log == nil {
+				log = slog.Default()
+			}
+			log.Error("verify dist", "dist", cfg.DistDir, "err", err)
+		}
+	}
+
 	if cfg.RenderTimeout > 0 {
 		renderTimeout.Store(cfg.RenderTimeout)
 	}
 
 	globalConfig.Store(cfg)
+
+	if os.Getenv("ALLOY_DEV") != "1" {
+		strictPreload(cfg)
+	}
+}
+
+func SaveConfig() (restore func()) {
+	prev := getConfig()
+	timeout := renderTimeout.Load()
+	return func() {
+		renderTimeout.Store(timeout)
+		globalConfig.Store(prev)
+	}
 }
 
 func getConfig() *Config {
@@ -244,16 +412,69 @@ func NewPage(component string) *PageHandler {
 }
 
 func (h *PageHandler) WithLoader(loader func(r *http.Request) map[string]any) *PageHandler {
-	h.loader = loader
+	h.loader = func(ctx context.Context, r *http.Request) (map[string]any, error) {
+		return loader(r), nil
+	}
+	return h
+}
+
+func (h *PageHandler) WithContext(fn func(r *http.Request) context.Context) *PageHandler {
+	h.ctx = fn
 	return h
 }
 
 func (h *PageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	ctx, span := startSpan(extractTraceContext(r), "alloy.render",
+		attribute.String("alloy.page", pageName(h.component)),
+		attribute.String("url.path", r.URL.Path),
+	)
+	r = withPageDebug(r.WithContext(ctx), h.component)
+	if h.ctx != nil {
+		r = r.WithContext(h.ctx(r))
+	}
+	if h.wantsLoaderPush(r) {
+		sw := &statusWriter{ResponseWriter: w}
+		h.serveLoaderPush(sw, r)
+		span.SetAttributes(attribute.Int("http.response.status_code", sw.Status()))
+		span.End()
+		logRender(r, h.component, sw.Status(), start)
+		return
+	}
+	r = withCSP(w, r)
+	r = withLayoutMeta(r, h.meta)
+	if noindex() {
+		w.Header().Set("X-Robots-Tag", "noindex")
+	}
+
+	sw := &statusWriter{ResponseWriter: w}
+	if mode := pageETagMode(); mode != ETagOff && CSPNonce(r) == "" {
+		ew := &etagWriter{ResponseWriter: sw}
+		h.servePage(ew, r)
+		ew.finish(r, mode)
+	} else {
+		h.servePage(sw, r)
+	}
+
+	span.SetAttributes(attribute.Int("http.response.status_code", sw.Status()))
+	if sw.Status() >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(sw.Status()))
+	}
+	span.End()
+	recordRender(h.component, sw.Status(), time.Since(start))
+	recordRequestInfo(r, h.component, time.Since(start))
+	logRender(r, h.component, sw.Status(), start)
+}
+
+func (h *PageHandler) servePage(w http.ResponseWriter, r *http.Request) {
 	cfg := getConfig()
+	if cfg.integrityErr != nil {
+		http.Error(w, cfg.integrityErr.Error(), http.StatusServiceUnavailable)
+		return
+	}
 	rootID := defaultRootID(h.component)
-	props := map[string]any{}
-	if h.loader != nil {
-		props = h.loader(r)
+	if id := BuildID(); id != "" {
+		w.Header().Set(BuildHeader, id)
 	}
 
 	files, err := resolvePrebuiltFiles(cfg.FS, h.component)
@@ -261,6 +482,71 @@ func (h *PageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	pageDebugFrom(r.Context()).record(func(d *pageDebug) { d.files = files })
+	r = h.withPrefetch(r, files)
+
+	mode := h.mode
+	if mode == "" {
+		mode = files.RenderMode
+	}
+	revalidate := h.revalidate
+	if revalidate == 0 {
+		revalidate = files.Revalidate
+	}
+	r = withVary(w, r)
+	addVary(w.Header(), DataHeader)
+	r, preview := withPreview(r)
+	if preview {
+		w.Header().Set("Cache-Control", "private, no-store")
+	}
+	r = withVariant(w, r)
+	r = r.WithContext(WithBindings(r.Context(), h.bindings))
+	data := wantsLoaderData(r)
+
+	if isHotRequest(r) && revalidate == 0 {
+		revalidate = hotRoutesInterval()
+	}
+	isr := (mode == RenderModeStatic && revalidate > 0 || isHotRequest(r)) && !preview && !h.fragment && !data && os.Getenv("ALLOY_DEV") != "1"
+	_, edited := devProps(r.Context())
+	if !isr && mode == RenderModeStatic && files.HTML != "" && !preview && !edited && !h.fragment && !data && Variant(r) == "" && serveStaticHTML(w, r, cfg.FS, files.HTML) {
+		return
+	}
+
+	r = withRouteContext(withRenderContext(withFlags(r)))
+	r, files = withLocale(w, r, files)
+	if isr {
+		h.serveISR(w, r, files, rootID, revalidate)
+		return
+	}
+	if h.coalesce && canCoalesce(r) && !preview && !edited && !h.fragment && !data && mode != RenderModeClient {
+		h.serveCoalesced(w, r, files, rootID)
+		return
+	}
+	props, err := h.loadProps(r)
+	if err != nil {
+		writeRenderError(w, err)
+		return
+	}
+	r, props, err = checkPropsSize(r, h.component, withTheme(w, r, withFlagProps(r, withVariantProps(r, props))))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if data {
+		serveLoaderData(w, r, props)
+		return
+	}
+
+	if h.fragment {
+		ServeFragment(w, r, h.component, props)
+		return
+	}
+
+	if mode == RenderModeClient && files.Client != "" {
+		ServeClientShell(w, r, props, rootID, files)
+		return
+	}
 
 	if files.Server != "" {
 		if err := RegisterPrebuiltBundleFromFS(h.component, rootID, cfg.FS, files); err != nil {
@@ -284,7 +570,25 @@ func newRuntimeWithContext() (*jsRuntime, error) {
 		rt.Close()
 		return nil, err
 	}
+	if err := loadServerExternals(ctx); err != nil {
+		ctx.Close()
+		rt.Close()
+		return nil, err
+	}
+	if err := loadBindings(ctx); err != nil {
+		ctx.Close()
+		rt.Close()
+		return nil, err
+	}
+	if err := loadNodeShims(ctx); err != nil {
+		ctx.Close()
+		rt.Close()
+		return nil, err
+	}
+	snapshot := ctx.Eval("__alloyGlobals.snapshot()")
+	snapshot.Free()
 
+	runtimesCreated.Add(1)
 	return &jsRuntime{
 		rt:  rt,
 		ctx: ctx,
@@ -295,11 +599,13 @@ func closeRuntime(vm *jsRuntime) {
 	if vm == nil {
 		return
 	}
+	forgetRuntimeMemory(vm)
 	if vm.ctx != nil {
 		vm.ctx.Close()
 	}
 	if vm.rt != nil {
 		vm.rt.Close()
+		runtimesClosed.Add(1)
 	}
 }
 
@@ -320,34 +626,46 @@ func loadPolyfills(ctx *quickjs.Context) error {
 func ServePage(w http.ResponseWriter, r *http.Request, componentPath string, props map[string]any, rootID string) {
 	result, err := RenderTSXFileWithHydrationWithContext(r.Context(), componentPath, props, rootID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		reportRenderError(r, componentPath, props, err)
+		writeRenderError(w, err)
 		return
 	}
+	result.Nonce = CSPNonce(r)
+	result.Layout = layoutMeta(r)
+	result.Context = RenderContext(r.Context())
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	fmt.Fprint(w, result.ToHTML(rootID))
+	writeHTML(r.Context(), w, result.ToHTML(rootID))
 }
 
 func ServePageWithContext(w http.ResponseWriter, r *http.Request, componentPath string, props map[string]any, rootID string) {
 	result, err := RenderTSXFileWithHydrationWithContext(r.Context(), componentPath, props, rootID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		reportRenderError(r, componentPath, props, err)
+		writeRenderError(w, err)
 		return
 	}
+	result.Nonce = CSPNonce(r)
+	result.Layout = layoutMeta(r)
+	result.Context = RenderContext(r.Context())
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	fmt.Fprint(w, result.ToHTML(rootID))
+	writeHTML(r.Context(), w, result.ToHTML(rootID))
 }
 
 func ServePrebuiltPage(w http.ResponseWriter, r *http.Request, componentPath string, props map[string]any, rootID string, files PrebuiltFiles) {
 	result, err := RenderPrebuiltWithContext(r.Context(), componentPath, props, rootID, files)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		reportRenderError(r, componentPath, props, err)
+		writeRenderError(w, err)
 		return
 	}
+	result.Nonce = CSPNonce(r)
+	result.Layout = layoutMeta(r)
+	result.Context = RenderContext(r.Context())
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	fmt.Fprint(w, result.ToHTML(rootID))
+	writeHTML(r.Context(), w, result.ToHTML(rootID))
 }
 
 func RegisterPrebuiltBundle(componentPath string, rootID string, serverJS string, clientJS string, css string) error {
@@ -399,15 +717,17 @@ func (r *RenderResult) ToHTML(rootID string) string {
 		return r.HTML
 	}
 
-	propsJSON, err := json.Marshal(r.Props)
+	propsJSON, err := r.encoded.marshal(r.Props)
 	if err != nil {
 		propsJSON = []byte("{}")
 	}
-	head := buildHead(r.Props)
+	head := renderHead(r.Props, r.Layout, r.Head, r.Nonce) + r.importMapTag() + slotHTML(r.Slots, SlotHead, "\n\t", r.nonceAttr())
 	cssTag := r.buildCSSTag()
 	scriptTag := r.buildScriptTag()
 
-	return fmt.Sprintf(htmlTemplate, head, cssTag, rootID, r.HTML, rootID, string(propsJSON), scriptTag)
+	scriptTag = renderContextScript(r.Context) + scriptTag + slotHTML(r.Slots, SlotBodyEnd, "\n        ", r.nonceAttr())
+
+	return fmt.Sprintf(htmlTemplate, htmlAttrs(r.Props), head, cssTag, rootID, r.HTML, rootID, string(propsJSON), scriptTag)
 }
 
 func (r *RenderResult) buildCSSTag() string {
@@ -419,7 +739,7 @@ func (r *RenderResult) buildCSSTag() string {
 		}
 		return fmt.Sprintf("\n\t<link rel=\"stylesheet\" href=\"%s\" />", cssURL)
 	case r.CSS != "":
-		return fmt.Sprintf("\n\t<style>%s</style>", r.CSS)
+		return fmt.Sprintf("\n\t<style%s>%s</style>", r.nonceAttr(), r.CSS)
 	}
 	return ""
 }
@@ -436,6 +756,9 @@ func (r *RenderResult) buildScriptTag() string {
 			}
 			fmt.Fprintf(&b, "<script type=\"module\" src=\"%s\"></script>\n", scriptURL)
 		}
+		if r.LegacyPath != "" {
+			fmt.Fprintf(&b, "<script nomodule defer src=\"%s\"></script>\n", r.LegacyPath)
+		}
 		return strings.TrimSuffix(b.String(), "\n")
 	case r.ClientPath != "":
 		scriptURL := r.ClientPath
@@ -444,31 +767,54 @@ func (r *RenderResult) buildScriptTag() string {
 		}
 		return fmt.Sprintf(`<script type="module" src="%s"></script>`, scriptURL)
 	case r.ClientJS != "":
-		return fmt.Sprintf(`<script type="module">%s</script>`, r.ClientJS)
+		return fmt.Sprintf(`<script type="module"%s>%s</script>`, r.nonceAttr(), r.ClientJS)
 	}
 	return ""
 }
 
-func buildHead(props map[string]any) string {
+func (r *RenderResult) nonceAttr() string {
+	if r.Nonce == "" {
+		return ""
+	}
+	return fmt.Sprintf(` nonce="%s"`, r.Nonce)
+}
+
+func buildHead(props map[string]any, extra ...HeadTag) string {
+	return renderHead(props, nil, extra, "")
+}
+
+func renderHead(props map[string]any, layout []HeadTag, extra []HeadTag, nonce string) string {
 	var b strings.Builder
 
 	b.WriteString("\t<meta charset=\"UTF-8\">\n")
 	b.WriteString("\t<meta name=\"viewport\" content=\"width=device-width, initial-scale=1.0\">\n")
+	info := cachedBuildInfo()
+	fmt.Fprintf(&b, "\t<meta name=\"generator\" content=\"%s\">\n", html.EscapeString(info.generator()))
+	if id := info.ID(); id != "" {
+		fmt.Fprintf(&b, "\t<meta name=\"alloy-build\" content=\"%s\">\n", id)
+	}
+
+	var defaults, tags, forced []HeadTag
+	if cfg := getConfig(); cfg != nil {
+		defaults = cfg.DefaultMeta
+	}
+	if meta, ok := props["meta"].([]any); ok {
+		tags = parseMetaTags(meta)
+	}
+	if noindex() {
+		forced = append(forced, HeadTag{Tag: "meta", Attrs: map[string]string{"name": "robots", "content": "noindex"}})
+	}
+	merged := mergeHeadTags(defaults, layout, tags, extra, forced)
 
-	title := stringFromMap(props, "title")
-	if title == "" {
-		title = "Alloy"
+	title := pageTitle(props)
+	if i := slices.IndexFunc(merged, func(tag HeadTag) bool { return tag.Tag == "title" }); i >= 0 {
+		title = merged[i].Text
+		merged = slices.Delete(merged, i, i+1)
 	}
 	fmt.Fprintf(&b, "\t<title>%s</title>", html.EscapeString(title))
 
-	if meta, ok := props["meta"].([]any); ok {
-		for _, tag := range parseMetaTags(meta) {
-			fmt.Fprintf(&b, "\n\t<%s", tag.Tag)
-			for k, v := range tag.Attrs {
-				fmt.Fprintf(&b, " %s=\"%s\"", k, html.EscapeString(v))
-			}
-			b.WriteString(">")
-		}
+	for _, tag := range merged {
+		writeHeadTag(&b, rewriteHeadTagRefs(tag), nonce)
 	}
 
 	return b.String()
@@ -489,7 +835,7 @@ func parseMetaTags(meta []any) []HeadTag {
 
 		attrs := make(map[string]string)
 		for k, v := range m {
-			if k == "tag" {
+			if k == "tag" || k == "text" {
 				continue
 			}
 			if s, ok := v.(string); ok {
@@ -497,8 +843,9 @@ func parseMetaTags(meta []any) []HeadTag {
 			}
 		}
 
-		if len(attrs) > 0 {
-			tags = append(tags, HeadTag{Tag: tag, Attrs: attrs})
+		text := stringFromMap(m, "text")
+		if len(attrs) > 0 || text != "" {
+			tags = append(tags, HeadTag{Tag: tag, Attrs: attrs, Text: text})
 		}
 	}
 	return tags
@@ -516,24 +863,28 @@ func RenderTSXFileWithHydrationWithContext(ctx context.Context, filePath string,
 	}
 
 	if _, err := os.Stat(absPath); err != nil {
-		return nil, fmt.Errorf("🔴 component not found %s: %w", absPath, err)
+		return nil, fmt.Errorf("🔴 %w %s: %w", ErrComponentNotFound, absPath, err)
 	}
 
-	serverJS, clientJS, css := readBundlesFromCache(absPath, rootID)
+	serverJS, clientJS, css := lookupBundles(ctx, absPath, rootID)
 	if serverJS == "" || clientJS == "" || css == "" {
-		return nil, fmt.Errorf("🔴 component %s (rootID=%s) not registered; run 'alloy dev' or 'alloy build' first", absPath, rootID)
+		return nil, fmt.Errorf("🔴 component %s (rootID=%s): %w; run 'alloy dev' or 'alloy build' first", absPath, rootID, ErrBundleNotRegistered)
 	}
 
-	html, err := executeSSR(ctx, serverJS, props)
+	out, err := executeSSR(withEvalPage(ctx, absPath), serverJS, props)
 	if err != nil {
+		metrics.ssrErrors.inc(pageName(absPath))
 		return nil, fmt.Errorf("🔴 ssr failed for %s: %w", absPath, err)
 	}
 
 	return &RenderResult{
-		HTML:     html,
+		HTML:     out.HTML,
 		ClientJS: clientJS,
 		CSS:      css,
 		Props:    props,
+		Head:     out.Head,
+		Slots:    out.Slots,
+		encoded:  encodedPropsFrom(ctx),
 	}, nil
 }
 
@@ -547,24 +898,22 @@ func RenderPrebuiltWithContext(ctx context.Context, filePath string, props map[s
 		return nil, err
 	}
 
-	serverJS, clientJS, css := readBundlesFromCache(absPath, rootID)
+	serverJS, clientJS, css := lookupBundles(ctx, absPath, rootID)
 	if serverJS == "" || clientJS == "" || css == "" {
-		return nil, fmt.Errorf("🔴 component %s (rootID=%s) not registered; call RegisterPrebuiltBundleFromFS before serving", absPath, rootID)
+		return nil, fmt.Errorf("🔴 component %s (rootID=%s): %w; call RegisterPrebuiltBundleFromFS before serving", absPath, rootID, ErrBundleNotRegistered)
 	}
 
-	html, err := executeSSR(ctx, serverJS, props)
+	out, err := executeSSR(withEvalPage(ctx, absPath), serverJS, props)
 	if err != nil {
+		metrics.ssrErrors.inc(pageName(absPath))
 		return nil, fmt.Errorf("🔴 ssr failed for %s: %w", absPath, err)
 	}
 
-	paths := []string{ensureLeadingSlash(filepath.ToSlash(files.Client))}
-
-	return &RenderResult{
-		HTML:        html,
-		ClientPaths: paths,
-		CSSPath:     ensureLeadingSlash(filepath.ToSlash(files.CSS)),
-		Props:       props,
-	}, nil
+	result := prebuiltResult(out.HTML, props, files)
+	result.Head = out.Head
+	result.Slots = out.Slots
+	result.encoded = encodedPropsFrom(ctx)
+	return result, nil
 }
 
 func generateServerEntryCode(componentPath string) string {
@@ -576,12 +925,17 @@ func generateClientEntryCode(componentPath, rootID string) string {
 }
 
 func BuildServerBundle(filePath string) (string, []string, error) {
+	return configuredBundler().BuildServer(filePath)
+}
+
+func buildServerBundle(filePath string) (string, []string, error) {
+	start := time.Now()
 	absPath, err := resolveAbsPath(filePath, "component path")
 	if err != nil {
 		return "", nil, err
 	}
 	if _, err := os.Stat(absPath); err != nil {
-		return "", nil, fmt.Errorf("🔴 component not found %s: %w", absPath, err)
+		return "", nil, fmt.Errorf("🔴 %w %s: %w", ErrComponentNotFound, absPath, err)
 	}
 
 	tmpDir, err := os.MkdirTemp("", "alloy-")
@@ -599,11 +953,13 @@ func BuildServerBundle(filePath string) (string, []string, error) {
 
 	opts := commonBuildOptions()
 	opts.EntryPoints = []string{entryPath}
+	opts.Outdir = tmpDir
 	opts.Write = false
 	opts.Metafile = true
 	opts.Format = api.FormatIIFE
 	opts.GlobalName = "__Component"
-	opts.Platform = api.PlatformBrowser
+	applyServerPlatform(&opts)
+	applyAssetLoaders(&opts, getConfig().DistDir)
 
 	result := api.Build(opts)
 
@@ -611,7 +967,14 @@ func BuildServerBundle(filePath string) (string, []string, error) {
 		return "", nil, err
 	}
 
-	if len(result.OutputFiles) == 0 {
+	serverOut := -1
+	for i, out := range result.OutputFiles {
+		if filepath.Ext(out.Path) == ".js" {
+			serverOut = i
+			break
+		}
+	}
+	if serverOut < 0 {
 		return "", nil, fmt.Errorf("🔴 esbuild produced no server bundle for %s", absPath)
 	}
 
@@ -622,7 +985,8 @@ func BuildServerBundle(filePath string) (string, []string, error) {
 
 	deps = filterOutPath(deps, entryPath)
 
-	return string(result.OutputFiles[0].Contents), deps, nil
+	logger().Debug("build", "kind", "server", "page", pageName(filePath), "duration", time.Since(start))
+	return string(result.OutputFiles[serverOut].Contents), deps, nil
 }
 
 func writeManifestEntry(dir string, name string, files *PrebuiltFiles) error {
@@ -631,41 +995,25 @@ func writeManifestEntry(dir string, name string, files *PrebuiltFiles) error {
 	}
 
 	path := filepath.Join(dir, "manifest.json")
-	updates := map[string]manifestEntry{
+	updates := map[string]ManifestPage{
 		name: {
-			Server: filepath.Base(files.Server),
-			Client: filepath.Base(files.Client),
-			CSS:    filepath.Base(files.CSS),
-			Chunks: baseNames(files.ClientChunks),
+			Server:     filepath.Base(files.Server),
+			Client:     filepath.Base(files.Client),
+			CSS:        filepath.Base(files.CSS),
+			Chunks:     baseNames(files.ClientChunks),
+			Assets:     assetRelNames(files.Assets),
+			HTML:       htmlBaseName(files.HTML),
+			Legacy:     htmlBaseName(files.Legacy),
+			Render:     string(files.RenderMode),
+			Revalidate: int(files.Revalidate / time.Second),
+			Locale:     files.Locale,
+			Locales:    manifestLocales(files.Locales),
 		},
 	}
 
 	return updateManifest(path, updates)
 }
 
-func updateManifest(manifestPath string, updates map[string]manifestEntry) error {
-	manifest := map[string]manifestEntry{}
-
-	if data, err := os.ReadFile(manifestPath); err == nil {
-		if err := json.Unmarshal(data, &manifest); err != nil {
-			return fmt.Errorf("🔴 decode manifest: %w", err)
-		}
-	}
-
-	maps.Copy(manifest, updates)
-
-	data, err := json.MarshalIndent(manifest, "", "  ")
-	if err != nil {
-		return fmt.Errorf("🔴 encode manifest: %w", err)
-	}
-
-	if err := os.WriteFile(manifestPath, data, 0644); err != nil {
-		return fmt.Errorf("🔴 write manifest file: %w", err)
-	}
-
-	return nil
-}
-
 func WriteManifest(dir string, name string, files PrebuiltFiles) error {
 	return writeManifestEntry(dir, name, &files)
 }
@@ -821,34 +1169,15 @@ func (r assetRoot) assetMeta(relPath string, hashed bool) (string, time.Time) {
 		return etag, info.ModTime()
 	}
 
-	data, err := fs.ReadFile(r.fs, relPath)
-	if err != nil {
-		return "", info.ModTime()
-	}
-
-	sum := sha1.Sum(data)
-	etag := fmt.Sprintf(`"%x"`, sum)
-	return etag, info.ModTime()
+	return r.contentETag(relPath, info), info.ModTime()
 }
 
-func addCacheHeaders(w http.ResponseWriter, assetPath string, root assetRoot, relPath string) {
-	hashed := isHashedAsset(assetPath)
-	cacheValue := "public, max-age=300"
-	if hashed {
-		cacheValue = "public, max-age=31536000, immutable"
-	}
-	w.Header().Set("Cache-Control", cacheValue)
-
-	etag, modTime := root.assetMeta(relPath, hashed)
-	if etag != "" {
-		w.Header().Set("ETag", etag)
-	}
-	if !modTime.IsZero() {
-		w.Header().Set("Last-Modified", modTime.UTC().Format(http.TimeFormat))
-	}
+func BuildClientBundles(entries []ClientEntry, outDir string) (map[string]ClientAssets, error) {
+	return configuredBundler().BuildClient(entries, outDir, "")
 }
 
-func BuildClientBundles(entries []ClientEntry, outDir string) (map[string]ClientAssets, error) {
+func buildClientBundles(entries []ClientEntry, outDir string, locale string) (map[string]ClientAssets, error) {
+	start := time.Now()
 	if len(entries) == 0 {
 		return nil, fmt.Errorf("🔴 entries required")
 	}
@@ -907,10 +1236,25 @@ func BuildClientBundles(entries []ClientEntry, outDir string) (map[string]Client
 	opts.Outdir = absOut
 	opts.Splitting = true
 	opts.Format = api.FormatESModule
-	opts.Write = true
+	opts.Write = false
 	opts.Metafile = true
 	opts.EntryNames = "client-[name]-[hash]"
 	opts.ChunkNames = "chunk-[hash]"
+	primary := locale == ""
+	if locales, _ := DiscoverLocales(localesDir()); len(locales) > 0 {
+		if locale == "" {
+			locale = DefaultLocale(locales)
+		} else if !slices.Contains(locales, locale) {
+			return nil, fmt.Errorf("🔴 no messages for locale %s in %s", locale, FormatPath(localesDir()))
+		}
+		opts.EntryNames = "client-[name]." + locale + "-[hash]"
+		opts.Plugins = append([]api.Plugin{messagesPlugin(localesDir(), locales, locale)}, opts.Plugins...)
+	}
+	applyAssetLoaders(&opts, absOut)
+	if err := addVendorEntries(&opts, tmpDir); err != nil {
+		return nil, err
+	}
+	opts.External = append(opts.External, importMapExternals(configImportMap())...)
 
 	result := api.Build(opts)
 
@@ -918,41 +1262,39 @@ func BuildClientBundles(entries []ClientEntry, outDir string) (map[string]Client
 		return nil, err
 	}
 
-	var meta struct {
-		Outputs map[string]struct {
-			EntryPoint string `json:"entryPoint"`
-			Imports    []struct {
-				Path string `json:"path"`
-				Kind string `json:"kind"`
-			} `json:"imports"`
-		} `json:"outputs"`
+	if err := scanOutputFiles(result.OutputFiles, getConfig().SecretPatterns); err != nil {
+		return nil, err
+	}
+	if err := writeOutputFiles(result.OutputFiles); err != nil {
+		return nil, err
+	}
+
+	meta, err := parseMetafile(result.Metafile)
+	if err != nil {
+		return nil, err
 	}
-	if err := json.Unmarshal([]byte(result.Metafile), &meta); err != nil {
-		return nil, fmt.Errorf("🔴 parse metafile: %w", err)
+	if primary {
+		if err := writeMetafile(absOut, result.Metafile); err != nil {
+			return nil, err
+		}
 	}
 
 	outputs := map[string]ClientAssets{}
-	prefix := filepath.Join(filepath.Base(filepath.Dir(absOut)), filepath.Base(absOut))
-	prefix = filepath.ToSlash(prefix)
+	prefix := distURLPrefix(absOut)
 	for outPath, out := range meta.Outputs {
 		if out.EntryPoint == "" {
 			continue
 		}
 		name := entryName(out.EntryPoint, entryPoints)
-		outPathAbs := outPath
-		if !filepath.IsAbs(outPathAbs) {
-			outPathAbs = filepath.Join(cwd, outPathAbs)
-		}
 
-		entryRel, err := filepath.Rel(absOut, outPathAbs)
+		entryRel, err := outputRel(cwd, absOut, outPath)
 		if err != nil {
 			return nil, fmt.Errorf("🔴 entry rel: %w", err)
 		}
-		entryRel = filepath.ToSlash(entryRel)
 		if name == "" {
-			name = entryNameFromOutput(entryRel)
+			name = strings.TrimSuffix(entryNameFromOutput(entryRel), "."+locale)
 		}
-		if name == "" {
+		if name == "" || isVendorEntry(name) {
 			continue
 		}
 
@@ -963,9 +1305,20 @@ func BuildClientBundles(entries []ClientEntry, outDir string) (map[string]Client
 			}
 		}
 
+		var assets []string
+		for _, file := range meta.fileImports(outPath) {
+			rel, err := outputRel(cwd, absOut, file)
+			if err != nil {
+				return nil, fmt.Errorf("🔴 asset rel: %w", err)
+			}
+			assets = append(assets, path.Join(prefix, rel))
+		}
+
 		outputs[name] = ClientAssets{
-			Entry:  filepath.ToSlash(filepath.Join(prefix, entryRel)),
-			Chunks: chunks,
+			Entry:   filepath.ToSlash(filepath.Join(prefix, entryRel)),
+			Chunks:  chunks,
+			Assets:  assets,
+			Sources: meta.sourceInputs(outPath, cwd),
 		}
 	}
 
@@ -975,6 +1328,7 @@ func BuildClientBundles(entries []ClientEntry, outDir string) (map[string]Client
 		}
 	}
 
+	logger().Debug("build", "kind", "client", "pages", len(outputs), "duration", time.Since(start))
 	return outputs, nil
 }
 
@@ -1010,7 +1364,7 @@ func toEntryPoints(entries map[string]ClientEntry) []api.EntryPoint {
 
 func commonBuildOptions() api.BuildOptions {
 	cwd, _ := os.Getwd()
-	return api.BuildOptions{
+	opts := api.BuildOptions{
 		Bundle:           true,
 		JSX:              api.JSXAutomatic,
 		JSXImportSource:  "react",
@@ -1019,7 +1373,22 @@ func commonBuildOptions() api.BuildOptions {
 		NodePaths:        []string{filepath.Join(cwd, "node_modules")},
 		MinifyWhitespace: true,
 		MinifySyntax:     true,
+		Define:           publicEnvDefines(),
+	}
+
+	if cfg := getConfig(); cfg != nil {
+		opts.Plugins = append(opts.Plugins, cfg.BuildPlugins...)
+	}
+	opts.Plugins = append(opts.Plugins, svgComponentPlugin(), slotsPlugin(), clientRuntimePlugin())
+	if locales, _ := DiscoverLocales(localesDir()); len(locales) > 0 {
+		opts.Plugins = append(opts.Plugins, messagesPlugin(localesDir(), locales, ""))
 	}
+
+	if tsconfig := findTsconfig(cwd); tsconfig != "" {
+		opts.Tsconfig = tsconfig
+	}
+
+	return opts
 }
 
 func disableMinify(opts *api.BuildOptions) {
@@ -1027,43 +1396,49 @@ func disableMinify(opts *api.BuildOptions) {
 	opts.MinifySyntax = false
 }
 
-func checkBuildErrors(result api.BuildResult, context string) error {
-	if len(result.Errors) > 0 {
-		return fmt.Errorf("🔴 %s: %s", context, result.Errors[0].Text)
-	}
-	return nil
+type BuildError struct {
+	Op          string       `json:"-"`
+	File        string       `json:"file,omitempty"`
+	Line        int          `json:"line,omitempty"`
+	Column      int          `json:"column,omitempty"`
+	Text        string       `json:"text"`
+	Diagnostics []Diagnostic `json:"-"`
 }
 
-func checkContextError(err error, context string) error {
-	if ctxErr, ok := err.(*api.ContextError); ok && ctxErr != nil {
-		return fmt.Errorf("🔴 %s: %v", context, err)
+func (e *BuildError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("🔴 %s: %s", e.Op, e.Text)
 	}
-	return nil
+	return fmt.Sprintf("🔴 %s: %s:%d:%d: %s", e.Op, e.File, e.Line, e.Column, e.Text)
 }
 
-func collectAssetRoots(filesystem fs.FS) []assetRoot {
-	var roots []assetRoot
-
-	if publicFS, err := fs.Sub(filesystem, "public"); err == nil {
-		roots = append(roots, assetRoot{
-			prefix:     "",
-			fs:         publicFS,
-			fileServer: http.FileServer(http.FS(publicFS)),
-		})
+func checkBuildErrors(result api.BuildResult, context string) error {
+	if len(result.Errors) == 0 {
+		return nil
 	}
+	err := newBuildError(context, result.Errors[0])
+	err.Diagnostics = messageDiagnostics(result.Errors, result.Warnings)
+	return err
+}
 
-	if distFS, err := fs.Sub(filesystem, DefaultDistDir); err == nil {
-		roots = append(roots, assetRoot{
-			prefix:     DefaultDistDir,
-			fs:         distFS,
-			fileServer: http.FileServer(http.FS(distFS)),
-		})
+func newBuildError(op string, msg api.Message) *BuildError {
+	err := &BuildError{Op: op, Text: msg.Text}
+	if msg.Location != nil {
+		err.File = msg.Location.File
+		err.Line = msg.Location.Line
+		err.Column = msg.Location.Column + 1
 	}
+	return err
+}
 
-	return roots
+func checkContextError(err error, context string) error {
+	if ctxErr, ok := err.(*api.ContextError); ok && ctxErr != nil {
+		return fmt.Errorf("🔴 %s: %v", context, err)
+	}
+	return nil
 }
 
-var hashPattern = regexp.MustCompile(`-[a-fA-F0-9]{8,}\.`)
+var hashPattern = regexp.MustCompile(`-([a-fA-F0-9]{8,}|[A-Z2-7]{8})\.`)
 
 func isHashedAsset(assetPath string) bool {
 	return hashPattern.MatchString(filepath.Base(assetPath))
@@ -1112,9 +1487,9 @@ func resolvePrebuiltFiles(filesystem fs.FS, component string) (PrebuiltFiles, er
 	}
 
 	return PrebuiltFiles{
-		Server: filepath.Join(dist, fmt.Sprintf("%s-server.js", base)),
-		Client: filepath.Join(dist, fmt.Sprintf("%s-client.js", base)),
-		CSS:    filepath.Join(dist, fmt.Sprintf("%s.css", base)),
+		Server: path.Join(dist, fmt.Sprintf("%s-server.js", base)),
+		Client: path.Join(dist, fmt.Sprintf("%s-client.js", base)),
+		CSS:    path.Join(dist, fmt.Sprintf("%s.css", base)),
 	}, nil
 }
 
@@ -1132,12 +1507,12 @@ func lookupManifest(filesystem fs.FS, dist string, base string) (PrebuiltFiles,
 		return PrebuiltFiles{}, false, fmt.Errorf("🔴 read manifest: %w", err)
 	}
 
-	manifest := map[string]manifestEntry{}
-	if err := json.Unmarshal(data, &manifest); err != nil {
-		return PrebuiltFiles{}, false, fmt.Errorf("🔴 decode manifest: %w", err)
+	manifest, err := ParseManifest(data)
+	if err != nil {
+		return PrebuiltFiles{}, false, err
 	}
 
-	entry, ok := manifest[base]
+	entry, ok := manifest.Pages[base]
 	if !ok {
 		return PrebuiltFiles{}, false, nil
 	}
@@ -1153,9 +1528,31 @@ func lookupManifest(filesystem fs.FS, dist string, base string) (PrebuiltFiles,
 		Client:       path.Join(dist, client),
 		ClientChunks: joinPaths(dist, entry.Chunks),
 		CSS:          path.Join(dist, entry.CSS),
+		Assets:       joinPaths(dist, entry.Assets),
+		HTML:         joinPath(dist, entry.HTML),
+		Legacy:       joinPath(dist, entry.Legacy),
+		RenderMode:   RenderMode(entry.Render),
+		Revalidate:   time.Duration(entry.Revalidate) * time.Second,
+		Locale:       entry.Locale,
+		Locales:      localeFiles(dist, entry.Locales),
+		ImportMap:    manifest.ImportMap,
 	}, true, nil
 }
 
+func joinPath(prefix string, name string) string {
+	if name == "" {
+		return ""
+	}
+	return path.Join(prefix, name)
+}
+
+func htmlBaseName(p string) string {
+	if p == "" {
+		return ""
+	}
+	return filepath.Base(p)
+}
+
 func joinPaths(prefix string, names []string) []string {
 	if len(names) == 0 {
 		return nil
@@ -1206,9 +1603,9 @@ func RunTailwind(cssPath string, root string) (string, error) {
 }
 
 func tailwindCmd(root string, args ...string) (*exec.Cmd, error) {
-	runner, baseArgs := ResolveTailwindRunner(root)
-	if runner == "" {
-		return nil, fmt.Errorf("🔴 tailwind runner not found")
+	runner, baseArgs, err := resolveTailwindRunner(root)
+	if err != nil {
+		return nil, err
 	}
 	fullArgs := append(baseArgs, args...)
 	cmd := exec.Command(runner, fullArgs...)
@@ -1217,32 +1614,47 @@ func tailwindCmd(root string, args ...string) (*exec.Cmd, error) {
 }
 
 func ResolveTailwindRunner(root string) (string, []string) {
+	configArgs := tailwindConfigArgs(root)
+	cfg := getConfig()
+	if cfg != nil && cfg.TailwindBinary != "" {
+		return cfg.TailwindBinary, configArgs
+	}
+	if tailwindStandaloneEnabled() {
+		if binary, err := tailwindCachePath(tailwindVersion()); err == nil && fileExists(binary) {
+			return binary, configArgs
+		}
+	}
+	runner, args := ResolvePackageRunner(root, tailwindRunnerPackage(root))
+	return runner, append(args, configArgs...)
+}
+
+func ResolvePackageRunner(root string, pkg string) (string, []string) {
 	switch {
 	case fileExists(filepath.Join(root, "pnpm-lock.yaml")):
-		return tailwindRunnerFor("pnpm")
+		return packageRunnerFor("pnpm", pkg)
 	case fileExists(filepath.Join(root, "yarn.lock")):
-		return tailwindRunnerFor("yarn")
+		return packageRunnerFor("yarn", pkg)
 	case fileExists(filepath.Join(root, "bun.lockb")):
-		return tailwindRunnerFor("bun")
+		return packageRunnerFor("bun", pkg)
 	case fileExists(filepath.Join(root, "package-lock.json")):
-		return tailwindRunnerFor("npm")
+		return packageRunnerFor("npm", pkg)
 	default:
-		return tailwindRunnerFor("npx")
+		return packageRunnerFor("npx", pkg)
 	}
 }
 
-func tailwindRunnerFor(name string) (string, []string) {
+func packageRunnerFor(name string, pkg string) (string, []string) {
 	switch name {
 	case "pnpm":
-		return "pnpx", []string{"@tailwindcss/cli"}
+		return "pnpx", []string{pkg}
 	case "npm", "npx":
-		return "npx", []string{"@tailwindcss/cli"}
+		return "npx", []string{pkg}
 	case "yarn":
-		return "yarn", []string{"@tailwindcss/cli"}
+		return "yarn", []string{pkg}
 	case "bun", "bunx":
-		return "bunx", []string{"@tailwindcss/cli"}
+		return "bunx", []string{pkg}
 	default:
-		return name, []string{"@tailwindcss/cli"}
+		return name, []string{pkg}
 	}
 }
 
@@ -1305,7 +1717,11 @@ func BuildDevBundles(pages []PageSpec, distDir string) error {
 	opts.Write = true
 	opts.EntryNames = "[name]-client"
 	opts.ChunkNames = "chunk-[hash]"
+	applyAssetLoaders(&opts, distDir)
 	disableMinify(&opts)
+	if err := addVendorEntries(&opts, tmpClientDir); err != nil {
+		return err
+	}
 
 	result := api.Build(opts)
 
@@ -1320,35 +1736,28 @@ func BuildDevBundles(pages []PageSpec, distDir string) error {
 	return nil
 }
 
-func writeDevManifest(pages []PageSpec, distDir string) error {
-	manifestPath := filepath.Join(distDir, "manifest.json")
-
-	existingManifest := map[string]manifestEntry{}
-	if data, err := os.ReadFile(manifestPath); err == nil {
-		json.Unmarshal(data, &existingManifest)
-	}
-
-	updates := make(map[string]manifestEntry, len(pages))
+func writeDevManifest(pages []PageSpec, distDir string, removed ...string) error {
+	updates := make(map[string]ManifestPage, len(pages))
 	for _, page := range pages {
-		updates[page.Name] = manifestEntry{
-			Server: fmt.Sprintf("%s-server.js", page.Name),
-			Client: fmt.Sprintf("%s-client.js", page.Name),
-			CSS:    "shared.css",
+		config := devPageConfig(distDir, page.Name)
+		updates[page.Name] = ManifestPage{
+			Server:     fmt.Sprintf("%s-server.js", page.Name),
+			Client:     fmt.Sprintf("%s-client.js", page.Name),
+			CSS:        CSSEntryForPage(page.Name).Name + ".css",
+			Render:     string(config.RenderMode),
+			Revalidate: int(config.Revalidate / time.Second),
 		}
 	}
-
-	for name, entry := range existingManifest {
-		if _, exists := updates[name]; !exists {
-			updates[name] = entry
-		}
+	if err := validateDevOutputs(distDir, updates); err != nil {
+		return err
 	}
 
-	return updateManifest(manifestPath, updates)
+	return updateManifest(filepath.Join(distDir, "manifest.json"), updates, removed...)
 }
 
 func WatchTailwind(ctx context.Context, inputPath, outputPath, cwd string) *exec.Cmd {
-	runner, baseArgs := ResolveTailwindRunner(cwd)
-	if runner == "" {
+	runner, baseArgs, err := resolveTailwindRunner(cwd)
+	if err != nil {
 		return nil
 	}
 
@@ -1366,156 +1775,7 @@ func WatchTailwind(ctx context.Context, inputPath, outputPath, cwd string) *exec
 }
 
 func WatchAndBuild(ctx context.Context, pages []PageSpec, distDir string, buildDone chan<- struct{}) error {
-	cssPath := filepath.Join(DefaultAppDir, "app.css")
-	cwd, _ := os.Getwd()
-
-	if err := BuildDevBundles(pages, distDir); err != nil {
-		return fmt.Errorf("🔴 initial build: %w", err)
-	}
-
-	fmt.Fprintf(os.Stdout, "✅ Initial build complete\n")
-
-	if buildDone != nil {
-		close(buildDone)
-	}
-
-	serverTmpDir, err := os.MkdirTemp("", "alloy-server-")
-	if err != nil {
-		return fmt.Errorf("🔴 create server temp dir: %w", err)
-	}
-	defer os.RemoveAll(serverTmpDir)
-
-	var g errgroup.Group
-
-	serverCtxs := make([]serverCtxInfo, 0, len(pages))
-
-	for _, page := range pages {
-		absComponent, err := resolveAbsPath(page.Component, "component path")
-		if err != nil {
-			return err
-		}
-
-		entryCode := generateServerEntryCode(absComponent)
-
-		entryPath := filepath.Join(serverTmpDir, page.Name+"-entry.tsx")
-		if err := os.WriteFile(entryPath, []byte(entryCode), 0644); err != nil {
-			return fmt.Errorf("🔴 write entry: %w", err)
-		}
-
-		outPath := filepath.Join(distDir, fmt.Sprintf("%s-server.js", page.Name))
-
-		opts := commonBuildOptions()
-		opts.EntryPoints = []string{entryPath}
-		opts.Write = true
-		opts.Outfile = outPath
-		opts.Format = api.FormatIIFE
-		opts.GlobalName = "__Component"
-		opts.Platform = api.PlatformBrowser
-		disableMinify(&opts)
-
-		buildCtx, err := api.Context(opts)
-		if err := checkContextError(err, fmt.Sprintf("create server context %s", page.Name)); err != nil {
-			return err
-		}
-
-		serverCtxs = append(serverCtxs, serverCtxInfo{ctx: buildCtx, tmp: entryPath})
-
-		watchCtx := buildCtx
-		pageName := page.Name
-		g.Go(func() error {
-			err := watchCtx.Watch(api.WatchOptions{})
-			if err != nil {
-				return fmt.Errorf("🔴 watch server %s: %w", pageName, err)
-			}
-
-			<-ctx.Done()
-			return nil
-		})
-	}
-
-	defer func() {
-		for _, info := range serverCtxs {
-			info.ctx.Dispose()
-		}
-	}()
-
-	tmpClientDir, err := os.MkdirTemp("", "alloy-client-")
-	if err != nil {
-		return fmt.Errorf("🔴 create client temp: %w", err)
-	}
-	defer os.RemoveAll(tmpClientDir)
-
-	clientEntries := make([]api.EntryPoint, 0, len(pages))
-	for _, page := range pages {
-		absComponent, err := resolveAbsPath(page.Component, "component path")
-		if err != nil {
-			return err
-		}
-
-		wrapperCode := generateClientEntryCode(absComponent, page.RootID)
-
-		entryPath := filepath.Join(tmpClientDir, page.Name+".tsx")
-		if err := os.WriteFile(entryPath, []byte(wrapperCode), 0644); err != nil {
-			return fmt.Errorf("🔴 write client entry: %w", err)
-		}
-
-		clientEntries = append(clientEntries, api.EntryPoint{
-			InputPath:  entryPath,
-			OutputPath: page.Name,
-		})
-	}
-
-	opts := commonBuildOptions()
-	opts.EntryPointsAdvanced = clientEntries
-	opts.Outdir = distDir
-	opts.Splitting = true
-	opts.Format = api.FormatESModule
-	opts.Write = true
-	opts.EntryNames = "[name]-client"
-	opts.ChunkNames = "chunk-[hash]"
-	disableMinify(&opts)
-
-	clientCtx, err := api.Context(opts)
-	if err := checkContextError(err, "create client context"); err != nil {
-		return err
-	}
-	defer clientCtx.Dispose()
-
-	g.Go(func() error {
-		err := clientCtx.Watch(api.WatchOptions{})
-		if err != nil {
-			return fmt.Errorf("🔴 watch client: %w", err)
-		}
-
-		<-ctx.Done()
-		return nil
-	})
-
-	g.Go(func() error {
-		cmd := WatchTailwind(ctx, cssPath, filepath.Join(distDir, "shared.css"), cwd)
-		if cmd == nil {
-			return fmt.Errorf("🔴 tailwind runner not found")
-		}
-
-		if err := cmd.Start(); err != nil {
-			return fmt.Errorf("🔴 start tailwind: %w", err)
-		}
-
-		go func() {
-			<-ctx.Done()
-			if cmd.Process != nil {
-				cmd.Process.Kill()
-			}
-		}()
-
-		return cmd.Wait()
-	})
-
-	if err := writeDevManifest(pages, distDir); err != nil {
-		return fmt.Errorf("🔴 write manifest: %w", err)
-	}
-
-	return g.Wait()
+	return watchDev(ctx, "", pages, distDir, buildDone)
 }
 
 func DiscoverPages(dir string) ([]PageSpec, error) {
@@ -1554,23 +1814,63 @@ func readBundlesFromCache(path string, rootID string) (string, string, string) {
 	return "", "", ""
 }
 
-func executeSSR(ctx context.Context, jsCode string, props map[string]any) (string, error) {
+func lookupBundles(ctx context.Context, absPath string, rootID string) (string, string, string) {
+	_, span := startSpan(ctx, "alloy.bundle_cache", attribute.String("alloy.page", pageName(absPath)))
+	serverJS, clientJS, css := readBundlesFromCache(absPath, rootID)
+	span.SetAttributes(attribute.Bool("alloy.cache.hit", serverJS != ""))
+	span.End()
+	metrics.bundleCache.inc(cacheResult(serverJS != ""))
+	return serverJS, clientJS, css
+}
+
+func executeSSR(ctx context.Context, jsCode string, props map[string]any) (RenderResponse, error) {
+	activeRenders.Add(1)
+	defer activeRenders.Done()
+	rendersInFlight.Add(1)
+	defer rendersInFlight.Add(-1)
+
+	renderer := configuredRenderer()
+	if renderer == nil {
+		release, err := acquireRenderSlot(ctx)
+		if err != nil {
+			return RenderResponse{}, err
+		}
+		defer release()
+	}
+	if err := ctx.Err(); err != nil {
+		return RenderResponse{}, fmt.Errorf("🔴 render: %w", err)
+	}
+
 	if timeout := currentRenderTimeout(); timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, timeout)
 		defer cancel()
 	}
 
+	ctx, span := startSpan(ctx, "alloy.eval")
+	started := time.Now()
+	var out RenderResponse
+	var err error
+	if renderer != nil {
+		out, err = renderer.Render(ctx, RenderRequest{Bundle: bundleID(jsCode), Code: jsCode, Props: props, Context: RenderContext(ctx)})
+	} else if pool := currentRuntimePool(); pool != nil {
+		out, err = pool.render(ctx, jsCode, props)
+	} else {
+		out, err = renderFresh(ctx, jsCode, props)
+	}
+	pageDebugFrom(ctx).record(func(d *pageDebug) { d.ssr += time.Since(started) })
+	recordEval(ctx, time.Since(started), err)
+	endSpan(span, err)
+	return out, err
+}
+
+func renderFresh(ctx context.Context, jsCode string, props map[string]any) (RenderResponse, error) {
 	vm, err := newRuntimeWithContext()
 	if err != nil {
-		return "", fmt.Errorf("🔴 create runtime: %w", err)
+		return RenderResponse{}, fmt.Errorf("🔴 create runtime: %w", err)
 	}
 	defer closeRuntime(vm)
-
-	vm.rt.SetInterruptHandler(makeInterruptHandler(ctx))
-	defer vm.rt.ClearInterruptHandler()
-
-	return runSSR(vm.ctx, jsCode, props)
+	return renderOnRuntime(ctx, vm, jsCode, props, false)
 }
 
 func makeInterruptHandler(ctx context.Context) quickjs.InterruptHandler {
@@ -1584,29 +1884,62 @@ func makeInterruptHandler(ctx context.Context) quickjs.InterruptHandler {
 	}
 }
 
-func runSSR(ctx *quickjs.Context, jsCode string, props map[string]any) (string, error) {
-	result := ctx.Eval(jsCode)
+func runSSR(ctx context.Context, js *quickjs.Context, jsCode string, props map[string]any) (RenderResponse, error) {
+	result := js.Eval(jsCode)
 	if result.IsException() {
 		result.Free()
-		return "", fmt.Errorf("🔴 eval component bundle: %s", ctx.Exception())
+		return RenderResponse{}, fmt.Errorf("🔴 eval component bundle: %w", js.Exception())
 	}
 	defer result.Free()
 
-	propsJSON, err := json.Marshal(props)
+	propsJSON, err := encodedPropsFrom(ctx).marshal(props)
 	if err != nil {
-		return "", fmt.Errorf("🔴 marshal props: %w", err)
+		return RenderResponse{}, fmt.Errorf("🔴 marshal props: %w", err)
 	}
 
+	contextJSON, err := renderContextJSON(ctx)
+	if err != nil {
+		return RenderResponse{}, err
+	}
+	assigned := js.Eval("globalThis.__ALLOY_SLOTS__ = {}; globalThis.__ALLOY_CTX__ = " + contextJSON)
+	if assigned.IsException() {
+		assigned.Free()
+		return RenderResponse{}, fmt.Errorf("🔴 set render context: %s", js.Exception())
+	}
+	assigned.Free()
+
+	release, err := installBindings(ctx, js)
+	if err != nil {
+		return RenderResponse{}, err
+	}
+	defer release()
+	defer discardPendingWork(js)
+
 	renderCode := fmt.Sprintf(renderTemplate, string(propsJSON))
 
-	renderResult := ctx.Eval(renderCode)
+	renderResult := js.Eval(renderCode)
+	if renderResult.IsPromise() {
+		settled, err := awaitRender(ctx, js, renderResult)
+		if err != nil {
+			return RenderResponse{}, err
+		}
+		renderResult = settled
+	}
 	defer renderResult.Free()
 
+	if renderResult.IsException() {
+		return RenderResponse{}, fmt.Errorf("🔴 render component: %w", js.Exception())
+	}
+
 	if !renderResult.IsString() {
-		return "", fmt.Errorf("🔴 render returned non-string: %s", renderResult.String())
+		return RenderResponse{}, fmt.Errorf("🔴 render returned non-string: %s", renderResult.String())
 	}
 
-	return renderResult.String(), nil
+	slots, err := collectSlots(js)
+	if err != nil {
+		return RenderResponse{}, err
+	}
+	return RenderResponse{HTML: renderResult.String(), Slots: slots}, nil
 }
 
 func bundleInputs(meta string) ([]string, error) {
@@ -1623,6 +1956,13 @@ func bundleInputs(meta string) ([]string, error) {
 
 	var inputs []string
 	for path := range mf.Inputs {
+		if strings.HasPrefix(path, messagesNamespace+":") {
+			inputs = append(inputs, catalogFiles()...)
+			continue
+		}
+		if strings.HasPrefix(path, slotsNamespace+":") || strings.HasPrefix(path, clientRuntimeNamespace+":") || strings.HasPrefix(path, nodeShimsNamespace+":") {
+			continue
+		}
 		abs, err := filepath.Abs(path)
 		if err != nil {
 			return nil, err
@@ -1648,12 +1988,16 @@ func filterOutPath(paths []string, skip string) []string {
 func ServePrebuiltPageWithContext(w http.ResponseWriter, r *http.Request, componentPath string, props map[string]any, rootID string, files PrebuiltFiles) {
 	result, err := RenderPrebuiltWithContext(r.Context(), componentPath, props, rootID, files)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		reportRenderError(r, componentPath, props, err)
+		writeRenderError(w, err)
 		return
 	}
+	result.Nonce = CSPNonce(r)
+	result.Layout = layoutMeta(r)
+	result.Context = RenderContext(r.Context())
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	fmt.Fprint(w, result.ToHTML(rootID))
+	writeHTML(r.Context(), w, result.ToHTML(rootID))
 }
 
 func QuietWriter() io.Writer {
@@ -1665,6 +2009,6 @@ func QuietWriter() io.Writer {
 
 func FormatPath(path string) string {
 	cwd, _ := os.Getwd()
-	rel := strings.TrimPrefix(path, cwd+"/")
+	rel := strings.TrimPrefix(path, cwd+string(filepath.Separator))
 	return rel
 }
@@ -0,0 +1,76 @@
+package alloy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+)
+
+var componentBundles = struct {
+	sync.Mutex
+	js map[string]string
+}{
+	js: map[string]string{},
+}
+
+func RenderComponent(ctx context.Context, component string, props map[string]any) (string, error) {
+	if props == nil {
+		props = map[string]any{}
+	}
+	serverJS, err := componentServerJS(component)
+	if err != nil {
+		return "", err
+	}
+	out, err := executeSSR(withEvalPage(ctx, component), serverJS, props)
+	if err != nil {
+		metrics.ssrErrors.inc(pageName(component))
+		return "", fmt.Errorf("🔴 render %s: %w", pageName(component), err)
+	}
+	return out.HTML, nil
+}
+
+func componentServerJS(component string) (string, error) {
+	if cfg := getConfig(); cfg != nil && cfg.FS != nil {
+		files, ok, err := lookupManifest(cfg.FS, DefaultDistDir, pageName(component))
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			data, err := readPrebuiltFile(cfg.FS, files.Server)
+			if err != nil {
+				return "", fmt.Errorf("🔴 read server bundle: %w", err)
+			}
+			return string(data), nil
+		}
+	}
+
+	if cfg := getConfig(); cfg != nil && cfg.RuntimeOnly {
+		return "", fmt.Errorf("🔴 component %s is not in the manifest: %w", pageName(component), ErrRuntimeOnly)
+	}
+
+	absPath, err := resolveAbsPath(component, "component path")
+	if err != nil {
+		return "", err
+	}
+	dev := os.Getenv("ALLOY_DEV") == "1"
+	if !dev {
+		componentBundles.Lock()
+		serverJS, ok := componentBundles.js[absPath]
+		componentBundles.Unlock()
+		if ok {
+			return serverJS, nil
+		}
+	}
+
+	serverJS, _, err := BuildServerBundle(absPath)
+	if err != nil {
+		return "", err
+	}
+	if !dev {
+		componentBundles.Lock()
+		componentBundles.js[absPath] = serverJS
+		componentBundles.Unlock()
+	}
+	return serverJS, nil
+}
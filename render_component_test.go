@@ -0,0 +1,43 @@
+package alloy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenderComponentBuildsFromSource(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(dir)
+	})
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react", "jsx-runtime.js"), `exports.jsx = (type, props) => ({ type, props });`+"\n")
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react-dom", "server.edge.js"), `exports.renderToString = (el) => String(el.type(el.props));`+"\n")
+	component := filepath.Join(dir, "app", "emails", "welcome.tsx")
+	writeTestFile(t, component, `export default function Welcome(props: { name: string }) { return "Welcome " + props.name; }`+"\n")
+
+	ctx := context.Background()
+	html, err := RenderComponent(ctx, component, map[string]any{"name": "Ada"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if html != "Welcome Ada" {
+		t.Fatalf("html = %q", html)
+	}
+
+	writeTestFile(t, component, `export default function Welcome(props: { name: string }) { return "Hi " + props.name; }`+"\n")
+	if html, _ := RenderComponent(ctx, component, map[string]any{"name": "Ada"}); html != "Welcome Ada" {
+		t.Fatalf("bundle not reused outside dev mode: %q", html)
+	}
+
+	t.Setenv("ALLOY_DEV", "1")
+	if html, _ := RenderComponent(ctx, component, map[string]any{"name": "Ada"}); html != "Hi Ada" {
+		t.Fatalf("bundle not rebuilt in dev mode: %q", html)
+	}
+
+	if _, err := RenderComponent(ctx, filepath.Join(dir, "app", "emails", "missing.tsx"), nil); err == nil {
+		t.Fatal("expected error for missing component")
+	}
+}
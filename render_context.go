@@ -0,0 +1,64 @@
+package alloy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+)
+
+type renderContextKey struct{}
+
+func withRenderContext(r *http.Request) *http.Request {
+	cfg := getConfig()
+	if cfg == nil || cfg.RequestContext == nil {
+		return r
+	}
+	values := cfg.RequestContext(r)
+	if len(values) == 0 {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), renderContextKey{}, values))
+}
+
+func withRenderContextValue(ctx context.Context, key string, value any) context.Context {
+	values, _ := ctx.Value(renderContextKey{}).(map[string]any)
+	if _, ok := values[key]; ok {
+		return ctx
+	}
+	merged := maps.Clone(values)
+	if merged == nil {
+		merged = map[string]any{}
+	}
+	merged[key] = value
+	return context.WithValue(ctx, renderContextKey{}, merged)
+}
+
+func RenderContext(ctx context.Context) map[string]any {
+	values, _ := ctx.Value(renderContextKey{}).(map[string]any)
+	return withFlagContext(ctx, values)
+}
+
+func renderContextScript(values map[string]any) string {
+	if len(values) == 0 {
+		return ""
+	}
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("<script id=\"__ALLOY_CTX__\" type=\"application/json\">%s</script>\n        ", encoded)
+}
+
+func renderContextJSON(ctx context.Context) (string, error) {
+	values := RenderContext(ctx)
+	if values == nil {
+		return "{}", nil
+	}
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("🔴 marshal render context: %w", err)
+	}
+	return string(encoded), nil
+}
@@ -0,0 +1,63 @@
+package alloy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const renderContextBundle = `var __Component = function(props) {
+	return "<p>" + (__ALLOY_CTX__.locale || "none") + "</p>";
+};`
+
+func TestExecuteSSRExposesRenderContext(t *testing.T) {
+	ctx := context.WithValue(context.Background(), renderContextKey{}, map[string]any{"locale": "fr"})
+	out, err := executeSSR(ctx, renderContextBundle, map[string]any{})
+	if err != nil {
+		t.Fatalf("🔴 render: %v", err)
+	}
+	if out.HTML != "<p>fr</p>" {
+		t.Fatalf("🔴 expected locale from context, got %q", out.HTML)
+	}
+}
+
+func TestRuntimePoolResetsRenderContext(t *testing.T) {
+	withRuntimePool(t, func(cfg *Config) { cfg.RuntimePoolSize = 1 })
+
+	ctx := context.WithValue(context.Background(), renderContextKey{}, map[string]any{"locale": "fr"})
+	if out, err := executeSSR(ctx, renderContextBundle, map[string]any{}); err != nil || out.HTML != "<p>fr</p>" {
+		t.Fatalf("🔴 first render: %q %v", out.HTML, err)
+	}
+	if out, err := executeSSR(context.Background(), renderContextBundle, map[string]any{}); err != nil || out.HTML != "<p>none</p>" {
+		t.Fatalf("🔴 expected context to be cleared, got %q %v", out.HTML, err)
+	}
+}
+
+func TestPageHandlerWritesRenderContext(t *testing.T) {
+	var loaderSaw any
+	handler := etagTestPage(t, func(cfg *Config) {
+		cfg.RequestContext = func(r *http.Request) map[string]any {
+			return map[string]any{"requestId": r.Header.Get("X-Request-Id")}
+		}
+	}).(*PageHandler)
+	loader := handler.loader
+	handler.WithLoader(func(r *http.Request) map[string]any {
+		loaderSaw = RenderContext(r.Context())["requestId"]
+		props, _ := loader(r.Context(), r)
+		return props
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/etag", nil)
+	req.Header.Set("X-Request-Id", "req-1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if !strings.Contains(rec.Body.String(), `<script id="__ALLOY_CTX__" type="application/json">{"requestId":"req-1"}</script>`) {
+		t.Fatalf("🔴 expected context payload:\n%s", rec.Body.String())
+	}
+	if loaderSaw != "req-1" {
+		t.Fatalf("🔴 expected loader to see context, got %v", loaderSaw)
+	}
+}
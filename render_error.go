@@ -0,0 +1,72 @@
+package alloy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/buke/quickjs-go"
+)
+
+type PageError struct {
+	Component string
+	Page      string
+	Props     map[string]any
+	Stack     string
+	Method    string
+	URL       string
+	Pattern   string
+	Header    http.Header
+	Err       error
+}
+
+func (e PageError) Error() string {
+	return e.Err.Error()
+}
+
+func (e PageError) Unwrap() error {
+	return e.Err
+}
+
+func reportRenderError(r *http.Request, component string, props map[string]any, err error) {
+	cfg := getConfig()
+	if cfg == nil || cfg.OnRenderError == nil || errors.Is(err, ErrRenderQueueTimeout) {
+		return
+	}
+
+	header := r.Header.Clone()
+	header.Del("Cookie")
+	header.Del("Authorization")
+	pageErr := PageError{
+		Component: component,
+		Page:      pageName(component),
+		Props:     snapshotProps(props),
+		Method:    r.Method,
+		URL:       r.URL.String(),
+		Pattern:   r.Pattern,
+		Header:    header,
+		Err:       err,
+	}
+	var jsErr *quickjs.Error
+	if errors.As(err, &jsErr) {
+		pageErr.Stack = jsErr.Stack
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			logger().Error("render error hook panicked", "page", pageErr.Page, "panic", p)
+		}
+	}()
+	cfg.OnRenderError(context.WithoutCancel(r.Context()), pageErr)
+}
+
+func snapshotProps(props map[string]any) map[string]any {
+	data, err := json.Marshal(props)
+	if err != nil {
+		return nil
+	}
+	var snapshot map[string]any
+	json.Unmarshal(data, &snapshot)
+	return snapshot
+}
@@ -0,0 +1,66 @@
+package alloy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOnRenderErrorReportsSSRFailures(t *testing.T) {
+	resetBundleCache()
+	t.Cleanup(resetBundleCache)
+
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "broken-server.js"), `var __Component = { default: function explode(props) { throw new Error("boom " + props.id); } };`)
+	writeTestFile(t, filepath.Join(dist, "broken-client-AAAAAAAA.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "shared-BBBBBBBB.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{"broken": {"server": "broken-server.js", "client": "broken-client-AAAAAAAA.js", "css": "shared-BBBBBBBB.css"}}`)
+
+	var reported []PageError
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+		cfg.OnRenderError = func(ctx context.Context, err PageError) {
+			reported = append(reported, err)
+		}
+	})
+
+	props := map[string]any{"id": "42"}
+	handler := NewPage(filepath.Join(root, "pages", "broken.tsx")).WithLoader(func(r *http.Request) map[string]any {
+		return props
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/broken?x=1", nil)
+	req.Header.Set("Cookie", "session=secret")
+	req.Header.Set("User-Agent", "test")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if len(reported) != 1 {
+		t.Fatalf("reported %d errors", len(reported))
+	}
+	got := reported[0]
+	if got.Page != "broken" || got.Method != http.MethodGet || got.URL != "/broken?x=1" {
+		t.Errorf("page error = %+v", got)
+	}
+	if got.Props["id"] != "42" {
+		t.Errorf("props = %v", got.Props)
+	}
+	props["id"] = "changed"
+	if got.Props["id"] != "42" {
+		t.Error("props snapshot shares the loader map")
+	}
+	if !strings.Contains(got.Error(), "boom 42") || !strings.Contains(got.Stack, "explode") {
+		t.Errorf("error = %q, stack = %q", got.Error(), got.Stack)
+	}
+	if got.Header.Get("Cookie") != "" || got.Header.Get("User-Agent") != "test" {
+		t.Errorf("header = %v", got.Header)
+	}
+}
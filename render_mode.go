@@ -0,0 +1,157 @@
+package alloy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type RenderMode string
+
+const (
+	RenderModeSSR    RenderMode = "ssr"
+	RenderModeStatic RenderMode = "static"
+	RenderModeClient RenderMode = "client"
+)
+
+func ParseRenderMode(value string) (RenderMode, error) {
+	switch mode := RenderMode(value); mode {
+	case "", RenderModeSSR, RenderModeStatic, RenderModeClient:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("🔴 unknown render mode %q: want ssr, static or client", value)
+	}
+}
+
+func (h *PageHandler) WithRenderMode(mode RenderMode) *PageHandler {
+	h.mode = mode
+	return h
+}
+
+type PageConfig struct {
+	RenderMode RenderMode
+	Revalidate time.Duration
+}
+
+func DetectPageConfig(serverJS string) (PageConfig, error) {
+	vm, err := newRuntimeWithContext()
+	if err != nil {
+		return PageConfig{}, fmt.Errorf("🔴 create runtime: %w", err)
+	}
+	defer closeRuntime(vm)
+
+	result := vm.ctx.Eval(serverJS)
+	if result.IsException() {
+		result.Free()
+		return PageConfig{}, fmt.Errorf("🔴 eval component bundle: %s", vm.ctx.Exception())
+	}
+	result.Free()
+
+	exports := vm.ctx.Eval(`JSON.stringify({ renderMode: String(__Component.renderMode || ""), revalidate: Number(__Component.revalidate) || 0 })`)
+	defer exports.Free()
+	if exports.IsException() {
+		return PageConfig{}, fmt.Errorf("🔴 read page config: %s", vm.ctx.Exception())
+	}
+
+	var raw struct {
+		RenderMode string  `json:"renderMode"`
+		Revalidate float64 `json:"revalidate"`
+	}
+	if err := json.Unmarshal([]byte(exports.String()), &raw); err != nil {
+		return PageConfig{}, fmt.Errorf("🔴 decode page config: %w", err)
+	}
+
+	mode, err := ParseRenderMode(raw.RenderMode)
+	if err != nil {
+		return PageConfig{}, err
+	}
+	return PageConfig{RenderMode: mode, Revalidate: time.Duration(raw.Revalidate * float64(time.Second))}, nil
+}
+
+func devPageConfig(distDir string, page string) PageConfig {
+	serverJS, err := os.ReadFile(filepath.Join(distDir, fmt.Sprintf("%s-server.js", page)))
+	if err != nil {
+		return PageConfig{}
+	}
+	config, _ := DetectPageConfig(string(serverJS))
+	return config
+}
+
+func PrerenderPage(serverJS string, rootID string, props map[string]any, files PrebuiltFiles) (string, error) {
+	if props == nil {
+		props = map[string]any{}
+	}
+	out, err := executeSSR(context.Background(), serverJS, props)
+	if err != nil {
+		return "", fmt.Errorf("🔴 prerender: %w", err)
+	}
+	result := prebuiltResult(out.HTML, props, files)
+	result.Head = out.Head
+	result.Slots = out.Slots
+	result.Nonce = cspNoncePlaceholder
+	return result.ToHTML(rootID), nil
+}
+
+func SaveHTML(html string, dir string, name string) (string, error) {
+	if html == "" {
+		return "", fmt.Errorf("🔴 html required")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("🔴 make dir: %w", err)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.html", name, shortHash(html)))
+	if err := os.WriteFile(path, []byte(html), 0644); err != nil {
+		return "", fmt.Errorf("🔴 write html: %w", err)
+	}
+	return path, nil
+}
+
+func prebuiltResult(html string, props map[string]any, files PrebuiltFiles) *RenderResult {
+	result := &RenderResult{
+		HTML:        html,
+		ClientPaths: []string{AssetURL(ensureLeadingSlash(filepath.ToSlash(files.Client)))},
+		CSSPath:     AssetURL(ensureLeadingSlash(filepath.ToSlash(files.CSS))),
+		Props:       props,
+		ImportMap:   files.ImportMap,
+	}
+	if files.Legacy != "" {
+		result.LegacyPath = AssetURL(ensureLeadingSlash(filepath.ToSlash(files.Legacy)))
+	}
+	return result
+}
+
+func readPrebuiltFile(filesystem fs.FS, name string) ([]byte, error) {
+	data, err := fs.ReadFile(filesystem, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		data, err = fs.ReadFile(os.DirFS("."), name)
+	}
+	return data, err
+}
+
+func serveStaticHTML(w http.ResponseWriter, r *http.Request, filesystem fs.FS, name string) bool {
+	data, err := readPrebuiltFile(filesystem, name)
+	if err != nil {
+		return false
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if r.Method != http.MethodHead {
+		writeHTML(r.Context(), w, fillNonce(string(data), CSPNonce(r)))
+	}
+	return true
+}
+
+func ServeClientShell(w http.ResponseWriter, r *http.Request, props map[string]any, rootID string, files PrebuiltFiles) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	result := prebuiltResult("", props, files)
+	result.Nonce = CSPNonce(r)
+	result.Layout = layoutMeta(r)
+	result.Context = RenderContext(r.Context())
+	writeHTML(r.Context(), w, result.ToHTML(rootID))
+}
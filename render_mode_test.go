@@ -0,0 +1,81 @@
+package alloy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const fakeStaticServerJS = `var __Component = { renderMode: "static", revalidate: 30, default: function(props) { return "<p>" + (props.name || "static") + "</p>"; } };`
+
+func TestParseRenderMode(t *testing.T) {
+	for _, value := range []string{"", "ssr", "static", "client"} {
+		if _, err := ParseRenderMode(value); err != nil {
+			t.Fatalf("ParseRenderMode(%q): %v", value, err)
+		}
+	}
+	if _, err := ParseRenderMode("edge"); err == nil {
+		t.Fatal("expected error for unknown mode")
+	}
+}
+
+func TestDetectRenderModeAndPrerender(t *testing.T) {
+	config, err := DetectPageConfig(fakeStaticServerJS)
+	if err != nil || config.RenderMode != RenderModeStatic || config.Revalidate != 30*time.Second {
+		t.Fatalf("detect: %+v %v", config, err)
+	}
+	if config, err := DetectPageConfig(`var __Component = { default: function() { return ""; } };`); err != nil || config != (PageConfig{}) {
+		t.Fatalf("detect without exports: %+v %v", config, err)
+	}
+
+	html, err := PrerenderPage(fakeStaticServerJS, "home-root", nil, PrebuiltFiles{Client: "dist/build/client-home-AAAAAAAA.js", CSS: "dist/build/shared-abcdef12.css"})
+	if err != nil {
+		t.Fatalf("prerender: %v", err)
+	}
+	for _, want := range []string{"<p>static</p>", `src="/dist/build/client-home-AAAAAAAA.js"`, `href="/dist/build/shared-abcdef12.css"`} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("prerendered html missing %s:\n%s", want, html)
+		}
+	}
+}
+
+func TestPageHandlerHonorsRenderMode(t *testing.T) {
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "home-abcdef12.html"), "<html>prerendered</html>")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{
+		"home": {"server": "home-server.js", "client": "client-home-AAAAAAAA.js", "css": "shared.css", "html": "home-abcdef12.html", "render": "static"},
+		"app": {"server": "app-server.js", "client": "client-app-BBBBBBBB.js", "css": "shared.css", "render": "client"}
+	}`)
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+	})
+
+	loaderCalls := 0
+	static := NewPage("pages/home.tsx").WithLoader(func(r *http.Request) map[string]any {
+		loaderCalls++
+		return nil
+	})
+	rec := httptest.NewRecorder()
+	static.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Body.String() != "<html>prerendered</html>" || loaderCalls != 0 {
+		t.Fatalf("static page: body %q, loader calls %d", rec.Body.String(), loaderCalls)
+	}
+
+	client := NewPage("pages/app.tsx").WithLoader(func(r *http.Request) map[string]any {
+		return map[string]any{"user": "ada"}
+	})
+	rec = httptest.NewRecorder()
+	client.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/app", nil))
+	body := rec.Body.String()
+	if !strings.Contains(body, `<div id="app-root"></div>`) || !strings.Contains(body, `"user":"ada"`) {
+		t.Fatalf("client shell missing empty root or props:\n%s", body)
+	}
+	if !strings.Contains(body, "/dist/build/client-app-BBBBBBBB.js") {
+		t.Fatalf("client shell missing script:\n%s", body)
+	}
+}
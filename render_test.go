@@ -3,11 +3,14 @@ package alloy
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/evanw/esbuild/pkg/api"
 )
 
 func TestRenderTSXFileWithHydration(t *testing.T) {
@@ -99,6 +102,37 @@ func TestRenderTSXFileWithHydrationRequiresRegisteredInProd(t *testing.T) {
 	if !strings.Contains(err.Error(), "not registered") {
 		t.Fatalf("unexpected error: %v", err)
 	}
+	if !errors.Is(err, ErrBundleNotRegistered) {
+		t.Fatalf("expected ErrBundleNotRegistered, got %v", err)
+	}
+}
+
+func TestRenderMissingComponentIsErrComponentNotFound(t *testing.T) {
+	_, err := RenderTSXFileWithHydrationWithContext(context.Background(), filepath.Join(t.TempDir(), "missing.tsx"), nil, "root")
+	if !errors.Is(err, ErrComponentNotFound) {
+		t.Fatalf("expected ErrComponentNotFound, got %v", err)
+	}
+}
+
+func TestCheckBuildErrorsReturnsBuildError(t *testing.T) {
+	err := checkBuildErrors(api.BuildResult{Errors: []api.Message{{
+		Text:     "Expected \";\" but found \"}\"",
+		Location: &api.Location{File: "app/pages/home.tsx", Line: 12, Column: 4},
+	}}}, "esbuild server bundle home.tsx")
+
+	var buildErr *BuildError
+	if !errors.As(err, &buildErr) {
+		t.Fatalf("expected *BuildError, got %T", err)
+	}
+	if buildErr.File != "app/pages/home.tsx" || buildErr.Line != 12 || buildErr.Column != 5 {
+		t.Fatalf("unexpected location: %+v", buildErr)
+	}
+	if !strings.Contains(err.Error(), "app/pages/home.tsx:12:5") {
+		t.Fatalf("unexpected message: %v", err)
+	}
+	if checkBuildErrors(api.BuildResult{}, "build") != nil {
+		t.Fatal("expected nil for a clean build")
+	}
 }
 
 func TestRenderResultToHTMLWithAssets(t *testing.T) {
@@ -298,12 +332,15 @@ func TestSaveFilesAndWriteManifest(t *testing.T) {
 		t.Fatalf("read manifest: %v", err)
 	}
 
-	var manifest map[string]manifestEntry
-	if err := json.Unmarshal(data, &manifest); err != nil {
+	manifest, err := ParseManifest(data)
+	if err != nil {
 		t.Fatalf("decode manifest: %v", err)
 	}
+	if manifest.Version != ManifestVersion {
+		t.Fatalf("manifest version = %d, want %d", manifest.Version, ManifestVersion)
+	}
 
-	entry, ok := manifest["home"]
+	entry, ok := manifest.Pages["home"]
 	if !ok {
 		t.Fatalf("manifest missing home entry")
 	}
@@ -397,7 +434,7 @@ func TestResolvePrebuiltFilesReadsManifest(t *testing.T) {
 	}
 
 	manifestPath := filepath.Join(distDir, "manifest.json")
-	entry := map[string]manifestEntry{
+	entry := map[string]ManifestPage{
 		"home": {
 			Server: "home-aaaa1111-server.js",
 			Client: "home-bbbb2222-client.js",
@@ -977,3 +1014,58 @@ func resetBundleCache() {
 	bundleCache.entries = make(map[string]*bundleCacheEntry)
 	bundleCache.Unlock()
 }
+
+func resetISRCache() {
+	isrCache.Lock()
+	isrCache.entries = make(map[string]*isrEntry)
+	isrCache.order.Init()
+	isrCache.Unlock()
+}
+
+func withTestConfig(t testing.TB, opt func(*Config)) {
+	t.Helper()
+	prev := getConfig()
+	cfg := *prev
+	cfg.ISRDir = t.TempDir()
+	opt(&cfg)
+	globalConfig.Store(&cfg)
+	t.Cleanup(func() {
+		isrRegenerations.Wait()
+		globalConfig.Store(prev)
+	})
+}
+
+func TestCommonBuildOptionsIncludesBuildPlugins(t *testing.T) {
+	plugin := api.Plugin{
+		Name: "virtual-greeting",
+		Setup: func(build api.PluginBuild) {
+			build.OnResolve(api.OnResolveOptions{Filter: `^virtual:greeting$`}, func(args api.OnResolveArgs) (api.OnResolveResult, error) {
+				return api.OnResolveResult{Path: args.Path, Namespace: "virtual"}, nil
+			})
+			build.OnLoad(api.OnLoadOptions{Filter: `.*`, Namespace: "virtual"}, func(args api.OnLoadArgs) (api.OnLoadResult, error) {
+				contents := `export default "hello from plugin";`
+				return api.OnLoadResult{Contents: &contents, Loader: api.LoaderJS}, nil
+			})
+		},
+	}
+	withTestConfig(t, func(cfg *Config) {
+		cfg.BuildPlugins = []api.Plugin{plugin}
+	})
+
+	entry := filepath.Join(t.TempDir(), "entry.ts")
+	if err := os.WriteFile(entry, []byte(`import greeting from "virtual:greeting"; console.log(greeting);`), 0644); err != nil {
+		t.Fatalf("write entry: %v", err)
+	}
+
+	opts := commonBuildOptions()
+	opts.EntryPoints = []string{entry}
+	opts.Write = false
+
+	result := api.Build(opts)
+	if err := checkBuildErrors(result, "build"); err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if !strings.Contains(string(result.OutputFiles[0].Contents), "hello from plugin") {
+		t.Fatalf("plugin output missing: %s", result.OutputFiles[0].Contents)
+	}
+}
@@ -0,0 +1,57 @@
+package alloy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+type RenderRequest struct {
+	Bundle  string         `json:"bundle"`
+	Code    string         `json:"code,omitempty"`
+	Props   map[string]any `json:"props"`
+	Context map[string]any `json:"context,omitempty"`
+}
+
+type RenderResponse struct {
+	HTML  string              `json:"html"`
+	Head  []HeadTag           `json:"head,omitempty"`
+	Slots map[string][]string `json:"slots,omitempty"`
+	Error string              `json:"error,omitempty"`
+}
+
+type Renderer interface {
+	Render(ctx context.Context, req RenderRequest) (RenderResponse, error)
+}
+
+func bundleID(serverJS string) string {
+	sum := sha256.Sum256([]byte(serverJS))
+	return hex.EncodeToString(sum[:8])
+}
+
+func configuredRenderer() Renderer {
+	cfg := getConfig()
+	switch {
+	case cfg == nil:
+		return nil
+	case cfg.Renderer != nil:
+		return cfg.Renderer
+	case cfg.RemoteRenderer != "":
+		return NewRemoteRenderer(cfg.RemoteRenderer)
+	}
+	return nil
+}
+
+func applyServerPlatform(opts *api.BuildOptions) {
+	opts.External = serverExternalNames()
+	if _, ok := configuredRenderer().(*NodeRenderer); ok {
+		opts.Platform = api.PlatformNode
+		return
+	}
+	opts.Platform = api.PlatformBrowser
+	if nodeShimsEnabled() {
+		opts.Plugins = append(opts.Plugins, nodeShimsPlugin())
+	}
+}
@@ -0,0 +1,63 @@
+package alloy
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type requestInfo struct {
+	component string
+	pattern   string
+	render    time.Duration
+}
+
+type requestInfoKey struct{}
+
+func RequestLogger() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			info := &requestInfo{}
+			req := r.WithContext(context.WithValue(r.Context(), requestInfoKey{}, info))
+			sw := &statusWriter{ResponseWriter: w}
+
+			next.ServeHTTP(sw, req)
+
+			pattern := info.pattern
+			if pattern == "" {
+				pattern = req.Pattern
+			}
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("route", pattern),
+				slog.Int("status", sw.Status()),
+				slog.Int64("bytes", sw.bytes),
+				slog.Duration("duration", time.Since(start)),
+			}
+			if info.component != "" {
+				attrs = append(attrs,
+					slog.String("page", pageName(info.component)),
+					slog.String("component", info.component),
+					slog.Duration("render", info.render),
+				)
+			}
+
+			level := slog.LevelInfo
+			if sw.Status() >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			logger().LogAttrs(r.Context(), level, "request", attrs...)
+		})
+	}
+}
+
+func recordRequestInfo(r *http.Request, component string, render time.Duration) {
+	if info, ok := r.Context().Value(requestInfoKey{}).(*requestInfo); ok {
+		info.component = component
+		info.pattern = r.Pattern
+		info.render = render
+	}
+}
@@ -0,0 +1,68 @@
+package alloy
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRequestLoggerAddsPageFields(t *testing.T) {
+	resetBundleCache()
+	t.Cleanup(resetBundleCache)
+
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "docs-server.js"), `var __Component = { default: function(props) { return "<p>" + props.slug + "</p>"; } };`)
+	writeTestFile(t, filepath.Join(dist, "client-docs-AAAAAAAA.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "shared.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{"docs": {"server": "docs-server.js", "client": "client-docs-AAAAAAAA.js", "css": "shared.css"}}`)
+
+	var buf bytes.Buffer
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+		cfg.Logger = slog.New(slog.NewJSONHandler(&buf, nil))
+	})
+
+	component := filepath.Join(root, "pages", "docs.tsx")
+	mux := http.NewServeMux()
+	mux.Handle("/docs/{slug}", NewPage(component).WithLoader(func(r *http.Request) map[string]any {
+		return map[string]any{"slug": r.PathValue("slug")}
+	}))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	handler := RequestLogger()(mux)
+
+	for _, target := range []string{"/docs/intro", "/healthz"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		if record["msg"] == "request" {
+			records = append(records, record)
+		}
+	}
+	if len(records) != 2 {
+		t.Fatalf("request records: %v", records)
+	}
+
+	page := records[0]
+	if page["method"] != "GET" || page["route"] != "/docs/{slug}" || page["page"] != "docs" || page["component"] != component || page["status"] != float64(200) || page["bytes"].(float64) <= 0 || page["render"] == nil {
+		t.Fatalf("page record: %v", page)
+	}
+	plain := records[1]
+	if plain["route"] != "/healthz" || plain["bytes"] != float64(2) || plain["page"] != nil {
+		t.Fatalf("plain record: %v", plain)
+	}
+}
@@ -0,0 +1,124 @@
+package alloy
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMissingParam = errors.New("missing route param")
+
+	routeWildcard = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)(\.\.\.)?\}`)
+	uuidPattern   = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+type Params struct {
+	Pattern  string
+	Wildcard string
+	names    []string
+	values   map[string]string
+}
+
+func RouteParams(r *http.Request) Params {
+	params := Params{Pattern: r.Pattern, values: map[string]string{}}
+	for _, match := range routeWildcard.FindAllStringSubmatch(r.Pattern, -1) {
+		name := match[1]
+		value := r.PathValue(name)
+		params.names = append(params.names, name)
+		params.values[name] = value
+		if match[2] != "" {
+			params.Wildcard = value
+		}
+	}
+	return params
+}
+
+func (p Params) Names() []string {
+	return p.names
+}
+
+func (p Params) Get(name string) string {
+	return p.values[name]
+}
+
+func (p Params) Lookup(name string) (string, bool) {
+	value, ok := p.values[name]
+	return value, ok
+}
+
+func (p Params) Map() map[string]string {
+	return maps.Clone(p.values)
+}
+
+func (p Params) Segments() []string {
+	if p.Wildcard == "" {
+		return nil
+	}
+	return strings.Split(p.Wildcard, "/")
+}
+
+func (p Params) Int(name string) (int, error) {
+	value, err := p.required(name)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("🔴 route param %s=%q is not an integer", name, value)
+	}
+	return n, nil
+}
+
+func (p Params) UUID(name string) (string, error) {
+	value, err := p.required(name)
+	if err != nil {
+		return "", err
+	}
+	if !uuidPattern.MatchString(value) {
+		return "", fmt.Errorf("🔴 route param %s=%q is not a UUID", name, value)
+	}
+	return strings.ToLower(value), nil
+}
+
+func (p Params) Date(name string) (time.Time, error) {
+	value, err := p.required(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("🔴 route param %s=%q is not a date (YYYY-MM-DD)", name, value)
+	}
+	return date, nil
+}
+
+func (p Params) required(name string) (string, error) {
+	value := p.values[name]
+	if value == "" {
+		return "", fmt.Errorf("🔴 route param %s: %w", name, ErrMissingParam)
+	}
+	return value, nil
+}
+
+func withRouteContext(r *http.Request) *http.Request {
+	params := RouteParams(r).Map()
+	if len(params) == 0 {
+		return r
+	}
+	return r.WithContext(withRenderContextValue(r.Context(), "params", params))
+}
+
+func WithRouteParams(r *http.Request, pattern string, params map[string]string) *http.Request {
+	r = r.WithContext(r.Context())
+	r.Pattern = pattern
+	for name, value := range params {
+		r.SetPathValue(name, value)
+	}
+	return r
+}
@@ -0,0 +1,88 @@
+package alloy
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func routeParamsFor(t *testing.T, pattern, target string) Params {
+	t.Helper()
+	var params Params
+	mux := http.NewServeMux()
+	mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		params = RouteParams(r)
+	})
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	return params
+}
+
+func TestRouteParamsExposesPatternAndWildcard(t *testing.T) {
+	params := routeParamsFor(t, "GET /docs/{version}/{path...}", "/docs/v2/guides/routing")
+
+	if params.Pattern != "GET /docs/{version}/{path...}" {
+		t.Fatalf("🔴 unexpected pattern %q", params.Pattern)
+	}
+	if params.Get("version") != "v2" || params.Wildcard != "guides/routing" || params.Get("path") != "guides/routing" {
+		t.Fatalf("🔴 unexpected params %v wildcard %q", params.Map(), params.Wildcard)
+	}
+	if strings.Join(params.Segments(), ",") != "guides,routing" || strings.Join(params.Names(), ",") != "version,path" {
+		t.Fatalf("🔴 unexpected segments %v names %v", params.Segments(), params.Names())
+	}
+	if _, ok := params.Lookup("missing"); ok {
+		t.Fatal("🔴 expected missing param to be absent")
+	}
+}
+
+func TestRouteParamsTypedGetters(t *testing.T) {
+	params := routeParamsFor(t, "/orders/{id}/{ref}/{day}", "/orders/42/0F8FAD5B-D9CB-469F-A165-70867728950E/2026-03-01")
+
+	if id, err := params.Int("id"); err != nil || id != 42 {
+		t.Fatalf("🔴 Int: %d %v", id, err)
+	}
+	if ref, err := params.UUID("ref"); err != nil || ref != "0f8fad5b-d9cb-469f-a165-70867728950e" {
+		t.Fatalf("🔴 UUID: %q %v", ref, err)
+	}
+	if day, err := params.Date("day"); err != nil || !day.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("🔴 Date: %v %v", day, err)
+	}
+}
+
+func TestRouteParamsTypedGetterErrors(t *testing.T) {
+	params := routeParamsFor(t, "/orders/{id}", "/orders/abc")
+
+	if _, err := params.Int("id"); err == nil || !strings.Contains(err.Error(), `id="abc"`) {
+		t.Fatalf("🔴 expected Int error, got %v", err)
+	}
+	if _, err := params.UUID("id"); err == nil {
+		t.Fatal("🔴 expected UUID error")
+	}
+	if _, err := params.Date("id"); err == nil {
+		t.Fatal("🔴 expected Date error")
+	}
+	if _, err := params.Int("page"); !errors.Is(err, ErrMissingParam) {
+		t.Fatalf("🔴 expected ErrMissingParam, got %v", err)
+	}
+}
+
+func TestRouteParamsWithoutPattern(t *testing.T) {
+	params := RouteParams(httptest.NewRequest(http.MethodGet, "/about", nil))
+	if params.Pattern != "" || len(params.Map()) != 0 || params.Get("slug") != "" {
+		t.Fatalf("🔴 expected empty params, got %+v", params)
+	}
+}
+
+func TestWithRouteParams(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/files/a/b", nil)
+	params := RouteParams(WithRouteParams(req, "/files/{path...}", map[string]string{"path": "a/b"}))
+
+	if params.Pattern != "/files/{path...}" || params.Wildcard != "a/b" {
+		t.Fatalf("🔴 unexpected params %+v", params)
+	}
+	if req.Pattern != "" || req.PathValue("path") != "" {
+		t.Fatal("🔴 expected original request to be unchanged")
+	}
+}
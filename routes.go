@@ -0,0 +1,104 @@
+package alloy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+)
+
+func PageRoute(name string) string {
+	switch name {
+	case "home", "index":
+		return "/{$}"
+	}
+	return "/" + filepath.ToSlash(name)
+}
+
+func Routes(mux *http.ServeMux, loaders map[string]func(r *http.Request) map[string]any) error {
+	pages, err := routePages()
+	if err != nil {
+		return err
+	}
+	if len(pages) == 0 {
+		return fmt.Errorf("🔴 no pages to route")
+	}
+
+	for name := range loaders {
+		if !slices.ContainsFunc(pages, func(page PageSpec) bool { return page.Name == name }) {
+			return fmt.Errorf("🔴 loader for unknown page %s", name)
+		}
+	}
+
+	routes := map[string]string{}
+	for _, page := range pages {
+		route := PageRoute(page.Name)
+		if other, ok := routes[route]; ok {
+			return fmt.Errorf("🔴 pages %s and %s both route to %s", other, page.Name, route)
+		}
+		routes[route] = page.Name
+	}
+
+	for _, page := range pages {
+		handler := NewPage(page.Component)
+		if loader := loaders[page.Name]; loader != nil {
+			handler.WithLoader(loader)
+		}
+		mux.Handle(PageRoute(page.Name), handler)
+		registerPageRoute(PageRoute(page.Name), page.Component)
+	}
+	return nil
+}
+
+func routePages() ([]PageSpec, error) {
+	cfg := getConfig()
+	pagesDir := DefaultPagesDir
+	if cfg != nil && cfg.PagesDir != "" {
+		pagesDir = cfg.PagesDir
+	}
+
+	if cfg != nil && cfg.FS != nil && os.Getenv("ALLOY_DEV") != "1" {
+		if manifest, err := ReadManifest(cfg.FS, DefaultDistDir); err == nil {
+			var pages []PageSpec
+			for name := range manifest.Pages {
+				pages = append(pages, PageSpec{
+					Component: filepath.Join(pagesDir, name+".tsx"),
+					Name:      name,
+					RootID:    defaultRootID(name),
+				})
+			}
+			sort.Slice(pages, func(i, j int) bool { return pages[i].Name < pages[j].Name })
+			return pages, nil
+		}
+	}
+
+	return DiscoverPages(pagesDir)
+}
+
+func DataLoaders(dir string) (map[string]func(r *http.Request) map[string]any, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("🔴 find data files: %w", err)
+	}
+
+	loaders := map[string]func(r *http.Request) map[string]any{}
+	for _, match := range matches {
+		data, err := os.ReadFile(match)
+		if err != nil {
+			return nil, fmt.Errorf("🔴 read %s: %w", FormatPath(match), err)
+		}
+		var props map[string]any
+		if err := json.Unmarshal(data, &props); err != nil {
+			return nil, fmt.Errorf("🔴 decode %s: %w", FormatPath(match), err)
+		}
+		loaders[pageName(match)] = func(r *http.Request) map[string]any {
+			fresh := map[string]any{}
+			json.Unmarshal(data, &fresh)
+			return fresh
+		}
+	}
+	return loaders, nil
+}
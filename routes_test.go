@@ -0,0 +1,125 @@
+package alloy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func routesTestDist(t *testing.T) {
+	t.Helper()
+	resetBundleCache()
+	t.Cleanup(resetBundleCache)
+
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "home-server.js"), `var __Component = function() { return "<p>home</p>"; };`)
+	writeTestFile(t, filepath.Join(dist, "about-server.js"), `var __Component = function(props) { return "<p>" + props.team + "</p>"; };`)
+	writeTestFile(t, filepath.Join(dist, "client-home.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "client-about.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "shared.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{"home": {"server": "home-server.js", "client": "client-home.js", "css": "shared.css"}, "about": {"server": "about-server.js", "client": "client-about.js", "css": "shared.css"}}`)
+	withTestConfig(t, func(cfg *Config) { cfg.FS = os.DirFS(root) })
+}
+
+func TestRoutesRegistersManifestPages(t *testing.T) {
+	routesTestDist(t)
+
+	mux := http.NewServeMux()
+	err := Routes(mux, map[string]func(r *http.Request) map[string]any{
+		"about": func(r *http.Request) map[string]any { return map[string]any{"team": "core"} },
+	})
+	if err != nil {
+		t.Fatalf("🔴 Routes: %v", err)
+	}
+
+	for target, want := range map[string]string{"/": "<p>home</p>", "/about": "<p>core</p>"} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), want) {
+			t.Fatalf("🔴 %s: expected %s, got %d\n%s", target, want, rec.Code, rec.Body.String())
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("🔴 expected 404 for unknown route, got %d", rec.Code)
+	}
+}
+
+func TestRoutesRejectsUnknownLoader(t *testing.T) {
+	routesTestDist(t)
+
+	err := Routes(http.NewServeMux(), map[string]func(r *http.Request) map[string]any{"blog": nil})
+	if err == nil || !strings.Contains(err.Error(), "blog") {
+		t.Fatalf("🔴 expected unknown loader error, got %v", err)
+	}
+}
+
+func TestRoutesDiscoversPagesWithoutManifest(t *testing.T) {
+	pagesDir := t.TempDir()
+	writeTestFile(t, filepath.Join(pagesDir, "index.tsx"), "export default function Page() {}")
+	writeTestFile(t, filepath.Join(pagesDir, "pricing.tsx"), "export default function Page() {}")
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(t.TempDir())
+		cfg.PagesDir = pagesDir
+	})
+
+	mux := http.NewServeMux()
+	if err := Routes(mux, nil); err != nil {
+		t.Fatalf("🔴 Routes: %v", err)
+	}
+	for target, pattern := range map[string]string{"/": "/{$}", "/pricing": "/pricing"} {
+		if _, got := mux.Handler(httptest.NewRequest(http.MethodGet, target, nil)); got != pattern {
+			t.Fatalf("🔴 %s: expected pattern %s, got %q", target, pattern, got)
+		}
+	}
+}
+
+func TestRoutesRejectsConflictingPages(t *testing.T) {
+	pagesDir := t.TempDir()
+	writeTestFile(t, filepath.Join(pagesDir, "index.tsx"), "export default function Page() {}")
+	writeTestFile(t, filepath.Join(pagesDir, "home.tsx"), "export default function Page() {}")
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(t.TempDir())
+		cfg.PagesDir = pagesDir
+	})
+
+	if err := Routes(http.NewServeMux(), nil); err == nil {
+		t.Fatal("🔴 expected conflict between home and index")
+	}
+}
+
+func TestDataLoadersReadJSONProps(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "about.json"), `{"team": "core", "tags": ["a"]}`)
+	writeTestFile(t, filepath.Join(dir, "notes.txt"), "ignored")
+
+	loaders, err := DataLoaders(dir)
+	if err != nil {
+		t.Fatalf("🔴 DataLoaders: %v", err)
+	}
+	if len(loaders) != 1 || loaders["about"] == nil {
+		t.Fatalf("🔴 expected one loader for about, got %v", sortedKeys(loaders))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/about", nil)
+	first := loaders["about"](req)
+	first["team"] = "changed"
+	if second := loaders["about"](req); second["team"] != "core" {
+		t.Fatalf("🔴 expected fresh props per request, got %v", second)
+	}
+}
+
+func TestDataLoadersRejectInvalidJSON(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "about.json"), `{`)
+
+	if _, err := DataLoaders(dir); err == nil || !strings.Contains(err.Error(), "about.json") {
+		t.Fatalf("🔴 expected decode error, got %v", err)
+	}
+}
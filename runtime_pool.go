@@ -0,0 +1,145 @@
+package alloy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+type runtimePolicy struct {
+	size       int
+	maxRenders int
+	maxAge     time.Duration
+}
+
+type runtimePool struct {
+	policy runtimePolicy
+	jobs   chan ssrJob
+	stop   chan struct{}
+}
+
+type ssrJob struct {
+	ctx   context.Context
+	code  string
+	props map[string]any
+	done  chan ssrResult
+}
+
+type ssrResult struct {
+	out RenderResponse
+	err error
+}
+
+var pools struct {
+	sync.Mutex
+	current *runtimePool
+}
+
+func currentRuntimePool() *runtimePool {
+	var policy runtimePolicy
+	if cfg := getConfig(); cfg != nil {
+		policy = runtimePolicy{size: cfg.RuntimePoolSize, maxRenders: cfg.RuntimeMaxRenders, maxAge: cfg.RuntimeMaxAge}
+	}
+
+	pools.Lock()
+	defer pools.Unlock()
+	if pools.current != nil && pools.current.policy == policy {
+		return pools.current
+	}
+	if pools.current != nil {
+		close(pools.current.stop)
+		pools.current = nil
+	}
+	if policy.size > 0 {
+		pools.current = newRuntimePool(policy)
+	}
+	return pools.current
+}
+
+func newRuntimePool(policy runtimePolicy) *runtimePool {
+	p := &runtimePool{policy: policy, jobs: make(chan ssrJob), stop: make(chan struct{})}
+	for range policy.size {
+		go p.work()
+	}
+	return p
+}
+
+func (p *runtimePool) render(ctx context.Context, code string, props map[string]any) (RenderResponse, error) {
+	job := ssrJob{ctx: ctx, code: code, props: props, done: make(chan ssrResult, 1)}
+	select {
+	case p.jobs <- job:
+	case <-p.stop:
+		return RenderResponse{}, fmt.Errorf("🔴 wait for runtime: pool closed")
+	case <-ctx.Done():
+		return RenderResponse{}, fmt.Errorf("🔴 wait for runtime: %w", ctx.Err())
+	}
+	res := <-job.done
+	return res.out, res.err
+}
+
+func (p *runtimePool) work() {
+	runtime.LockOSThread()
+
+	var (
+		vm      *jsRuntime
+		renders int
+		started time.Time
+	)
+	retire := func(reason string) {
+		closeRuntime(vm)
+		vm = nil
+		metrics.runtimeRecycles.inc(reason)
+		runtimeRecycled.Add(1)
+	}
+
+	for {
+		select {
+		case <-p.stop:
+			closeRuntime(vm)
+			return
+		case job := <-p.jobs:
+			if vm != nil && p.policy.maxAge > 0 && time.Since(started) > p.policy.maxAge {
+				retire("age")
+			}
+			if vm == nil {
+				created, err := newRuntimeWithContext()
+				if err != nil {
+					job.done <- ssrResult{err: fmt.Errorf("🔴 create runtime: %w", err)}
+					continue
+				}
+				vm, renders, started = created, 0, time.Now()
+			}
+
+			out, err := renderOnRuntime(job.ctx, vm, job.code, job.props, true)
+			renders++
+			switch {
+			case job.ctx.Err() != nil:
+				retire("interrupted")
+			case p.policy.maxRenders > 0 && renders >= p.policy.maxRenders:
+				retire("renders")
+			}
+			job.done <- ssrResult{out: out, err: err}
+		}
+	}
+}
+
+func renderOnRuntime(ctx context.Context, vm *jsRuntime, code string, props map[string]any, reused bool) (RenderResponse, error) {
+	vm.rt.SetInterruptHandler(makeInterruptHandler(ctx))
+	defer vm.rt.ClearInterruptHandler()
+
+	if reused {
+		reset := vm.ctx.Eval("__alloyTimers.reset(); __alloyGlobals.restore()")
+		reset.Free()
+	}
+	out, err := runSSR(ctx, vm.ctx, code, props)
+	if reused {
+		sampleRuntimeMemory(vm)
+	}
+	if err != nil && ctx.Err() != nil && !errors.Is(err, ErrRenderTimeout) {
+		return RenderResponse{}, fmt.Errorf("🔴 %w: %w", ErrRenderTimeout, err)
+	}
+	return out, err
+}
@@ -0,0 +1,154 @@
+package alloy
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+const poolTestBundle = `var __Component = function(props) {
+	globalThis.renders = (globalThis.renders || 0) + 1;
+	return "<p>" + props.name + "</p>";
+};`
+
+func withRuntimePool(t *testing.T, opt func(*Config)) {
+	t.Helper()
+	t.Cleanup(func() { currentRuntimePool() })
+	withTestConfig(t, opt)
+}
+
+func recycles(reason string) float64 {
+	c := metrics.runtimeRecycles
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[labelKey(c.labels, []string{reason})]
+}
+
+func renderPooled(t *testing.T, ctx context.Context, code string) (string, error) {
+	t.Helper()
+	out, err := executeSSR(ctx, code, map[string]any{"name": "pooled"})
+	return out.HTML, err
+}
+
+func TestRuntimePoolReusesRuntimes(t *testing.T) {
+	withRuntimePool(t, func(cfg *Config) { cfg.RuntimePoolSize = 1 })
+
+	before := runtimesCreated.Load()
+	for range 5 {
+		html, err := renderPooled(t, context.Background(), poolTestBundle)
+		if err != nil || html != "<p>pooled</p>" {
+			t.Fatalf("🔴 render: %q %v", html, err)
+		}
+	}
+	if created := runtimesCreated.Load() - before; created != 1 {
+		t.Fatalf("🔴 expected one runtime, created %d", created)
+	}
+}
+
+func TestRuntimePoolRetiresAfterMaxRenders(t *testing.T) {
+	withRuntimePool(t, func(cfg *Config) {
+		cfg.RuntimePoolSize = 1
+		cfg.RuntimeMaxRenders = 2
+	})
+
+	before := runtimesCreated.Load()
+	recycled := recycles("renders")
+	for range 5 {
+		if _, err := renderPooled(t, context.Background(), poolTestBundle); err != nil {
+			t.Fatalf("render: %v", err)
+		}
+	}
+	if created := runtimesCreated.Load() - before; created != 3 {
+		t.Fatalf("🔴 expected three runtimes, created %d", created)
+	}
+	if got := recycles("renders") - recycled; got != 2 {
+		t.Fatalf("🔴 expected two recycles, got %v", got)
+	}
+}
+
+func TestRuntimePoolRetiresAfterMaxAge(t *testing.T) {
+	withRuntimePool(t, func(cfg *Config) {
+		cfg.RuntimePoolSize = 1
+		cfg.RuntimeMaxAge = 10 * time.Millisecond
+	})
+
+	recycled := recycles("age")
+	renderPooled(t, context.Background(), poolTestBundle)
+	time.Sleep(20 * time.Millisecond)
+	renderPooled(t, context.Background(), poolTestBundle)
+	if got := recycles("age") - recycled; got != 1 {
+		t.Fatalf("🔴 expected one age recycle, got %v", got)
+	}
+}
+
+func TestRuntimePoolDropsTimersAndInterruptedRuntimes(t *testing.T) {
+	withRuntimePool(t, func(cfg *Config) { cfg.RuntimePoolSize = 1 })
+
+	leaky := `var __Component = function() { setTimeout(function() { throw new Error("leaked timer"); }, 0); return "ok"; };`
+	async := `var __Component = function() { return new Promise(function(resolve) { setTimeout(function() { resolve("done"); }, 1); }); };`
+	if _, err := renderPooled(t, context.Background(), leaky); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if html, err := renderPooled(t, context.Background(), async); err != nil || html != "done" {
+		t.Fatalf("🔴 previous render's timers leaked: %q %v", html, err)
+	}
+
+	recycled := recycles("interrupted")
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := renderPooled(t, ctx, `var __Component = function() { while (true) {} };`)
+	if err == nil || !strings.Contains(err.Error(), "interrupted") {
+		t.Fatalf("🔴 expected interrupted render, got %v", err)
+	}
+	if got := recycles("interrupted") - recycled; got != 1 {
+		t.Fatalf("🔴 expected interrupted runtime to be retired, got %v", got)
+	}
+	if html, err := renderPooled(t, context.Background(), poolTestBundle); err != nil || html != "<p>pooled</p>" {
+		t.Fatalf("🔴 pool should recover: %q %v", html, err)
+	}
+}
+
+func TestRuntimePoolRestoresGlobals(t *testing.T) {
+	withRuntimePool(t, func(cfg *Config) { cfg.RuntimePoolSize = 1 })
+
+	code := `var __Component = function() {
+	var seen = typeof globalThis.user + "," + typeof parseInt;
+	globalThis.user = "alice";
+	parseInt = null;
+	return seen;
+};`
+	for range 3 {
+		html, err := renderPooled(t, context.Background(), code)
+		if err != nil || html != "undefined,function" {
+			t.Fatalf("🔴 previous render's globals leaked: %q %v", html, err)
+		}
+	}
+}
+
+func TestRuntimePoolFailsQueuedCallersOnClose(t *testing.T) {
+	withRuntimePool(t, func(cfg *Config) { cfg.RuntimePoolSize = 1 })
+
+	pool := currentRuntimePool()
+	slow := `var __Component = function() { var end = Date.now() + 100; while (Date.now() < end) {} return "slow"; };`
+	go pool.render(context.Background(), slow, nil)
+	time.Sleep(20 * time.Millisecond)
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := pool.render(context.Background(), poolTestBundle, map[string]any{"name": "queued"})
+		errs <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	getConfig().RuntimePoolSize = 2
+	currentRuntimePool()
+
+	select {
+	case err := <-errs:
+		if err == nil || !strings.Contains(err.Error(), "pool closed") {
+			t.Fatalf("🔴 expected pool closed error, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("🔴 queued caller still blocked after the pool closed")
+	}
+}
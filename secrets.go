@@ -0,0 +1,142 @@
+package alloy
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+const minSecretLength = 8
+
+var DefaultSecretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`),
+	regexp.MustCompile(`AKIA[0-9A-Z]{16}`),
+	regexp.MustCompile(`gh[pousr]_[A-Za-z0-9]{36}`),
+	regexp.MustCompile(`sk_live_[0-9A-Za-z]{24,}`),
+	regexp.MustCompile(`xox[baprs]-[0-9A-Za-z-]{10,}`),
+}
+
+var nonSecretEnv = map[string]bool{
+	"HOME": true, "PATH": true, "PWD": true, "OLDPWD": true, "SHELL": true,
+	"USER": true, "LOGNAME": true, "TERM": true, "LANG": true, "TMPDIR": true,
+	"HOSTNAME": true, "EDITOR": true, "GOPATH": true, "GOROOT": true,
+	"GOMODCACHE": true, "GOFLAGS": true, "GOPROXY": true, "NODE_ENV": true,
+	"TERM_PROGRAM": true, "COLORTERM": true, "GITHUB_REPOSITORY": true,
+	"GITHUB_REF": true, "GITHUB_REF_NAME": true, "GITHUB_SHA": true,
+}
+
+var nonSecretValues = map[string]bool{
+	"true": true, "false": true, "production": true, "development": true, "localhost": true,
+}
+
+func noisyEnvValue(value string) bool {
+	if nonSecretValues[strings.ToLower(value)] || filepath.IsAbs(value) || strings.HasPrefix(value, "~/") {
+		return true
+	}
+	return strings.Trim(value, "0123456789.") == ""
+}
+
+type SecretLeak struct {
+	File   string
+	Offset int
+	Source string
+}
+
+type SecretLeakError struct {
+	Leaks []SecretLeak
+}
+
+func (e *SecretLeakError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🔴 %d secret(s) found in client bundles", len(e.Leaks))
+	for _, leak := range e.Leaks {
+		fmt.Fprintf(&b, "\n\t%s offset %d: %s", FormatPath(leak.File), leak.Offset, leak.Source)
+	}
+	return b.String()
+}
+
+func ScanForSecrets(files map[string][]byte, patterns []*regexp.Regexp) error {
+	secrets := secretEnvValues()
+	allPatterns := append(append([]*regexp.Regexp{}, DefaultSecretPatterns...), patterns...)
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var leaks []SecretLeak
+	for _, name := range names {
+		contents := files[name]
+		for value, envName := range secrets {
+			if offset := bytes.Index(contents, []byte(value)); offset >= 0 {
+				leaks = append(leaks, SecretLeak{File: name, Offset: offset, Source: "env " + envName})
+			}
+		}
+		for _, pattern := range allPatterns {
+			if loc := pattern.FindIndex(contents); loc != nil {
+				leaks = append(leaks, SecretLeak{File: name, Offset: loc[0], Source: "pattern " + pattern.String()})
+			}
+		}
+	}
+
+	if len(leaks) > 0 {
+		sort.SliceStable(leaks, func(i, j int) bool {
+			if leaks[i].File != leaks[j].File {
+				return leaks[i].File < leaks[j].File
+			}
+			return leaks[i].Offset < leaks[j].Offset
+		})
+		return &SecretLeakError{Leaks: leaks}
+	}
+	return nil
+}
+
+func scanOutputFiles(outputs []api.OutputFile, patterns []*regexp.Regexp) error {
+	files := make(map[string][]byte, len(outputs))
+	for _, out := range outputs {
+		if filepath.Ext(out.Path) == ".map" {
+			continue
+		}
+		files[out.Path] = out.Contents
+	}
+	return ScanForSecrets(files, patterns)
+}
+
+func writeOutputFiles(outputs []api.OutputFile) error {
+	for _, out := range outputs {
+		if err := os.MkdirAll(filepath.Dir(out.Path), 0755); err != nil {
+			return fmt.Errorf("🔴 make output dir: %w", err)
+		}
+		if err := os.WriteFile(out.Path, out.Contents, 0644); err != nil {
+			return fmt.Errorf("🔴 write %s: %w", FormatPath(out.Path), err)
+		}
+	}
+	return nil
+}
+
+func secretEnvValues() map[string]string {
+	public := map[string]bool{}
+	for _, value := range PublicEnv() {
+		public[value] = true
+	}
+
+	values := map[string]string{}
+	for _, kv := range os.Environ() {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || strings.HasPrefix(key, PublicEnvPrefix) || nonSecretEnv[key] || strings.HasPrefix(key, "LC_") || strings.HasPrefix(key, "XDG_") {
+			continue
+		}
+		if len(value) < minSecretLength || public[value] || noisyEnvValue(value) {
+			continue
+		}
+		values[value] = key
+	}
+	return values
+}
@@ -0,0 +1,123 @@
+package alloy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+type ServeOptions struct {
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	ShutdownTimeout   time.Duration
+	Listener          net.Listener
+}
+
+var activeRenders sync.WaitGroup
+
+func Serve(ctx context.Context, addr string, handler http.Handler, options ...func(*ServeOptions)) error {
+	opts := ServeOptions{
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      60 * time.Second,
+		IdleTimeout:       120 * time.Second,
+		ShutdownTimeout:   30 * time.Second,
+	}
+	for _, option := range options {
+		option(&opts)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ln := opts.Listener
+	if ln == nil {
+		var err error
+		if ln, err = net.Listen("tcp", addr); err != nil {
+			return fmt.Errorf("🔴 listen: %w", err)
+		}
+	}
+
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: opts.ReadHeaderTimeout,
+		ReadTimeout:       opts.ReadTimeout,
+		WriteTimeout:      opts.WriteTimeout,
+		IdleTimeout:       opts.IdleTimeout,
+	}
+
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ln) }()
+	go func() {
+		if err := WarmRoutes(ctx, handler); err != nil {
+			logger().Error("warm routes", "err", err)
+		}
+	}()
+	logger().Info("serve", "addr", ln.Addr().String())
+
+	select {
+	case err := <-served:
+		shutdownRenderers()
+		return fmt.Errorf("🔴 serve: %w", err)
+	case <-ctx.Done():
+	}
+
+	logger().Info("shutdown", "timeout", opts.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), opts.ShutdownTimeout)
+	defer cancel()
+
+	err := srv.Shutdown(shutdownCtx)
+	if drainErr := drainRenders(shutdownCtx); err == nil {
+		err = drainErr
+	}
+	shutdownRenderers()
+	if err != nil {
+		srv.Close()
+		return fmt.Errorf("🔴 shutdown: %w", err)
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("🔴 serve: %w", err)
+	}
+	return nil
+}
+
+func drainRenders(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		activeRenders.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("🔴 drain renders: %w", ctx.Err())
+	}
+}
+
+func shutdownRenderers() {
+	pools.Lock()
+	if pools.current != nil {
+		close(pools.current.stop)
+		pools.current = nil
+	}
+	pools.Unlock()
+
+	if cfg := getConfig(); cfg != nil {
+		if closer, ok := cfg.Renderer.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				logger().Error("close renderer", "err", err)
+			}
+		}
+	}
+}
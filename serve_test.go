@@ -0,0 +1,100 @@
+package alloy
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestServeDrainsInFlightRequests(t *testing.T) {
+	withRuntimePool(t, func(cfg *Config) { cfg.RuntimePoolSize = 1 })
+	if _, err := executeSSR(context.Background(), poolTestBundle, map[string]any{"name": "Ada"}); err != nil {
+		t.Fatalf("🔴 warm pool: %v", err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "done")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- Serve(ctx, "", handler, func(opts *ServeOptions) { opts.Listener = ln })
+	}()
+
+	body := make(chan string, 1)
+	go func() {
+		res, err := http.Get("http://" + ln.Addr().String())
+		if err != nil {
+			body <- err.Error()
+			return
+		}
+		defer res.Body.Close()
+		data, _ := io.ReadAll(res.Body)
+		body <- string(data)
+	}()
+
+	<-started
+	cancel()
+	select {
+	case err := <-served:
+		t.Fatalf("🔴 Serve returned before the request finished: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	if got := <-body; got != "done" {
+		t.Fatalf("🔴 expected in-flight request to finish, got %q", got)
+	}
+	if err := <-served; err != nil {
+		t.Fatalf("🔴 Serve: %v", err)
+	}
+
+	pools.Lock()
+	current := pools.current
+	pools.Unlock()
+	if current != nil {
+		t.Fatal("🔴 expected runtime pool to be closed")
+	}
+}
+
+func TestServeReportsShutdownTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- Serve(ctx, "", handler, func(opts *ServeOptions) {
+			opts.Listener = ln
+			opts.ShutdownTimeout = 20 * time.Millisecond
+		})
+	}()
+	go http.Get("http://" + ln.Addr().String())
+
+	<-started
+	cancel()
+	if err := <-served; err == nil {
+		t.Fatal("🔴 expected shutdown timeout error")
+	}
+}
@@ -0,0 +1,161 @@
+package alloy
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+var ErrBundleTampered = errors.New("bundle verification failed")
+
+func ParseSigningKey(data []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("🔴 signing key: no PEM block")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("🔴 signing key: %w", err)
+	}
+	private, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("🔴 signing key: want ed25519, got %T", key)
+	}
+	return private, nil
+}
+
+func ParseVerifyKey(data []byte) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("🔴 verify key: no PEM block")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("🔴 verify key: %w", err)
+	}
+	public, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("🔴 verify key: want ed25519, got %T", key)
+	}
+	return public, nil
+}
+
+func SignManifest(distDir string, key ed25519.PrivateKey) error {
+	manifestPath := filepath.Join(distDir, "manifest.json")
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return fmt.Errorf("🔴 read manifest: %w", err)
+	}
+	manifest, err := ParseManifest(data)
+	if err != nil {
+		return err
+	}
+	if len(manifest.Files) == 0 {
+		return fmt.Errorf("🔴 sign manifest: no file hashes to sign, rebuild with alloy build")
+	}
+	if err := hashPublicFiles(distDir, manifest); err != nil {
+		return err
+	}
+
+	payload, err := manifest.signedPayload()
+	if err != nil {
+		return err
+	}
+	manifest.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload))
+
+	encoded, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("🔴 encode manifest: %w", err)
+	}
+	if err := os.WriteFile(manifestPath, encoded, 0644); err != nil {
+		return fmt.Errorf("🔴 write manifest file: %w", err)
+	}
+	return nil
+}
+
+func VerifyManifest(filesystem fs.FS, dist string, key ed25519.PublicKey) error {
+	manifest, err := ReadManifest(filesystem, dist)
+	if err != nil {
+		return fmt.Errorf("🔴 %w: %w", ErrBundleTampered, err)
+	}
+	if manifest.Signature == "" {
+		return fmt.Errorf("🔴 %w: manifest is not signed", ErrBundleTampered)
+	}
+	signature, err := base64.StdEncoding.DecodeString(manifest.Signature)
+	if err != nil {
+		return fmt.Errorf("🔴 %w: decode signature: %w", ErrBundleTampered, err)
+	}
+	payload, err := manifest.signedPayload()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(key, payload, signature) {
+		return fmt.Errorf("🔴 %w: manifest signature does not match", ErrBundleTampered)
+	}
+
+	names := make([]string, 0, len(manifest.Files))
+	for name := range manifest.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		contents, err := readPrebuiltFile(filesystem, path.Join(filepath.ToSlash(dist), name))
+		if err != nil {
+			return fmt.Errorf("🔴 %w: read %s: %w", ErrBundleTampered, name, err)
+		}
+		if integrity(contents) != manifest.Files[name].Integrity {
+			return fmt.Errorf("🔴 %w: %s does not match its manifest hash", ErrBundleTampered, name)
+		}
+	}
+	if _, signed := manifest.Files[publicManifestName]; !signed {
+		if _, err := fs.Stat(filesystem, path.Join(filepath.ToSlash(dist), publicManifestName)); err == nil {
+			return fmt.Errorf("🔴 %w: %s is not signed", ErrBundleTampered, publicManifestName)
+		}
+	}
+	return nil
+}
+
+func hashPublicFiles(distDir string, manifest *Manifest) error {
+	names, err := publicFileNames(distDir)
+	if err != nil || names == nil {
+		return err
+	}
+	filesystem, err := unpackFS(os.DirFS(distDir), ".")
+	if err != nil {
+		return err
+	}
+	for _, name := range append([]string{publicManifestName}, names...) {
+		contents, err := fs.ReadFile(filesystem, name)
+		if err != nil {
+			return fmt.Errorf("🔴 read %s: %w", name, err)
+		}
+		manifest.Files[name] = ManifestFile{Bytes: int64(len(contents)), Gzip: gzipSize(contents), Integrity: integrity(contents)}
+	}
+	return nil
+}
+
+func (m *Manifest) signedPayload() ([]byte, error) {
+	unsigned := *m
+	unsigned.Signature = ""
+	payload, err := json.Marshal(unsigned)
+	if err != nil {
+		return nil, fmt.Errorf("🔴 encode manifest: %w", err)
+	}
+	return payload, nil
+}
+
+func IntegrityError() error {
+	if cfg := getConfig(); cfg != nil {
+		return cfg.integrityErr
+	}
+	return nil
+}
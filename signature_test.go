@@ -0,0 +1,151 @@
+package alloy
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func signedTestDist(t *testing.T) (string, string, ed25519.PublicKey) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "home-server.js"), `var __Component = function() { return "<p>home</p>"; };`)
+	writeTestFile(t, filepath.Join(dist, "client-home-AAAAAAAA.js"), "client")
+	if err := updateManifest(filepath.Join(dist, "manifest.json"), map[string]ManifestPage{
+		"home": {Server: "home-server.js", Client: "client-home-AAAAAAAA.js"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SignManifest(dist, private); err != nil {
+		t.Fatalf("🔴 sign: %v", err)
+	}
+	return root, dist, public
+}
+
+func TestVerifyManifestAcceptsSignedDist(t *testing.T) {
+	root, _, public := signedTestDist(t)
+	if err := VerifyManifest(os.DirFS(root), DefaultDistDir, public); err != nil {
+		t.Fatalf("🔴 verify: %v", err)
+	}
+}
+
+func TestVerifyManifestRejectsTampering(t *testing.T) {
+	root, dist, public := signedTestDist(t)
+	writeTestFile(t, filepath.Join(dist, "client-home-AAAAAAAA.js"), "evil()")
+
+	err := VerifyManifest(os.DirFS(root), DefaultDistDir, public)
+	if !errors.Is(err, ErrBundleTampered) {
+		t.Fatalf("🔴 expected tampered file to fail, got %v", err)
+	}
+
+	other, _, _ := ed25519.GenerateKey(rand.Reader)
+	root, _, _ = signedTestDist(t)
+	if err := VerifyManifest(os.DirFS(root), DefaultDistDir, other); !errors.Is(err, ErrBundleTampered) {
+		t.Fatalf("🔴 expected wrong key to fail, got %v", err)
+	}
+}
+
+func TestVerifyManifestCoversPublicFiles(t *testing.T) {
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+	sign := func() (string, string) {
+		root := t.TempDir()
+		dist := filepath.Join(root, "dist", "build")
+		writeTestFile(t, filepath.Join(dist, "client-home-AAAAAAAA.js"), "client")
+		writeTestFile(t, filepath.Join(dist, "public", "logo-5e6f7a8b.png"), "png")
+		writeTestFile(t, filepath.Join(dist, publicManifestName), `{"/logo.png": "/dist/build/public/logo-5e6f7a8b.png"}`)
+		if err := updateManifest(filepath.Join(dist, "manifest.json"), map[string]ManifestPage{"home": {Client: "client-home-AAAAAAAA.js"}}); err != nil {
+			t.Fatal(err)
+		}
+		if err := SignManifest(dist, private); err != nil {
+			t.Fatalf("🔴 sign: %v", err)
+		}
+		return root, dist
+	}
+
+	root, dist := sign()
+	if err := VerifyManifest(os.DirFS(root), DefaultDistDir, public); err != nil {
+		t.Fatalf("🔴 verify: %v", err)
+	}
+	writeTestFile(t, filepath.Join(dist, "public", "logo-5e6f7a8b.png"), "evil")
+	if err := VerifyManifest(os.DirFS(root), DefaultDistDir, public); !errors.Is(err, ErrBundleTampered) {
+		t.Fatalf("🔴 expected swapped public file to fail, got %v", err)
+	}
+
+	root, dist = sign()
+	writeTestFile(t, filepath.Join(dist, publicManifestName), `{"/logo.png": "https://evil.example.com/logo.png"}`)
+	if err := VerifyManifest(os.DirFS(root), DefaultDistDir, public); !errors.Is(err, ErrBundleTampered) {
+		t.Fatalf("🔴 expected swapped public manifest to fail, got %v", err)
+	}
+
+	root, dist, public = signedTestDist(t)
+	writeTestFile(t, filepath.Join(dist, publicManifestName), `{"/logo.png": "https://evil.example.com/logo.png"}`)
+	if err := VerifyManifest(os.DirFS(root), DefaultDistDir, public); !errors.Is(err, ErrBundleTampered) {
+		t.Fatalf("🔴 expected unsigned public manifest to fail, got %v", err)
+	}
+}
+
+func TestVerifyManifestRejectsUnsignedManifest(t *testing.T) {
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{"version": 2, "pages": {}}`)
+	public, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	if err := VerifyManifest(os.DirFS(root), DefaultDistDir, public); !errors.Is(err, ErrBundleTampered) {
+		t.Fatalf("🔴 expected unsigned manifest to fail, got %v", err)
+	}
+}
+
+func TestInitRefusesTamperedDist(t *testing.T) {
+	root, dist, public := signedTestDist(t)
+	writeTestFile(t, filepath.Join(dist, "home-server.js"), `var __Component = function() { return "<p>evil</p>"; };`)
+	prev := getConfig()
+	t.Cleanup(func() { globalConfig.Store(prev) })
+	Init(os.DirFS(root), func(cfg *Config) { cfg.ManifestPublicKey = public })
+
+	if !errors.Is(IntegrityError(), ErrBundleTampered) {
+		t.Fatalf("🔴 expected integrity error, got %v", IntegrityError())
+	}
+
+	rec := httptest.NewRecorder()
+	NewPage("app/pages/home.tsx").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("🔴 expected 503 for page, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	AssetsMiddleware()(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/dist/build/client-home-AAAAAAAA.js", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("🔴 expected 503 for asset, got %d", rec.Code)
+	}
+}
+
+func TestParseKeysFromPEM(t *testing.T) {
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+	privateDER, _ := x509.MarshalPKCS8PrivateKey(private)
+	publicDER, _ := x509.MarshalPKIXPublicKey(public)
+
+	parsedPrivate, err := ParseSigningKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}))
+	if err != nil || !parsedPrivate.Equal(private) {
+		t.Fatalf("🔴 signing key: %v", err)
+	}
+	parsedPublic, err := ParseVerifyKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+	if err != nil || !parsedPublic.Equal(public) {
+		t.Fatalf("🔴 verify key: %v", err)
+	}
+	if _, err := ParseSigningKey([]byte("nope")); err == nil {
+		t.Fatal("🔴 expected error for invalid PEM")
+	}
+}
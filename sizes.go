@@ -0,0 +1,220 @@
+package alloy
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type BundleSize struct {
+	Page  string
+	Files []string
+	Bytes int64
+	Gzip  int64
+}
+
+type SizeReport struct {
+	Pages []BundleSize
+	Total BundleSize
+}
+
+type SizeBudgets struct {
+	Pages map[string]int64
+	Total int64
+}
+
+type BudgetViolation struct {
+	Page   string
+	Gzip   int64
+	Budget int64
+}
+
+type BudgetError struct {
+	Violations []BudgetViolation
+}
+
+func (e *BudgetError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🔴 %d size budget(s) exceeded", len(e.Violations))
+	for _, v := range e.Violations {
+		fmt.Fprintf(&b, "\n\t%s: %s gzip > %s", v.Page, FormatSize(v.Gzip), FormatSize(v.Budget))
+	}
+	return b.String()
+}
+
+func MeasureBundleSizes(distDir string) (*SizeReport, error) {
+	manifest, err := ReadManifest(os.DirFS(distDir), ".")
+	if err != nil {
+		return nil, err
+	}
+
+	measured := map[string][2]int64{}
+	measure := func(name string) ([2]int64, error) {
+		if size, ok := measured[name]; ok {
+			return size, nil
+		}
+		if info, ok := manifest.Files[name]; ok && info.Gzip > 0 {
+			size := [2]int64{info.Bytes, info.Gzip}
+			measured[name] = size
+			return size, nil
+		}
+		contents, err := os.ReadFile(filepath.Join(distDir, name))
+		if err != nil {
+			return [2]int64{}, fmt.Errorf("🔴 read bundle %s: %w", name, err)
+		}
+		size := [2]int64{int64(len(contents)), gzipSize(contents)}
+		measured[name] = size
+		return size, nil
+	}
+
+	report := &SizeReport{Total: BundleSize{Page: "total"}}
+	for _, page := range sortedKeys(manifest.Pages) {
+		bundle := BundleSize{Page: page}
+		for _, name := range manifest.PageFiles(page) {
+			size, err := measure(name)
+			if err != nil {
+				return nil, err
+			}
+			bundle.Files = append(bundle.Files, name)
+			bundle.Bytes += size[0]
+			bundle.Gzip += size[1]
+		}
+		report.Pages = append(report.Pages, bundle)
+	}
+
+	for _, name := range sortedKeys(measured) {
+		report.Total.Files = append(report.Total.Files, name)
+		report.Total.Bytes += measured[name][0]
+		report.Total.Gzip += measured[name][1]
+	}
+	return report, nil
+}
+
+func gzipSize(data []byte) int64 {
+	counter := &countingWriter{}
+	zw, _ := gzip.NewWriterLevel(counter, gzip.BestCompression)
+	zw.Write(data)
+	zw.Close()
+	return counter.n
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+func (r *SizeReport) page(name string) (BundleSize, bool) {
+	for _, page := range r.Pages {
+		if page.Page == name {
+			return page, true
+		}
+	}
+	return BundleSize{}, false
+}
+
+func (r *SizeReport) CheckBudgets(budgets SizeBudgets) error {
+	var violations []BudgetViolation
+	for _, page := range r.Pages {
+		budget, ok := budgets.Pages[page.Page]
+		if !ok {
+			budget = budgets.Pages["*"]
+		}
+		if budget > 0 && page.Gzip > budget {
+			violations = append(violations, BudgetViolation{Page: page.Page, Gzip: page.Gzip, Budget: budget})
+		}
+	}
+	if budgets.Total > 0 && r.Total.Gzip > budgets.Total {
+		violations = append(violations, BudgetViolation{Page: r.Total.Page, Gzip: r.Total.Gzip, Budget: budgets.Total})
+	}
+
+	if len(violations) > 0 {
+		return &BudgetError{Violations: violations}
+	}
+	return nil
+}
+
+func (r *SizeReport) Print(w io.Writer, baseline *SizeReport) {
+	row := func(current BundleSize, previous BundleSize, hasPrevious bool) {
+		line := fmt.Sprintf("  %-24s %10s %10s gzip", current.Page, FormatSize(current.Bytes), FormatSize(current.Gzip))
+		if hasPrevious {
+			line += "  " + formatSizeDelta(current.Gzip-previous.Gzip)
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	for _, page := range r.Pages {
+		var previous BundleSize
+		found := false
+		if baseline != nil {
+			previous, found = baseline.page(page.Page)
+		}
+		row(page, previous, found)
+	}
+
+	var previousTotal BundleSize
+	if baseline != nil {
+		previousTotal = baseline.Total
+	}
+	row(r.Total, previousTotal, baseline != nil)
+}
+
+func formatSizeDelta(delta int64) string {
+	switch {
+	case delta > 0:
+		return "+" + FormatSize(delta)
+	case delta < 0:
+		return "-" + FormatSize(-delta)
+	default:
+		return "±0 B"
+	}
+}
+
+var sizeUnits = []struct {
+	suffix string
+	bytes  float64
+}{
+	{"kib", 1 << 10},
+	{"mib", 1 << 20},
+	{"kb", 1e3},
+	{"mb", 1e6},
+	{"k", 1e3},
+	{"m", 1e6},
+	{"b", 1},
+}
+
+func ParseSize(value string) (int64, error) {
+	s := strings.ToLower(strings.TrimSpace(value))
+	multiplier := 1.0
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			multiplier = unit.bytes
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("🔴 invalid size %q", value)
+	}
+	return int64(n * multiplier), nil
+}
+
+func FormatSize(n int64) string {
+	switch {
+	case n >= 1e6:
+		return fmt.Sprintf("%.2f MB", float64(n)/1e6)
+	case n >= 1e3:
+		return fmt.Sprintf("%.1f kB", float64(n)/1e3)
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
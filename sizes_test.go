@@ -0,0 +1,73 @@
+package alloy
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	cases := map[string]int64{
+		"2048":   2048,
+		"170kB":  170000,
+		"1.5 MB": 1500000,
+		"4KiB":   4096,
+		"10b":    10,
+	}
+	for input, want := range cases {
+		got, err := ParseSize(input)
+		if err != nil || got != want {
+			t.Fatalf("ParseSize(%q) = %d, %v; want %d", input, got, err, want)
+		}
+	}
+	if _, err := ParseSize("big"); err == nil {
+		t.Fatal("expected error for invalid size")
+	}
+}
+
+func TestMeasureBundleSizesAndBudgets(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "client-home-AAAA.js"), strings.Repeat("home();", 200))
+	writeTestFile(t, filepath.Join(dir, "client-about-BBBB.js"), "about();")
+	writeTestFile(t, filepath.Join(dir, "chunk-CCCC.js"), strings.Repeat("shared();", 500))
+	writeTestFile(t, filepath.Join(dir, "shared-DDDD.css"), "body{margin:0}")
+	writeTestFile(t, filepath.Join(dir, "manifest.json"), `{
+		"home": {"server": "home-server.js", "client": "client-home-AAAA.js", "css": "shared-DDDD.css", "chunks": ["chunk-CCCC.js"]},
+		"about": {"server": "about-server.js", "client": "client-about-BBBB.js", "css": "shared-DDDD.css", "chunks": ["chunk-CCCC.js"]}
+	}`)
+
+	report, err := MeasureBundleSizes(dir)
+	if err != nil {
+		t.Fatalf("measure: %v", err)
+	}
+	if len(report.Pages) != 2 || report.Pages[0].Page != "about" || len(report.Pages[1].Files) != 3 {
+		t.Fatalf("unexpected pages: %+v", report.Pages)
+	}
+	if len(report.Total.Files) != 4 {
+		t.Fatalf("shared files should be counted once: %v", report.Total.Files)
+	}
+	if home := report.Pages[1]; home.Gzip <= 0 || home.Gzip >= home.Bytes {
+		t.Fatalf("unexpected gzip size: %+v", home)
+	}
+
+	if err := report.CheckBudgets(SizeBudgets{Pages: map[string]int64{"*": 1 << 20}, Total: 1 << 20}); err != nil {
+		t.Fatalf("budgets should pass: %v", err)
+	}
+
+	err = report.CheckBudgets(SizeBudgets{Pages: map[string]int64{"*": 1, "about": 1 << 20}, Total: 1})
+	var budgetErr *BudgetError
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("expected BudgetError, got %v", err)
+	}
+	if len(budgetErr.Violations) != 2 || budgetErr.Violations[0].Page != "home" || budgetErr.Violations[1].Page != "total" {
+		t.Fatalf("unexpected violations: %+v", budgetErr.Violations)
+	}
+
+	var out bytes.Buffer
+	report.Print(&out, report)
+	if !strings.Contains(out.String(), "home") || !strings.Contains(out.String(), "±0 B") {
+		t.Fatalf("unexpected report:\n%s", out.String())
+	}
+}
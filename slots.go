@@ -0,0 +1,88 @@
+package alloy
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/buke/quickjs-go"
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+const (
+	slotsModule    = "alloy:slots"
+	slotsNamespace = "alloy-slots"
+
+	SlotHead    = "head"
+	SlotBodyEnd = "body-end"
+)
+
+const serverSlotsSource = `const slots = () => globalThis.__ALLOY_SLOTS__ || (globalThis.__ALLOY_SLOTS__ = {});
+export function slot(name, html) {
+  const list = slots()[name] || (slots()[name] = []);
+  list.push(String(html));
+}
+`
+
+const clientSlotsSource = `export function slot() {}
+`
+
+func slotsPlugin() api.Plugin {
+	return api.Plugin{
+		Name: "alloy-slots",
+		Setup: func(build api.PluginBuild) {
+			contents := clientSlotsSource
+			if build.InitialOptions.Format == api.FormatIIFE {
+				contents = serverSlotsSource
+			}
+
+			build.OnResolve(api.OnResolveOptions{Filter: `^alloy:slots$`}, func(args api.OnResolveArgs) (api.OnResolveResult, error) {
+				return api.OnResolveResult{Path: slotsModule, Namespace: slotsNamespace}, nil
+			})
+
+			build.OnLoad(api.OnLoadOptions{Filter: `.*`, Namespace: slotsNamespace}, func(args api.OnLoadArgs) (api.OnLoadResult, error) {
+				return api.OnLoadResult{Contents: &contents, Loader: api.LoaderJS}, nil
+			})
+		},
+	}
+}
+
+func collectSlots(js *quickjs.Context) (map[string][]string, error) {
+	collected := js.Eval("JSON.stringify(globalThis.__ALLOY_SLOTS__ || {})")
+	defer collected.Free()
+	if collected.IsException() {
+		return nil, fmt.Errorf("🔴 collect slots: %s", js.Exception())
+	}
+
+	var slots map[string][]string
+	if err := json.Unmarshal([]byte(collected.String()), &slots); err != nil {
+		return nil, fmt.Errorf("🔴 decode slots: %w", err)
+	}
+	if len(slots) == 0 {
+		return nil, nil
+	}
+	return slots, nil
+}
+
+var slotInlineTag = regexp.MustCompile(`(?i)<(?:script|style)\b[^>]*`)
+
+func slotHTML(slots map[string][]string, name string, indent string, nonceAttr string) string {
+	var b strings.Builder
+	for _, html := range slots[name] {
+		b.WriteString(indent)
+		if nonceAttr != "" {
+			html = slotInlineTag.ReplaceAllStringFunc(html, func(tag string) string {
+				if strings.Contains(strings.ToLower(tag), " nonce=") {
+					return tag
+				}
+				if open, ok := strings.CutSuffix(tag, "/"); ok {
+					return open + nonceAttr + "/"
+				}
+				return tag + nonceAttr
+			})
+		}
+		b.WriteString(html)
+	}
+	return b.String()
+}
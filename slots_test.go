@@ -0,0 +1,69 @@
+package alloy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+func TestSlotsSplicedIntoDocument(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(dir)
+	})
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react", "jsx-runtime.js"), `exports.jsx = (type, props) => ({ type, props });`+"\n")
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react-dom", "server.edge.js"), `exports.renderToString = (el) => String(el.type(el.props));`+"\n")
+	component := filepath.Join(dir, "app", "pages", "home.tsx")
+	writeTestFile(t, component, `import { slot } from "alloy:slots";
+export default function Home(props: { name: string }) {
+  slot("head", '<link rel="preconnect" href="https://cdn.example.com">');
+  slot("body-end", '<div id="modals"></div>');
+  return "<main>" + props.name + "</main>";
+}
+`)
+
+	serverJS, _, err := BuildServerBundle(component)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := PrebuiltFiles{Server: "home-server.js", Client: "client-home.js", CSS: "shared.css"}
+	for range 2 {
+		html, err := PrerenderPage(serverJS, "home-root", map[string]any{"name": "Ada"}, files)
+		if err != nil {
+			t.Fatal(err)
+		}
+		head := html[:strings.Index(html, "</head>")]
+		if strings.Count(head, `<link rel="preconnect" href="https://cdn.example.com">`) != 1 {
+			t.Fatalf("head slot not spliced once:\n%s", html)
+		}
+		if !strings.Contains(html, "></script>\n        <div id=\"modals\"></div>\n    </body>") {
+			t.Fatalf("body-end slot missing:\n%s", html)
+		}
+	}
+
+	html := slotHTML(map[string][]string{SlotHead: {
+		`<script>track()</script>`,
+		`<script src="/a.js" nonce="own"></script>`,
+		`<style media="print">p{}</style>`,
+	}}, SlotHead, "", ` nonce="abc"`)
+	if want := `<script nonce="abc">track()</script><script src="/a.js" nonce="own"></script><style media="print" nonce="abc">p{}</style>`; html != want {
+		t.Fatalf("slot tags missing nonce:\n%s", html)
+	}
+
+	out := api.Build(api.BuildOptions{
+		Stdin:   &api.StdinOptions{Contents: `import { slot } from "alloy:slots"; slot("head", "<meta>");`},
+		Bundle:  true,
+		Format:  api.FormatESModule,
+		Plugins: []api.Plugin{slotsPlugin()},
+	})
+	if len(out.Errors) > 0 {
+		t.Fatal(out.Errors)
+	}
+	if client := string(out.OutputFiles[0].Contents); strings.Contains(client, "__ALLOY_SLOTS__") {
+		t.Fatalf("client bundle collects slots:\n%s", client)
+	}
+}
@@ -0,0 +1,70 @@
+package alloy
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+const assetNames = "assets/[name]-[hash]"
+
+var fileLoaders = map[string]api.Loader{
+	".png":   api.LoaderFile,
+	".jpg":   api.LoaderFile,
+	".jpeg":  api.LoaderFile,
+	".gif":   api.LoaderFile,
+	".webp":  api.LoaderFile,
+	".avif":  api.LoaderFile,
+	".ico":   api.LoaderFile,
+	".bmp":   api.LoaderFile,
+	".svg":   api.LoaderFile,
+	".woff":  api.LoaderFile,
+	".woff2": api.LoaderFile,
+	".ttf":   api.LoaderFile,
+	".otf":   api.LoaderFile,
+	".eot":   api.LoaderFile,
+	".mp4":   api.LoaderFile,
+	".webm":  api.LoaderFile,
+	".mp3":   api.LoaderFile,
+	".wav":   api.LoaderFile,
+	".pdf":   api.LoaderFile,
+}
+
+func applyAssetLoaders(opts *api.BuildOptions, distDir string) {
+	if opts.Loader == nil {
+		opts.Loader = map[string]api.Loader{}
+	}
+	for ext, loader := range fileLoaders {
+		if _, ok := opts.Loader[ext]; !ok {
+			opts.Loader[ext] = loader
+		}
+	}
+	opts.AssetNames = assetNames
+	opts.PublicPath = "/" + distURLPrefix(mustResolveAbsPath(distDir))
+	if cfg := getConfig(); cfg != nil && cfg.AssetURL != "" {
+		opts.PublicPath = strings.TrimSuffix(cfg.AssetURL, "/")
+	}
+}
+
+func distURLPrefix(absOut string) string {
+	return filepath.ToSlash(filepath.Join(filepath.Base(filepath.Dir(absOut)), filepath.Base(absOut)))
+}
+
+func assetRelNames(paths []string) []string {
+	if len(paths) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(paths))
+	for _, p := range paths {
+		slashPath := filepath.ToSlash(p)
+		if idx := strings.LastIndex(slashPath, "/assets/"); idx >= 0 {
+			slashPath = slashPath[idx+1:]
+		} else if !strings.HasPrefix(slashPath, "assets/") {
+			slashPath = path.Base(slashPath)
+		}
+		out = append(out, slashPath)
+	}
+	return out
+}
@@ -0,0 +1,93 @@
+package alloy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+func TestAssetLoadersEmitHashedURLs(t *testing.T) {
+	dir := t.TempDir()
+	outDir := filepath.Join(dir, "dist", "build")
+	entry := filepath.Join(dir, "app", "entry.ts")
+	writeTestFile(t, filepath.Join(dir, "app", "logo.png"), "PNGDATA")
+	writeTestFile(t, entry, `import logo from "./logo.png"; console.log(logo);`)
+
+	opts := commonBuildOptions()
+	opts.EntryPoints = []string{entry}
+	opts.Outdir = outDir
+	opts.Write = false
+	opts.Metafile = true
+	applyAssetLoaders(&opts, outDir)
+
+	result := api.Build(opts)
+	if err := checkBuildErrors(result, "build"); err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	var js, asset string
+	for _, out := range result.OutputFiles {
+		switch filepath.Ext(out.Path) {
+		case ".js":
+			js = string(out.Contents)
+		case ".png":
+			asset = out.Path
+		}
+	}
+	if asset == "" {
+		t.Fatalf("expected png output, got %d files", len(result.OutputFiles))
+	}
+	if !isHashedAsset(asset) {
+		t.Fatalf("asset should be hashed: %s", asset)
+	}
+
+	wantURL := "/dist/build/assets/" + filepath.Base(asset)
+	if !strings.Contains(js, wantURL) {
+		t.Fatalf("bundle missing asset url %s:\n%s", wantURL, js)
+	}
+
+	meta, err := parseMetafile(result.Metafile)
+	if err != nil {
+		t.Fatalf("parse metafile: %v", err)
+	}
+	var entryOut string
+	for outPath, out := range meta.Outputs {
+		if out.EntryPoint != "" {
+			entryOut = outPath
+		}
+	}
+	files := meta.fileImports(entryOut)
+	if len(files) != 1 || filepath.Base(files[0]) != filepath.Base(asset) {
+		t.Fatalf("file imports: %v", files)
+	}
+}
+
+func TestManifestRecordsAssets(t *testing.T) {
+	dir := t.TempDir()
+	distDir := filepath.Join(dir, DefaultDistDir)
+
+	files := PrebuiltFiles{
+		Server: filepath.Join(distDir, "home-aaaa1111-server.js"),
+		Client: filepath.Join(distDir, "client-home-bbbb2222.js"),
+		CSS:    filepath.Join(distDir, "shared-cccc3333.css"),
+		Assets: []string{"dist/build/assets/logo-PYREF2CC.png"},
+	}
+	if err := os.MkdirAll(distDir, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := WriteManifest(distDir, "home", files); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+
+	resolved, err := resolvePrebuiltFiles(os.DirFS(dir), "app/pages/home.tsx")
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	want := DefaultDistDir + "/assets/logo-PYREF2CC.png"
+	if len(resolved.Assets) != 1 || resolved.Assets[0] != want {
+		t.Fatalf("assets: want [%s], got %v", want, resolved.Assets)
+	}
+}
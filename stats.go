@@ -0,0 +1,88 @@
+package alloy
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type RenderStats struct {
+	Pages    map[string]PageStats `json:"pages"`
+	Runtimes RuntimeStats         `json:"runtimes"`
+	Pool     PoolStats            `json:"pool"`
+	Memory   MemoryStats          `json:"memory"`
+}
+
+type PageStats struct {
+	Renders     int64         `json:"renders"`
+	Errors      int64         `json:"errors"`
+	CacheHits   int64         `json:"cacheHits"`
+	CacheMisses int64         `json:"cacheMisses"`
+	AvgDuration time.Duration `json:"avgDuration"`
+	MaxDuration time.Duration `json:"maxDuration"`
+}
+
+type PoolStats struct {
+	Size          int   `json:"size"`
+	MaxConcurrent int   `json:"maxConcurrent"`
+	InFlight      int64 `json:"inFlight"`
+	Queued        int64 `json:"queued"`
+	Recycled      int64 `json:"recycled"`
+}
+
+type pageCounters struct {
+	renders     int64
+	errors      int64
+	cacheHits   int64
+	cacheMisses int64
+	total       time.Duration
+	max         time.Duration
+}
+
+var pageStats = struct {
+	sync.Mutex
+	pages map[string]*pageCounters
+}{
+	pages: map[string]*pageCounters{},
+}
+
+var (
+	rendersInFlight atomic.Int64
+	rendersQueued   atomic.Int64
+	runtimeRecycled atomic.Int64
+)
+
+func Stats() RenderStats {
+	stats := RenderStats{Pages: map[string]PageStats{}, Runtimes: currentRuntimeStats()}
+
+	pageStats.Lock()
+	for page, c := range pageStats.pages {
+		s := PageStats{Renders: c.renders, Errors: c.errors, CacheHits: c.cacheHits, CacheMisses: c.cacheMisses, MaxDuration: c.max}
+		if c.renders > 0 {
+			s.AvgDuration = c.total / time.Duration(c.renders)
+		}
+		stats.Pages[page] = s
+	}
+	pageStats.Unlock()
+
+	if cfg := getConfig(); cfg != nil {
+		stats.Pool.Size = cfg.RuntimePoolSize
+		stats.Pool.MaxConcurrent = cfg.MaxConcurrentRenders
+	}
+	stats.Pool.InFlight = rendersInFlight.Load()
+	stats.Pool.Queued = rendersQueued.Load()
+	stats.Pool.Recycled = runtimeRecycled.Load()
+	stats.Memory = currentMemoryStats()
+	return stats
+}
+
+func recordPageStats(page string, update func(c *pageCounters)) {
+	pageStats.Lock()
+	defer pageStats.Unlock()
+	c := pageStats.pages[page]
+	if c == nil {
+		c = &pageCounters{}
+		pageStats.pages[page] = c
+	}
+	update(c)
+}
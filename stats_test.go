@@ -0,0 +1,71 @@
+package alloy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStatsReportsPageCounters(t *testing.T) {
+	resetBundleCache()
+	t.Cleanup(resetBundleCache)
+
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "stats-server.js"), `var __Component = { default: function(props) { if (props.fail) throw new Error("boom"); return "<p>stats</p>"; } };`)
+	writeTestFile(t, filepath.Join(dist, "client-stats-AAAAAAAA.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "shared.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{"stats": {"server": "stats-server.js", "client": "client-stats-AAAAAAAA.js", "css": "shared.css"}}`)
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+		cfg.MaxConcurrentRenders = 4
+	})
+
+	before := Stats().Pages["stats"]
+	handler := NewPage(filepath.Join(root, "pages", "stats.tsx")).WithLoader(func(r *http.Request) map[string]any {
+		return map[string]any{"fail": r.URL.Query().Has("fail")}
+	})
+	for _, target := range []string{"/stats", "/stats", "/stats?fail"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	stats := Stats()
+	page := stats.Pages["stats"]
+	if page.Renders-before.Renders != 3 || page.Errors-before.Errors != 1 {
+		t.Fatalf("page stats = %+v", page)
+	}
+	if page.AvgDuration <= 0 || page.MaxDuration < page.AvgDuration {
+		t.Fatalf("durations = %s avg, %s max", page.AvgDuration, page.MaxDuration)
+	}
+	if stats.Pool.MaxConcurrent != 4 || stats.Pool.InFlight != 0 || stats.Pool.Queued != 0 {
+		t.Fatalf("pool stats = %+v", stats.Pool)
+	}
+}
+
+func TestStatsReportsRuntimeMemory(t *testing.T) {
+	withRuntimePool(t, func(cfg *Config) { cfg.RuntimePoolSize = 1 })
+	code := `var __Component = function() {
+	globalThis.leak = (globalThis.leak || []).concat([{ big: "x".repeat(100000) }]);
+	return "<p>" + globalThis.leak.length + "</p>";
+};`
+	if _, err := executeSSR(context.Background(), code, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	memory := Stats().Memory
+	if memory.Runtimes < 1 || memory.MallocSize < 100000 || memory.MemoryUsed <= 0 || memory.Objects <= 0 || memory.Strings <= 0 || memory.GCRuns < 1 || memory.SampledAt.IsZero() {
+		t.Fatalf("memory stats = %+v", memory)
+	}
+
+	var out strings.Builder
+	WriteMetrics(&out)
+	for _, want := range []string{`alloy_runtime_memory_bytes{kind="malloc"}`, `alloy_runtime_objects{kind="object"}`, `alloy_runtime_gc_runs_total`} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("metrics missing %s:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package alloy
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+)
+
+func Preload() error {
+	cfg := getConfig()
+	if cfg == nil || cfg.FS == nil {
+		return fmt.Errorf("🔴 alloy.Init must run before Preload")
+	}
+	manifest, err := ReadManifest(cfg.FS, DefaultDistDir)
+	if err != nil {
+		return err
+	}
+
+	pagesDir := cfg.PagesDir
+	if pagesDir == "" {
+		pagesDir = DefaultPagesDir
+	}
+
+	var errs []error
+	for _, name := range sortedKeys(manifest.Pages) {
+		component := filepath.Join(pagesDir, name+".tsx")
+		files, err := resolvePrebuiltFiles(cfg.FS, component)
+		if err == nil {
+			err = RegisterPrebuiltBundleFromFS(component, defaultRootID(component), cfg.FS, files)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("🔴 page %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func strictPreload(cfg *Config) {
+	if !cfg.Strict || cfg.integrityErr != nil {
+		return
+	}
+	if err := Preload(); err != nil {
+		cfg.integrityErr = err
+		log := cfg.Logger
+		if log == nil {
+			log = slog.Default()
+		}
+		log.Error("preload pages", "dist", cfg.DistDir, "err", err)
+	}
+}
@@ -0,0 +1,55 @@
+package alloy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStrictInitFailsOnMissingBundle(t *testing.T) {
+	resetBundleCache()
+	t.Cleanup(resetBundleCache)
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "home-server.js"), `var __Component = function() { return "<p>home</p>"; };`)
+	writeTestFile(t, filepath.Join(dist, "home-client.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "home.css"), "p{}")
+	writeTestFile(t, filepath.Join(dist, "about-server.js"), `var __Component = function() { return "<p>about</p>"; };`)
+	writeTestFile(t, filepath.Join(dist, "about-client.js"), "client")
+	if err := updateManifest(filepath.Join(dist, "manifest.json"), map[string]ManifestPage{
+		"home":  {Server: "home-server.js", Client: "home-client.js", CSS: "home.css"},
+		"about": {Server: "about-server.js", Client: "about-client.js", CSS: "about.css"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	prev := getConfig()
+	t.Cleanup(func() { globalConfig.Store(prev) })
+
+	Init(os.DirFS(root))
+	if IntegrityError() != nil {
+		t.Fatalf("🔴 expected lazy init without Strict, got %v", IntegrityError())
+	}
+	err := Preload()
+	if err == nil || !strings.Contains(err.Error(), "page about") || strings.Contains(err.Error(), "page home") {
+		t.Fatalf("🔴 expected about to fail preload, got %v", err)
+	}
+
+	Init(os.DirFS(root), func(cfg *Config) { cfg.Strict = true })
+	if err := IntegrityError(); err == nil || !strings.Contains(err.Error(), "read css") {
+		t.Fatalf("🔴 expected strict init to fail, got %v", err)
+	}
+	rec := httptest.NewRecorder()
+	NewPage("app/pages/home.tsx").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("🔴 expected 503, got %d", rec.Code)
+	}
+
+	writeTestFile(t, filepath.Join(dist, "about.css"), "p{}")
+	Init(os.DirFS(root), func(cfg *Config) { cfg.Strict = true })
+	if err := IntegrityError(); err != nil {
+		t.Fatalf("🔴 expected strict init to pass, got %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package alloy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+const svgComponentNamespace = "alloy-svg-component"
+
+var (
+	svgRootPattern    = regexp.MustCompile(`(?is)<svg\b([^>]*)>(.*)</svg>`)
+	svgAttrPattern    = regexp.MustCompile(`([A-Za-z_:][-A-Za-z0-9_:.]*)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	svgPreludePattern = regexp.MustCompile(`(?is)<\?xml.*?\?>|<!DOCTYPE.*?>|<!--.*?-->`)
+)
+
+func svgComponentPlugin() api.Plugin {
+	return api.Plugin{
+		Name: "alloy-svg-component",
+		Setup: func(build api.PluginBuild) {
+			build.OnResolve(api.OnResolveOptions{Filter: `\.svg\?component$`}, func(args api.OnResolveArgs) (api.OnResolveResult, error) {
+				file := strings.TrimSuffix(args.Path, "?component")
+				if !filepath.IsAbs(file) {
+					file = filepath.Join(args.ResolveDir, file)
+				}
+				return api.OnResolveResult{Path: file, Namespace: svgComponentNamespace}, nil
+			})
+
+			build.OnLoad(api.OnLoadOptions{Filter: `.*`, Namespace: svgComponentNamespace}, func(args api.OnLoadArgs) (api.OnLoadResult, error) {
+				data, err := os.ReadFile(args.Path)
+				if err != nil {
+					return api.OnLoadResult{}, fmt.Errorf("🔴 read svg %s: %w", FormatPath(args.Path), err)
+				}
+
+				contents, err := svgToComponent(string(data))
+				if err != nil {
+					return api.OnLoadResult{}, fmt.Errorf("🔴 svg %s: %w", FormatPath(args.Path), err)
+				}
+
+				return api.OnLoadResult{
+					Contents:   &contents,
+					Loader:     api.LoaderJS,
+					ResolveDir: filepath.Dir(args.Path),
+					WatchFiles: []string{args.Path},
+				}, nil
+			})
+		},
+	}
+}
+
+func svgToComponent(source string) (string, error) {
+	source = svgPreludePattern.ReplaceAllString(source, "")
+	match := svgRootPattern.FindStringSubmatch(source)
+	if match == nil {
+		return "", fmt.Errorf("no <svg> root element")
+	}
+
+	attrs := map[string]any{}
+	for _, attr := range svgAttrPattern.FindAllStringSubmatch(match[1], -1) {
+		value := attr[2]
+		if value == "" {
+			value = attr[3]
+		}
+		name := svgPropName(attr[1])
+		if name == "style" {
+			attrs[name] = svgStyleObject(value)
+			continue
+		}
+		attrs[name] = value
+	}
+
+	attrsJSON, err := json.Marshal(attrs)
+	if err != nil {
+		return "", err
+	}
+	innerJSON, err := json.Marshal(strings.TrimSpace(match[2]))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(`import { createElement } from "react";
+const attrs = %s;
+const inner = %s;
+export default function SvgComponent(props) {
+	return createElement("svg", Object.assign({}, attrs, props, { dangerouslySetInnerHTML: { __html: inner } }));
+}
+`, attrsJSON, innerJSON), nil
+}
+
+func svgPropName(attr string) string {
+	switch {
+	case attr == "class":
+		return "className"
+	case strings.HasPrefix(attr, "data-"), strings.HasPrefix(attr, "aria-"):
+		return attr
+	}
+	return camelCase(strings.ReplaceAll(attr, ":", "-"))
+}
+
+func svgStyleObject(style string) map[string]string {
+	out := map[string]string{}
+	for decl := range strings.SplitSeq(style, ";") {
+		key, value, ok := strings.Cut(decl, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		if !strings.HasPrefix(key, "--") {
+			key = camelCase(key)
+		}
+		out[key] = strings.TrimSpace(value)
+	}
+	return out
+}
+
+func camelCase(s string) string {
+	parts := strings.Split(s, "-")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,70 @@
+package alloy
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+func TestSvgComponentImport(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "node_modules", "react", "index.js"), `
+exports.createElement = function(tag, props) {
+	var attrs = "";
+	for (var k in props) {
+		if (k !== "dangerouslySetInnerHTML") attrs += " " + k + "=" + JSON.stringify(props[k]);
+	}
+	return "<" + tag + attrs + ">" + props.dangerouslySetInnerHTML.__html + "</" + tag + ">";
+};`)
+	writeTestFile(t, filepath.Join(dir, "app", "icon.svg"), `<?xml version="1.0"?>
+<!-- exported -->
+<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 24 24" stroke-width="2" class="icon">
+	<path d="M0 0h24v24H0z"/>
+</svg>`)
+	entry := filepath.Join(dir, "app", "page.ts")
+	writeTestFile(t, entry, `import Icon from "./icon.svg?component";
+import iconURL from "./icon.svg";
+export default function render(props: any) { return Icon({ width: props.size }) + "|" + iconURL; }`)
+
+	opts := commonBuildOptions()
+	opts.EntryPoints = []string{entry}
+	opts.Outdir = filepath.Join(dir, "dist", "build")
+	opts.Write = false
+	opts.Format = api.FormatIIFE
+	opts.GlobalName = "__Component"
+	applyAssetLoaders(&opts, opts.Outdir)
+
+	result := api.Build(opts)
+	if err := checkBuildErrors(result, "build"); err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	var js string
+	for _, out := range result.OutputFiles {
+		if filepath.Ext(out.Path) == ".js" {
+			js = string(out.Contents)
+		}
+	}
+
+	out, err := executeSSR(context.Background(), js, map[string]any{"size": "32"})
+	if err != nil {
+		t.Fatalf("execute ssr: %v", err)
+	}
+	html := out.HTML
+
+	for _, want := range []string{`viewBox="0 0 24 24"`, `strokeWidth="2"`, `className="icon"`, `width="32"`, `<path d="M0 0h24v24H0z"/>`, "|/dist/build/assets/icon-"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("output missing %q: %s", want, html)
+		}
+	}
+}
+
+func TestSvgStyleObject(t *testing.T) {
+	style := svgStyleObject("fill-rule: evenodd; --accent: red;")
+	if style["fillRule"] != "evenodd" || style["--accent"] != "red" {
+		t.Fatalf("unexpected style: %v", style)
+	}
+}
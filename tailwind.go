@@ -0,0 +1,280 @@
+package alloy
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	tailwindPackage        = "@tailwindcss/cli"
+	tailwindV3Package      = "tailwindcss"
+	DefaultTailwindVersion = "4.1.13"
+	tailwindStandaloneEnv  = "ALLOY_TAILWIND_STANDALONE"
+)
+
+var tailwindReleaseURL = "https://github.com/tailwindlabs/tailwindcss/releases/download"
+
+var tailwindDownloadClient = &http.Client{Timeout: 5 * time.Minute}
+
+func tailwindAssetName(goos, goarch string, musl bool) (string, error) {
+	var osName string
+	switch goos {
+	case "linux":
+		osName = "linux"
+	case "darwin":
+		osName = "macos"
+	case "windows":
+		osName = "windows"
+	default:
+		return "", fmt.Errorf("🔴 standalone tailwind unsupported on %s", goos)
+	}
+
+	var arch string
+	switch goarch {
+	case "amd64":
+		arch = "x64"
+	case "arm64":
+		arch = "arm64"
+	default:
+		return "", fmt.Errorf("🔴 standalone tailwind unsupported on %s/%s", goos, goarch)
+	}
+
+	name := "tailwindcss-" + osName + "-" + arch
+	switch {
+	case goos == "windows":
+		name += ".exe"
+	case goos == "linux" && musl:
+		name += "-musl"
+	}
+	return name, nil
+}
+
+func isMuslLibc() bool {
+	matches, _ := filepath.Glob("/lib/ld-musl-*")
+	return len(matches) > 0
+}
+
+func tailwindStandaloneEnabled() bool {
+	cfg := getConfig()
+	if cfg != nil && cfg.TailwindStandalone {
+		return true
+	}
+	return os.Getenv(tailwindStandaloneEnv) == "1"
+}
+
+func tailwindVersion() string {
+	cfg := getConfig()
+	if cfg != nil && cfg.TailwindVersion != "" {
+		return strings.TrimPrefix(cfg.TailwindVersion, "v")
+	}
+	return DefaultTailwindVersion
+}
+
+var tailwindConfigNames = []string{"tailwind.config.js", "tailwind.config.cjs", "tailwind.config.mjs", "tailwind.config.ts"}
+
+func tailwindConfigFile(root string) string {
+	for _, name := range tailwindConfigNames {
+		if fileExists(filepath.Join(root, name)) {
+			return name
+		}
+	}
+	return ""
+}
+
+func tailwindMajor(root string) int {
+	if cfg := getConfig(); cfg != nil && cfg.TailwindVersion != "" {
+		return majorVersion(cfg.TailwindVersion)
+	}
+
+	var installed struct {
+		Version string `json:"version"`
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "node_modules", "tailwindcss", "package.json")); err == nil && json.Unmarshal(data, &installed) == nil && installed.Version != "" {
+		return majorVersion(installed.Version)
+	}
+
+	var pkg struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "package.json")); err == nil && json.Unmarshal(data, &pkg) == nil {
+		for _, deps := range []map[string]string{pkg.Dependencies, pkg.DevDependencies} {
+			if _, ok := deps[tailwindPackage]; ok {
+				return 4
+			}
+			if spec, ok := deps[tailwindV3Package]; ok {
+				return majorVersion(spec)
+			}
+		}
+	}
+
+	if tailwindConfigFile(root) != "" {
+		return 3
+	}
+	return 4
+}
+
+func majorVersion(version string) int {
+	version = strings.TrimLeft(version, "^~>=v ")
+	major, _, _ := strings.Cut(version, ".")
+	n, err := strconv.Atoi(major)
+	if err != nil {
+		return 4
+	}
+	return n
+}
+
+func tailwindConfigArgs(root string) []string {
+	if tailwindMajor(root) != 3 {
+		return nil
+	}
+	if file := tailwindConfigFile(root); file != "" {
+		return []string{"-c", file}
+	}
+	return nil
+}
+
+func tailwindCachePath(version string) (string, error) {
+	asset, err := tailwindAssetName(runtime.GOOS, runtime.GOARCH, isMuslLibc() && majorVersion(version) >= 4)
+	if err != nil {
+		return "", err
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("🔴 user cache dir: %w", err)
+	}
+	return filepath.Join(cacheDir, "alloy", "tailwindcss", "v"+version, asset), nil
+}
+
+func resolveTailwindRunner(root string) (string, []string, error) {
+	configArgs := tailwindConfigArgs(root)
+	cfg := getConfig()
+	if cfg != nil && cfg.TailwindBinary != "" {
+		return cfg.TailwindBinary, configArgs, nil
+	}
+
+	if tailwindStandaloneEnabled() {
+		binary, err := ensureTailwindBinary(tailwindVersion())
+		if err != nil {
+			return "", nil, err
+		}
+		return binary, configArgs, nil
+	}
+
+	runner, args := ResolvePackageRunner(root, tailwindRunnerPackage(root))
+	if runner == "" {
+		return "", nil, fmt.Errorf("🔴 tailwind runner not found")
+	}
+	return runner, append(args, configArgs...), nil
+}
+
+func tailwindRunnerPackage(root string) string {
+	if tailwindMajor(root) == 3 {
+		return tailwindV3Package
+	}
+	return tailwindPackage
+}
+
+func ensureTailwindBinary(version string) (string, error) {
+	dest, err := tailwindCachePath(version)
+	if err != nil {
+		return "", err
+	}
+	if fileExists(dest) {
+		return dest, nil
+	}
+	if err := downloadTailwind(tailwindReleaseURL, version, filepath.Base(dest), dest); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+func downloadTailwind(baseURL, version, asset, dest string) error {
+	releaseURL := strings.TrimSuffix(baseURL, "/") + "/v" + version
+
+	sums, err := fetchTailwindChecksums(releaseURL + "/sha256sums.txt")
+	if err != nil {
+		return err
+	}
+	want, ok := sums[asset]
+	if !ok {
+		return fmt.Errorf("🔴 no checksum for %s in tailwind v%s", asset, version)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("🔴 make tailwind cache dir: %w", err)
+	}
+
+	resp, err := tailwindDownloadClient.Get(releaseURL + "/" + asset)
+	if err != nil {
+		return fmt.Errorf("🔴 download tailwind %s: %w", asset, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("🔴 download tailwind %s: %s", asset, resp.Status)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".download-*")
+	if err != nil {
+		return fmt.Errorf("🔴 create tailwind temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hash), resp.Body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("🔴 download tailwind %s: %w", asset, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("🔴 write tailwind binary: %w", err)
+	}
+
+	if got := hex.EncodeToString(hash.Sum(nil)); got != want {
+		return fmt.Errorf("🔴 tailwind %s checksum mismatch: got %s, want %s", asset, got, want)
+	}
+
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return fmt.Errorf("🔴 chmod tailwind binary: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return fmt.Errorf("🔴 install tailwind binary: %w", err)
+	}
+	return nil
+}
+
+func fetchTailwindChecksums(url string) (map[string]string, error) {
+	resp, err := tailwindDownloadClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("🔴 download tailwind checksums: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("🔴 download tailwind checksums: %s", resp.Status)
+	}
+
+	sums := map[string]string{}
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		name := strings.TrimPrefix(strings.TrimPrefix(fields[1], "*"), "./")
+		sums[name] = strings.ToLower(fields[0])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("🔴 read tailwind checksums: %w", err)
+	}
+	return sums, nil
+}
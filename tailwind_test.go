@@ -0,0 +1,143 @@
+package alloy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTailwindAssetName(t *testing.T) {
+	cases := []struct {
+		goos, goarch string
+		musl         bool
+		want         string
+	}{
+		{"linux", "amd64", false, "tailwindcss-linux-x64"},
+		{"linux", "arm64", true, "tailwindcss-linux-arm64-musl"},
+		{"darwin", "arm64", false, "tailwindcss-macos-arm64"},
+		{"windows", "amd64", false, "tailwindcss-windows-x64.exe"},
+	}
+	for _, tc := range cases {
+		got, err := tailwindAssetName(tc.goos, tc.goarch, tc.musl)
+		if err != nil {
+			t.Fatalf("%s/%s: %v", tc.goos, tc.goarch, err)
+		}
+		if got != tc.want {
+			t.Fatalf("%s/%s: want %s, got %s", tc.goos, tc.goarch, tc.want, got)
+		}
+	}
+
+	if _, err := tailwindAssetName("plan9", "386", false); err == nil {
+		t.Fatal("expected error for unsupported platform")
+	}
+}
+
+func TestDownloadTailwindVerifiesChecksum(t *testing.T) {
+	binary := []byte("#!/bin/sh\necho tailwind\n")
+	sum := sha256.Sum256(binary)
+	checksum := hex.EncodeToString(sum[:])
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v4.0.0/sha256sums.txt":
+			fmt.Fprintf(w, "%s  ./tailwindcss-linux-x64\n%s  ./tailwindcss-linux-bad\n", checksum, strings.Repeat("0", 64))
+		case "/v4.0.0/tailwindcss-linux-x64", "/v4.0.0/tailwindcss-linux-bad":
+			w.Write(binary)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "cache", "tailwindcss")
+	if err := downloadTailwind(server.URL, "4.0.0", "tailwindcss-linux-x64", dest); err != nil {
+		t.Fatalf("download: %v", err)
+	}
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatalf("read binary: %v", err)
+	}
+	if string(got) != string(binary) {
+		t.Fatalf("unexpected binary contents: %q", got)
+	}
+	if info, _ := os.Stat(dest); info.Mode()&0100 == 0 {
+		t.Fatalf("binary not executable: %v", info.Mode())
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad")
+	err = downloadTailwind(server.URL, "4.0.0", "tailwindcss-linux-bad", bad)
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	if fileExists(bad) {
+		t.Fatal("binary with bad checksum should not be installed")
+	}
+
+	if err := downloadTailwind(server.URL, "4.0.0", "tailwindcss-missing", bad); err == nil {
+		t.Fatal("expected error for missing checksum entry")
+	}
+}
+
+func TestResolveTailwindRunnerPrefersBinary(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) {
+		cfg.TailwindBinary = "/opt/tailwindcss"
+	})
+
+	runner, args := ResolveTailwindRunner(t.TempDir())
+	if runner != "/opt/tailwindcss" || len(args) != 0 {
+		t.Fatalf("want configured binary, got %s %v", runner, args)
+	}
+
+	runner, args, err := resolveStepRunner(tailwindStep, t.TempDir())
+	if err != nil || runner != "/opt/tailwindcss" || len(args) != 0 {
+		t.Fatalf("tailwind step: got %s %v %v", runner, args, err)
+	}
+}
+
+func TestTailwindV3Detection(t *testing.T) {
+	t.Setenv(tailwindStandaloneEnv, "")
+	withTestConfig(t, func(cfg *Config) {
+		cfg.TailwindBinary = ""
+		cfg.TailwindVersion = ""
+		cfg.TailwindStandalone = false
+	})
+
+	root := t.TempDir()
+	if got := tailwindMajor(root); got != 4 {
+		t.Fatalf("empty project: v%d", got)
+	}
+	runner, args := ResolveTailwindRunner(root)
+	if runner != "npx" || strings.Join(args, " ") != tailwindPackage {
+		t.Fatalf("v4 runner: %s %v", runner, args)
+	}
+
+	writeTestFile(t, filepath.Join(root, "tailwind.config.js"), "module.exports = {}")
+	if got := tailwindMajor(root); got != 3 {
+		t.Fatalf("config file: v%d", got)
+	}
+	runner, args = ResolveTailwindRunner(root)
+	if runner != "npx" || strings.Join(args, " ") != "tailwindcss -c tailwind.config.js" {
+		t.Fatalf("v3 runner: %s %v", runner, args)
+	}
+
+	writeTestFile(t, filepath.Join(root, "package.json"), `{"devDependencies": {"@tailwindcss/cli": "^4.1.0", "tailwindcss": "^4.1.0"}}`)
+	if got := tailwindMajor(root); got != 4 {
+		t.Fatalf("v4 dependency with config file: v%d", got)
+	}
+
+	writeTestFile(t, filepath.Join(root, "node_modules", "tailwindcss", "package.json"), `{"version": "3.4.17"}`)
+	if got := tailwindMajor(root); got != 3 {
+		t.Fatalf("installed v3: v%d", got)
+	}
+
+	withTestConfig(t, func(cfg *Config) { cfg.TailwindVersion = "v4.1.13" })
+	if got := tailwindMajor(root); got != 4 {
+		t.Fatalf("configured version: v%d", got)
+	}
+}
@@ -0,0 +1,66 @@
+package alloy
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const colorSchemeHint = "Sec-CH-Prefers-Color-Scheme"
+
+var themeValue = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+func themeCookie() string {
+	if cfg := getConfig(); cfg != nil {
+		return cfg.ThemeCookie
+	}
+	return ""
+}
+
+func Theme(r *http.Request) string {
+	name := themeCookie()
+	if name == "" {
+		return ""
+	}
+	if cookie, err := r.Cookie(name); err == nil && themeValue.MatchString(cookie.Value) {
+		return cookie.Value
+	}
+	if hint := strings.Trim(r.Header.Get(colorSchemeHint), `"`); hint == "light" || hint == "dark" {
+		return hint
+	}
+	return ""
+}
+
+func withTheme(w http.ResponseWriter, r *http.Request, props map[string]any) map[string]any {
+	if themeCookie() == "" {
+		return props
+	}
+	w.Header().Set("Accept-CH", colorSchemeHint)
+	addVary(w.Header(), "Cookie", colorSchemeHint)
+	return themeProps(r, props)
+}
+
+func themeProps(r *http.Request, props map[string]any) map[string]any {
+	theme := Theme(r)
+	if theme == "" {
+		return props
+	}
+	if props == nil {
+		props = map[string]any{}
+	}
+	if _, ok := props["theme"]; !ok {
+		props["theme"] = theme
+	}
+	return props
+}
+
+func htmlAttrs(props map[string]any) string {
+	theme, _ := props["theme"].(string)
+	if !themeValue.MatchString(theme) {
+		return ""
+	}
+	theme = html.EscapeString(theme)
+	return fmt.Sprintf(` class="%s" data-theme="%s"`, theme, theme)
+}
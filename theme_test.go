@@ -0,0 +1,66 @@
+package alloy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPageHandlerRendersThemeFromCookie(t *testing.T) {
+	handler := etagTestPage(t, func(cfg *Config) { cfg.ThemeCookie = "theme" })
+
+	req := httptest.NewRequest(http.MethodGet, "/etag", nil)
+	req.AddCookie(&http.Cookie{Name: "theme", Value: "dark"})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	body := rec.Body.String()
+	if !strings.Contains(body, `<html class="dark" data-theme="dark">`) || !strings.Contains(body, `"theme":"dark"`) {
+		t.Fatalf("🔴 expected dark theme in document:\n%s", body)
+	}
+	if rec.Header().Get("Accept-CH") != colorSchemeHint || !strings.Contains(rec.Header().Get("Vary"), "Cookie") {
+		t.Fatalf("🔴 unexpected headers: %v", rec.Header())
+	}
+}
+
+func TestThemeFallsBackToColorSchemeHint(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) { cfg.ThemeCookie = "theme" })
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(colorSchemeHint, `"dark"`)
+	if got := Theme(req); got != "dark" {
+		t.Fatalf("🔴 expected dark from hint, got %q", got)
+	}
+
+	req.AddCookie(&http.Cookie{Name: "theme", Value: "light"})
+	if got := Theme(req); got != "light" {
+		t.Fatalf("🔴 expected cookie to win, got %q", got)
+	}
+}
+
+func TestThemeIgnoresInvalidValues(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) { cfg.ThemeCookie = "theme" })
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Cookie", "theme=dark.mode")
+	if got := Theme(req); got != "" {
+		t.Fatalf("🔴 expected invalid cookie to be ignored, got %q", got)
+	}
+	if attrs := htmlAttrs(map[string]any{"theme": `x" onclick="y`}); attrs != "" {
+		t.Fatalf("🔴 expected no attrs, got %q", attrs)
+	}
+}
+
+func TestThemeDisabledWithoutCookieName(t *testing.T) {
+	handler := etagTestPage(t, func(cfg *Config) {})
+
+	req := httptest.NewRequest(http.MethodGet, "/etag", nil)
+	req.AddCookie(&http.Cookie{Name: "theme", Value: "dark"})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if !strings.Contains(rec.Body.String(), "<html>") || rec.Header().Get("Accept-CH") != "" {
+		t.Fatalf("🔴 expected theme support to be off:\n%s", rec.Body.String())
+	}
+}
@@ -0,0 +1,57 @@
+package alloy
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/3-lines-studio/alloy"
+
+func tracer() trace.Tracer {
+	if cfg := getConfig(); cfg != nil && cfg.TracerProvider != nil {
+		return cfg.TracerProvider.Tracer(tracerName)
+	}
+	return otel.GetTracerProvider().Tracer(tracerName)
+}
+
+func propagator() propagation.TextMapPropagator {
+	if cfg := getConfig(); cfg != nil && cfg.Propagator != nil {
+		return cfg.Propagator
+	}
+	return otel.GetTextMapPropagator()
+}
+
+func extractTraceContext(r *http.Request) context.Context {
+	ctx := r.Context()
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+	return propagator().Extract(ctx, propagation.HeaderCarrier(r.Header))
+}
+
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func writeHTML(ctx context.Context, w io.Writer, html string) {
+	html = injectPrefetch(ctx, html)
+	html = injectDevToolbar(ctx, html)
+	_, span := startSpan(ctx, "alloy.write", attribute.Int("alloy.bytes", len(html)))
+	_, err := io.WriteString(w, html)
+	endSpan(span, err)
+}
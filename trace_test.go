@@ -0,0 +1,94 @@
+package alloy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestPageHandlerEmitsRenderSpans(t *testing.T) {
+	resetBundleCache()
+	t.Cleanup(resetBundleCache)
+
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "about-server.js"), `var __Component = { default: function() { return "<p>about</p>"; } };`)
+	writeTestFile(t, filepath.Join(dist, "client-about-AAAAAAAA.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "shared.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{"about": {"server": "about-server.js", "client": "client-about-AAAAAAAA.js", "css": "shared.css"}}`)
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+		cfg.TracerProvider = provider
+	})
+
+	parentCtx, parent := provider.Tracer("test").Start(context.Background(), "incoming")
+	handler := NewPage(filepath.Join(root, "pages", "about.tsx")).WithLoader(func(r *http.Request) map[string]any {
+		return map[string]any{}
+	})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/about", nil).WithContext(parentCtx))
+	parent.End()
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	for _, name := range []string{"alloy.render", "alloy.loader", "alloy.bundle_cache", "alloy.eval", "alloy.write"} {
+		span, ok := spans[name]
+		if !ok {
+			t.Fatalf("missing span %s in %v", name, spans)
+		}
+		if span.SpanContext().TraceID() != parent.SpanContext().TraceID() {
+			t.Fatalf("span %s not part of the incoming trace", name)
+		}
+	}
+	if got := spans["alloy.render"].Parent().SpanID(); got != parent.SpanContext().SpanID() {
+		t.Fatalf("render span parent: %s", got)
+	}
+	if got := spans["alloy.eval"].Parent().SpanID(); got != spans["alloy.render"].SpanContext().SpanID() {
+		t.Fatalf("eval span parent: %s", got)
+	}
+}
+
+func TestPageHandlerExtractsTraceparent(t *testing.T) {
+	resetBundleCache()
+	t.Cleanup(resetBundleCache)
+
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "about-server.js"), `var __Component = { default: function() { return "<p>about</p>"; } };`)
+	writeTestFile(t, filepath.Join(dist, "client-about-AAAAAAAA.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{"about": {"server": "about-server.js", "client": "client-about-AAAAAAAA.js"}}`)
+
+	recorder := tracetest.NewSpanRecorder()
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+		cfg.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		cfg.Propagator = propagation.TraceContext{}
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/about", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	NewPage(filepath.Join(root, "pages", "about.tsx")).ServeHTTP(httptest.NewRecorder(), req)
+
+	for _, span := range recorder.Ended() {
+		if span.Name() != "alloy.render" {
+			continue
+		}
+		if span.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" || span.Parent().SpanID().String() != "00f067aa0ba902b7" || !span.Parent().IsRemote() {
+			t.Fatalf("🔴 render span not parented to traceparent: trace %s parent %s", span.SpanContext().TraceID(), span.Parent().SpanID())
+		}
+		return
+	}
+	t.Fatal("missing alloy.render span")
+}
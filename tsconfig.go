@@ -0,0 +1,127 @@
+package alloy
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type tsconfigFile struct {
+	Files           []string       `json:"files"`
+	CompilerOptions map[string]any `json:"compilerOptions"`
+	References      []struct {
+		Path string `json:"path"`
+	} `json:"references"`
+}
+
+func findTsconfig(dir string) string {
+	cfg := getConfig()
+	if cfg != nil && cfg.Tsconfig != "" {
+		return mustResolveAbsPath(cfg.Tsconfig)
+	}
+	for _, name := range []string{"tsconfig.json", "jsconfig.json"} {
+		candidate := filepath.Join(dir, name)
+		if fileExists(candidate) {
+			return solutionTsconfig(candidate)
+		}
+	}
+	return ""
+}
+
+// esbuild doesn't follow project references, so for a solution-style
+// config it gets the first referenced config instead.
+func solutionTsconfig(file string) string {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return file
+	}
+	var cfg tsconfigFile
+	if err := json.Unmarshal(stripJSONC(data), &cfg); err != nil {
+		return file
+	}
+	if cfg.Files == nil || len(cfg.Files) > 0 || len(cfg.CompilerOptions) > 0 {
+		return file
+	}
+	for _, ref := range cfg.References {
+		refPath := filepath.Join(filepath.Dir(file), ref.Path)
+		if info, err := os.Stat(refPath); err == nil && info.IsDir() {
+			refPath = filepath.Join(refPath, "tsconfig.json")
+		}
+		if fileExists(refPath) {
+			return refPath
+		}
+	}
+	return file
+}
+
+func stripJSONC(data []byte) []byte {
+	return stripTrailingCommas(stripJSONComments(data))
+}
+
+func stripJSONComments(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i+1 < len(data) && data[i+1] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			i += 2
+			for i+1 < len(data) && !(data[i] == '*' && data[i+1] == '/') {
+				i++
+			}
+			i++
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func stripTrailingCommas(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == ',':
+			j := i + 1
+			for j < len(data) && strings.ContainsRune(" \t\r\n", rune(data[j])) {
+				j++
+			}
+			if j < len(data) && (data[j] == '}' || data[j] == ']') {
+				continue
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
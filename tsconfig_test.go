@@ -0,0 +1,70 @@
+package alloy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+func writeTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("mkdir %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestTsconfigPathsFollowsReferencesAndExtends(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeTestFile(t, filepath.Join(dir, "tsconfig.json"), `{
+	// solution-style config
+	"files": [],
+	"references": [{ "path": "./tsconfig.app.json" }],
+}`)
+	writeTestFile(t, filepath.Join(dir, "tsconfig.app.json"), `{
+	"extends": ["@acme/tsconfig", "./tsconfig.base"],
+	"include": ["app"],
+}`)
+	writeTestFile(t, filepath.Join(dir, "tsconfig.base.json"), `{
+	"compilerOptions": {
+		/* aliases */
+		"paths": { "@/*": ["./app/*"], "@ui": ["./app/components/ui/index.ts"], },
+	},
+}`)
+	writeTestFile(t, filepath.Join(dir, "node_modules", "@acme", "tsconfig", "tsconfig.json"), `{ "compilerOptions": { "baseUrl": "../../.." } }`)
+	writeTestFile(t, filepath.Join(dir, "app", "components", "Button.tsx"), `export const Button = "button-component";`)
+	writeTestFile(t, filepath.Join(dir, "app", "components", "ui", "index.ts"), `export const ui = "ui-kit";`)
+	writeTestFile(t, filepath.Join(dir, "lib", "util.ts"), `export const util = "base-url-import";`)
+
+	if got := findTsconfig(dir); got != filepath.Join(dir, "tsconfig.app.json") {
+		t.Fatalf("findTsconfig = %s", got)
+	}
+
+	entry := filepath.Join(t.TempDir(), "entry.ts")
+	writeTestFile(t, entry, `import { Button } from "@/components/Button";
+import { ui } from "@ui";
+import { util } from "lib/util";
+console.log(Button, ui, util);`)
+
+	opts := commonBuildOptions()
+	opts.EntryPoints = []string{entry}
+	opts.Write = false
+
+	result := api.Build(opts)
+	if err := checkBuildErrors(result, "build"); err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	out := string(result.OutputFiles[0].Contents)
+	for _, want := range []string{"button-component", "ui-kit", "base-url-import"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("bundle missing %q:\n%s", want, out)
+		}
+	}
+}
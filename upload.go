@@ -0,0 +1,143 @@
+package alloy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+)
+
+type Uploader interface {
+	Upload(ctx context.Context, key string, data []byte, header http.Header) error
+}
+
+func OpenUploader(spec string) (Uploader, error) {
+	store, err := OpenBuildCache(spec)
+	if err != nil {
+		return nil, err
+	}
+	uploader, ok := store.(Uploader)
+	if !ok {
+		return nil, fmt.Errorf("🔴 %q does not support uploads", spec)
+	}
+	return uploader, nil
+}
+
+func AssetURL(p string) string {
+	cfg := getConfig()
+	if cfg == nil || cfg.AssetURL == "" || p == "" {
+		return p
+	}
+	rel, ok := strings.CutPrefix(p, "/"+DefaultDistDir+"/")
+	if !ok {
+		return p
+	}
+	return strings.TrimSuffix(cfg.AssetURL, "/") + "/" + rel
+}
+
+func UploadDist(ctx context.Context, distDir string, uploader Uploader) (int, error) {
+	manifest, err := ReadManifest(os.DirFS(distDir), ".")
+	if err != nil {
+		return 0, err
+	}
+
+	names := map[string]bool{}
+	for _, entry := range manifest.Pages {
+		for _, name := range append(append([]string{entry.Client, entry.CSS, entry.Legacy}, append(entry.Chunks, entry.Assets...)...), manifestLocaleNames(entry)...) {
+			if name != "" {
+				names[name] = true
+			}
+		}
+	}
+	public, err := publicFileNames(distDir)
+	if err != nil {
+		return 0, err
+	}
+	for _, name := range public {
+		names[name] = true
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(8)
+	for _, name := range sortedKeys(names) {
+		g.Go(func() error {
+			return uploadDistFile(gctx, uploader, distDir, name)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return 0, err
+	}
+
+	manifests := []string{"manifest.json"}
+	if public != nil {
+		manifests = []string{publicManifestName, "manifest.json"}
+	}
+	header := http.Header{"Content-Type": {"application/json"}, "Cache-Control": {"no-cache"}}
+	for _, name := range manifests {
+		data, err := os.ReadFile(filepath.Join(distDir, name))
+		if err != nil {
+			return 0, fmt.Errorf("🔴 read %s: %w", name, err)
+		}
+		if err := uploader.Upload(ctx, name, data, header); err != nil {
+			return 0, fmt.Errorf("🔴 upload %s: %w", name, err)
+		}
+	}
+	return len(names) + len(manifests), nil
+}
+
+// Public manifest values are URLs under the dist prefix; the hashed copies
+// live at the same path relative to distDir.
+func publicFileNames(distDir string) ([]string, error) {
+	public, err := ReadPublicManifest(os.DirFS(distDir), ".")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	absDist, err := resolveAbsPath(distDir, "dist dir")
+	if err != nil {
+		return nil, err
+	}
+	prefix := "/" + distURLPrefix(absDist) + "/"
+	names := []string{}
+	for _, hashed := range public {
+		name, ok := strings.CutPrefix(hashed, prefix)
+		if !ok {
+			return nil, fmt.Errorf("🔴 public asset %s is outside %s", hashed, prefix)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func uploadDistFile(ctx context.Context, uploader Uploader, distDir string, name string) error {
+	header := http.Header{}
+	file := filepath.Join(distDir, filepath.FromSlash(name))
+	data, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		data, err = os.ReadFile(file + packedExt)
+		header.Set("Content-Encoding", "gzip")
+	}
+	if err != nil {
+		return fmt.Errorf("🔴 read %s: %w", FormatPath(file), err)
+	}
+
+	if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	header.Set("Cache-Control", AssetCacheControl(name, isHashedAsset(name)))
+
+	if err := uploader.Upload(ctx, name, data, header); err != nil {
+		return fmt.Errorf("🔴 upload %s: %w", name, err)
+	}
+	return nil
+}
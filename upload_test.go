@@ -0,0 +1,112 @@
+package alloy
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type recordingUploader struct {
+	sync.Mutex
+	keys    []string
+	headers map[string]http.Header
+}
+
+func (u *recordingUploader) Upload(ctx context.Context, key string, data []byte, header http.Header) error {
+	u.Lock()
+	defer u.Unlock()
+	u.keys = append(u.keys, key)
+	if u.headers == nil {
+		u.headers = map[string]http.Header{}
+	}
+	u.headers[key] = header
+	return nil
+}
+
+func TestUploadDist(t *testing.T) {
+	dist := t.TempDir()
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{"version":2,"pages":{"home":{"server":"home-server-1a2b3c4d.js","client":"home-client-1a2b3c4d.js","css":"home.css","legacy":"home-legacy-1a2b3c4d.js","assets":["assets/logo-1a2b3c4d.png"],"html":"home-1a2b3c4d.html"}}}`)
+	writeTestFile(t, filepath.Join(dist, "home-legacy-1a2b3c4d.js"), "legacy")
+	writeTestFile(t, filepath.Join(dist, "home-server-1a2b3c4d.js"), "server")
+	writeTestFile(t, filepath.Join(dist, "home-client-1a2b3c4d.js.gz"), "gzipped")
+	writeTestFile(t, filepath.Join(dist, "home.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "assets", "logo-1a2b3c4d.png"), "png")
+
+	uploader := &recordingUploader{}
+	count, err := UploadDist(context.Background(), dist, uploader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 5 {
+		t.Fatalf("count = %d, keys = %v", count, uploader.keys)
+	}
+	if legacy := uploader.headers["home-legacy-1a2b3c4d.js"]; legacy == nil {
+		t.Errorf("legacy bundle not uploaded: %v", uploader.keys)
+	}
+	if last := uploader.keys[len(uploader.keys)-1]; last != "manifest.json" {
+		t.Fatalf("last upload = %s, want manifest.json", last)
+	}
+	for _, key := range uploader.keys {
+		if strings.Contains(key, "server") || strings.HasSuffix(key, ".html") {
+			t.Errorf("uploaded %s", key)
+		}
+	}
+
+	client := uploader.headers["home-client-1a2b3c4d.js"]
+	if client.Get("Content-Encoding") != "gzip" || client.Get("Cache-Control") != "public, max-age=31536000, immutable" {
+		t.Errorf("client headers = %v", client)
+	}
+	if !strings.HasPrefix(client.Get("Content-Type"), "text/javascript") {
+		t.Errorf("client content type = %q", client.Get("Content-Type"))
+	}
+	if css := uploader.headers["home.css"]; css.Get("Cache-Control") != "public, max-age=300" {
+		t.Errorf("css headers = %v", css)
+	}
+	if manifest := uploader.headers["manifest.json"]; manifest.Get("Cache-Control") != "no-cache" {
+		t.Errorf("manifest headers = %v", manifest)
+	}
+}
+
+func TestUploadDistPublicAssets(t *testing.T) {
+	dist := filepath.Join(t.TempDir(), "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{"version":2,"pages":{"home":{"server":"home-server-1a2b3c4d.js","client":"home-client-1a2b3c4d.js","css":"home.css"}}}`)
+	writeTestFile(t, filepath.Join(dist, "home-client-1a2b3c4d.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "home.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "public", "img", "logo-5e6f7a8b.png"), "png")
+	writeTestFile(t, filepath.Join(dist, publicManifestName), `{"/img/logo.png": "/dist/build/public/img/logo-5e6f7a8b.png"}`)
+
+	uploader := &recordingUploader{}
+	count, err := UploadDist(context.Background(), dist, uploader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 5 {
+		t.Fatalf("count = %d, keys = %v", count, uploader.keys)
+	}
+	if logo := uploader.headers["public/img/logo-5e6f7a8b.png"]; logo.Get("Cache-Control") != "public, max-age=31536000, immutable" {
+		t.Errorf("public asset headers = %v", logo)
+	}
+	if keys := uploader.keys[len(uploader.keys)-2:]; keys[0] != publicManifestName || keys[1] != "manifest.json" {
+		t.Fatalf("last uploads = %v", keys)
+	}
+}
+
+func TestAssetURL(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) {
+		cfg.AssetURL = "https://cdn.example.com/app/"
+	})
+
+	result := prebuiltResult("", nil, PrebuiltFiles{Client: "dist/build/home-client-1a2b3c4d.js", CSS: "dist/build/home.css"})
+	if result.ClientPaths[0] != "https://cdn.example.com/app/home-client-1a2b3c4d.js" {
+		t.Errorf("client path = %s", result.ClientPaths[0])
+	}
+	if result.CSSPath != "https://cdn.example.com/app/home.css" {
+		t.Errorf("css path = %s", result.CSSPath)
+	}
+	if got := AssetURL("/favicon.ico"); got != "/favicon.ico" {
+		t.Errorf("AssetURL(/favicon.ico) = %s", got)
+	}
+}
@@ -0,0 +1,108 @@
+package alloy
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"regexp"
+	"slices"
+	"time"
+)
+
+const VariantHeader = "X-Alloy-Variant"
+
+const variantCookieMaxAge = 30 * 24 * time.Hour
+
+var variantValue = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+type VariantResolver func(w http.ResponseWriter, r *http.Request) string
+
+type variantContextKey struct{}
+
+func VariantFromCookie(name string, variants ...string) VariantResolver {
+	return func(w http.ResponseWriter, r *http.Request) string {
+		addVary(w.Header(), "Cookie")
+		if cookie, err := r.Cookie(name); err == nil && slices.Contains(variants, cookie.Value) {
+			return cookie.Value
+		}
+		return ""
+	}
+}
+
+func VariantFromHeader(name string, variants ...string) VariantResolver {
+	return func(w http.ResponseWriter, r *http.Request) string {
+		addVary(w.Header(), name)
+		if value := r.Header.Get(name); slices.Contains(variants, value) {
+			return value
+		}
+		return ""
+	}
+}
+
+func VariantSplit(cookie string, weights map[string]int) VariantResolver {
+	variants := sortedKeys(weights)
+	total := 0
+	for _, variant := range variants {
+		total += max(weights[variant], 0)
+	}
+	return func(w http.ResponseWriter, r *http.Request) string {
+		addVary(w.Header(), "Cookie")
+		if c, err := r.Cookie(cookie); err == nil && weights[c.Value] > 0 {
+			return c.Value
+		}
+		if total == 0 {
+			return ""
+		}
+
+		variant := ""
+		n := rand.IntN(total)
+		for _, name := range variants {
+			if n -= max(weights[name], 0); n < 0 {
+				variant = name
+				break
+			}
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name:     cookie,
+			Value:    variant,
+			Path:     "/",
+			MaxAge:   int(variantCookieMaxAge.Seconds()),
+			HttpOnly: true,
+			Secure:   r.TLS != nil,
+			SameSite: http.SameSiteLaxMode,
+		})
+		return variant
+	}
+}
+
+func Variant(r *http.Request) string {
+	variant, _ := r.Context().Value(variantContextKey{}).(string)
+	return variant
+}
+
+func withVariant(w http.ResponseWriter, r *http.Request) *http.Request {
+	cfg := getConfig()
+	if cfg == nil || cfg.Variant == nil {
+		return r
+	}
+	variant := cfg.Variant(w, r)
+	if !variantValue.MatchString(variant) {
+		return r
+	}
+	w.Header().Set(VariantHeader, variant)
+	return r.WithContext(context.WithValue(r.Context(), variantContextKey{}, variant))
+}
+
+func withVariantProps(r *http.Request, props map[string]any) map[string]any {
+	variant := Variant(r)
+	if variant == "" {
+		return props
+	}
+	if props == nil {
+		props = map[string]any{}
+	}
+	if _, ok := props["variant"]; !ok {
+		props["variant"] = variant
+	}
+	return props
+}
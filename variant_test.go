@@ -0,0 +1,112 @@
+package alloy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVariantSplitIsSticky(t *testing.T) {
+	resolve := VariantSplit("ab", map[string]int{"control": 1, "new-nav": 0})
+
+	rec := httptest.NewRecorder()
+	if got := resolve(rec, httptest.NewRequest(http.MethodGet, "/", nil)); got != "control" {
+		t.Fatalf("variant = %q", got)
+	}
+	if cookie := rec.Header().Get("Set-Cookie"); !strings.HasPrefix(cookie, "ab=control;") {
+		t.Fatalf("Set-Cookie = %q", cookie)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "ab", Value: "control"})
+	rec = httptest.NewRecorder()
+	if got := resolve(rec, req); got != "control" || rec.Header().Get("Set-Cookie") != "" {
+		t.Fatalf("sticky variant = %q, Set-Cookie = %q", got, rec.Header().Get("Set-Cookie"))
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "ab", Value: "new-nav"})
+	rec = httptest.NewRecorder()
+	if got := resolve(rec, req); got != "control" {
+		t.Fatalf("variant without weight kept: %q", got)
+	}
+}
+
+func TestVariantFromCookieAcceptsConfiguredVariants(t *testing.T) {
+	resolve := VariantFromCookie("ab", "control", "new-nav")
+	for value, want := range map[string]string{"control": "control", "new-nav": "new-nav", "x7Kq9": ""} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: "ab", Value: value})
+		if got := resolve(httptest.NewRecorder(), req); got != want {
+			t.Fatalf("variant for cookie %q = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestPageHandlerVariants(t *testing.T) {
+	resetBundleCache()
+	t.Cleanup(resetBundleCache)
+	t.Cleanup(resetISRCache)
+
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "home-server.js"), `var __Component = { default: function(props) { return "<p>" + props.variant + ":" + props.seen + "</p>"; } };`)
+	writeTestFile(t, filepath.Join(dist, "news-server.js"), `var __Component = { default: function(props) { return "<p>news " + props.variant + "</p>"; } };`)
+	writeTestFile(t, filepath.Join(dist, "client-home-AAAAAAAA.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "shared.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "news.html"), "<p>prebuilt</p>")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{
+		"home": {"server": "home-server.js", "client": "client-home-AAAAAAAA.js", "css": "shared.css"},
+		"news": {"server": "news-server.js", "client": "client-home-AAAAAAAA.js", "css": "shared.css", "html": "news.html", "render": "static", "revalidate": 60}
+	}`)
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+		cfg.ISRDir = t.TempDir()
+		cfg.Variant = VariantFromHeader("X-Bucket", "a", "b")
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle("/", NewPage(filepath.Join(root, "pages", "home.tsx")).WithLoader(func(r *http.Request) map[string]any {
+		return map[string]any{"seen": Variant(r)}
+	}))
+	mux.Handle("/news", NewPage(filepath.Join(root, "pages", "news.tsx")))
+
+	get := func(path, bucket string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if bucket != "" {
+			req.Header.Set("X-Bucket", bucket)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/", "b")
+	if !strings.Contains(rec.Body.String(), "<p>b:b</p>") {
+		t.Fatalf("variant not passed to loader and props:\n%s", rec.Body.String())
+	}
+	if rec.Header().Get(VariantHeader) != "b" || rec.Header().Get("Vary") != "X-Alloy-Data, X-Bucket" {
+		t.Fatalf("headers = %v", rec.Header())
+	}
+	for _, bucket := range []string{"<script>", "c"} {
+		if rec := get("/", bucket); rec.Header().Get(VariantHeader) != "" || !strings.Contains(rec.Body.String(), "<p>undefined:</p>") {
+			t.Fatalf("variant %q used: %v\n%s", bucket, rec.Header(), rec.Body.String())
+		}
+	}
+
+	if rec := get("/news", "a"); rec.Header().Get("X-Alloy-Cache") != "MISS" || !strings.Contains(rec.Body.String(), "<p>news a</p>") {
+		t.Fatalf("variant a: %s %q", rec.Header().Get("X-Alloy-Cache"), rec.Body.String())
+	}
+	if rec := get("/news", "b"); rec.Header().Get("X-Alloy-Cache") != "MISS" || !strings.Contains(rec.Body.String(), "<p>news b</p>") {
+		t.Fatalf("variant b: %s %q", rec.Header().Get("X-Alloy-Cache"), rec.Body.String())
+	}
+	if rec := get("/news", "a"); rec.Header().Get("X-Alloy-Cache") != "HIT" || !strings.Contains(rec.Body.String(), "<p>news a</p>") {
+		t.Fatalf("variant a cached: %s %q", rec.Header().Get("X-Alloy-Cache"), rec.Body.String())
+	}
+	if rec := get("/news", ""); !strings.Contains(rec.Body.String(), "<p>prebuilt</p>") {
+		t.Fatalf("no variant should use the prebuilt html:\n%s", rec.Body.String())
+	}
+}
@@ -0,0 +1,108 @@
+package alloy
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const maxVaryValue = 64
+
+var varyLanguage = regexp.MustCompile(`^[a-z]{2,3}$`)
+
+type varyContextKey struct{}
+
+func VaryOn(r *http.Request, fields ...string) {
+	if header, ok := r.Context().Value(varyContextKey{}).(http.Header); ok && header != nil {
+		addVary(header, fields...)
+	}
+}
+
+func withVary(w http.ResponseWriter, r *http.Request) *http.Request {
+	header := w.Header()
+	if cfg := getConfig(); cfg != nil {
+		addVary(header, cfg.Vary...)
+	}
+	return r.WithContext(context.WithValue(r.Context(), varyContextKey{}, header))
+}
+
+func withoutVary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, varyContextKey{}, http.Header(nil))
+}
+
+func addVary(header http.Header, fields ...string) {
+	seen := map[string]bool{}
+	var merged []string
+	for _, value := range append(header.Values("Vary"), fields...) {
+		for _, field := range strings.Split(value, ",") {
+			field = strings.TrimSpace(field)
+			if field == "*" {
+				header.Set("Vary", "*")
+				return
+			}
+			if field == "" || seen[strings.ToLower(field)] {
+				continue
+			}
+			seen[strings.ToLower(field)] = true
+			merged = append(merged, field)
+		}
+	}
+	if len(merged) > 0 {
+		header.Set("Vary", strings.Join(merged, ", "))
+	}
+}
+
+func varyKey(r *http.Request) string {
+	cfg := getConfig()
+	if cfg == nil {
+		return ""
+	}
+	var key strings.Builder
+	for _, field := range cfg.Vary {
+		key.WriteString("\x00" + strings.ToLower(field) + "=" + varyValue(cfg, r, field))
+	}
+	return key.String()
+}
+
+func varyValue(cfg *Config, r *http.Request, field string) string {
+	field = http.CanonicalHeaderKey(field)
+	value := strings.TrimSpace(r.Header.Get(field))
+	for name, normalize := range cfg.VaryNormalize {
+		if strings.EqualFold(name, field) {
+			return normalize(value)
+		}
+	}
+	switch field {
+	case "Accept-Language":
+		if lang := preferredLanguage(r); lang != "" || value == "" {
+			return lang
+		}
+		return rawVaryValue(value)
+	case "Accept-Encoding":
+		if value == "" {
+			return ""
+		}
+		return rawVaryValue(value)
+	}
+	if len(value) > maxVaryValue {
+		return rawVaryValue(value)
+	}
+	return strings.ToLower(value)
+}
+
+func rawVaryValue(value string) string {
+	return "#" + sha256Hex([]byte(value))[:16]
+}
+
+func preferredLanguage(r *http.Request) string {
+	tags := acceptLanguages(r)
+	if len(tags) == 0 {
+		return ""
+	}
+	base, _, _ := strings.Cut(strings.ToLower(tags[0]), "-")
+	if !varyLanguage.MatchString(base) {
+		return ""
+	}
+	return base
+}
@@ -0,0 +1,130 @@
+package alloy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAddVary(t *testing.T) {
+	header := http.Header{"Vary": {"Accept-Encoding"}}
+	addVary(header, "Cookie", "accept-encoding", "Accept-Language, cookie")
+	if got := header.Values("Vary"); len(got) != 1 || got[0] != "Accept-Encoding, Cookie, Accept-Language" {
+		t.Fatalf("Vary = %q", got)
+	}
+	addVary(header, "*")
+	if got := header.Get("Vary"); got != "*" {
+		t.Fatalf("Vary = %q", got)
+	}
+}
+
+func TestPageHandlerVary(t *testing.T) {
+	resetBundleCache()
+	t.Cleanup(resetBundleCache)
+	t.Cleanup(resetISRCache)
+
+	root := t.TempDir()
+	dist := filepath.Join(root, "dist", "build")
+	writeTestFile(t, filepath.Join(dist, "home-server.js"), `var __Component = { default: function(props) { return "<p>" + props.lang + "</p>"; } };`)
+	writeTestFile(t, filepath.Join(dist, "news-server.js"), `var __Component = { default: function(props) { return "<p>news " + props.lang + "</p>"; } };`)
+	writeTestFile(t, filepath.Join(dist, "client-home-AAAAAAAA.js"), "client")
+	writeTestFile(t, filepath.Join(dist, "shared.css"), "body{}")
+	writeTestFile(t, filepath.Join(dist, "manifest.json"), `{
+		"home": {"server": "home-server.js", "client": "client-home-AAAAAAAA.js", "css": "shared.css"},
+		"news": {"server": "news-server.js", "client": "client-home-AAAAAAAA.js", "css": "shared.css", "render": "static", "revalidate": 60}
+	}`)
+	withTestConfig(t, func(cfg *Config) {
+		cfg.FS = os.DirFS(root)
+		cfg.ISRDir = t.TempDir()
+		cfg.ThemeCookie = "theme"
+		cfg.Vary = []string{"Accept-Language"}
+	})
+
+	loader := func(r *http.Request) map[string]any {
+		VaryOn(r, "X-Device")
+		return map[string]any{"lang": r.Header.Get("Accept-Language")}
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/", NewPage(filepath.Join(root, "pages", "home.tsx")).WithLoader(loader))
+	mux.Handle("/news", NewPage(filepath.Join(root, "pages", "news.tsx")).WithLoader(loader))
+
+	get := func(path, lang string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept-Language", lang)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/", "de")
+	if got := rec.Header().Values("Vary"); len(got) != 1 || got[0] != "Accept-Language, X-Alloy-Data, X-Device, Cookie, "+colorSchemeHint {
+		t.Fatalf("Vary = %q", got)
+	}
+
+	if rec := get("/news", "de"); rec.Header().Get("X-Alloy-Cache") != "MISS" || !strings.Contains(rec.Body.String(), "<p>news de</p>") {
+		t.Fatalf("de: %s %q", rec.Header().Get("X-Alloy-Cache"), rec.Body.String())
+	}
+	rec = get("/news", "fr")
+	if rec.Header().Get("X-Alloy-Cache") != "MISS" || !strings.Contains(rec.Body.String(), "<p>news fr</p>") {
+		t.Fatalf("fr: %s %q", rec.Header().Get("X-Alloy-Cache"), rec.Body.String())
+	}
+	if got := rec.Header().Get("Vary"); got != "Accept-Language, X-Alloy-Data, Cookie, "+colorSchemeHint+", X-Device" {
+		t.Fatalf("ISR Vary = %q", got)
+	}
+	if rec := get("/news", "de"); rec.Header().Get("X-Alloy-Cache") != "HIT" || !strings.Contains(rec.Body.String(), "<p>news de</p>") {
+		t.Fatalf("de cached: %s %q", rec.Header().Get("X-Alloy-Cache"), rec.Body.String())
+	}
+	if rec := get("/news", "de-AT,de;q=0.9,en;q=0.1"); rec.Header().Get("X-Alloy-Cache") != "HIT" || !strings.Contains(rec.Body.String(), "<p>news de</p>") {
+		t.Fatalf("de-AT: %s %q", rec.Header().Get("X-Alloy-Cache"), rec.Body.String())
+	}
+
+	odd := "<script>" + strings.Repeat("x", maxVaryValue)
+	if rec := get("/news", odd); rec.Header().Get("X-Alloy-Cache") != "MISS" {
+		t.Fatalf("odd: %s %q", rec.Header().Get("X-Alloy-Cache"), rec.Body.String())
+	}
+	if rec := get("/news", ""); rec.Header().Get("X-Alloy-Cache") != "MISS" || strings.Contains(rec.Body.String(), "xxxx") {
+		t.Fatalf("🔴 odd Accept-Language served to a request without it: %s %q", rec.Header().Get("X-Alloy-Cache"), rec.Body.String())
+	}
+}
+
+func TestVaryKeyNormalizesHeaders(t *testing.T) {
+	withTestConfig(t, func(cfg *Config) {
+		cfg.Vary = []string{"Accept-Language", "X-Device", "x-plan"}
+		cfg.VaryNormalize = map[string]func(string) string{
+			"X-Plan": func(value string) string {
+				if value == "pro" {
+					return "pro"
+				}
+				return "free"
+			},
+		}
+	})
+
+	key := func(headers map[string]string) string {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		return varyKey(req)
+	}
+
+	base := key(map[string]string{"Accept-Language": "en", "X-Device": "Mobile", "X-Plan": "team"})
+	for _, headers := range []map[string]string{
+		{"Accept-Language": "en-US,en;q=0.9", "X-Device": "mobile", "X-Plan": "enterprise"},
+		{"Accept-Language": "fr;q=0.1, EN-gb", "X-Device": " MOBILE ", "X-Plan": ""},
+	} {
+		if got := key(headers); got != base {
+			t.Fatalf("key(%v) = %q, want %q", headers, got, base)
+		}
+	}
+	long := key(map[string]string{"X-Device": strings.Repeat("x", maxVaryValue+1)})
+	if long == key(nil) || long == key(map[string]string{"X-Device": strings.Repeat("y", maxVaryValue+1)}) || len(long) > len(base)+32 {
+		t.Fatalf("🔴 oversized value should be hashed into the key: %q", long)
+	}
+	if got := key(map[string]string{"Accept-Language": "not a language"}); got == key(nil) {
+		t.Fatalf("🔴 invalid language shares the key of a request without the header: %q", got)
+	}
+}
@@ -0,0 +1,63 @@
+package alloy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+const vendorEntryPrefix = "_vendor-"
+
+var DefaultVendorChunk = []string{"react", "react-dom", "react-dom/client", "react/jsx-runtime"}
+
+func ParseVendorChunk(value string) (string, []string, error) {
+	name, list, ok := strings.Cut(value, "=")
+	if !ok {
+		name, list = "vendor", value
+	}
+
+	var pkgs []string
+	for _, pkg := range strings.Split(list, ",") {
+		if pkg = strings.TrimSpace(pkg); pkg != "" {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	if name == "" || len(pkgs) == 0 {
+		return "", nil, fmt.Errorf("🔴 vendor chunk %q: want [name=]pkg,...", value)
+	}
+	return name, pkgs, nil
+}
+
+func vendorEntrySource(pkgs []string) string {
+	var b strings.Builder
+	for _, pkg := range pkgs {
+		fmt.Fprintf(&b, "import %q;\n", pkg)
+	}
+	return b.String()
+}
+
+func addVendorEntries(opts *api.BuildOptions, dir string) error {
+	cfg := getConfig()
+	if cfg == nil || len(cfg.VendorChunks) == 0 {
+		return nil
+	}
+
+	for _, name := range sortedKeys(cfg.VendorChunks) {
+		entryPath := filepath.Join(dir, vendorEntryPrefix+name+".js")
+		if err := os.WriteFile(entryPath, []byte(vendorEntrySource(cfg.VendorChunks[name])), 0644); err != nil {
+			return fmt.Errorf("🔴 write vendor entry %s: %w", name, err)
+		}
+		opts.EntryPointsAdvanced = append(opts.EntryPointsAdvanced, api.EntryPoint{
+			InputPath:  entryPath,
+			OutputPath: vendorEntryPrefix + name,
+		})
+	}
+	return nil
+}
+
+func isVendorEntry(name string) bool {
+	return strings.HasPrefix(name, vendorEntryPrefix)
+}
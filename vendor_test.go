@@ -0,0 +1,84 @@
+package alloy
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+func TestParseVendorChunk(t *testing.T) {
+	name, pkgs, err := ParseVendorChunk("react=react, react-dom/client")
+	if err != nil || name != "react" || len(pkgs) != 2 || pkgs[1] != "react-dom/client" {
+		t.Fatalf("unexpected parse: %s %v %v", name, pkgs, err)
+	}
+
+	name, pkgs, err = ParseVendorChunk("preact")
+	if err != nil || name != "vendor" || len(pkgs) != 1 {
+		t.Fatalf("unnamed chunk: %s %v %v", name, pkgs, err)
+	}
+
+	if _, _, err := ParseVendorChunk("react="); err == nil {
+		t.Fatal("expected error for empty package list")
+	}
+}
+
+func TestVendorEntriesSeparateVendorCode(t *testing.T) {
+	dir := t.TempDir()
+	lib := filepath.Join(dir, "lib.js")
+	writeTestFile(t, lib, "export const vendorMarker = 'VENDOR_CODE';\n")
+	writeTestFile(t, filepath.Join(dir, "shared.js"), "export const appMarker = 'APP_CODE';\n")
+	for _, page := range []string{"home", "about"} {
+		writeTestFile(t, filepath.Join(dir, page+".js"), "import { vendorMarker } from './lib.js';\nimport { appMarker } from './shared.js';\nconsole.log(vendorMarker, appMarker);\n")
+	}
+
+	withTestConfig(t, func(cfg *Config) {
+		cfg.VendorChunks = map[string][]string{"lib": {lib}}
+	})
+
+	opts := api.BuildOptions{
+		EntryPointsAdvanced: []api.EntryPoint{
+			{InputPath: filepath.Join(dir, "home.js"), OutputPath: "home"},
+			{InputPath: filepath.Join(dir, "about.js"), OutputPath: "about"},
+		},
+		Bundle:     true,
+		Splitting:  true,
+		Format:     api.FormatESModule,
+		Outdir:     filepath.Join(dir, "out"),
+		ChunkNames: "chunk-[hash]",
+	}
+	if err := addVendorEntries(&opts, dir); err != nil {
+		t.Fatalf("add vendor entries: %v", err)
+	}
+	if len(opts.EntryPointsAdvanced) != 3 || !isVendorEntry(opts.EntryPointsAdvanced[2].OutputPath) {
+		t.Fatalf("vendor entry not added: %+v", opts.EntryPointsAdvanced)
+	}
+
+	result := api.Build(opts)
+	if err := checkBuildErrors(result, "build"); err != nil {
+		t.Fatal(err)
+	}
+
+	var vendorChunks, appChunks int
+	for _, out := range result.OutputFiles {
+		if !strings.HasPrefix(filepath.Base(out.Path), "chunk-") {
+			continue
+		}
+		contents := string(out.Contents)
+		hasVendor := strings.Contains(contents, "VENDOR_CODE")
+		hasApp := strings.Contains(contents, "APP_CODE")
+		if hasVendor && hasApp {
+			t.Fatalf("vendor and app code share a chunk:\n%s", contents)
+		}
+		if hasVendor {
+			vendorChunks++
+		}
+		if hasApp {
+			appChunks++
+		}
+	}
+	if vendorChunks != 1 || appChunks != 1 {
+		t.Fatalf("want one vendor and one app chunk, got %d and %d", vendorChunks, appChunks)
+	}
+}